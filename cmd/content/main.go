@@ -0,0 +1,172 @@
+// Command content imports and exports content packs directly against the
+// database named by DB_URL, without a running server.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/76dillon/battle_squads/internal/config"
+	"github.com/76dillon/battle_squads/internal/content"
+	"github.com/76dillon/battle_squads/internal/migrate"
+	"github.com/76dillon/battle_squads/internal/store"
+	_ "github.com/lib/pq"  // register postgres driver
+	_ "modernc.org/sqlite" // register sqlite driver
+)
+
+const usage = `usage:
+  content import [--dry-run] [--admin-id=ID] FILE   apply a content pack (- reads stdin)
+  content export [--name=NAME] [--version=V] [FILE] write all content as a pack (default stdout)
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "import":
+		err = runImport(os.Args[2:])
+	case "export":
+		err = runExport(os.Args[2:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "show what would change without changing anything")
+	adminID := fs.Int64("admin-id", 1, "admin player recorded in the audit log")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var r io.Reader = os.Stdin
+	if path := fs.Arg(0); path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	pack, err := content.ReadPack(r)
+	if err != nil {
+		return err
+	}
+
+	st, db, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	p, err := st.GetPlayerByID(ctx, *adminID)
+	if err != nil || !p.IsAdmin {
+		return fmt.Errorf("player %d is not an admin", *adminID)
+	}
+
+	result, err := content.NewService(st).Import(ctx, *adminID, pack, *dryRun)
+	if err != nil {
+		return err
+	}
+	for _, c := range result.Changes {
+		fmt.Printf("%-6s %-18s %s\n", c.Action, c.Entity, c.Name)
+		if c.Action == content.ActionUpdate {
+			before, _ := json.Marshal(c.Before)
+			after, _ := json.Marshal(c.After)
+			fmt.Printf("         - %s\n         + %s\n", before, after)
+		}
+	}
+	if result.DryRun {
+		fmt.Printf("dry run: %d changes, %d unchanged (nothing was written)\n", len(result.Changes), result.Unchanged)
+	} else {
+		fmt.Printf("imported %s: %d changes, %d unchanged\n", pack.Name, len(result.Changes), result.Unchanged)
+	}
+	return nil
+}
+
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	name := fs.String("name", "export", "pack name")
+	version := fs.String("version", "", "pack version")
+	fs.Parse(args)
+	if fs.NArg() > 1 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	st, db, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	pack, err := content.NewService(st).Export(context.Background(), *name, *version)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if fs.NArg() == 1 {
+		f, err := os.Create(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(pack)
+}
+
+// openStore connects to DB_URL and checks the schema is up to date.
+func openStore() (store.Store, *sql.DB, error) {
+	cfg, err := config.Load("db")
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading config: %w", err)
+	}
+	db, err := sql.Open(cfg.Driver, cfg.DatabaseURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error connecting to db: %w", err)
+	}
+	if cfg.Driver == "sqlite" {
+		db.SetMaxOpenConns(1)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, nil, fmt.Errorf("error pinging db: %w", err)
+	}
+
+	m, err := migrate.New(db, cfg.Driver)
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	if err := m.Check(context.Background()); err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+
+	if cfg.Driver == "sqlite" {
+		return store.NewSQLiteStore(db), db, nil
+	}
+	return store.NewSQLStore(db), db, nil
+}
@@ -1,58 +1,123 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
 
 	"github.com/76dillon/battle_squads/internal/config"
+	"github.com/76dillon/battle_squads/internal/content"
+	"github.com/76dillon/battle_squads/internal/devutil"
 	"github.com/76dillon/battle_squads/internal/game"
 	httpapi "github.com/76dillon/battle_squads/internal/http"
+	"github.com/76dillon/battle_squads/internal/migrate"
+	"github.com/76dillon/battle_squads/internal/player"
+	"github.com/76dillon/battle_squads/internal/squad"
 	"github.com/76dillon/battle_squads/internal/store"
-	_ "github.com/lib/pq" // register postgres driver
+	_ "github.com/lib/pq"  // register postgres driver
+	_ "modernc.org/sqlite" // register sqlite driver
 )
 
+const usage = `usage:
+  server [--storage=db|memory] [--migrate]   run the HTTP server
+  server migrate status|up|down              manage the database schema
+`
+
 func main() {
+	storage := flag.String("storage", "db", "storage backend: db (Postgres or SQLite, from DB_URL) or memory")
+	autoMigrate := flag.Bool("migrate", false, "apply pending migrations before starting the server")
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	// migrate subcommand: only needs the database, not the server
+	if flag.Arg(0) == "migrate" {
+		if err := runMigrate(flag.Arg(1)); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if flag.NArg() > 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
 
 	// 1. Load config
-	cfg, err := config.Load()
+	cfg, err := config.Load(*storage)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error reading config: %v\n", err)
 		os.Exit(1)
 	}
-	// 2. Open DB: sql.Open("postgres", cfg.DatabaseURL)
-	db, err := sql.Open("postgres", cfg.DatabaseURL)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "error connecting to db: %v", err)
-		os.Exit(1)
-	}
 
-	// 3. Ping DB to verify connection
-	if err := db.Ping(); err != nil {
-		fmt.Fprintf(os.Stderr, "error pinging db: %v\n", err)
-		os.Exit(1)
-	}
-	fmt.Println("successfully connected to database")
+	// 2. Open the store
+	var st store.Store
+	switch cfg.Storage {
+	case "memory":
+		// Nothing is persisted; seed the starter content so the API is usable
+		mem := store.NewMemoryStore()
+		if err := devutil.SeedDemoContent(context.Background(), mem); err != nil {
+			fmt.Fprintf(os.Stderr, "error seeding memory store: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("using in-memory storage (data is lost on exit)")
+		st = mem
+	default:
+		db, err := openDB(cfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("successfully connected to", cfg.Driver, "database")
 
-	// 4. Defer db.Close()
-	defer db.Close()
+		// 2c. Defer db.Close()
+		defer db.Close()
 
-	// 5. Create store := store.New(db)
-	q := store.New(db) // or whatever constructor sqlc generated
+		// 2d. Bring the schema up to date, or refuse to run against an old one
+		m, err := migrate.New(db, cfg.Driver)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		ctx := context.Background()
+		if *autoMigrate {
+			if err := m.Up(ctx, os.Stdout); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+		}
+		if err := m.Check(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
 
-	// 6. Create Game Service
-	svc := game.NewService(db)
+		if cfg.Driver == "sqlite" {
+			st = store.NewSQLiteStore(db)
+		} else {
+			st = store.NewSQLStore(db)
+		}
+	}
 
-	// For Dev purposes only - delete when done!!!
-	//ctx := context.Background()
-	//if err := devutil.ResetDB(ctx, db); err != nil {
-	//	fmt.Fprintf(os.Stderr, "error resetting db: %v\n", err)
-	//	os.Exit(1)
-	//}
+	// 3. Create Game, Squad and Content Services
+	chat := game.DefaultChatRules()
+	chat.Filter = game.NewWordFilter(cfg.ChatBlockedWords)
+	svc := game.NewService(st, chat)
+	squads := squad.NewService(st, squad.Rules{
+		MinSize:             cfg.SquadMinSize,
+		MaxSize:             cfg.SquadMaxSize,
+		AllowDuplicateUnits: cfg.SquadAllowDuplicateUnits,
+		MaxNameLength:       squad.DefaultRules().MaxNameLength,
+		MaxBagSize:          squad.DefaultRules().MaxBagSize,
+	})
 
-	// 7. Start HTTP server on cfg.HTTPPort
-	api := httpapi.NewServer(q, svc)
+	// 4. Start HTTP server on cfg.HTTPPort
+	api := httpapi.NewServer(st, svc, squads, player.NewService(st), content.NewService(st))
+	go api.ExpireIdempotencyKeys(context.Background())
 
 	addr := ":" + cfg.HTTPPort
 	fmt.Println("listening on", addr)
@@ -61,3 +126,53 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// openDB opens and pings the database named by DB_URL.
+func openDB(cfg *config.Config) (*sql.DB, error) {
+	// 2a. Open DB: sql.Open(cfg.Driver, cfg.DatabaseURL)
+	db, err := sql.Open(cfg.Driver, cfg.DatabaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to db: %w", err)
+	}
+	if cfg.Driver == "sqlite" {
+		// SQLite allows one writer at a time anyway
+		db.SetMaxOpenConns(1)
+	}
+
+	// 2b. Ping DB to verify connection
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error pinging db: %w", err)
+	}
+	return db, nil
+}
+
+// runMigrate implements `server migrate status|up|down`.
+func runMigrate(cmd string) error {
+	cfg, err := config.Load("db")
+	if err != nil {
+		return fmt.Errorf("error reading config: %w", err)
+	}
+	db, err := openDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	m, err := migrate.New(db, cfg.Driver)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch cmd {
+	case "status":
+		return m.Status(ctx, os.Stdout)
+	case "up":
+		return m.Up(ctx, os.Stdout)
+	case "down":
+		return m.Down(ctx, os.Stdout)
+	default:
+		return fmt.Errorf("unknown migrate command %q (want status, up or down)", cmd)
+	}
+}
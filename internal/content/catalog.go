@@ -0,0 +1,151 @@
+package content
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/76dillon/battle_squads/internal/store"
+	"github.com/76dillon/battle_squads/internal/validation"
+)
+
+// UnitFilter narrows and orders the unit catalog. Zero values mean no filter.
+type UnitFilter struct {
+	// Type is a unit type ID or name
+	Type string
+	// Name matches units whose name contains it, ignoring case
+	Name string
+	// Sort is one of UnitSortFields, prefixed with "-" for descending order.
+	// The default is by ID.
+	Sort string
+}
+
+// UnitSortFields are the values UnitFilter.Sort accepts.
+var UnitSortFields = []string{"id", "name", "base_hp", "base_attack", "base_speed"}
+
+// ListUnits returns the units that have not been deleted, filtered and
+// sorted by f. The catalog is small, so this is done in memory.
+func (s *Service) ListUnits(ctx context.Context, f UnitFilter) ([]store.Unit, error) {
+	verr := validation.New("query")
+	desc := strings.HasPrefix(f.Sort, "-")
+	less := unitLess(strings.TrimPrefix(f.Sort, "-"))
+	if less == nil {
+		verr.Add("sort", "must be one of %s, optionally prefixed with -", strings.Join(UnitSortFields, ", "))
+	}
+
+	typeID := int64(-1)
+	if f.Type != "" {
+		id, err := s.resolveType(ctx, f.Type)
+		if err != nil {
+			return nil, err
+		}
+		if id == 0 {
+			verr.Add("type", "unknown type %q", f.Type)
+		}
+		typeID = id
+	}
+	if err := verr.Err(); err != nil {
+		return nil, err
+	}
+
+	units, err := s.store.ListUnits(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list units: %w", err)
+	}
+	name := strings.ToLower(f.Name)
+	out := units[:0]
+	for _, u := range units {
+		if typeID >= 0 && u.TypeID != typeID {
+			continue
+		}
+		if name != "" && !strings.Contains(strings.ToLower(u.Name), name) {
+			continue
+		}
+		out = append(out, u)
+	}
+
+	sort.SliceStable(out, func(a, b int) bool {
+		if desc {
+			return less(out[b], out[a])
+		}
+		return less(out[a], out[b])
+	})
+	return out, nil
+}
+
+func unitLess(field string) func(a, b store.Unit) bool {
+	switch field {
+	case "", "id":
+		return func(a, b store.Unit) bool { return a.ID < b.ID }
+	case "name":
+		return func(a, b store.Unit) bool { return strings.ToLower(a.Name) < strings.ToLower(b.Name) }
+	case "base_hp":
+		return func(a, b store.Unit) bool { return a.BaseHp < b.BaseHp }
+	case "base_attack":
+		return func(a, b store.Unit) bool { return a.BaseAttack < b.BaseAttack }
+	case "base_speed":
+		return func(a, b store.Unit) bool { return a.BaseSpeed < b.BaseSpeed }
+	}
+	return nil
+}
+
+// ListMoves returns the moves that have not been deleted, optionally only
+// those of typ (a type ID or name).
+func (s *Service) ListMoves(ctx context.Context, typ string) ([]store.Move, error) {
+	typeID := int64(-1)
+	if typ != "" {
+		id, err := s.resolveType(ctx, typ)
+		if err != nil {
+			return nil, err
+		}
+		if id == 0 {
+			verr := validation.New("query")
+			verr.Add("type", "unknown type %q", typ)
+			return nil, verr
+		}
+		typeID = id
+	}
+
+	moves, err := s.store.ListMoves(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list moves: %w", err)
+	}
+	out := moves[:0]
+	for _, mv := range moves {
+		if typeID < 0 || mv.TypeID == typeID {
+			out = append(out, mv)
+		}
+	}
+	return out, nil
+}
+
+// GetUnit returns a unit that has not been deleted, with its learnset.
+func (s *Service) GetUnit(ctx context.Context, id int64) (store.Unit, []store.Move, error) {
+	u, err := getLiveUnit(ctx, s.store, id)
+	if err != nil {
+		return store.Unit{}, nil, err
+	}
+	moves, err := s.store.ListMovesForUnit(ctx, id)
+	if err != nil {
+		return store.Unit{}, nil, fmt.Errorf("list moves for unit: %w", err)
+	}
+	return u, moves, nil
+}
+
+// resolveType returns the ID of the type with ID or name typ, or 0 if there
+// is none.
+func (s *Service) resolveType(ctx context.Context, typ string) (int64, error) {
+	types, err := s.store.ListUnitTypes(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("list unit types: %w", err)
+	}
+	id, _ := strconv.ParseInt(typ, 10, 64)
+	for _, t := range types {
+		if t.ID == id || strings.EqualFold(t.Name, typ) {
+			return t.ID, nil
+		}
+	}
+	return 0, nil
+}
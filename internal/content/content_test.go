@@ -0,0 +1,107 @@
+package content_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/76dillon/battle_squads/internal/content"
+	"github.com/76dillon/battle_squads/internal/devutil"
+	"github.com/76dillon/battle_squads/internal/squad"
+	"github.com/76dillon/battle_squads/internal/store"
+	"github.com/76dillon/battle_squads/internal/validation"
+)
+
+// adminID is the dev admin the migrations seed.
+const adminID = 1
+
+// testEnv is a store with the demo content and the services over it.
+type testEnv struct {
+	st      store.Store
+	content *content.Service
+	squads  *squad.Service
+}
+
+func newTestEnv(t *testing.T, st store.Store) *testEnv {
+	t.Helper()
+	if err := devutil.SeedDemoContent(context.Background(), st); err != nil {
+		t.Fatal(err)
+	}
+	return &testEnv{
+		st:      st,
+		content: content.NewService(st),
+		squads:  squad.NewService(st, squad.DefaultRules()),
+	}
+}
+
+// unit returns the demo unit called name.
+func (e *testEnv) unit(t *testing.T, name string) store.Unit {
+	t.Helper()
+	units, err := e.st.ListUnits(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, u := range units {
+		if u.Name == name {
+			return u
+		}
+	}
+	t.Fatalf("no unit %q", name)
+	return store.Unit{}
+}
+
+// newSquad creates a player with a squad of unitIDs and returns the squad.
+func (e *testEnv) newSquad(t *testing.T, name string, unitIDs ...int64) (int64, store.Squad) {
+	t.Helper()
+	ctx := context.Background()
+	p, err := e.st.CreatePlayer(ctx, store.CreatePlayerParams{Username: name, PasswordHash: "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sq, err := e.squads.Create(ctx, p.ID, name+"'s squad", unitIDs, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p.ID, sq
+}
+
+// auditCount returns how many admin changes have been recorded.
+func (e *testEnv) auditCount(t *testing.T) int {
+	t.Helper()
+	entries, err := e.st.ListAdminAuditEntries(context.Background(), 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(entries)
+}
+
+// wantProblems fails unless err is a validation error with a problem for
+// each of fields, in order.
+func wantProblems(t *testing.T, err error, fields ...string) {
+	t.Helper()
+	var verr *validation.Error
+	if !errors.As(err, &verr) {
+		t.Fatalf("got %v, want a validation error", err)
+	}
+	var got []string
+	for _, p := range verr.Problems {
+		got = append(got, p.Field)
+	}
+	if len(got) != len(fields) {
+		t.Fatalf("problems with %v, want %v", got, fields)
+	}
+	for i := range fields {
+		if got[i] != fields[i] {
+			t.Fatalf("problems with %v, want %v", got, fields)
+		}
+	}
+}
+
+// wantInUse fails unless err is content.ErrInUse.
+func wantInUse(t *testing.T, err error) {
+	t.Helper()
+	var inUse content.ErrInUse
+	if !errors.As(err, &inUse) {
+		t.Fatalf("got %v, want ErrInUse", err)
+	}
+}
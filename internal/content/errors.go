@@ -0,0 +1,14 @@
+package content
+
+type ErrNotFound struct {
+	Msg string
+}
+
+func (e ErrNotFound) Error() string { return e.Msg }
+
+// ErrInUse is returned when deleting content that active squads depend on.
+type ErrInUse struct {
+	Msg string
+}
+
+func (e ErrInUse) Error() string { return e.Msg }
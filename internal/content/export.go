@@ -0,0 +1,67 @@
+package content
+
+import (
+	"context"
+	"fmt"
+)
+
+// Export returns all live content as a pack named name. Deleted units and
+// moves are left out.
+func (s *Service) Export(ctx context.Context, name, version string) (Pack, error) {
+	p := Pack{
+		FormatVersion: PackFormatVersion,
+		Name:          name,
+		Version:       version,
+		Types:         []string{},
+		Moves:         []PackMove{},
+		Units:         []PackUnit{},
+	}
+
+	types, err := s.store.ListUnitTypes(ctx)
+	if err != nil {
+		return Pack{}, fmt.Errorf("list unit types: %w", err)
+	}
+	// Only the importer's type names are needed to turn rows into pack entries
+	imp := &importer{typeNames: make(map[int64]string, len(types))}
+	for _, t := range types {
+		p.Types = append(p.Types, t.Name)
+		imp.typeNames[t.ID] = t.Name
+	}
+
+	chart, err := s.store.ListTypeEffectiveness(ctx)
+	if err != nil {
+		return Pack{}, fmt.Errorf("list type effectiveness: %w", err)
+	}
+	for _, te := range chart {
+		p.Effectiveness = append(p.Effectiveness, PackEffectiveness{
+			Attack:     imp.typeNames[te.AttackTypeID],
+			Defend:     imp.typeNames[te.DefendTypeID],
+			Multiplier: float64(te.MultiplierPercent) / 100,
+		})
+	}
+
+	moves, err := s.store.ListMoves(ctx)
+	if err != nil {
+		return Pack{}, fmt.Errorf("list moves: %w", err)
+	}
+	for _, mv := range moves {
+		p.Moves = append(p.Moves, imp.packMove(mv))
+	}
+
+	units, err := s.store.ListUnits(ctx)
+	if err != nil {
+		return Pack{}, fmt.Errorf("list units: %w", err)
+	}
+	for _, u := range units {
+		pu := imp.packUnit(u)
+		learnset, err := s.store.ListMovesForUnit(ctx, u.ID)
+		if err != nil {
+			return Pack{}, fmt.Errorf("list moves for unit %s: %w", u.Name, err)
+		}
+		for _, mv := range learnset {
+			pu.Moves = append(pu.Moves, mv.Name)
+		}
+		p.Units = append(p.Units, pu)
+	}
+	return p, nil
+}
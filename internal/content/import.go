@@ -0,0 +1,478 @@
+package content
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/76dillon/battle_squads/internal/store"
+	"github.com/76dillon/battle_squads/internal/validation"
+)
+
+// Audit log entity types and actions used only by pack imports
+const (
+	EntityTypeEffectiveness = "type_effectiveness"
+	EntityContentPack       = "content_pack"
+
+	ActionImport = "import"
+)
+
+// MaxMultiplier is the largest type chart multiplier a pack may use.
+const MaxMultiplier = 4
+
+// Change is one create or update an import made, or would make on a dry run.
+type Change struct {
+	Action string `json:"action"`
+	Entity string `json:"entity"`
+	Name   string `json:"name"`
+	Before any    `json:"before,omitempty"`
+	After  any    `json:"after,omitempty"`
+}
+
+type ImportResult struct {
+	DryRun  bool     `json:"dry_run"`
+	Changes []Change `json:"changes"`
+	// Unchanged counts pack entries that already matched the database
+	Unchanged int `json:"unchanged"`
+}
+
+// errDryRun rolls back a dry-run import once its changes are known.
+var errDryRun = errors.New("dry run")
+
+// Import applies p in one transaction. Types, units and moves are matched by
+// name: missing ones are created and ones that differ are updated. Learnset
+// entries and type chart entries are added or updated. Nothing is deleted, so
+// content the pack doesn't mention is left alone.
+//
+// With dryRun the import runs in full, including validation, and is then
+// rolled back, so the result lists exactly what a real import would change.
+func (s *Service) Import(ctx context.Context, adminID int64, p Pack, dryRun bool) (ImportResult, error) {
+	result := ImportResult{DryRun: dryRun}
+	err := s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		imp, err := newImporter(ctx, qtx, adminID)
+		if err != nil {
+			return err
+		}
+		if err := imp.validate(p); err != nil {
+			return err
+		}
+
+		//1. Types first, since everything else refers to them
+		for _, name := range p.Types {
+			if err := imp.importType(name); err != nil {
+				return err
+			}
+		}
+		//2. Moves before units, so learnsets can refer to new moves
+		for _, pm := range p.Moves {
+			if err := imp.importMove(pm); err != nil {
+				return err
+			}
+		}
+		for _, pu := range p.Units {
+			if err := imp.importUnit(pu); err != nil {
+				return err
+			}
+		}
+		//3. Type chart
+		for _, pe := range p.Effectiveness {
+			if err := imp.importEffectiveness(pe); err != nil {
+				return err
+			}
+		}
+
+		result.Changes = imp.changes
+		result.Unchanged = imp.unchanged
+		if dryRun {
+			return errDryRun
+		}
+		return audit(ctx, qtx, adminID, ActionImport, EntityContentPack, 0, struct {
+			Name      string `json:"name"`
+			Version   string `json:"version,omitempty"`
+			Changes   int    `json:"changes"`
+			Unchanged int    `json:"unchanged"`
+		}{p.Name, p.Version, len(imp.changes), imp.unchanged})
+	})
+	if errors.Is(err, errDryRun) {
+		err = nil
+	}
+	if err != nil {
+		return ImportResult{}, err
+	}
+	return result, nil
+}
+
+// importer holds the live content by name while a pack is applied.
+type importer struct {
+	ctx     context.Context
+	q       store.Querier
+	adminID int64
+
+	// types is keyed by lower-cased name, as type names are unique
+	// regardless of case
+	types     map[string]store.UnitType
+	typeNames map[int64]string
+	units     map[string][]store.Unit
+	moves     map[string][]store.Move
+	chart     map[[2]int64]int32
+
+	changes   []Change
+	unchanged int
+}
+
+func newImporter(ctx context.Context, q store.Querier, adminID int64) (*importer, error) {
+	imp := &importer{
+		ctx:       ctx,
+		q:         q,
+		adminID:   adminID,
+		types:     make(map[string]store.UnitType),
+		typeNames: make(map[int64]string),
+		units:     make(map[string][]store.Unit),
+		moves:     make(map[string][]store.Move),
+		chart:     make(map[[2]int64]int32),
+	}
+
+	types, err := q.ListUnitTypes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list unit types: %w", err)
+	}
+	for _, t := range types {
+		imp.addType(t)
+	}
+	units, err := q.ListUnits(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list units: %w", err)
+	}
+	for _, u := range units {
+		imp.units[u.Name] = append(imp.units[u.Name], u)
+	}
+	moves, err := q.ListMoves(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list moves: %w", err)
+	}
+	for _, mv := range moves {
+		imp.moves[mv.Name] = append(imp.moves[mv.Name], mv)
+	}
+	chart, err := q.ListTypeEffectiveness(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list type effectiveness: %w", err)
+	}
+	for _, te := range chart {
+		imp.chart[[2]int64{te.AttackTypeID, te.DefendTypeID}] = te.MultiplierPercent
+	}
+	return imp, nil
+}
+
+func (imp *importer) addType(t store.UnitType) {
+	imp.types[strings.ToLower(t.Name)] = t
+	imp.typeNames[t.ID] = t.Name
+}
+
+func (imp *importer) typeID(name string) int64 {
+	return imp.types[strings.ToLower(name)].ID
+}
+
+// validate checks the whole pack against itself and the live content before
+// anything is written, reporting every problem at once.
+func (imp *importer) validate(p Pack) error {
+	verr := validation.New("content pack")
+	if p.FormatVersion != PackFormatVersion {
+		verr.Add("format_version", "unsupported format version %d, want %d", p.FormatVersion, PackFormatVersion)
+	}
+	validateName(verr, "name", p.Name)
+
+	knownTypes := make(map[string]bool)
+	for name := range imp.types {
+		knownTypes[name] = true
+	}
+	packTypes := make(map[string]bool)
+	for i, name := range p.Types {
+		field := fmt.Sprintf("types[%d]", i)
+		validateName(verr, field, name)
+		if packTypes[strings.ToLower(name)] {
+			verr.Add(field, "type %q is listed twice", name)
+		}
+		packTypes[strings.ToLower(name)] = true
+		knownTypes[strings.ToLower(name)] = true
+	}
+	checkType := func(field, name string) {
+		if !knownTypes[strings.ToLower(name)] {
+			verr.Add(field, "unknown type %q", name)
+		}
+	}
+
+	knownMoves := make(map[string]bool)
+	for name := range imp.moves {
+		knownMoves[name] = true
+	}
+	packMoves := make(map[string]bool)
+	for i, pm := range p.Moves {
+		prefix := fmt.Sprintf("moves[%d].", i)
+		validateName(verr, prefix+"name", pm.Name)
+		if packMoves[pm.Name] {
+			verr.Add(prefix+"name", "move %q is listed twice", pm.Name)
+		}
+		if n := len(imp.moves[pm.Name]); n > 1 {
+			verr.Add(prefix+"name", "matches %d existing moves; rename or delete the duplicates first", n)
+		}
+		packMoves[pm.Name] = true
+		knownMoves[pm.Name] = true
+		checkType(prefix+"type", pm.Type)
+		validateRange(verr, prefix+"power", pm.Power, MinPower, MaxPower)
+		validateRange(verr, prefix+"accuracy", pm.Accuracy, MinAccuracy, MaxAccuracy)
+	}
+
+	packUnits := make(map[string]bool)
+	for i, pu := range p.Units {
+		prefix := fmt.Sprintf("units[%d].", i)
+		validateName(verr, prefix+"name", pu.Name)
+		if packUnits[pu.Name] {
+			verr.Add(prefix+"name", "unit %q is listed twice", pu.Name)
+		}
+		if n := len(imp.units[pu.Name]); n > 1 {
+			verr.Add(prefix+"name", "matches %d existing units; rename or delete the duplicates first", n)
+		}
+		packUnits[pu.Name] = true
+		checkType(prefix+"type", pu.Type)
+		validateRange(verr, prefix+"base_hp", pu.BaseHP, MinBaseStat, MaxBaseStat)
+		validateRange(verr, prefix+"base_attack", pu.BaseAttack, MinBaseStat, MaxBaseStat)
+		validateRange(verr, prefix+"base_speed", pu.BaseSpeed, MinBaseStat, MaxBaseStat)
+		validateAbility(verr, prefix+"ability", pu.Ability)
+		for j, moveName := range pu.Moves {
+			field := fmt.Sprintf("%smoves[%d]", prefix, j)
+			if !knownMoves[moveName] {
+				verr.Add(field, "unknown move %q", moveName)
+			} else if !packMoves[moveName] && len(imp.moves[moveName]) > 1 {
+				verr.Add(field, "move %q is ambiguous: %d moves have that name", moveName, len(imp.moves[moveName]))
+			}
+		}
+	}
+
+	pairs := make(map[[2]string]bool)
+	for i, pe := range p.Effectiveness {
+		prefix := fmt.Sprintf("effectiveness[%d].", i)
+		checkType(prefix+"attack", pe.Attack)
+		checkType(prefix+"defend", pe.Defend)
+		pair := [2]string{strings.ToLower(pe.Attack), strings.ToLower(pe.Defend)}
+		if pairs[pair] {
+			verr.Add(prefix+"defend", "%s against %s is listed twice", pe.Attack, pe.Defend)
+		}
+		pairs[pair] = true
+		if pe.Multiplier < 0 || pe.Multiplier > MaxMultiplier {
+			verr.Add(prefix+"multiplier", "must be between 0 and %d, got %g", MaxMultiplier, pe.Multiplier)
+		}
+	}
+	return verr.Err()
+}
+
+// record notes a change and writes its audit row. details is what the admin
+// endpoints would log for the same change.
+func (imp *importer) record(c Change, entityID int64, details any) error {
+	imp.changes = append(imp.changes, c)
+	return audit(imp.ctx, imp.q, imp.adminID, c.Action, c.Entity, entityID, details)
+}
+
+func (imp *importer) importType(name string) error {
+	if _, ok := imp.types[strings.ToLower(name)]; ok {
+		imp.unchanged++
+		return nil
+	}
+	t, err := imp.q.CreateUnitType(imp.ctx, name)
+	if err != nil {
+		return fmt.Errorf("create unit type %s: %w", name, err)
+	}
+	imp.addType(t)
+	return imp.record(Change{Action: ActionCreate, Entity: EntityUnitType, Name: name}, t.ID, unitTypeDetails{Name: name})
+}
+
+func (imp *importer) importMove(pm PackMove) error {
+	in := MoveInput{
+		Name:     pm.Name,
+		Power:    pm.Power,
+		Accuracy: pm.Accuracy,
+		TypeID:   imp.typeID(pm.Type),
+	}
+
+	existing := imp.moves[pm.Name]
+	if len(existing) == 0 {
+		mv, err := imp.q.CreateMove(imp.ctx, store.CreateMoveParams{
+			Name:     in.Name,
+			Power:    in.Power,
+			Accuracy: in.Accuracy,
+			TypeID:   in.TypeID,
+		})
+		if err != nil {
+			return fmt.Errorf("create move %s: %w", pm.Name, err)
+		}
+		imp.moves[pm.Name] = []store.Move{mv}
+		return imp.record(Change{Action: ActionCreate, Entity: EntityMove, Name: pm.Name, After: pm}, mv.ID, in)
+	}
+
+	old := existing[0]
+	if moveInput(old) == in {
+		imp.unchanged++
+		return nil
+	}
+	mv, err := imp.q.UpdateMove(imp.ctx, store.UpdateMoveParams{
+		ID:       old.ID,
+		Name:     in.Name,
+		Power:    in.Power,
+		Accuracy: in.Accuracy,
+		TypeID:   in.TypeID,
+	})
+	if err != nil {
+		return fmt.Errorf("update move %s: %w", pm.Name, err)
+	}
+	imp.moves[pm.Name] = []store.Move{mv}
+	return imp.record(
+		Change{Action: ActionUpdate, Entity: EntityMove, Name: pm.Name, Before: imp.packMove(old), After: pm},
+		mv.ID, change{Before: moveInput(old), After: in},
+	)
+}
+
+func (imp *importer) importUnit(pu PackUnit) error {
+	in := UnitInput{
+		Name:       pu.Name,
+		TypeID:     imp.typeID(pu.Type),
+		BaseHP:     pu.BaseHP,
+		BaseAttack: pu.BaseAttack,
+		BaseSpeed:  pu.BaseSpeed,
+		Ability:    pu.Ability,
+	}
+	// The learnset is compared separately below
+	after := pu
+	after.Moves = nil
+
+	var u store.Unit
+	existing := imp.units[pu.Name]
+	switch {
+	case len(existing) == 0:
+		var err error
+		u, err = imp.q.CreateUnit(imp.ctx, store.CreateUnitParams{
+			Name:       in.Name,
+			TypeID:     in.TypeID,
+			BaseHp:     in.BaseHP,
+			BaseAttack: in.BaseAttack,
+			BaseSpeed:  in.BaseSpeed,
+			Ability:    in.Ability,
+		})
+		if err != nil {
+			return fmt.Errorf("create unit %s: %w", pu.Name, err)
+		}
+		if err := imp.record(Change{Action: ActionCreate, Entity: EntityUnit, Name: pu.Name, After: after}, u.ID, in); err != nil {
+			return err
+		}
+	case unitInput(existing[0]) == in:
+		u = existing[0]
+		imp.unchanged++
+	default:
+		old := existing[0]
+		var err error
+		u, err = imp.q.UpdateUnit(imp.ctx, store.UpdateUnitParams{
+			ID:         old.ID,
+			Name:       in.Name,
+			TypeID:     in.TypeID,
+			BaseHp:     in.BaseHP,
+			BaseAttack: in.BaseAttack,
+			BaseSpeed:  in.BaseSpeed,
+			Ability:    in.Ability,
+		})
+		if err != nil {
+			return fmt.Errorf("update unit %s: %w", pu.Name, err)
+		}
+		before := imp.packUnit(old)
+		if err := imp.record(
+			Change{Action: ActionUpdate, Entity: EntityUnit, Name: pu.Name, Before: before, After: after},
+			u.ID, change{Before: unitInput(old), After: in},
+		); err != nil {
+			return err
+		}
+	}
+	imp.units[pu.Name] = []store.Unit{u}
+
+	//--Learnset: add the moves the unit doesn't know yet
+	known, err := imp.q.ListMovesForUnit(imp.ctx, u.ID)
+	if err != nil {
+		return fmt.Errorf("list moves for unit %s: %w", pu.Name, err)
+	}
+	knows := make(map[int64]bool, len(known))
+	for _, mv := range known {
+		knows[mv.ID] = true
+	}
+	for _, moveName := range pu.Moves {
+		moveID := imp.moves[moveName][0].ID
+		if knows[moveID] {
+			imp.unchanged++
+			continue
+		}
+		if _, err := imp.q.CreateUnitMove(imp.ctx, store.CreateUnitMoveParams{UnitID: u.ID, MoveID: moveID}); err != nil {
+			return fmt.Errorf("assign move %s to %s: %w", moveName, pu.Name, err)
+		}
+		knows[moveID] = true
+		if err := imp.record(
+			Change{Action: ActionCreate, Entity: EntityUnitMove, Name: pu.Name + ": " + moveName},
+			u.ID, unitMoveDetails{MoveID: moveID},
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type effectivenessDetails struct {
+	DefendTypeID      int64 `json:"defend_type_id"`
+	MultiplierPercent int32 `json:"multiplier_percent"`
+}
+
+func (imp *importer) importEffectiveness(pe PackEffectiveness) error {
+	attackID, defendID := imp.typeID(pe.Attack), imp.typeID(pe.Defend)
+	percent := int32(math.Round(pe.Multiplier * 100))
+	name := pe.Attack + " vs " + pe.Defend
+
+	old, exists := imp.chart[[2]int64{attackID, defendID}]
+	if exists && old == percent {
+		imp.unchanged++
+		return nil
+	}
+	if _, err := imp.q.UpsertTypeEffectiveness(imp.ctx, store.UpsertTypeEffectivenessParams{
+		AttackTypeID:      attackID,
+		DefendTypeID:      defendID,
+		MultiplierPercent: percent,
+	}); err != nil {
+		return fmt.Errorf("set type effectiveness %s: %w", name, err)
+	}
+	imp.chart[[2]int64{attackID, defendID}] = percent
+
+	details := effectivenessDetails{DefendTypeID: defendID, MultiplierPercent: percent}
+	if !exists {
+		return imp.record(Change{Action: ActionCreate, Entity: EntityTypeEffectiveness, Name: name, After: pe}, attackID, details)
+	}
+	before := pe
+	before.Multiplier = float64(old) / 100
+	return imp.record(
+		Change{Action: ActionUpdate, Entity: EntityTypeEffectiveness, Name: name, Before: before, After: pe},
+		attackID, change{Before: effectivenessDetails{DefendTypeID: defendID, MultiplierPercent: old}, After: details},
+	)
+}
+
+func (imp *importer) packMove(mv store.Move) PackMove {
+	return PackMove{
+		Name:     mv.Name,
+		Type:     imp.typeNames[mv.TypeID],
+		Power:    mv.Power,
+		Accuracy: mv.Accuracy,
+	}
+}
+
+func (imp *importer) packUnit(u store.Unit) PackUnit {
+	return PackUnit{
+		Name:       u.Name,
+		Type:       imp.typeNames[u.TypeID],
+		BaseHP:     u.BaseHp,
+		BaseAttack: u.BaseAttack,
+		BaseSpeed:  u.BaseSpeed,
+		Ability:    u.Ability,
+	}
+}
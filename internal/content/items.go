@@ -0,0 +1,106 @@
+package content
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/76dillon/battle_squads/internal/store"
+)
+
+func (s *Service) CreateItem(ctx context.Context, adminID int64, in ItemInput) (store.Item, error) {
+	var it store.Item
+	err := s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		if err := validateItem(in); err != nil {
+			return err
+		}
+		var err error
+		it, err = qtx.CreateItem(ctx, store.CreateItemParams{
+			Name:   in.Name,
+			Kind:   in.Kind,
+			Effect: in.Effect,
+			Amount: in.Amount,
+		})
+		if err != nil {
+			return fmt.Errorf("create item: %w", err)
+		}
+		return audit(ctx, qtx, adminID, ActionCreate, EntityItem, it.ID, in)
+	})
+	return it, err
+}
+
+// UpdateItem replaces an item's name, kind, effect and amount. Matches read
+// the item when it takes effect, so changes reach matches in progress. An
+// item some squad is carrying can't change kind, as it would no longer fit
+// where the squad carries it.
+func (s *Service) UpdateItem(ctx context.Context, adminID, id int64, in ItemInput) (store.Item, error) {
+	var it store.Item
+	err := s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		old, err := getLiveItem(ctx, qtx, id)
+		if err != nil {
+			return err
+		}
+		if err := validateItem(in); err != nil {
+			return err
+		}
+		if in.Kind != old.Kind {
+			if err := checkItemNotInSquads(ctx, qtx, id); err != nil {
+				return err
+			}
+		}
+		it, err = qtx.UpdateItem(ctx, store.UpdateItemParams{
+			ID:     id,
+			Name:   in.Name,
+			Kind:   in.Kind,
+			Effect: in.Effect,
+			Amount: in.Amount,
+		})
+		if err != nil {
+			return fmt.Errorf("update item: %w", err)
+		}
+		return audit(ctx, qtx, adminID, ActionUpdate, EntityItem, id, change{Before: itemInput(old), After: in})
+	})
+	return it, err
+}
+
+// DeleteItem soft-deletes an item so squads can no longer carry it. Items
+// carried by a non-deleted squad cannot be deleted.
+func (s *Service) DeleteItem(ctx context.Context, adminID, id int64) error {
+	return s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		old, err := getLiveItem(ctx, qtx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkItemNotInSquads(ctx, qtx, id); err != nil {
+			return err
+		}
+		if err := qtx.SoftDeleteItem(ctx, id); err != nil {
+			return fmt.Errorf("delete item: %w", err)
+		}
+		return audit(ctx, qtx, adminID, ActionDelete, EntityItem, id, itemInput(old))
+	})
+}
+
+// ListItems returns the items that have not been deleted.
+func (s *Service) ListItems(ctx context.Context) ([]store.Item, error) {
+	items, err := s.store.ListItems(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list items: %w", err)
+	}
+	return items, nil
+}
+
+// GetItem returns an item that has not been deleted.
+func (s *Service) GetItem(ctx context.Context, id int64) (store.Item, error) {
+	return getLiveItem(ctx, s.store, id)
+}
+
+func checkItemNotInSquads(ctx context.Context, q store.Querier, itemID int64) error {
+	n, err := q.CountActiveSquadsWithItem(ctx, itemID)
+	if err != nil {
+		return fmt.Errorf("count squads with item: %w", err)
+	}
+	if n > 0 {
+		return ErrInUse{Msg: fmt.Sprintf("item %d is carried by %d squads", itemID, n)}
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package content
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/76dillon/battle_squads/internal/store"
+	"github.com/76dillon/battle_squads/internal/validation"
+)
+
+type unitMoveDetails struct {
+	MoveID int64 `json:"move_id"`
+}
+
+// CreateMove creates a move and adds it to the learnset of each of unitIDs.
+func (s *Service) CreateMove(ctx context.Context, adminID int64, in MoveInput, unitIDs []int64) (store.Move, error) {
+	var mv store.Move
+	err := s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		verr := validation.New("move")
+		if err := validateMove(ctx, qtx, verr, in); err != nil {
+			return err
+		}
+		seen := make(map[int64]bool, len(unitIDs))
+		for i, unitID := range unitIDs {
+			field := fmt.Sprintf("unit_ids[%d]", i)
+			if seen[unitID] {
+				verr.Add(field, "unit %d is listed twice", unitID)
+				continue
+			}
+			seen[unitID] = true
+			if _, err := getLiveUnit(ctx, qtx, unitID); err != nil {
+				var notFound ErrNotFound
+				if errors.As(err, &notFound) {
+					verr.Add(field, "unit %d does not exist", unitID)
+					continue
+				}
+				return err
+			}
+		}
+		if err := verr.Err(); err != nil {
+			return err
+		}
+
+		var err error
+		mv, err = qtx.CreateMove(ctx, store.CreateMoveParams{
+			Name:     in.Name,
+			Power:    in.Power,
+			Accuracy: in.Accuracy,
+			TypeID:   in.TypeID,
+		})
+		if err != nil {
+			return fmt.Errorf("create move: %w", err)
+		}
+		if err := audit(ctx, qtx, adminID, ActionCreate, EntityMove, mv.ID, in); err != nil {
+			return err
+		}
+
+		for _, unitID := range unitIDs {
+			if _, err := qtx.CreateUnitMove(ctx, store.CreateUnitMoveParams{UnitID: unitID, MoveID: mv.ID}); err != nil {
+				return fmt.Errorf("assign move to unit %d: %w", unitID, err)
+			}
+			if err := audit(ctx, qtx, adminID, ActionCreate, EntityUnitMove, unitID, unitMoveDetails{MoveID: mv.ID}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return mv, err
+}
+
+func (s *Service) UpdateMove(ctx context.Context, adminID, id int64, in MoveInput) (store.Move, error) {
+	var mv store.Move
+	err := s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		old, err := getLiveMove(ctx, qtx, id)
+		if err != nil {
+			return err
+		}
+		verr := validation.New("move")
+		if err := validateMove(ctx, qtx, verr, in); err != nil {
+			return err
+		}
+		if err := verr.Err(); err != nil {
+			return err
+		}
+		mv, err = qtx.UpdateMove(ctx, store.UpdateMoveParams{
+			ID:       id,
+			Name:     in.Name,
+			Power:    in.Power,
+			Accuracy: in.Accuracy,
+			TypeID:   in.TypeID,
+		})
+		if err != nil {
+			return fmt.Errorf("update move: %w", err)
+		}
+		return audit(ctx, qtx, adminID, ActionUpdate, EntityMove, id, change{Before: moveInput(old), After: in})
+	})
+	return mv, err
+}
+
+// DeleteMove soft-deletes a move, removing it from every learnset it is in.
+// Moves that a unit in a non-deleted squad can learn cannot be deleted.
+func (s *Service) DeleteMove(ctx context.Context, adminID, id int64) error {
+	return s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		old, err := getLiveMove(ctx, qtx, id)
+		if err != nil {
+			return err
+		}
+		n, err := qtx.CountActiveSquadsWithMove(ctx, id)
+		if err != nil {
+			return fmt.Errorf("count squads with move: %w", err)
+		}
+		if n > 0 {
+			return ErrInUse{Msg: fmt.Sprintf("move %d is used by %d squads", id, n)}
+		}
+		if err := qtx.SoftDeleteMove(ctx, id); err != nil {
+			return fmt.Errorf("delete move: %w", err)
+		}
+		return audit(ctx, qtx, adminID, ActionDelete, EntityMove, id, moveInput(old))
+	})
+}
+
+// AddUnitMove adds moveID to unitID's learnset.
+func (s *Service) AddUnitMove(ctx context.Context, adminID, unitID, moveID int64) error {
+	return s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		if _, err := getLiveUnit(ctx, qtx, unitID); err != nil {
+			return err
+		}
+		verr := validation.New("learnset")
+		if _, err := getLiveMove(ctx, qtx, moveID); err != nil {
+			var notFound ErrNotFound
+			if !errors.As(err, &notFound) {
+				return err
+			}
+			verr.Add("move_id", "move %d does not exist", moveID)
+			return verr
+		}
+		moves, err := qtx.ListMovesForUnit(ctx, unitID)
+		if err != nil {
+			return fmt.Errorf("list moves for unit: %w", err)
+		}
+		for _, mv := range moves {
+			if mv.ID == moveID {
+				verr.Add("move_id", "unit %d already knows move %d", unitID, moveID)
+				return verr
+			}
+		}
+
+		if _, err := qtx.CreateUnitMove(ctx, store.CreateUnitMoveParams{UnitID: unitID, MoveID: moveID}); err != nil {
+			return fmt.Errorf("create unit move: %w", err)
+		}
+		return audit(ctx, qtx, adminID, ActionCreate, EntityUnitMove, unitID, unitMoveDetails{MoveID: moveID})
+	})
+}
+
+// RemoveUnitMove removes moveID from unitID's learnset. Learnsets of units in
+// non-deleted squads are locked, since a match could be relying on them.
+func (s *Service) RemoveUnitMove(ctx context.Context, adminID, unitID, moveID int64) error {
+	return s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		if _, err := getLiveUnit(ctx, qtx, unitID); err != nil {
+			return err
+		}
+		if err := checkUnitNotInSquads(ctx, qtx, unitID); err != nil {
+			return err
+		}
+		n, err := qtx.DeleteUnitMove(ctx, store.DeleteUnitMoveParams{UnitID: unitID, MoveID: moveID})
+		if err != nil {
+			return fmt.Errorf("delete unit move: %w", err)
+		}
+		if n == 0 {
+			return ErrNotFound{Msg: fmt.Sprintf("unit %d does not know move %d", unitID, moveID)}
+		}
+		return audit(ctx, qtx, adminID, ActionDelete, EntityUnitMove, unitID, unitMoveDetails{MoveID: moveID})
+	})
+}
@@ -0,0 +1,151 @@
+package content_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/76dillon/battle_squads/internal/content"
+	"github.com/76dillon/battle_squads/internal/store"
+	"github.com/76dillon/battle_squads/internal/store/storetest"
+)
+
+func TestCreateMove(t *testing.T) {
+	for name, open := range storetest.Backends() {
+		t.Run(name, func(t *testing.T) {
+			testCreateMove(t, newTestEnv(t, open(t)))
+		})
+	}
+}
+
+func testCreateMove(t *testing.T, e *testEnv) {
+	ctx := context.Background()
+	wolf := e.unit(t, "Flame Wolf")
+	drake := e.unit(t, "Aqua Drake")
+
+	//--out of range stats, an unknown type and bad unit IDs, all at once
+	before := e.auditCount(t)
+	_, err := e.content.CreateMove(ctx, adminID, content.MoveInput{
+		Name:     "Ember",
+		Power:    content.MaxPower + 1,
+		Accuracy: 0,
+		TypeID:   999,
+	}, []int64{wolf.ID, wolf.ID, 999})
+	wantProblems(t, err, "power", "accuracy", "type_id", "unit_ids[1]", "unit_ids[2]")
+	if got := e.auditCount(t); got != before {
+		t.Errorf("%d audit entries after a rejected move, want %d", got, before)
+	}
+
+	//--a move and each learnset entry are audited
+	mv, err := e.content.CreateMove(ctx, adminID, content.MoveInput{
+		Name:     "Ember",
+		Power:    content.MaxPower,
+		Accuracy: content.MinAccuracy,
+		TypeID:   wolf.TypeID,
+	}, []int64{wolf.ID, drake.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := e.auditCount(t) - before; got != 3 {
+		t.Errorf("%d audit entries, want 3", got)
+	}
+	for _, u := range []int64{wolf.ID, drake.ID} {
+		moves, err := e.st.ListMovesForUnit(ctx, u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !hasMove(moves, mv.ID) {
+			t.Errorf("unit %d can't learn the new move", u)
+		}
+	}
+}
+
+func TestLearnsets(t *testing.T) {
+	for name, open := range storetest.Backends() {
+		t.Run(name, func(t *testing.T) {
+			testLearnsets(t, newTestEnv(t, open(t)))
+		})
+	}
+}
+
+func testLearnsets(t *testing.T, e *testEnv) {
+	ctx := context.Background()
+	wolf := e.unit(t, "Flame Wolf")
+	sprite := e.unit(t, "Leaf Sprite")
+	moves, err := e.st.ListMovesForUnit(ctx, sprite.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	known := moves[0].ID
+
+	wantProblems(t, e.content.AddUnitMove(ctx, adminID, sprite.ID, 999), "move_id")
+	wantProblems(t, e.content.AddUnitMove(ctx, adminID, sprite.ID, known), "move_id")
+	var notFound content.ErrNotFound
+	if err := e.content.AddUnitMove(ctx, adminID, 999, known); !errors.As(err, &notFound) {
+		t.Errorf("adding to an unknown unit: got %v, want ErrNotFound", err)
+	}
+
+	//--a learnset can change until a squad uses the unit
+	wolfMoves, err := e.st.ListMovesForUnit(ctx, wolf.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var newMove int64
+	for _, mv := range wolfMoves {
+		if !hasMove(moves, mv.ID) {
+			newMove = mv.ID
+		}
+	}
+	if err := e.content.AddUnitMove(ctx, adminID, sprite.ID, newMove); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.content.RemoveUnitMove(ctx, adminID, sprite.ID, newMove); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.content.RemoveUnitMove(ctx, adminID, sprite.ID, newMove); !errors.As(err, &notFound) {
+		t.Errorf("removing a move twice: got %v, want ErrNotFound", err)
+	}
+	e.newSquad(t, "alice", sprite.ID)
+	wantInUse(t, e.content.RemoveUnitMove(ctx, adminID, sprite.ID, known))
+}
+
+func TestDeleteMoveInUse(t *testing.T) {
+	for name, open := range storetest.Backends() {
+		t.Run(name, func(t *testing.T) {
+			testDeleteMoveInUse(t, newTestEnv(t, open(t)))
+		})
+	}
+}
+
+// testDeleteMoveInUse deletes moves a squad's unit can and can't learn.
+func testDeleteMoveInUse(t *testing.T, e *testEnv) {
+	ctx := context.Background()
+	drake := e.unit(t, "Aqua Drake")
+	moves, err := e.st.ListMovesForUnit(ctx, drake.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.newSquad(t, "alice", drake.ID)
+
+	all, err := e.st.ListMoves(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mv := range all {
+		err := e.content.DeleteMove(ctx, adminID, mv.ID)
+		if hasMove(moves, mv.ID) {
+			wantInUse(t, err)
+		} else if err != nil {
+			t.Errorf("deleting %s, which no squad uses: %v", mv.Name, err)
+		}
+	}
+}
+
+func hasMove(moves []store.Move, id int64) bool {
+	for _, mv := range moves {
+		if mv.ID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package content
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// PackFormatVersion is the content pack format this server reads and writes.
+// It changes only when the format does, not when a pack's content does.
+const PackFormatVersion = 1
+
+// Pack is a shareable set of game content. Everything refers to types, units
+// and moves by name, so a pack can be imported into any database.
+type Pack struct {
+	FormatVersion int    `json:"format_version"`
+	Name          string `json:"name"`
+	// Version is the pack author's own version, e.g. "1.2.0"
+	Version       string              `json:"version,omitempty"`
+	Types         []string            `json:"types"`
+	Effectiveness []PackEffectiveness `json:"effectiveness,omitempty"`
+	Moves         []PackMove          `json:"moves"`
+	Units         []PackUnit          `json:"units"`
+}
+
+// PackEffectiveness is one entry of the type chart. Multiplier scales the
+// damage of an Attack-type move against a Defend-type unit: 2 is super
+// effective, 0.5 resisted and 0 immune. Pairs without an entry are neutral.
+type PackEffectiveness struct {
+	Attack     string  `json:"attack"`
+	Defend     string  `json:"defend"`
+	Multiplier float64 `json:"multiplier"`
+}
+
+type PackMove struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Power    int32  `json:"power"`
+	Accuracy int32  `json:"accuracy"`
+}
+
+type PackUnit struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	BaseHP     int32  `json:"base_hp"`
+	BaseAttack int32  `json:"base_attack"`
+	BaseSpeed  int32  `json:"base_speed"`
+	Ability    string `json:"ability,omitempty"`
+	// Moves is the unit's learnset, by move name
+	Moves []string `json:"moves,omitempty"`
+}
+
+// ReadPack decodes a JSON content pack. Unknown fields are rejected so typos
+// don't silently drop content.
+func ReadPack(r io.Reader) (Pack, error) {
+	var p Pack
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		return Pack{}, fmt.Errorf("decode content pack: %w", err)
+	}
+	return p, nil
+}
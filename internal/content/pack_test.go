@@ -0,0 +1,200 @@
+package content_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/76dillon/battle_squads/internal/content"
+	"github.com/76dillon/battle_squads/internal/store"
+	"github.com/76dillon/battle_squads/internal/store/storetest"
+)
+
+func TestReadPack(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		json string
+		ok   bool
+	}{
+		{"valid", `{"format_version": 1, "name": "p", "types": ["Fire"], "moves": [], "units": [{"name": "U", "type": "Fire", "moves": ["Ember"]}]}`, true},
+		{"unknown top-level field", `{"format_version": 1, "name": "p", "unit": []}`, false},
+		{"unknown unit field", `{"format_version": 1, "name": "p", "units": [{"name": "U", "base_hit": 10}]}`, false},
+		{"unknown chart field", `{"format_version": 1, "name": "p", "effectiveness": [{"attack": "Fire", "defend": "Grass", "multi": 2}]}`, false},
+		{"wrong type", `{"format_version": "1", "name": "p"}`, false},
+		{"not JSON", `format_version: 1`, false},
+	} {
+		_, err := content.ReadPack(strings.NewReader(tc.json))
+		if (err == nil) != tc.ok {
+			t.Errorf("%s: got error %v, want ok = %v", tc.name, err, tc.ok)
+		}
+	}
+}
+
+func TestImportPack(t *testing.T) {
+	for name, open := range storetest.Backends() {
+		t.Run(name, func(t *testing.T) {
+			testImportPack(t, newTestEnv(t, open(t)))
+		})
+	}
+}
+
+// testImportPack imports a pack that adds a type, a move and a unit and
+// updates a demo unit, first as a dry run, which must leave no trace, and
+// then for real.
+func testImportPack(t *testing.T, e *testEnv) {
+	ctx := context.Background()
+	pack := content.Pack{
+		FormatVersion: content.PackFormatVersion,
+		Name:          "storm",
+		Types:         []string{"fire", "Electric"},
+		Effectiveness: []content.PackEffectiveness{{Attack: "Electric", Defend: "Water", Multiplier: 2}},
+		Moves:         []content.PackMove{{Name: "Spark", Type: "Electric", Power: 15, Accuracy: 100}},
+		Units: []content.PackUnit{
+			{Name: "Volt Cub", Type: "Electric", BaseHP: 30, BaseAttack: 9, BaseSpeed: 14, Moves: []string{"Spark", "Tackle"}},
+			{Name: "Flame Wolf", Type: "Fire", BaseHP: 45, BaseAttack: 12, BaseSpeed: 10, Moves: []string{"Fireball", "Tackle", "Spark"}},
+		},
+	}
+	snapshot := func() content.Pack {
+		t.Helper()
+		p, err := e.content.Export(ctx, "snapshot", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	//--a dry run reports the changes and rolls them all back
+	before := snapshot()
+	audits := e.auditCount(t)
+	dry, err := e.content.Import(ctx, adminID, pack, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !dry.DryRun || len(dry.Changes) == 0 {
+		t.Fatalf("dry run: %+v, want changes", dry)
+	}
+	if after := snapshot(); !reflect.DeepEqual(after, before) {
+		t.Errorf("dry run changed the content:\nbefore %+v\nafter  %+v", before, after)
+	}
+	if got := e.auditCount(t); got != audits {
+		t.Errorf("dry run left %d audit entries", got-audits)
+	}
+
+	//--"fire" is the existing Fire type; the rest are changes
+	var updated *content.Change
+	for i, c := range dry.Changes {
+		if c.Entity == content.EntityUnitType && c.Name != "Electric" {
+			t.Errorf("dry run creates type %q", c.Name)
+		}
+		if c.Action == content.ActionUpdate && c.Entity == content.EntityUnit {
+			updated = &dry.Changes[i]
+		}
+	}
+	if updated == nil || updated.Name != "Flame Wolf" {
+		t.Errorf("dry run changes %+v, want an update to Flame Wolf", dry.Changes)
+	}
+
+	//--the real import makes exactly the changes the dry run listed
+	applied, err := e.content.Import(ctx, adminID, pack, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if applied.DryRun || !reflect.DeepEqual(applied.Changes, dry.Changes) || applied.Unchanged != dry.Unchanged {
+		t.Errorf("import: %+v\nwant the dry run's %+v", applied, dry)
+	}
+	if e.unit(t, "Flame Wolf").BaseHp != 45 {
+		t.Error("Flame Wolf was not updated")
+	}
+	if got := e.auditCount(t) - audits; got != len(applied.Changes)+1 {
+		t.Errorf("%d audit entries, want one per change and one for the pack", got)
+	}
+
+	//--importing again, or importing an export, changes nothing
+	for name, p := range map[string]content.Pack{"again": pack, "export": snapshot()} {
+		again, err := e.content.Import(ctx, adminID, p, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(again.Changes) != 0 {
+			t.Errorf("%s: changes %+v, want none", name, again.Changes)
+		}
+	}
+}
+
+func TestImportPackInvalid(t *testing.T) {
+	for name, open := range storetest.Backends() {
+		t.Run(name, func(t *testing.T) {
+			testImportPackInvalid(t, newTestEnv(t, open(t)))
+		})
+	}
+}
+
+// testImportPackInvalid checks that a pack with any problem is refused as a
+// whole, with every problem reported, including names that match more than
+// one existing unit or move.
+func testImportPackInvalid(t *testing.T, e *testEnv) {
+	ctx := context.Background()
+	fire := e.unit(t, "Flame Wolf").TypeID
+
+	//--a second Tackle and a second Leaf Sprite make those names ambiguous
+	if _, err := e.st.CreateMove(ctx, store.CreateMoveParams{Name: "Tackle", Power: 5, Accuracy: 100, TypeID: fire}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.st.CreateUnit(ctx, store.CreateUnitParams{Name: "Leaf Sprite", TypeID: fire, BaseHp: 10, BaseAttack: 10, BaseSpeed: 10}); err != nil {
+		t.Fatal(err)
+	}
+
+	before, err := e.content.Export(ctx, "before", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	audits := e.auditCount(t)
+	for _, dryRun := range []bool{true, false} {
+		_, err := e.content.Import(ctx, adminID, content.Pack{
+			FormatVersion: content.PackFormatVersion + 1,
+			Name:          "broken",
+			Types:         []string{"Electric", "electric"},
+			Effectiveness: []content.PackEffectiveness{
+				{Attack: "Electric", Defend: "Fire", Multiplier: 2},
+				{Attack: "electric", Defend: "fire", Multiplier: content.MaxMultiplier + 1},
+			},
+			Moves: []content.PackMove{
+				{Name: "Spark", Type: "Electric", Power: 15, Accuracy: 100},
+				{Name: "Tackle", Type: "Fire", Power: 10, Accuracy: 100},
+			},
+			Units: []content.PackUnit{
+				{Name: "Volt Cub", Type: "Plasma", BaseHP: 30, BaseAttack: 9, BaseSpeed: 14, Moves: []string{"Spark", "Thunder"}},
+				{Name: "Leaf Sprite", Type: "Grass", BaseHP: 30, BaseAttack: 8, BaseSpeed: 15},
+				{Name: "Flame Wolf", Type: "Fire", BaseHP: 40, BaseAttack: 12, BaseSpeed: 10, Moves: []string{"Tackle"}},
+			},
+		}, dryRun)
+		wantProblems(t, err,
+			"format_version",
+			"types[1]",
+			"moves[1].name",
+			"units[0].type",
+			"units[0].moves[1]",
+			"units[1].name",
+			"effectiveness[1].defend",
+			"effectiveness[1].multiplier",
+		)
+	}
+
+	//--the learnset entry only counts as ambiguous if the pack doesn't
+	//  define the move itself
+	_, err = e.content.Import(ctx, adminID, content.Pack{
+		FormatVersion: content.PackFormatVersion,
+		Name:          "learnset",
+		Units:         []content.PackUnit{{Name: "Flame Wolf", Type: "Fire", BaseHP: 40, BaseAttack: 12, BaseSpeed: 10, Moves: []string{"Tackle"}}},
+	}, true)
+	wantProblems(t, err, "units[0].moves[0]")
+
+	after, err := e.content.Export(ctx, "before", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(after, before) || e.auditCount(t) != audits {
+		t.Error("a refused import changed the content")
+	}
+}
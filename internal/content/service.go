@@ -0,0 +1,131 @@
+package content
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/76dillon/battle_squads/internal/store"
+)
+
+// Limits on admin-provided content. They keep the damage formula within sane
+// bounds; matches never see values outside these ranges.
+const (
+	MaxNameLength = 50
+	MinBaseStat   = 1
+	MaxBaseStat   = 255
+	MinPower      = 1
+	MaxPower      = 250
+	MinAccuracy   = 1
+	MaxAccuracy   = 100
+)
+
+// Audit log entity types
+const (
+	EntityUnitType = "unit_type"
+	EntityUnit     = "unit"
+	EntityMove     = "move"
+	EntityUnitMove = "unit_move"
+	EntityItem     = "item"
+)
+
+// Audit log actions
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// Service manages the game content admins can edit: unit types, units, moves,
+// learnsets and items. Every change is validated and written together with an
+// admin_audit_log row in one transaction.
+type Service struct {
+	store store.Store
+}
+
+func NewService(st store.Store) *Service {
+	return &Service{store: st}
+}
+
+// UnitInput is the editable part of a unit. Ability is the key of an
+// ability registered with the game engine, or empty for none.
+type UnitInput struct {
+	Name       string `json:"name"`
+	TypeID     int64  `json:"type_id"`
+	BaseHP     int32  `json:"base_hp"`
+	BaseAttack int32  `json:"base_attack"`
+	BaseSpeed  int32  `json:"base_speed"`
+	Ability    string `json:"ability"`
+}
+
+// MoveInput is the editable part of a move.
+type MoveInput struct {
+	Name     string `json:"name"`
+	Power    int32  `json:"power"`
+	Accuracy int32  `json:"accuracy"`
+	TypeID   int64  `json:"type_id"`
+}
+
+// ItemInput is the editable part of an item. Effect must be one of
+// game.ItemEffects[Kind].
+type ItemInput struct {
+	Name   string `json:"name"`
+	Kind   string `json:"kind"`
+	Effect string `json:"effect"`
+	Amount int32  `json:"amount"`
+}
+
+func unitInput(u store.Unit) UnitInput {
+	return UnitInput{
+		Name:       u.Name,
+		TypeID:     u.TypeID,
+		BaseHP:     u.BaseHp,
+		BaseAttack: u.BaseAttack,
+		BaseSpeed:  u.BaseSpeed,
+		Ability:    u.Ability,
+	}
+}
+
+func moveInput(mv store.Move) MoveInput {
+	return MoveInput{
+		Name:     mv.Name,
+		Power:    mv.Power,
+		Accuracy: mv.Accuracy,
+		TypeID:   mv.TypeID,
+	}
+}
+
+func itemInput(it store.Item) ItemInput {
+	return ItemInput{
+		Name:   it.Name,
+		Kind:   it.Kind,
+		Effect: it.Effect,
+		Amount: it.Amount,
+	}
+}
+
+// change is the audit details of an update.
+type change struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// audit records that adminID performed action on an entity. details is
+// stored as JSON.
+func audit(ctx context.Context, q store.Querier, adminID int64, action, entityType string, entityID int64, details any) error {
+	b, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Errorf("marshal audit details: %w", err)
+	}
+	_, err = q.CreateAdminAuditEntry(ctx, store.CreateAdminAuditEntryParams{
+		AdminPlayerID: adminID,
+		Action:        action,
+		EntityType:    entityType,
+		EntityID:      entityID,
+		Details:       string(b),
+	})
+	if err != nil {
+		return fmt.Errorf("create audit entry: %w", err)
+	}
+	return nil
+}
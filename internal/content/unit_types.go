@@ -0,0 +1,87 @@
+package content
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/76dillon/battle_squads/internal/store"
+)
+
+type unitTypeDetails struct {
+	Name string `json:"name"`
+}
+
+func (s *Service) CreateUnitType(ctx context.Context, adminID int64, name string) (store.UnitType, error) {
+	var t store.UnitType
+	err := s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		if err := validateUnitTypeName(ctx, qtx, name, 0); err != nil {
+			return err
+		}
+		var err error
+		t, err = qtx.CreateUnitType(ctx, name)
+		if err != nil {
+			return fmt.Errorf("create unit type: %w", err)
+		}
+		return audit(ctx, qtx, adminID, ActionCreate, EntityUnitType, t.ID, unitTypeDetails{Name: t.Name})
+	})
+	return t, err
+}
+
+// UpdateUnitType renames a unit type. Units and moves refer to types by ID,
+// so they are unaffected.
+func (s *Service) UpdateUnitType(ctx context.Context, adminID, id int64, name string) (store.UnitType, error) {
+	var t store.UnitType
+	err := s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		old, err := getUnitType(ctx, qtx, id)
+		if err != nil {
+			return err
+		}
+		if err := validateUnitTypeName(ctx, qtx, name, id); err != nil {
+			return err
+		}
+		t, err = qtx.UpdateUnitType(ctx, store.UpdateUnitTypeParams{ID: id, Name: name})
+		if err != nil {
+			return fmt.Errorf("update unit type: %w", err)
+		}
+		return audit(ctx, qtx, adminID, ActionUpdate, EntityUnitType, id, change{
+			Before: unitTypeDetails{Name: old.Name},
+			After:  unitTypeDetails{Name: t.Name},
+		})
+	})
+	return t, err
+}
+
+// DeleteUnitType deletes a type that no unit or move uses, including deleted
+// ones, which finished matches may still refer to.
+func (s *Service) DeleteUnitType(ctx context.Context, adminID, id int64) error {
+	return s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		old, err := getUnitType(ctx, qtx, id)
+		if err != nil {
+			return err
+		}
+		refs, err := qtx.CountUnitTypeReferences(ctx, id)
+		if err != nil {
+			return fmt.Errorf("count unit type references: %w", err)
+		}
+		if refs > 0 {
+			return ErrInUse{Msg: fmt.Sprintf("unit type %d is used by %d units or moves", id, refs)}
+		}
+		if err := qtx.DeleteUnitType(ctx, id); err != nil {
+			return fmt.Errorf("delete unit type: %w", err)
+		}
+		return audit(ctx, qtx, adminID, ActionDelete, EntityUnitType, id, unitTypeDetails{Name: old.Name})
+	})
+}
+
+func getUnitType(ctx context.Context, q store.Querier, id int64) (store.UnitType, error) {
+	t, err := q.GetUnitTypeByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return store.UnitType{}, ErrNotFound{Msg: fmt.Sprintf("unit type %d not found", id)}
+	}
+	if err != nil {
+		return store.UnitType{}, fmt.Errorf("get unit type %d: %w", id, err)
+	}
+	return t, nil
+}
@@ -0,0 +1,63 @@
+package content_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/76dillon/battle_squads/internal/content"
+	"github.com/76dillon/battle_squads/internal/store/storetest"
+)
+
+func TestUnitTypes(t *testing.T) {
+	for name, open := range storetest.Backends() {
+		t.Run(name, func(t *testing.T) {
+			testUnitTypes(t, newTestEnv(t, open(t)))
+		})
+	}
+}
+
+func testUnitTypes(t *testing.T, e *testEnv) {
+	ctx := context.Background()
+	wolf := e.unit(t, "Flame Wolf")
+
+	_, err := e.content.CreateUnitType(ctx, adminID, "fire")
+	wantProblems(t, err, "name")
+	_, err = e.content.CreateUnitType(ctx, adminID, " ")
+	wantProblems(t, err, "name")
+
+	//--a type is in use while any unit or move has it, even a deleted one
+	wantInUse(t, e.content.DeleteUnitType(ctx, adminID, wolf.TypeID))
+
+	electric, err := e.content.CreateUnitType(ctx, adminID, "Electric")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = e.content.UpdateUnitType(ctx, adminID, electric.ID, "Water")
+	wantProblems(t, err, "name")
+	if _, err := e.content.UpdateUnitType(ctx, adminID, electric.ID, "ELECTRIC"); err != nil {
+		t.Errorf("changing a type name's case: %v", err)
+	}
+
+	u, err := e.content.CreateUnit(ctx, adminID, content.UnitInput{
+		Name:       "Volt Mouse",
+		TypeID:     electric.ID,
+		BaseHP:     20,
+		BaseAttack: 10,
+		BaseSpeed:  20,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.content.DeleteUnit(ctx, adminID, u.ID); err != nil {
+		t.Fatal(err)
+	}
+	wantInUse(t, e.content.DeleteUnitType(ctx, adminID, electric.ID))
+
+	unused, err := e.content.CreateUnitType(ctx, adminID, "Ghost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.content.DeleteUnitType(ctx, adminID, unused.ID); err != nil {
+		t.Errorf("deleting an unused type: %v", err)
+	}
+}
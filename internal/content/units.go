@@ -0,0 +1,90 @@
+package content
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/76dillon/battle_squads/internal/store"
+)
+
+func (s *Service) CreateUnit(ctx context.Context, adminID int64, in UnitInput) (store.Unit, error) {
+	var u store.Unit
+	err := s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		if err := validateUnit(ctx, qtx, in); err != nil {
+			return err
+		}
+		var err error
+		u, err = qtx.CreateUnit(ctx, store.CreateUnitParams{
+			Name:       in.Name,
+			TypeID:     in.TypeID,
+			BaseHp:     in.BaseHP,
+			BaseAttack: in.BaseAttack,
+			BaseSpeed:  in.BaseSpeed,
+			Ability:    in.Ability,
+		})
+		if err != nil {
+			return fmt.Errorf("create unit: %w", err)
+		}
+		return audit(ctx, qtx, adminID, ActionCreate, EntityUnit, u.ID, in)
+	})
+	return u, err
+}
+
+// UpdateUnit replaces a unit's name, type, stats and ability. Matches read
+// them from the unit when a turn is applied, so balance changes take effect
+// at once.
+func (s *Service) UpdateUnit(ctx context.Context, adminID, id int64, in UnitInput) (store.Unit, error) {
+	var u store.Unit
+	err := s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		old, err := getLiveUnit(ctx, qtx, id)
+		if err != nil {
+			return err
+		}
+		if err := validateUnit(ctx, qtx, in); err != nil {
+			return err
+		}
+		u, err = qtx.UpdateUnit(ctx, store.UpdateUnitParams{
+			ID:         id,
+			Name:       in.Name,
+			TypeID:     in.TypeID,
+			BaseHp:     in.BaseHP,
+			BaseAttack: in.BaseAttack,
+			BaseSpeed:  in.BaseSpeed,
+			Ability:    in.Ability,
+		})
+		if err != nil {
+			return fmt.Errorf("update unit: %w", err)
+		}
+		return audit(ctx, qtx, adminID, ActionUpdate, EntityUnit, id, change{Before: unitInput(old), After: in})
+	})
+	return u, err
+}
+
+// DeleteUnit soft-deletes a unit so it can no longer be added to squads.
+// Units in any non-deleted squad cannot be deleted.
+func (s *Service) DeleteUnit(ctx context.Context, adminID, id int64) error {
+	return s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		old, err := getLiveUnit(ctx, qtx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkUnitNotInSquads(ctx, qtx, id); err != nil {
+			return err
+		}
+		if err := qtx.SoftDeleteUnit(ctx, id); err != nil {
+			return fmt.Errorf("delete unit: %w", err)
+		}
+		return audit(ctx, qtx, adminID, ActionDelete, EntityUnit, id, unitInput(old))
+	})
+}
+
+func checkUnitNotInSquads(ctx context.Context, q store.Querier, unitID int64) error {
+	n, err := q.CountActiveSquadsWithUnit(ctx, unitID)
+	if err != nil {
+		return fmt.Errorf("count squads with unit: %w", err)
+	}
+	if n > 0 {
+		return ErrInUse{Msg: fmt.Sprintf("unit %d is used by %d squads", unitID, n)}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package content_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/76dillon/battle_squads/internal/content"
+	"github.com/76dillon/battle_squads/internal/store/storetest"
+)
+
+func TestUnitCRUD(t *testing.T) {
+	for name, open := range storetest.Backends() {
+		t.Run(name, func(t *testing.T) {
+			testUnitCRUD(t, newTestEnv(t, open(t)))
+		})
+	}
+}
+
+func testUnitCRUD(t *testing.T, e *testEnv) {
+	ctx := context.Background()
+	wolf := e.unit(t, "Flame Wolf")
+	in := content.UnitInput{Name: "Ember Cub", TypeID: wolf.TypeID, BaseHP: 30, BaseAttack: 9, BaseSpeed: 11}
+
+	//--every change is audited
+	before := e.auditCount(t)
+	u, err := e.content.CreateUnit(ctx, adminID, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in.BaseAttack = 10
+	in.Ability = "rage"
+	if u, err = e.content.UpdateUnit(ctx, adminID, u.ID, in); err != nil {
+		t.Fatal(err)
+	}
+	if u.BaseAttack != 10 || u.Ability != "rage" {
+		t.Errorf("updated unit: attack %d, ability %q", u.BaseAttack, u.Ability)
+	}
+	if err := e.content.DeleteUnit(ctx, adminID, u.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := e.auditCount(t) - before; got != 3 {
+		t.Errorf("%d audit entries, want 3", got)
+	}
+	var notFound content.ErrNotFound
+	if err := e.content.DeleteUnit(ctx, adminID, u.ID); !errors.As(err, &notFound) {
+		t.Errorf("deleting twice: got %v, want ErrNotFound", err)
+	}
+
+	//--every problem is reported, and nothing is written
+	before = e.auditCount(t)
+	_, err = e.content.UpdateUnit(ctx, adminID, wolf.ID, content.UnitInput{
+		Name:       "Flame Wolf",
+		TypeID:     999,
+		BaseHP:     0,
+		BaseAttack: 12,
+		BaseSpeed:  256,
+		Ability:    "telepathy",
+	})
+	wantProblems(t, err, "base_hp", "base_speed", "ability", "type_id")
+	if got := e.auditCount(t); got != before {
+		t.Errorf("%d audit entries after a rejected update, want %d", got, before)
+	}
+}
+
+func TestDeleteUnitInUse(t *testing.T) {
+	for name, open := range storetest.Backends() {
+		t.Run(name, func(t *testing.T) {
+			testDeleteUnitInUse(t, newTestEnv(t, open(t)))
+		})
+	}
+}
+
+// testDeleteUnitInUse deletes a unit a squad uses, which must wait until the
+// squad is deleted.
+func testDeleteUnitInUse(t *testing.T, e *testEnv) {
+	ctx := context.Background()
+	wolf := e.unit(t, "Flame Wolf")
+	playerID, sq := e.newSquad(t, "alice", wolf.ID)
+
+	before := e.auditCount(t)
+	wantInUse(t, e.content.DeleteUnit(ctx, adminID, wolf.ID))
+	if got := e.auditCount(t); got != before {
+		t.Errorf("%d audit entries after a refused delete, want %d", got, before)
+	}
+	if _, err := e.st.GetUnitByID(ctx, wolf.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.squads.Delete(ctx, playerID, sq.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.content.DeleteUnit(ctx, adminID, wolf.ID); err != nil {
+		t.Errorf("deleting a unit only deleted squads use: %v", err)
+	}
+}
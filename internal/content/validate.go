@@ -0,0 +1,137 @@
+package content
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/76dillon/battle_squads/internal/game"
+	"github.com/76dillon/battle_squads/internal/store"
+	"github.com/76dillon/battle_squads/internal/validation"
+)
+
+func validateName(verr *validation.Error, field, name string) {
+	if strings.TrimSpace(name) == "" {
+		verr.Add(field, "is required")
+	} else if n := utf8.RuneCountInString(name); n > MaxNameLength {
+		verr.Add(field, "must be at most %d characters, got %d", MaxNameLength, n)
+	}
+}
+
+func validateRange(verr *validation.Error, field string, v, lo, hi int32) {
+	if v < lo || v > hi {
+		verr.Add(field, "must be between %d and %d, got %d", lo, hi, v)
+	}
+}
+
+// validateTypeID records a problem if typeID is not an existing unit type.
+// It only returns an error if the type could not be looked up.
+func validateTypeID(ctx context.Context, q store.Querier, verr *validation.Error, typeID int64) error {
+	_, err := q.GetUnitTypeByID(ctx, typeID)
+	if errors.Is(err, sql.ErrNoRows) {
+		verr.Add("type_id", "unit type %d does not exist", typeID)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("get unit type %d: %w", typeID, err)
+	}
+	return nil
+}
+
+func validateUnit(ctx context.Context, q store.Querier, in UnitInput) error {
+	verr := validation.New("unit")
+	validateName(verr, "name", in.Name)
+	validateRange(verr, "base_hp", in.BaseHP, MinBaseStat, MaxBaseStat)
+	validateRange(verr, "base_attack", in.BaseAttack, MinBaseStat, MaxBaseStat)
+	validateRange(verr, "base_speed", in.BaseSpeed, MinBaseStat, MaxBaseStat)
+	validateAbility(verr, "ability", in.Ability)
+	if err := validateTypeID(ctx, q, verr, in.TypeID); err != nil {
+		return err
+	}
+	return verr.Err()
+}
+
+// validateAbility records a problem unless key is empty or the key of a
+// registered ability.
+func validateAbility(verr *validation.Error, field, key string) {
+	if _, ok := game.LookupAbility(key); key != "" && !ok {
+		verr.Add(field, "unknown ability %q", key)
+	}
+}
+
+func validateMove(ctx context.Context, q store.Querier, verr *validation.Error, in MoveInput) error {
+	validateName(verr, "name", in.Name)
+	validateRange(verr, "power", in.Power, MinPower, MaxPower)
+	validateRange(verr, "accuracy", in.Accuracy, MinAccuracy, MaxAccuracy)
+	return validateTypeID(ctx, q, verr, in.TypeID)
+}
+
+func validateItem(in ItemInput) error {
+	verr := validation.New("item")
+	validateName(verr, "name", in.Name)
+	effects, ok := game.ItemEffects[in.Kind]
+	if !ok {
+		verr.Add("kind", "must be %q or %q", game.ItemHeld, game.ItemConsumable)
+	} else if !slices.Contains(effects, in.Effect) {
+		verr.Add("effect", "must be one of %s for a %s item", strings.Join(effects, ", "), in.Kind)
+	}
+	validateRange(verr, "amount", in.Amount, 1, game.MaxItemAmount)
+	return verr.Err()
+}
+
+// validateUnitTypeName checks name and that no other type (other than
+// excludeID) already uses it.
+func validateUnitTypeName(ctx context.Context, q store.Querier, name string, excludeID int64) error {
+	verr := validation.New("unit type")
+	validateName(verr, "name", name)
+	types, err := q.ListUnitTypes(ctx)
+	if err != nil {
+		return fmt.Errorf("list unit types: %w", err)
+	}
+	for _, t := range types {
+		if t.ID != excludeID && strings.EqualFold(t.Name, name) {
+			verr.Add("name", "unit type %q already exists", t.Name)
+		}
+	}
+	return verr.Err()
+}
+
+// getLiveUnit returns a unit that exists and is not deleted.
+func getLiveUnit(ctx context.Context, q store.Querier, id int64) (store.Unit, error) {
+	u, err := q.GetUnitByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && u.DeletedAt.Valid) {
+		return store.Unit{}, ErrNotFound{Msg: fmt.Sprintf("unit %d not found", id)}
+	}
+	if err != nil {
+		return store.Unit{}, fmt.Errorf("get unit %d: %w", id, err)
+	}
+	return u, nil
+}
+
+// getLiveItem returns an item that exists and is not deleted.
+func getLiveItem(ctx context.Context, q store.Querier, id int64) (store.Item, error) {
+	it, err := q.GetItemByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && it.DeletedAt.Valid) {
+		return store.Item{}, ErrNotFound{Msg: fmt.Sprintf("item %d not found", id)}
+	}
+	if err != nil {
+		return store.Item{}, fmt.Errorf("get item %d: %w", id, err)
+	}
+	return it, nil
+}
+
+// getLiveMove returns a move that exists and is not deleted.
+func getLiveMove(ctx context.Context, q store.Querier, id int64) (store.Move, error) {
+	mv, err := q.GetMoveByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && mv.DeletedAt.Valid) {
+		return store.Move{}, ErrNotFound{Msg: fmt.Sprintf("move %d not found", id)}
+	}
+	if err != nil {
+		return store.Move{}, fmt.Errorf("get move %d: %w", id, err)
+	}
+	return mv, nil
+}
@@ -0,0 +1,80 @@
+package devutil
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/76dillon/battle_squads/internal/store"
+)
+
+// SeedDemoContent inserts the starter types, units and moves from
+// test_scripts/db_seeds.txt through the Store, so a fresh in-memory server is
+// playable straight away.
+func SeedDemoContent(ctx context.Context, st store.Store) error {
+	return st.ExecTx(ctx, func(q store.Querier) error {
+		typeIDs := make(map[string]int64)
+		for _, name := range []string{"Fire", "Water", "Grass"} {
+			t, err := q.CreateUnitType(ctx, name)
+			if err != nil {
+				return fmt.Errorf("create unit type %s: %w", name, err)
+			}
+			typeIDs[name] = t.ID
+		}
+
+		units := []struct {
+			name              string
+			typ               string
+			hp, attack, speed int32
+		}{
+			{"Flame Wolf", "Fire", 40, 12, 10},
+			{"Aqua Drake", "Water", 35, 10, 9},
+			{"Leaf Sprite", "Grass", 30, 8, 15},
+		}
+		unitIDs := make(map[string]int64)
+		for _, u := range units {
+			created, err := q.CreateUnit(ctx, store.CreateUnitParams{
+				Name:       u.name,
+				TypeID:     typeIDs[u.typ],
+				BaseHp:     u.hp,
+				BaseAttack: u.attack,
+				BaseSpeed:  u.speed,
+			})
+			if err != nil {
+				return fmt.Errorf("create unit %s: %w", u.name, err)
+			}
+			unitIDs[u.name] = created.ID
+		}
+
+		moves := []struct {
+			name            string
+			typ             string
+			power, accuracy int32
+			learnedBy       []string
+		}{
+			{"Fireball", "Fire", 20, 95, []string{"Flame Wolf"}},
+			{"Water Jet", "Water", 18, 100, []string{"Aqua Drake"}},
+			{"Leaf Blade", "Grass", 22, 90, []string{"Leaf Sprite"}},
+			{"Tackle", "Fire", 10, 100, []string{"Flame Wolf", "Aqua Drake", "Leaf Sprite"}},
+		}
+		for _, m := range moves {
+			created, err := q.CreateMove(ctx, store.CreateMoveParams{
+				Name:     m.name,
+				Power:    m.power,
+				Accuracy: m.accuracy,
+				TypeID:   typeIDs[m.typ],
+			})
+			if err != nil {
+				return fmt.Errorf("create move %s: %w", m.name, err)
+			}
+			for _, unitName := range m.learnedBy {
+				if _, err := q.CreateUnitMove(ctx, store.CreateUnitMoveParams{
+					UnitID: unitIDs[unitName],
+					MoveID: created.ID,
+				}); err != nil {
+					return fmt.Errorf("assign move %s to %s: %w", m.name, unitName, err)
+				}
+			}
+		}
+		return nil
+	})
+}
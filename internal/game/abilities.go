@@ -0,0 +1,81 @@
+package game
+
+import "fmt"
+
+// The abilities units can have out of the box. Content refers to them by
+// key, so a key must never change once units use it.
+func init() {
+	for _, a := range []Ability{
+		resistType{key: "fire_resist", typeName: "Fire"},
+		resistType{key: "water_resist", typeName: "Water"},
+		resistType{key: "grass_resist", typeName: "Grass"},
+		intimidate{},
+		rage{},
+		speedBoost{},
+	} {
+		RegisterAbility(a)
+	}
+}
+
+// resistType halves the damage of moves of one unit type.
+type resistType struct {
+	key      string
+	typeName string
+}
+
+func (a resistType) Key() string { return a.key }
+
+func (a resistType) Description() string {
+	return fmt.Sprintf("Takes half damage from %s moves.", a.typeName)
+}
+
+func (a resistType) BeforeDamage(b *Battle, hit *Hit) (bool, error) {
+	if hit.MoveType != a.typeName || hit.Damage <= 0 {
+		return false, nil
+	}
+	hit.Damage = max(hit.Damage/2, 1)
+	return true, nil
+}
+
+// intimidate lowers the opposing unit's attack when the unit comes in.
+type intimidate struct{}
+
+func (intimidate) Key() string { return "intimidate" }
+
+func (intimidate) Description() string {
+	return "Lowers the opposing unit's attack by 20% when it comes into battle."
+}
+
+func (intimidate) OnSwitchIn(b *Battle) (bool, error) {
+	opponent, err := b.Opponent()
+	if err != nil || opponent.CurrentHp <= 0 {
+		return false, err
+	}
+	return b.Boost(opponent, StatAttack, -20)
+}
+
+// rage raises the unit's attack each time it is hit.
+type rage struct{}
+
+func (rage) Key() string { return "rage" }
+
+func (rage) Description() string {
+	return "Raises its attack by 10% each time a move hits it."
+}
+
+func (rage) AfterHit(b *Battle, hit Hit) (bool, error) {
+	return b.Boost(b.Unit, StatAttack, 10)
+}
+
+// speedBoost raises the unit's speed after each of its side's turns.
+type speedBoost struct{}
+
+func (speedBoost) Key() string { return "speed_boost" }
+
+func (speedBoost) Description() string {
+	return "Raises its speed by 10% at the end of each of its turns."
+}
+
+func (speedBoost) EndOfTurn(b *Battle) (bool, error) {
+	return b.Boost(b.Unit, StatSpeed, 10)
+}
@@ -0,0 +1,275 @@
+package game
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"github.com/76dillon/battle_squads/internal/store"
+)
+
+// Ability is a unit's passive ability. Units refer to it by Key in
+// units.ability. An ability takes part in battles by also implementing one
+// or more of the hook interfaces below, which the engine calls at that point
+// of a turn.
+type Ability interface {
+	Key() string
+	// Description says what the ability does, for players
+	Description() string
+}
+
+// Every hook reports whether the ability triggered. Each trigger is recorded
+// in match_turns as an 'ability' turn.
+
+// SwitchInHook is called when the unit comes into battle: as a lead at the
+// start of the match, when it is switched in, or when it replaces a knocked
+// out unit.
+type SwitchInHook interface {
+	OnSwitchIn(b *Battle) (bool, error)
+}
+
+// BeforeDamageHook is called when a move is about to hit the unit, and can
+// change the damage it does.
+type BeforeDamageHook interface {
+	BeforeDamage(b *Battle, hit *Hit) (bool, error)
+}
+
+// AfterHitHook is called after a move has hit the unit, if it is still
+// standing.
+type AfterHitHook interface {
+	AfterHit(b *Battle, hit Hit) (bool, error)
+}
+
+// EndOfTurnHook is called after each turn the unit's side takes while it is
+// active and standing.
+type EndOfTurnHook interface {
+	EndOfTurn(b *Battle) (bool, error)
+}
+
+// Hit is a move hitting the unit whose hook is called.
+type Hit struct {
+	Attacker store.MatchUnit
+	Move     store.Move
+	// MoveType is the name of the move's unit type
+	MoveType string
+	Damage   int32
+}
+
+// Stats abilities can raise or lower, in percent, for the rest of the time
+// the unit is active.
+const (
+	StatAttack = "attack"
+	StatSpeed  = "speed"
+)
+
+// Bonuses on a stat are kept within these percentages
+const (
+	MinStatBonus = -50
+	MaxStatBonus = 100
+)
+
+// Battle is the match a hook is called in. It lets the ability look at and
+// change the units in battle.
+type Battle struct {
+	ctx   context.Context
+	q     store.Querier
+	match store.Match
+	side  store.MatchSide
+	// Unit is the unit with the ability, as it is when the hook is called
+	Unit store.MatchUnit
+	// target is the unit the ability affected, for the turn history
+	target store.MatchUnit
+}
+
+// Opponent returns the active unit on the other side.
+func (b *Battle) Opponent() (store.MatchUnit, error) {
+	sides, err := b.q.GetMatchSidesByMatchID(b.ctx, b.match.ID)
+	if err != nil {
+		return store.MatchUnit{}, fmt.Errorf("error retrieving match sides: %w", err)
+	}
+	_, opponent := splitSides(sides, b.side.PlayerID)
+	if opponent == nil {
+		return store.MatchUnit{}, ErrIllegalMove{Msg: "no opponent side found"}
+	}
+	mu, err := b.q.GetActiveMatchUnitForSide(b.ctx, opponent.ID)
+	if err != nil {
+		return store.MatchUnit{}, fmt.Errorf("get opponent active unit: %w", err)
+	}
+	return mu, nil
+}
+
+// Boost adds percent, which may be negative, to mu's bonus on stat. It
+// reports false if the bonus was already at its limit.
+func (b *Battle) Boost(mu store.MatchUnit, stat string, percent int32) (bool, error) {
+	arg := store.UpdateMatchUnitBonusesParams{
+		ID:          mu.ID,
+		AttackBonus: mu.AttackBonus,
+		SpeedBonus:  mu.SpeedBonus,
+	}
+	bonus := &arg.AttackBonus
+	if stat == StatSpeed {
+		bonus = &arg.SpeedBonus
+	}
+	old := *bonus
+	*bonus = min(max(old+percent, MinStatBonus), MaxStatBonus)
+	if *bonus == old {
+		return false, nil
+	}
+	mu, err := b.q.UpdateMatchUnitBonuses(b.ctx, arg)
+	if err != nil {
+		return false, fmt.Errorf("update stat bonuses: %w", err)
+	}
+	if mu.ID == b.Unit.ID {
+		b.Unit = mu
+	}
+	b.target = mu
+	return true, nil
+}
+
+var abilities = make(map[string]Ability)
+
+// RegisterAbility makes a available to units under its key. It panics if the
+// key is empty or already taken, so it belongs in package initialization.
+func RegisterAbility(a Ability) {
+	if a.Key() == "" {
+		panic("game: ability with an empty key")
+	}
+	if _, ok := abilities[a.Key()]; ok {
+		panic(fmt.Sprintf("game: ability %q registered twice", a.Key()))
+	}
+	abilities[a.Key()] = a
+}
+
+// LookupAbility returns the ability registered under key.
+func LookupAbility(key string) (Ability, bool) {
+	a, ok := abilities[key]
+	return a, ok
+}
+
+// Abilities returns every registered ability, ordered by key.
+func Abilities() []Ability {
+	out := make([]Ability, 0, len(abilities))
+	for _, a := range abilities {
+		out = append(out, a)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key() < out[j].Key() })
+	return out
+}
+
+// unitAbility returns mu's ability, or nil if it has none. A key that is no
+// longer registered counts as none.
+func unitAbility(ctx context.Context, q store.Querier, mu store.MatchUnit) (Ability, error) {
+	unit, err := q.GetUnitByID(ctx, mu.UnitID)
+	if err != nil {
+		return nil, fmt.Errorf("get unit: %w", err)
+	}
+	a, ok := LookupAbility(unit.Ability)
+	if !ok {
+		return nil, nil
+	}
+	return a, nil
+}
+
+// runHook calls call if mu, on side, has an ability implementing the hook H,
+// and records an ability turn if it triggered.
+func runHook[H any](ctx context.Context, qtx store.Querier, match store.Match, side store.MatchSide, mu store.MatchUnit, call func(H, *Battle) (bool, error)) error {
+	a, err := unitAbility(ctx, qtx, mu)
+	if err != nil || a == nil {
+		return err
+	}
+	hook, ok := a.(H)
+	if !ok {
+		return nil
+	}
+	b := &Battle{ctx: ctx, q: qtx, match: match, side: side, Unit: mu, target: mu}
+	triggered, err := call(hook, b)
+	if err != nil || !triggered {
+		return err
+	}
+	return recordAbilityTurn(ctx, qtx, match, side.PlayerID, b.Unit, b.target, a.Key())
+}
+
+// switchIn runs the switch-in hook of side's active unit.
+func switchIn(ctx context.Context, qtx store.Querier, match store.Match, side store.MatchSide) error {
+	active, err := qtx.GetActiveMatchUnitForSide(ctx, side.ID)
+	if err != nil {
+		return fmt.Errorf("get active unit: %w", err)
+	}
+	return runHook(ctx, qtx, match, side, active, func(h SwitchInHook, b *Battle) (bool, error) {
+		return h.OnSwitchIn(b)
+	})
+}
+
+// beforeDamage runs the before-damage hook of the unit hit, which is on
+// side, and returns the damage it lets through.
+func beforeDamage(ctx context.Context, qtx store.Querier, match store.Match, side store.MatchSide, target store.MatchUnit, hit Hit) (int32, error) {
+	err := runHook(ctx, qtx, match, side, target, func(h BeforeDamageHook, b *Battle) (bool, error) {
+		return h.BeforeDamage(b, &hit)
+	})
+	return max(hit.Damage, 0), err
+}
+
+// afterHit runs the after-hit hook of the unit hit, which is on side, if it
+// is still standing.
+func afterHit(ctx context.Context, qtx store.Querier, match store.Match, side store.MatchSide, target store.MatchUnit, hit Hit) error {
+	if target.CurrentHp <= 0 {
+		return nil
+	}
+	return runHook(ctx, qtx, match, side, target, func(h AfterHitHook, b *Battle) (bool, error) {
+		return h.AfterHit(b, hit)
+	})
+}
+
+// endTurn runs what happens after each turn side takes: its active unit's
+// held item, then its ability.
+func endTurn(ctx context.Context, qtx store.Querier, rs Ruleset, match store.Match, side store.MatchSide) error {
+	if err := healHeldItem(ctx, qtx, rs, match, side); err != nil {
+		return err
+	}
+	active, err := qtx.GetActiveMatchUnitForSide(ctx, side.ID)
+	if err != nil {
+		return fmt.Errorf("get active unit: %w", err)
+	}
+	if active.CurrentHp <= 0 {
+		return nil
+	}
+	return runHook(ctx, qtx, match, side, active, func(h EndOfTurnHook, b *Battle) (bool, error) {
+		return h.EndOfTurn(b)
+	})
+}
+
+// withBonus applies a percentage bonus to stat, never going below 1.
+func withBonus(stat, bonus int32) int32 {
+	return max(stat*(100+bonus)/100, 1)
+}
+
+// resetBonuses clears the stat bonuses of a unit leaving battle.
+func resetBonuses(ctx context.Context, qtx store.Querier, mu store.MatchUnit) error {
+	if mu.AttackBonus == 0 && mu.SpeedBonus == 0 {
+		return nil
+	}
+	_, err := qtx.UpdateMatchUnitBonuses(ctx, store.UpdateMatchUnitBonusesParams{ID: mu.ID})
+	if err != nil {
+		return fmt.Errorf("reset stat bonuses: %w", err)
+	}
+	return nil
+}
+
+// recordAbilityTurn records mu's ability triggering and affecting target.
+func recordAbilityTurn(ctx context.Context, qtx store.Querier, match store.Match, playerID int64, mu, target store.MatchUnit, key string) error {
+	_, err := qtx.CreateMatchTurn(ctx, store.CreateMatchTurnParams{
+		MatchID:           match.ID,
+		TurnNumber:        match.CurrentTurnNumber,
+		ActingPlayerID:    playerID,
+		ActingMatchUnitID: mu.ID,
+		TargetMatchUnitID: target.ID,
+		TargetHpAfter:     target.CurrentHp,
+		Action:            ActionAbility,
+		Ability:           sql.NullString{String: key, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("create match turn: %w", err)
+	}
+	return nil
+}
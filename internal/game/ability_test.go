@@ -0,0 +1,320 @@
+package game_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/76dillon/battle_squads/internal/game"
+	"github.com/76dillon/battle_squads/internal/store"
+	"github.com/76dillon/battle_squads/internal/store/storetest"
+)
+
+// setAbility gives the demo unit called name the ability key.
+func (e *testEnv) setAbility(t *testing.T, name, key string) {
+	t.Helper()
+	u := e.unit(t, name)
+	_, err := e.st.UpdateUnit(context.Background(), store.UpdateUnitParams{
+		ID:         u.ID,
+		Name:       u.Name,
+		TypeID:     u.TypeID,
+		BaseHp:     u.BaseHp,
+		BaseAttack: u.BaseAttack,
+		BaseSpeed:  u.BaseSpeed,
+		Ability:    key,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// abilityTurns returns the turns in which key triggered in matchID.
+func (e *testEnv) abilityTurns(t *testing.T, matchID int64, key string) []store.MatchTurn {
+	t.Helper()
+	turns, err := e.st.ListMatchTurns(context.Background(), matchID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []store.MatchTurn
+	for _, turn := range turns {
+		if turn.Action == game.ActionAbility && turn.Ability.String == key {
+			out = append(out, turn)
+		}
+	}
+	return out
+}
+
+func TestSwitchInAbility(t *testing.T) {
+	for name, open := range storetest.Backends() {
+		t.Run(name, func(t *testing.T) {
+			testSwitchInAbility(t, newTestEnv(t, open(t)))
+		})
+	}
+}
+
+// turn plays action for playerID, which must be the player the match is
+// waiting on.
+func (e *testEnv) turn(t *testing.T, matchID, playerID int64, action game.TurnAction) {
+	t.Helper()
+	m, err := e.st.GetMatchByID(context.Background(), matchID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.CurrentActorPlayerID.Int64 != playerID {
+		t.Fatalf("turn %d: waiting on player %d, not %d", m.CurrentTurnNumber, m.CurrentActorPlayerID.Int64, playerID)
+	}
+	if err := e.game.ApplyTurn(context.Background(), matchID, playerID, action, m.CurrentTurnNumber); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testSwitchInAbility gives the Aqua Drake intimidate, which lowers the
+// opposing unit's attack by 20% as a lead, when switched in and when it
+// replaces a knocked out unit. The Flame Wolf is faster than the Drake and
+// slower than the Leaf Sprite, which fixes who goes first in each round.
+func testSwitchInAbility(t *testing.T, e *testEnv) {
+	e.setAbility(t, "Aqua Drake", "intimidate")
+	p1, s1 := e.newSquadPlayer(t, "alice", "Aqua Drake", "Leaf Sprite")
+	p2, s2 := e.newSquadPlayer(t, "bob", "Flame Wolf")
+	m := e.startMatch(t, game.StandardRulesetID, p1, s1, p2, s2)
+	wolf := e.active(t, m.ID, p2)
+	tackle := game.TurnAction{Kind: game.ActionMove, MoveID: e.move(t, "Tackle").ID}
+	toDrake := game.TurnAction{Kind: game.ActionSwitch, SwitchTo: 0}
+	toSprite := game.TurnAction{Kind: game.ActionSwitch, SwitchTo: 1}
+
+	//--as a lead, recorded as turn 0
+	turns := e.abilityTurns(t, m.ID, "intimidate")
+	if len(turns) != 1 || turns[0].TurnNumber != 0 || turns[0].TargetMatchUnitID != wolf.ID {
+		t.Fatalf("lead intimidate: got %+v, want one on turn 0 targeting the Flame Wolf", turns)
+	}
+	if wolf.AttackBonus != -20 {
+		t.Errorf("Flame Wolf attack bonus %d, want -20", wolf.AttackBonus)
+	}
+
+	//--the lowered attack goes into the damage model: Tackle does 10 plus
+	//  half of 12 less 20%
+	e.turn(t, m.ID, p2, tackle)
+	if want, got := int32(10+12*80/100/2), e.moveTurns(t, m.ID)[0].DamageDone; got != want {
+		t.Errorf("intimidated Tackle did %d damage, want %d", got, want)
+	}
+
+	//--switched in
+	e.turn(t, m.ID, p1, toSprite)
+	if got := len(e.abilityTurns(t, m.ID, "intimidate")); got != 1 {
+		t.Errorf("intimidate triggered %d times after switching it out, want 1", got)
+	}
+	e.turn(t, m.ID, p1, toDrake)
+	if got := len(e.abilityTurns(t, m.ID, "intimidate")); got != 2 {
+		t.Errorf("intimidate triggered %d times after switching it in, want 2", got)
+	}
+	if bonus := e.active(t, m.ID, p2).AttackBonus; bonus != -40 {
+		t.Errorf("Flame Wolf attack bonus %d after two intimidates, want -40", bonus)
+	}
+
+	//--replacing a knocked out unit
+	e.turn(t, m.ID, p2, tackle)
+	e.setHP(t, e.active(t, m.ID, p1), e.unit(t, "Aqua Drake").BaseHp)
+	e.turn(t, m.ID, p2, tackle)
+	e.turn(t, m.ID, p1, toSprite)
+	e.turn(t, m.ID, p1, tackle)
+	e.setHP(t, e.active(t, m.ID, p1), 1)
+	e.turn(t, m.ID, p2, tackle)
+	if active := e.active(t, m.ID, p1); active.UnitID != e.unit(t, "Aqua Drake").ID {
+		t.Fatalf("unit %d replaced the Leaf Sprite, want the Aqua Drake", active.UnitID)
+	}
+	if got := len(e.abilityTurns(t, m.ID, "intimidate")); got != 3 {
+		t.Errorf("intimidate triggered %d times after a KO, want 3", got)
+	}
+	if bonus := e.active(t, m.ID, p2).AttackBonus; bonus != game.MinStatBonus {
+		t.Errorf("Flame Wolf attack bonus %d after three intimidates, want %d", bonus, game.MinStatBonus)
+	}
+}
+
+func TestBeforeDamageAbilities(t *testing.T) {
+	for name, open := range storetest.Backends() {
+		t.Run(name, func(t *testing.T) {
+			testBeforeDamageAbilities(t, newTestEnv(t, open(t)))
+		})
+	}
+}
+
+// testBeforeDamageAbilities gives the target Flame Wolf each resist ability
+// in turn and hits it with a move of the resisted type, or of another type. The resist halves damage after the type
+// chart and after the attacker's held item: with Water doing 200% to Fire, a
+// Water Jet (18) from an Aqua Drake (attack 10) holding a +50% attack item
+// does (18 + 15/2) * 2 / 2 = 25 to a water_resist Flame Wolf, where halving
+// first would give 24.
+func testBeforeDamageAbilities(t *testing.T, e *testEnv) {
+	ctx := context.Background()
+	band := e.newItem(t, "Power Band", game.ItemHeld, game.EffectAttackBoost, 50)
+	noChart := e.newRuleset(t, "No chart", func(r *game.Ruleset) { r.TypeEffectivenessEnabled = false })
+
+	for i, tc := range []struct {
+		name       string
+		ability    string
+		attacker   string
+		held       int64
+		move       string
+		chart      int32 // percent for the move's type against Fire, 0 for neutral
+		rulesetID  int64
+		wantDamage int32
+		triggered  bool
+	}{
+		{"fire_resist on Fire", "fire_resist", "Flame Wolf", 0, "Fireball", 0, game.StandardRulesetID, (20 + 6) / 2, true},
+		{"water_resist on Water", "water_resist", "Aqua Drake", 0, "Water Jet", 0, game.StandardRulesetID, (18 + 5) / 2, true},
+		{"grass_resist on Grass", "grass_resist", "Leaf Sprite", 0, "Leaf Blade", 0, game.StandardRulesetID, (22 + 4) / 2, true},
+		{"water_resist on Fire", "water_resist", "Flame Wolf", 0, "Tackle", 0, game.StandardRulesetID, 10 + 6, false},
+		{"after the type chart", "water_resist", "Aqua Drake", 0, "Water Jet", 200, game.StandardRulesetID, (18 + 5) * 2 / 2, true},
+		{"after the held item", "water_resist", "Aqua Drake", band.ID, "Water Jet", 200, game.StandardRulesetID, (18 + 7) * 2 / 2, true},
+		{"chart turned off", "water_resist", "Aqua Drake", band.ID, "Water Jet", 200, noChart.ID, (18 + 7) / 2, true},
+	} {
+		e.setAbility(t, "Flame Wolf", tc.ability)
+		move := e.move(t, tc.move)
+		_, err := e.st.UpsertTypeEffectiveness(ctx, store.UpsertTypeEffectivenessParams{
+			AttackTypeID:      move.TypeID,
+			DefendTypeID:      e.unit(t, "Flame Wolf").TypeID,
+			MultiplierPercent: max(tc.chart, 100),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		p1, s1 := e.newItemPlayer(t, fmt.Sprintf("attacker%d", i), tc.attacker, tc.held)
+		p2, s2 := e.newItemPlayer(t, fmt.Sprintf("target%d", i), "Flame Wolf", 0)
+		m := e.startMatch(t, tc.rulesetID, p1, s1, p2, s2)
+		if m.CurrentActorPlayerID.Int64 != p1 {
+			// only the attacker's move is checked
+			e.turn(t, m.ID, p2, game.TurnAction{Kind: game.ActionMove, MoveID: e.move(t, "Tackle").ID})
+		}
+		e.turn(t, m.ID, p1, game.TurnAction{Kind: game.ActionMove, MoveID: move.ID})
+
+		moves := e.moveTurns(t, m.ID)
+		last := moves[len(moves)-1]
+		if last.DamageDone != tc.wantDamage {
+			t.Errorf("%s: %s did %d damage, want %d", tc.name, tc.move, last.DamageDone, tc.wantDamage)
+		}
+		target := e.active(t, m.ID, p2)
+		resisted := false
+		for _, turn := range e.abilityTurns(t, m.ID, tc.ability) {
+			resisted = resisted || turn.ActingMatchUnitID == target.ID
+		}
+		if resisted != tc.triggered {
+			t.Errorf("%s: %s triggered = %v, want %v", tc.name, tc.ability, resisted, tc.triggered)
+		}
+	}
+}
+
+func TestAfterHitAbility(t *testing.T) {
+	for name, open := range storetest.Backends() {
+		t.Run(name, func(t *testing.T) {
+			testAfterHitAbility(t, newTestEnv(t, open(t)))
+		})
+	}
+}
+
+// testAfterHitAbility gives the Flame Wolf rage, which raises its attack by
+// 10% each time a move hits it and leaves it standing, up to the limit.
+func testAfterHitAbility(t *testing.T, e *testEnv) {
+	ctx := context.Background()
+	e.setAbility(t, "Flame Wolf", "rage")
+	p1, s1 := e.newSquadPlayer(t, "alice", "Leaf Sprite")
+	p2, s2 := e.newSquadPlayer(t, "bob", "Flame Wolf")
+	m := e.startMatch(t, game.StandardRulesetID, p1, s1, p2, s2)
+	tackle := game.TurnAction{Kind: game.ActionMove, MoveID: e.move(t, "Tackle").ID}
+
+	//--the Leaf Sprite is faster, so it hits first
+	if err := e.game.ApplyTurn(ctx, m.ID, p1, tackle, 1); err != nil {
+		t.Fatal(err)
+	}
+	wolf := e.active(t, m.ID, p2)
+	turns := e.abilityTurns(t, m.ID, "rage")
+	if len(turns) != 1 || turns[0].TargetMatchUnitID != wolf.ID || wolf.AttackBonus != 10 {
+		t.Fatalf("rage after a hit: turns %+v, bonus %d; want one raising it to 10", turns, wolf.AttackBonus)
+	}
+	all, err := e.st.ListMatchTurns(ctx, m.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := all[len(all)-1]; last.Action != game.ActionAbility {
+		t.Errorf("last turn is a %s turn, want rage after the move", last.Action)
+	}
+
+	//--at the limit it doesn't trigger
+	if _, err := e.st.UpdateMatchUnitBonuses(ctx, store.UpdateMatchUnitBonusesParams{ID: wolf.ID, AttackBonus: game.MaxStatBonus}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.game.ApplyTurn(ctx, m.ID, p2, tackle, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.game.ApplyTurn(ctx, m.ID, p1, tackle, 3); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(e.abilityTurns(t, m.ID, "rage")); got != 1 {
+		t.Errorf("rage triggered %d times at the limit, want 1", got)
+	}
+
+	//--a knocked out unit doesn't rage
+	e.setHP(t, e.active(t, m.ID, p1), e.unit(t, "Leaf Sprite").BaseHp)
+	if err := e.game.ApplyTurn(ctx, m.ID, p2, tackle, 4); err != nil {
+		t.Fatal(err)
+	}
+	e.setHP(t, e.active(t, m.ID, p2), 1)
+	if err := e.game.ApplyTurn(ctx, m.ID, p1, tackle, 5); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(e.abilityTurns(t, m.ID, "rage")); got != 1 {
+		t.Errorf("rage triggered %d times after a KO, want 1", got)
+	}
+}
+
+func TestEndOfTurnAbility(t *testing.T) {
+	for name, open := range storetest.Backends() {
+		t.Run(name, func(t *testing.T) {
+			testEndOfTurnAbility(t, newTestEnv(t, open(t)))
+		})
+	}
+}
+
+// testEndOfTurnAbility gives the Leaf Sprite speed_boost, which raises its
+// speed by 10% after each of its side's turns. It comes after the side's
+// held item heals.
+func testEndOfTurnAbility(t *testing.T, e *testEnv) {
+	ctx := context.Background()
+	e.setAbility(t, "Leaf Sprite", "speed_boost")
+	leftovers := e.newItem(t, "Leftovers", game.ItemHeld, game.EffectHealEachTurn, 10)
+	p1, s1 := e.newItemPlayer(t, "alice", "Leaf Sprite", leftovers.ID)
+	p2, s2 := e.newItemPlayer(t, "bob", "Aqua Drake", 0)
+	m := e.startMatch(t, game.StandardRulesetID, p1, s1, p2, s2)
+	tackle := game.TurnAction{Kind: game.ActionMove, MoveID: e.move(t, "Tackle").ID}
+
+	e.setHP(t, e.active(t, m.ID, p1), 20)
+	if err := e.game.ApplyTurn(ctx, m.ID, p1, tackle, 1); err != nil {
+		t.Fatal(err)
+	}
+	if bonus := e.active(t, m.ID, p1).SpeedBonus; bonus != 10 {
+		t.Errorf("speed bonus %d after its turn, want 10", bonus)
+	}
+	all, err := e.st.ListMatchTurns(ctx, m.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actions []string
+	for _, turn := range all {
+		actions = append(actions, turn.Action)
+	}
+	want := []string{game.ActionMove, game.ActionItem, game.ActionAbility}
+	if len(actions) != len(want) || actions[0] != want[0] || actions[1] != want[1] || actions[2] != want[2] {
+		t.Errorf("turn 1: %v, want %v", actions, want)
+	}
+
+	//--not after the opponent's turn
+	if err := e.game.ApplyTurn(ctx, m.ID, p2, tackle, 2); err != nil {
+		t.Fatal(err)
+	}
+	if bonus := e.active(t, m.ID, p1).SpeedBonus; bonus != 10 {
+		t.Errorf("speed bonus %d after the opponent's turn, want 10", bonus)
+	}
+	if got := len(e.abilityTurns(t, m.ID, "speed_boost")); got != 1 {
+		t.Errorf("speed_boost triggered %d times, want 1", got)
+	}
+}
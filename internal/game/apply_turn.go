@@ -3,312 +3,664 @@ package game
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"math/rand"
+	"sort"
 
 	"github.com/76dillon/battle_squads/internal/store"
 )
 
+// Turn actions as stored in match_turns.action. Abilities triggering are
+// recorded as ActionAbility turns, which players never send.
+const (
+	ActionMove    = "move"
+	ActionSwitch  = "switch"
+	ActionItem    = "item"
+	ActionAbility = "ability"
+)
+
+// TurnAction is what a player does with a turn: use one of their active
+// unit's moves, switch it out for a unit on their bench, or use an item
+// from their bag on it. Switches and items depend on the match's ruleset.
+type TurnAction struct {
+	// Kind is ActionMove, ActionSwitch or ActionItem
+	Kind   string
+	MoveID int64
+	// SwitchTo is the squad position of the unit to switch in
+	SwitchTo int32
+	ItemID   int64
+}
+
 // ApplyTurn applies a player's action to the current match state.
+// expectedTurnNumber is the turn the client believes it is acting on; if the
+// match has already moved past it the turn is rejected with ErrStaleTurn.
+//
+// In a simultaneous match the action is only chosen here; once both players
+// have chosen, both actions are resolved, switches and items first and then
+// moves in speed order.
 func (s *Service) ApplyTurn(
 	ctx context.Context,
 	matchID int64,
 	actingPlayerID int64,
-	moveID int64,
+	action TurnAction,
+	expectedTurnNumber int32,
 ) error {
+	// 1. Run everything in one transaction; any error rolls it back
+	err := s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		//2. Load match by match ID and lock the row until commit, so concurrent
+		//   submissions for the same match are applied one at a time
+		match, err := qtx.GetMatchByIDForUpdate(ctx, matchID)
+		if err != nil {
+			return fmt.Errorf("error retrieving match information: %w", err)
+		}
+		rs, err := getRuleset(ctx, qtx, match.RulesetID)
+		if err != nil {
+			return err
+		}
+
+		//3. Validate it's the right player's turn
+		//--match.State must be in progress
+		if match.State != "IN_PROGRESS" {
+			return ErrMatchNotInProgress{Msg: "match is not in progress"}
+		}
+		//--match.CurrentTurnNumber must be equal to expectedTurnNumber. This
+		//  comes before the actor check: a client acting on an old turn is
+		//  told so, even though the turn has since passed to the other player
+		if match.CurrentTurnNumber != expectedTurnNumber {
+			return ErrStaleTurn{Msg: fmt.Sprintf("expected turn %d but match is on turn %d", expectedTurnNumber, match.CurrentTurnNumber)}
+		}
+		//--match.CurrentActorPlayerID must be equal to actingPlayerID; in
+		//  simultaneous mode there is no actor, both players choose
+		if rs.TurnMode == TurnsAlternating &&
+			(!match.CurrentActorPlayerID.Valid || match.CurrentActorPlayerID.Int64 != actingPlayerID) {
+			return ErrWrongTurn{Msg: "it is not your turn yet"}
+		}
+
+		//4. Find acting player's match_side and check the action is legal for it
+		sides, err := qtx.GetMatchSidesByMatchID(ctx, match.ID)
+		if err != nil {
+			return fmt.Errorf("error retrieving match sides: %w", err)
+		}
+		actingSide, opponentSide := splitSides(sides, actingPlayerID)
+		if actingSide == nil {
+			return ErrIllegalMove{Msg: "no side found for acting player"}
+		}
+		if opponentSide == nil {
+			return ErrIllegalMove{Msg: "no opponent side found"}
+		}
+		if err := checkAction(ctx, qtx, rs, *actingSide, action); err != nil {
+			return err
+		}
+
+		if rs.TurnMode == TurnsSimultaneous {
+			return s.chooseAction(ctx, qtx, rs, match, *actingSide, *opponentSide, action)
+		}
+
+		//5. Resolve the action
+		winnerID, err := s.resolveAction(ctx, qtx, rs, match, actingPlayerID, action)
+		if err != nil {
+			return err
+		}
+
+		//6. If match completed, update match to complete and commit transaction
+		if winnerID != 0 {
+			return finishMatch(ctx, qtx, match, winnerID)
+		}
+
+		//7. If match not completed
+		//--Increment match.CurrentTurnNumber
+		currentTurn := match.CurrentTurnNumber
+		nextTurn := currentTurn + 1
 
-	// 1. Begin transaction
-	tx, err := s.db.BeginTx(ctx, nil)
+		//--Decide next actor based on round logic
+		var nextActor int64
+		if currentTurn%2 == 1 {
+			// first action in this round -> second action goes to opponent
+			nextActor = opponentSide.PlayerID
+		} else {
+			// second action in round -> new round, recompute who goes first
+			// from the *current* active units (they may have switched)
+			p1Speed, err := activeSpeed(ctx, qtx, rs, sides, match.Player1ID)
+			if err != nil {
+				return err
+			}
+			p2Speed, err := activeSpeed(ctx, qtx, rs, sides, match.Player2ID)
+			if err != nil {
+				return err
+			}
+			nextActor = faster(match.Player1ID, p1Speed, match.Player2ID, p2Speed)
+		}
+
+		//8. Update matches with new current_turn_number and current_actor_player_id
+		_, err = qtx.UpdateMatchTurnAndActor(ctx, store.UpdateMatchTurnAndActorParams{
+			ID:                match.ID,
+			CurrentTurnNumber: nextTurn,
+			CurrentActorPlayerID: sql.NullInt64{
+				Int64: nextActor,
+				Valid: true,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("update match turn/actor: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("begin tx: %w", err)
+		return err
 	}
 
-	qtx := s.q.WithTx(tx)
+	//9. Tell anyone watching the match once the turn is committed
+	s.notify(matchID)
+	return nil
+}
+
+// ClaimTimeout ends a match whose turn timer has run out as a win for
+// playerID. Only a player the match isn't waiting on can claim it: the one
+// not on turn, or in simultaneous mode the one who has chosen an action
+// while the opponent hasn't.
+func (s *Service) ClaimTimeout(ctx context.Context, matchID, playerID int64) error {
+	err := s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		match, err := qtx.GetMatchByIDForUpdate(ctx, matchID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrMatchNotFound{Msg: fmt.Sprintf("match %d not found", matchID)}
+		}
+		if err != nil {
+			return fmt.Errorf("error retrieving match information: %w", err)
+		}
+		if match.State != "IN_PROGRESS" {
+			return ErrMatchNotInProgress{Msg: "match is not in progress"}
+		}
+		if playerID != match.Player1ID && playerID != match.Player2ID {
+			return ErrForbidden{Msg: "you are not playing in this match"}
+		}
+		rs, err := getRuleset(ctx, qtx, match.RulesetID)
+		if err != nil {
+			return err
+		}
+		deadline, ok := rs.TurnDeadline(match)
+		if !ok {
+			return ErrIllegalMove{Msg: "match has no turn timer"}
+		}
+		if s.now().Before(deadline) {
+			return ErrWrongTurn{Msg: "the turn timer has not run out yet"}
+		}
 
-	//2. Load match by match ID
-	match, err := qtx.GetMatchByID(ctx, matchID)
+		//--the match must be waiting on the opponent alone
+		if rs.TurnMode == TurnsAlternating {
+			if match.CurrentActorPlayerID.Int64 == playerID {
+				return ErrWrongTurn{Msg: "the match is waiting on you"}
+			}
+		} else {
+			sides, err := qtx.GetMatchSidesByMatchID(ctx, match.ID)
+			if err != nil {
+				return fmt.Errorf("error retrieving match sides: %w", err)
+			}
+			own, opp := splitSides(sides, playerID)
+			if own == nil || opp == nil {
+				return ErrIllegalMove{Msg: "match sides not found"}
+			}
+			if !hasPendingAction(*own) {
+				return ErrWrongTurn{Msg: "the match is waiting on you"}
+			}
+			if hasPendingAction(*opp) {
+				return ErrWrongTurn{Msg: "the match is not waiting on your opponent"}
+			}
+			//--the round will never be resolved
+			err = qtx.SetMatchSidePendingAction(ctx, store.SetMatchSidePendingActionParams{ID: own.ID})
+			if err != nil {
+				return fmt.Errorf("clear pending action: %w", err)
+			}
+		}
+		return finishMatch(ctx, qtx, match, playerID)
+	})
 	if err != nil {
-		_ = tx.Rollback()
-		return fmt.Errorf("error retrieving match information: %w", err)
+		return err
 	}
+	s.notify(matchID)
+	return nil
+}
 
-	//3. Validate it's the right player's turn
-	//--match.State must be in progress
-	if match.State != "IN_PROGRESS" {
-		_ = tx.Rollback()
-		return ErrMatchNotInProgress{Msg: "match is not in progress"}
+// splitSides returns playerID's side and their opponent's, either nil if
+// missing.
+func splitSides(sides []store.MatchSide, playerID int64) (own, opponent *store.MatchSide) {
+	for i := range sides {
+		if sides[i].PlayerID == playerID {
+			own = &sides[i]
+		} else {
+			opponent = &sides[i]
+		}
 	}
-	//--match.CurrentActorPlayerID must be equal to actingPlayerID
-	if !match.CurrentActorPlayerID.Valid || match.CurrentActorPlayerID.Int64 != actingPlayerID {
-		_ = tx.Rollback()
-		return ErrWrongTurn{Msg: "it is not your turn yet"}
+	return own, opponent
+}
+
+// checkAction returns ErrIllegalMove unless side can take action now.
+func checkAction(ctx context.Context, qtx store.Querier, rs Ruleset, side store.MatchSide, action TurnAction) error {
+	switch action.Kind {
+	case ActionMove:
+		//--Ensure the move is one the active unit knows
+		active, err := qtx.GetActiveMatchUnitForSide(ctx, side.ID)
+		if err != nil {
+			return fmt.Errorf("get acting active unit: %w", err)
+		}
+		if _, err := unitMove(ctx, qtx, active.UnitID, action.MoveID); err != nil {
+			return err
+		}
+		return nil
+	case ActionSwitch:
+		//--Ensure switching is allowed and the unit can come in
+		if !rs.SwitchingEnabled {
+			return ErrIllegalMove{Msg: "switching is not allowed in this match"}
+		}
+		units, err := qtx.GetMatchUnitsBySideID(ctx, side.ID)
+		if err != nil {
+			return fmt.Errorf("get units for side: %w", err)
+		}
+		for _, mu := range units {
+			if mu.Position != action.SwitchTo {
+				continue
+			}
+			if mu.Position == side.ActiveIndex {
+				return ErrIllegalMove{Msg: "that unit is already active"}
+			}
+			if mu.CurrentHp <= 0 {
+				return ErrIllegalMove{Msg: "that unit is knocked out"}
+			}
+			return nil
+		}
+		return ErrIllegalMove{Msg: fmt.Sprintf("no unit at position %d", action.SwitchTo)}
+	case ActionItem:
+		return checkItem(ctx, qtx, rs, side, action.ItemID)
+	default:
+		return ErrIllegalMove{Msg: fmt.Sprintf("unknown action %q", action.Kind)}
 	}
+}
 
-	//4. Find acting player's match_side and active_unit
-	//--GetMatchSidesByMatchID -> Pick one with PlayerID = actingPlayerID
-	sides, err := qtx.GetMatchSidesByMatchID(ctx, match.ID)
+// unitMove returns moveID if unitID knows it, or ErrIllegalMove.
+func unitMove(ctx context.Context, qtx store.Querier, unitID, moveID int64) (store.Move, error) {
+	moves, err := qtx.ListMovesForUnit(ctx, unitID)
 	if err != nil {
-		_ = tx.Rollback()
-		return fmt.Errorf("error retrieving match sides: %w", err)
+		return store.Move{}, fmt.Errorf("error retrieving moves for unit: %w", err)
 	}
-	var actingSide *store.MatchSide
-	for _, side := range sides {
-		if side.PlayerID == actingPlayerID {
-			actingSide = &side
-			break
+	for _, mv := range moves {
+		if mv.ID == moveID {
+			return mv, nil
 		}
 	}
-	if actingSide == nil {
-		_ = tx.Rollback()
-		return ErrIllegalMove{Msg: "no side found for acting player"}
-	}
+	return store.Move{}, ErrIllegalMove{Msg: "unit does not know this move"}
+}
 
-	//--GetActiveMatchUnitForSide(matchSide.ID) -> actingUnit
+// resolveAction carries out a checked action by playerID and records it as
+// a turn, along with any abilities it triggers, followed by the acting
+// side's end of turn. It returns the winner's ID if the action won the
+// match, else 0.
+func (s *Service) resolveAction(ctx context.Context, qtx store.Querier, rs Ruleset, match store.Match, playerID int64, action TurnAction) (int64, error) {
+	//--sides are reloaded as an earlier action this round may have changed them
+	sides, err := qtx.GetMatchSidesByMatchID(ctx, match.ID)
+	if err != nil {
+		return 0, fmt.Errorf("error retrieving match sides: %w", err)
+	}
+	actingSide, opponentSide := splitSides(sides, playerID)
+	if actingSide == nil || opponentSide == nil {
+		return 0, ErrIllegalMove{Msg: "match sides not found"}
+	}
 	actingMatchUnit, err := qtx.GetActiveMatchUnitForSide(ctx, actingSide.ID)
 	if err != nil {
-		_ = tx.Rollback()
-		return fmt.Errorf("get acting active unit: %w", err)
+		return 0, fmt.Errorf("get acting active unit: %w", err)
 	}
 
-	//5. Validate the target
-	//--find opponent side
-	var opponentSide *store.MatchSide
-	for i := range sides {
-		if sides[i].PlayerID != actingPlayerID {
-			opponentSide = &sides[i]
-			break
+	switch action.Kind {
+	case ActionSwitch:
+		if err := switchUnit(ctx, qtx, match, *actingSide, actingMatchUnit, action.SwitchTo); err != nil {
+			return 0, err
 		}
+		return 0, endTurn(ctx, qtx, rs, match, *actingSide)
+	case ActionItem:
+		if err := useItem(ctx, qtx, rs, match, *actingSide, actingMatchUnit, action.ItemID); err != nil {
+			return 0, err
+		}
+		return 0, endTurn(ctx, qtx, rs, match, *actingSide)
 	}
-	if opponentSide == nil {
-		_ = tx.Rollback()
-		return ErrIllegalMove{Msg: "no opponent side found"}
-	}
-	//--Load targetMatchUnit by GetActiveMatchUnitForSide(opponentSide.ID)
+
+	//--Load the target, the opponent's active unit, and ensure it is still up
 	targetMU, err := qtx.GetActiveMatchUnitForSide(ctx, opponentSide.ID)
 	if err != nil {
-		_ = tx.Rollback()
-		return fmt.Errorf("error retrieving opponent active unit: %w", err)
+		return 0, fmt.Errorf("error retrieving opponent active unit: %w", err)
 	}
-
-	//--Ensure targetMatchUnut.CurrentHp > 0
 	if targetMU.CurrentHp <= 0 {
-		_ = tx.Rollback()
-		return ErrIllegalMove{Msg: "opponent's active unit is already KO'd"}
+		return 0, ErrIllegalMove{Msg: "opponent's active unit is already KO'd"}
 	}
 
-	//6. Validate the move
-	//--Ensure moveID is allowed for actingUnit.UnitID(ListMovesForUnit and check for a dedicated query)
-	moves, err := qtx.ListMovesForUnit(ctx, actingMatchUnit.UnitID)
+	move, err := unitMove(ctx, qtx, actingMatchUnit.UnitID, action.MoveID)
 	if err != nil {
-		_ = tx.Rollback()
-		return fmt.Errorf("error retrieving moves for unit: %w", err)
+		return 0, err
 	}
 
-	var move *store.Move
-	for i := range moves {
-		if moves[i].ID == moveID {
-			move = &moves[i]
-			break
-		}
+	//--Roll the move's accuracy: a move with accuracy n hits n times in 100
+	if s.roll(100) >= int(move.Accuracy) {
+		return 0, missMove(ctx, qtx, rs, match, *actingSide, actingMatchUnit, targetMU, move)
 	}
 
-	if move == nil {
-		_ = tx.Rollback()
-		return ErrIllegalMove{Msg: "unit does not know this move"}
-	}
-
-	//7. Compute Damage
-	//--Load move (power, type) and actingUnit's Unit (base_attack)
+	//--Compute damage: the ruleset's damage model with the attacker's held
+	//  item and ability bonus, then the type chart (move type vs. target
+	//  unit type) if the ruleset uses it, then the target's ability
 	attackerUnit, err := qtx.GetUnitByID(ctx, actingMatchUnit.UnitID)
 	if err != nil {
-		_ = tx.Rollback()
-		return fmt.Errorf("get attacker unit: %w", err)
+		return 0, fmt.Errorf("get attacker unit: %w", err)
+	}
+	attack, err := boosted(ctx, qtx, rs, actingMatchUnit, attackerUnit.BaseAttack, EffectAttackBoost)
+	if err != nil {
+		return 0, err
+	}
+	attack = withBonus(attack, actingMatchUnit.AttackBonus)
+	damage := rs.Damage(move.Power, attack)
+	if rs.TypeEffectivenessEnabled {
+		targetUnit, err := qtx.GetUnitByID(ctx, targetMU.UnitID)
+		if err != nil {
+			return 0, fmt.Errorf("get target unit: %w", err)
+		}
+		damage, err = applyTypeEffectiveness(ctx, qtx, damage, move.TypeID, targetUnit.TypeID)
+		if err != nil {
+			return 0, err
+		}
+	}
+	moveType, err := qtx.GetUnitTypeByID(ctx, move.TypeID)
+	if err != nil {
+		return 0, fmt.Errorf("get move type: %w", err)
 	}
-	//--Damage rule: move.Power + unit.BaseAttack/2
-	damage := move.Power + attackerUnit.BaseAttack/2
-	if damage < 1 {
-		damage = 1
+	hit := Hit{Attacker: actingMatchUnit, Move: move, MoveType: moveType.Name, Damage: damage}
+	damage, err = beforeDamage(ctx, qtx, match, *opponentSide, targetMU, hit)
+	if err != nil {
+		return 0, err
 	}
+	hit.Damage = damage
 
 	//--Subtract damage from targetMatchUnit.CurrentHp, clamp at >=0
 	newHP := targetMU.CurrentHp - damage
 	if newHP < 0 {
 		newHP = 0
 	}
-	//8. Update target HP in match_units
 	updatedTargetMU, err := qtx.UpdateMatchUnitHP(ctx, store.UpdateMatchUnitHPParams{
 		ID:        targetMU.ID,
 		CurrentHp: newHP,
 	})
 	if err != nil {
-		_ = tx.Rollback()
-		return fmt.Errorf("update target hp: %w", err)
+		return 0, fmt.Errorf("update target hp: %w", err)
 	}
 
+	//--Damage beyond the target's remaining HP isn't dealt
+	dealt := targetMU.CurrentHp - newHP
 	didKO := updatedTargetMU.CurrentHp == 0
-	matchCompleted := false
-	var winnerID int64
-	//9. Check KO cases
+	_, err = qtx.CreateMatchTurn(ctx, store.CreateMatchTurnParams{
+		MatchID:           match.ID,
+		TurnNumber:        match.CurrentTurnNumber,
+		ActingPlayerID:    playerID,
+		ActingMatchUnitID: actingMatchUnit.ID,
+		MoveID:            sql.NullInt64{Int64: move.ID, Valid: true},
+		TargetMatchUnitID: updatedTargetMU.ID,
+		DamageDone:        dealt,
+		TargetHpAfter:     updatedTargetMU.CurrentHp,
+		DidKoTarget:       didKO,
+		Action:            ActionMove,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("create match turn: %w", err)
+	}
+	//--keep the acting player's stats current in the same transaction
+	if err := recordTurnStats(ctx, qtx, playerID, move.ID, dealt, didKO); err != nil {
+		return 0, err
+	}
+	if err := afterHit(ctx, qtx, match, *opponentSide, updatedTargetMU, hit); err != nil {
+		return 0, err
+	}
+
+	//--A KO brings in the opponent's next unit, or wins the match
 	if didKO {
-		// load units for the opponent side
-		units, err := qtx.GetMatchUnitsBySideID(ctx, opponentSide.ID)
+		replaced, err := replaceKnockedOut(ctx, qtx, match, *opponentSide)
 		if err != nil {
-			_ = tx.Rollback()
-			return fmt.Errorf("get units for opponent side: %w", err)
+			return 0, err
 		}
-
-		nextIndex := int32(-1)
-		for _, mu := range units {
-			if mu.Position > opponentSide.ActiveIndex && mu.CurrentHp > 0 {
-				nextIndex = mu.Position
-				break
-			}
+		if !replaced {
+			return playerID, nil
 		}
+	}
+	return 0, endTurn(ctx, qtx, rs, match, *actingSide)
+}
 
-		if nextIndex == -1 {
-			// no alive units left → mark match as completed and winner
-			if opponentSide.PlayerID == match.Player1ID {
-				winnerID = match.Player2ID
-			} else {
-				winnerID = match.Player1ID
-			}
-			matchCompleted = true
-		} else {
-			// auto-switch
-			_, err := qtx.UpdateMatchSideActiveIndex(ctx, store.UpdateMatchSideActiveIndexParams{
-				ID:          opponentSide.ID,
-				ActiveIndex: nextIndex,
-			})
-			if err != nil {
-				_ = tx.Rollback()
-				return fmt.Errorf("update active index: %w", err)
-			}
-		}
+// missMove records a move by side's active unit that missed its target. It
+// deals no damage and triggers no abilities, but counts as a use of the
+// move, and the acting side's turn still ends.
+func missMove(ctx context.Context, qtx store.Querier, rs Ruleset, match store.Match, side store.MatchSide, attacker, target store.MatchUnit, move store.Move) error {
+	_, err := qtx.CreateMatchTurn(ctx, store.CreateMatchTurnParams{
+		MatchID:           match.ID,
+		TurnNumber:        match.CurrentTurnNumber,
+		ActingPlayerID:    side.PlayerID,
+		ActingMatchUnitID: attacker.ID,
+		MoveID:            sql.NullInt64{Int64: move.ID, Valid: true},
+		TargetMatchUnitID: target.ID,
+		TargetHpAfter:     target.CurrentHp,
+		Action:            ActionMove,
+		Missed:            true,
+	})
+	if err != nil {
+		return fmt.Errorf("create match turn: %w", err)
+	}
+	if err := recordTurnStats(ctx, qtx, side.PlayerID, move.ID, 0, false); err != nil {
+		return err
+	}
+	return endTurn(ctx, qtx, rs, match, side)
+}
+
+// switchUnit makes the unit at position side's active unit and records the
+// switch as a turn whose target is the unit coming in. The unit going out
+// loses its stat bonuses, and the one coming in gets its switch-in ability.
+func switchUnit(ctx context.Context, qtx store.Querier, match store.Match, side store.MatchSide, outgoing store.MatchUnit, position int32) error {
+	if err := resetBonuses(ctx, qtx, outgoing); err != nil {
+		return err
+	}
+	if err := activate(ctx, qtx, side, position); err != nil {
+		return err
+	}
+	incoming, err := qtx.GetActiveMatchUnitForSide(ctx, side.ID)
+	if err != nil {
+		return fmt.Errorf("get switched in unit: %w", err)
 	}
 	_, err = qtx.CreateMatchTurn(ctx, store.CreateMatchTurnParams{
 		MatchID:           match.ID,
 		TurnNumber:        match.CurrentTurnNumber,
-		ActingPlayerID:    actingPlayerID,
-		ActingMatchUnitID: actingMatchUnit.ID,
-		MoveID:            moveID,
-		TargetMatchUnitID: updatedTargetMU.ID,
-		DamageDone:        damage,
-		TargetHpAfter:     updatedTargetMU.CurrentHp,
-		DidKoTarget:       didKO,
+		ActingPlayerID:    side.PlayerID,
+		ActingMatchUnitID: outgoing.ID,
+		TargetMatchUnitID: incoming.ID,
+		TargetHpAfter:     incoming.CurrentHp,
+		Action:            ActionSwitch,
 	})
 	if err != nil {
-		_ = tx.Rollback()
 		return fmt.Errorf("create match turn: %w", err)
 	}
+	return switchIn(ctx, qtx, match, side)
+}
 
-	//11. If match completed, update match to complete and commit transaction
-	if matchCompleted {
-		// finalize match
-		_, err = qtx.CompleteMatch(ctx, store.CompleteMatchParams{
-			ID: match.ID,
-			WinnerPlayerID: sql.NullInt64{
-				Int64: winnerID,
-				Valid: true,
-			},
-		})
-		if err != nil {
-			_ = tx.Rollback()
-			return fmt.Errorf("complete match: %w", err)
+// replaceKnockedOut brings in side's first unit still standing after its
+// active unit was knocked out, with its switch-in ability. It reports false
+// if there is none left.
+func replaceKnockedOut(ctx context.Context, qtx store.Querier, match store.Match, side store.MatchSide) (bool, error) {
+	units, err := qtx.GetMatchUnitsBySideID(ctx, side.ID)
+	if err != nil {
+		return false, fmt.Errorf("get units for side: %w", err)
+	}
+	for _, mu := range units {
+		if mu.Position != side.ActiveIndex && mu.CurrentHp > 0 {
+			if err := activate(ctx, qtx, side, mu.Position); err != nil {
+				return false, err
+			}
+			return true, switchIn(ctx, qtx, match, side)
 		}
+	}
+	return false, nil
+}
 
-		if err := tx.Commit(); err != nil {
-			return fmt.Errorf("commit tx: %w", err)
-		}
+// activate makes the unit at position side's active unit.
+func activate(ctx context.Context, qtx store.Querier, side store.MatchSide, position int32) error {
+	_, err := qtx.UpdateMatchSideActiveIndex(ctx, store.UpdateMatchSideActiveIndexParams{
+		ID:          side.ID,
+		ActiveIndex: position,
+	})
+	if err != nil {
+		return fmt.Errorf("update active index: %w", err)
+	}
+	//--the unit switching in is now visible to the opponent
+	err = qtx.RevealMatchUnit(ctx, store.RevealMatchUnitParams{
+		MatchSideID: side.ID,
+		Position:    position,
+	})
+	if err != nil {
+		return fmt.Errorf("reveal unit: %w", err)
+	}
+	return nil
+}
+
+// activeSpeed is the level-scaled speed of playerID's active unit, raised by
+// its held item and changed by abilities.
+func activeSpeed(ctx context.Context, qtx store.Querier, rs Ruleset, sides []store.MatchSide, playerID int64) (int32, error) {
+	side, _ := splitSides(sides, playerID)
+	if side == nil {
+		return 0, ErrIllegalMove{Msg: "match sides not found"}
+	}
+	active, err := qtx.GetActiveMatchUnitForSide(ctx, side.ID)
+	if err != nil {
+		return 0, fmt.Errorf("get active unit: %w", err)
+	}
+	unit, err := qtx.GetUnitByID(ctx, active.UnitID)
+	if err != nil {
+		return 0, fmt.Errorf("get unit: %w", err)
+	}
+	speed, err := boosted(ctx, qtx, rs, active, unit.BaseSpeed, EffectSpeedBoost)
+	if err != nil {
+		return 0, err
+	}
+	return rs.Stat(withBonus(speed, active.SpeedBonus)), nil
+}
+
+// chooseAction records side's action for the current round of a
+// simultaneous match, and resolves the round once both sides have chosen.
+func (s *Service) chooseAction(ctx context.Context, qtx store.Querier, rs Ruleset, match store.Match, side, opponent store.MatchSide, action TurnAction) error {
+	if hasPendingAction(side) {
+		return ErrWrongTurn{Msg: "you have already chosen your action for this turn"}
+	}
+	arg := store.SetMatchSidePendingActionParams{ID: side.ID}
+	switch action.Kind {
+	case ActionMove:
+		arg.PendingMoveID = sql.NullInt64{Int64: action.MoveID, Valid: true}
+	case ActionSwitch:
+		arg.PendingSwitchTo = sql.NullInt32{Int32: action.SwitchTo, Valid: true}
+	case ActionItem:
+		arg.PendingItemID = sql.NullInt64{Int64: action.ItemID, Valid: true}
+	}
+	if err := qtx.SetMatchSidePendingAction(ctx, arg); err != nil {
+		return fmt.Errorf("set pending action: %w", err)
+	}
+	if !hasPendingAction(opponent) {
 		return nil
 	}
+	side.PendingMoveID, side.PendingSwitchTo, side.PendingItemID = arg.PendingMoveID, arg.PendingSwitchTo, arg.PendingItemID
+	return s.resolveRound(ctx, qtx, rs, match, []store.MatchSide{side, opponent})
+}
 
-	//11. If match not completed
-	//--Increment match.CurrentTurnNumber
-	currentTurn := match.CurrentTurnNumber
-	nextTurn := currentTurn + 1
-
-	//--Decide next actor based on round logic
-	var opponentID int64
-	if actingPlayerID == match.Player1ID {
-		opponentID = match.Player2ID
-	} else {
-		opponentID = match.Player1ID
-	}
-	var nextActor int64
-
-	if currentTurn%2 == 1 {
-		// first action in this round -> second action goes to opponent
-		nextActor = opponentID
-	} else {
-		// second action in round -> new round, recompute who goes first
-
-		// Recompute speeds from *current* active units (they may have auto-switched)
-		// 1) find both sides again (you already have 'sides')
-		var p1Side, p2Side *store.MatchSide
-		for i := range sides {
-			if sides[i].PlayerID == match.Player1ID {
-				p1Side = &sides[i]
-			} else if sides[i].PlayerID == match.Player2ID {
-				p2Side = &sides[i]
-			}
-		}
+// chosenAction is one side's pending action in a simultaneous round.
+type chosenAction struct {
+	playerID int64
+	action   TurnAction
+	// unitID is the match unit that was active when the action was chosen
+	unitID int64
+	speed  int32
+}
 
-		// 2) get active match units for each side
-		p1Active, err := qtx.GetActiveMatchUnitForSide(ctx, p1Side.ID)
+// resolveRound resolves both sides' pending actions: switches and items
+// first, then moves, the faster unit first. An action is dropped if the
+// unit that chose it was knocked out before it could act.
+func (s *Service) resolveRound(ctx context.Context, qtx store.Querier, rs Ruleset, match store.Match, sides []store.MatchSide) error {
+	chosen := make([]chosenAction, 0, len(sides))
+	for _, side := range sides {
+		active, err := qtx.GetActiveMatchUnitForSide(ctx, side.ID)
 		if err != nil {
-			_ = tx.Rollback()
-			return fmt.Errorf("get p1 active unit: %w", err)
+			return fmt.Errorf("get active unit: %w", err)
 		}
-		p2Active, err := qtx.GetActiveMatchUnitForSide(ctx, p2Side.ID)
+		speed, err := activeSpeed(ctx, qtx, rs, sides, side.PlayerID)
 		if err != nil {
-			_ = tx.Rollback()
-			return fmt.Errorf("get p2 active unit: %w", err)
+			return err
+		}
+		c := chosenAction{playerID: side.PlayerID, unitID: active.ID, speed: speed}
+		switch {
+		case side.PendingMoveID.Valid:
+			c.action = TurnAction{Kind: ActionMove, MoveID: side.PendingMoveID.Int64}
+		case side.PendingItemID.Valid:
+			c.action = TurnAction{Kind: ActionItem, ItemID: side.PendingItemID.Int64}
+		default:
+			c.action = TurnAction{Kind: ActionSwitch, SwitchTo: side.PendingSwitchTo.Int32}
 		}
+		chosen = append(chosen, c)
+	}
+	first := faster(chosen[0].playerID, chosen[0].speed, chosen[1].playerID, chosen[1].speed)
+	sort.SliceStable(chosen, func(a, b int) bool {
+		if ma, mb := chosen[a].action.Kind == ActionMove, chosen[b].action.Kind == ActionMove; ma != mb {
+			return mb
+		}
+		return chosen[a].playerID == first
+	})
 
-		// 3) load units to get BaseSpeed
-		p1Unit, err := qtx.GetUnitByID(ctx, p1Active.UnitID)
+	for _, side := range sides {
+		if err := qtx.SetMatchSidePendingAction(ctx, store.SetMatchSidePendingActionParams{ID: side.ID}); err != nil {
+			return fmt.Errorf("clear pending action: %w", err)
+		}
+	}
+	for _, c := range chosen {
+		own, _ := splitSides(sides, c.playerID)
+		active, err := qtx.GetActiveMatchUnitForSide(ctx, own.ID)
 		if err != nil {
-			_ = tx.Rollback()
-			return fmt.Errorf("get p1 unit: %w", err)
+			return fmt.Errorf("get active unit: %w", err)
 		}
-		p2Unit, err := qtx.GetUnitByID(ctx, p2Active.UnitID)
+		if active.ID != c.unitID {
+			continue
+		}
+		winnerID, err := s.resolveAction(ctx, qtx, rs, match, c.playerID, c.action)
 		if err != nil {
-			_ = tx.Rollback()
-			return fmt.Errorf("get p2 unit: %w", err)
+			return err
 		}
-
-		// 4) compare speeds
-		if p1Unit.BaseSpeed > p2Unit.BaseSpeed {
-			nextActor = match.Player1ID
-		} else if p2Unit.BaseSpeed > p1Unit.BaseSpeed {
-			nextActor = match.Player2ID
-		} else {
-			// tie-breaker random
-			if rand.Intn(2) == 0 {
-				nextActor = match.Player1ID
-			} else {
-				nextActor = match.Player2ID
-			}
+		if winnerID != 0 {
+			return finishMatch(ctx, qtx, match, winnerID)
 		}
 	}
 
-	//12. Update matches with new current_turn_number and current_actor_player_id
-	_, err = qtx.UpdateMatchTurnAndActor(ctx, store.UpdateMatchTurnAndActorParams{
+	//--start the next round, with no actor
+	_, err := qtx.UpdateMatchTurnAndActor(ctx, store.UpdateMatchTurnAndActorParams{
 		ID:                match.ID,
-		CurrentTurnNumber: nextTurn,
-		CurrentActorPlayerID: sql.NullInt64{
-			Int64: nextActor,
+		CurrentTurnNumber: match.CurrentTurnNumber + 1,
+	})
+	if err != nil {
+		return fmt.Errorf("update match turn/actor: %w", err)
+	}
+	return nil
+}
+
+func hasPendingAction(side store.MatchSide) bool {
+	return side.PendingMoveID.Valid || side.PendingSwitchTo.Valid || side.PendingItemID.Valid
+}
+
+// finishMatch completes match as a win for winnerID and records the result
+// everywhere it counts, in the caller's transaction.
+func finishMatch(ctx context.Context, qtx store.Querier, match store.Match, winnerID int64) error {
+	_, err := qtx.CompleteMatch(ctx, store.CompleteMatchParams{
+		ID: match.ID,
+		WinnerPlayerID: sql.NullInt64{
+			Int64: winnerID,
 			Valid: true,
 		},
 	})
 	if err != nil {
-		_ = tx.Rollback()
-		return fmt.Errorf("update match turn/actor: %w", err)
+		return fmt.Errorf("complete match: %w", err)
 	}
-	//13. Commit transaction
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("commit tx: %w", err)
+	if err := recordMatchResult(ctx, qtx, match, winnerID); err != nil {
+		return err
 	}
-
-	return nil
+	//--a tournament match moves its tournament on, in the same transaction
+	return advanceTournament(ctx, qtx, match.ID, winnerID)
 }
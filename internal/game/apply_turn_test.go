@@ -0,0 +1,191 @@
+package game_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/76dillon/battle_squads/internal/game"
+	"github.com/76dillon/battle_squads/internal/store"
+	"github.com/76dillon/battle_squads/internal/store/storetest"
+)
+
+func TestApplyTurnConcurrent(t *testing.T) {
+	for name, open := range storetest.Backends() {
+		t.Run(name, func(t *testing.T) {
+			testApplyTurnConcurrent(t, newTestEnv(t, open(t)))
+		})
+	}
+}
+
+// testApplyTurnConcurrent submits the same turn many times at once, as a
+// client retrying on a flaky connection might. Exactly one must be applied.
+func testApplyTurnConcurrent(t *testing.T, e *testEnv) {
+	ctx := context.Background()
+	m := e.newMatch(t)
+	actor := m.CurrentActorPlayerID.Int64
+	moves, err := e.st.ListMovesForUnit(ctx, e.units[0].ID)
+	if err != nil || len(moves) == 0 {
+		t.Fatalf("no moves for unit %d: %v", e.units[0].ID, err)
+	}
+	action := game.TurnAction{Kind: game.ActionMove, MoveID: moves[0].ID}
+
+	const n = 8
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = e.game.ApplyTurn(ctx, m.ID, actor, action, m.CurrentTurnNumber)
+		}()
+	}
+	wg.Wait()
+
+	applied := 0
+	for _, err := range errs {
+		var stale game.ErrStaleTurn
+		switch {
+		case err == nil:
+			applied++
+		case !errors.As(err, &stale):
+			t.Errorf("ApplyTurn: got %v, want nil or ErrStaleTurn", err)
+		}
+	}
+	if applied != 1 {
+		t.Errorf("%d turns applied, want 1", applied)
+	}
+
+	after, err := e.st.GetMatchByID(ctx, m.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.CurrentTurnNumber != m.CurrentTurnNumber+1 {
+		t.Errorf("match on turn %d, want %d", after.CurrentTurnNumber, m.CurrentTurnNumber+1)
+	}
+}
+
+func TestMoveAccuracy(t *testing.T) {
+	for name, open := range storetest.Backends() {
+		t.Run(name, func(t *testing.T) {
+			testMoveAccuracy(t, newTestEnv(t, open(t)))
+		})
+	}
+}
+
+// testMoveAccuracy plays turns with fixed accuracy rolls either side of a
+// move's accuracy. A miss leaves the target alone but still uses the turn.
+func testMoveAccuracy(t *testing.T, e *testEnv) {
+	ctx := context.Background()
+	m := e.newMatch(t)
+	moves, err := e.st.ListMovesForUnit(ctx, e.units[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := make(map[string]store.Move)
+	for _, mv := range moves {
+		byName[mv.Name] = mv
+	}
+	fireball, tackle := byName["Fireball"], byName["Tackle"]
+	if fireball.Accuracy != 95 || tackle.Accuracy != 100 {
+		t.Fatalf("demo moves: Fireball accuracy %d, Tackle %d; want 95 and 100", fireball.Accuracy, tackle.Accuracy)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		move     store.Move
+		roll     int
+		wantMiss bool
+	}{
+		{"just under the accuracy", fireball, 94, false},
+		{"at the accuracy", fireball, 95, true},
+		{"highest roll at accuracy 100", tackle, 99, false},
+	} {
+		m, err := e.st.GetMatchByID(ctx, m.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		actor := m.CurrentActorPlayerID.Int64
+		// Full HP, so no hit ends the match
+		e.setHP(t, opponentActive(t, e, m, actor), e.units[0].BaseHp)
+		target := opponentActive(t, e, m, actor)
+
+		game.SetRoll(e.game, func(n int) int { return tc.roll })
+		action := game.TurnAction{Kind: game.ActionMove, MoveID: tc.move.ID}
+		if err := e.game.ApplyTurn(ctx, m.ID, actor, action, m.CurrentTurnNumber); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+
+		turns, err := e.st.ListMatchTurns(ctx, m.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		turn := turns[len(turns)-1]
+		after := opponentActive(t, e, m, actor)
+		if turn.Missed != tc.wantMiss {
+			t.Errorf("%s: missed %v, want %v", tc.name, turn.Missed, tc.wantMiss)
+		}
+		if tc.wantMiss && (turn.DamageDone != 0 || after.CurrentHp != target.CurrentHp) {
+			t.Errorf("%s: a miss did %d damage, HP %d -> %d", tc.name, turn.DamageDone, target.CurrentHp, after.CurrentHp)
+		}
+		if !tc.wantMiss && (turn.DamageDone <= 0 || after.CurrentHp != target.CurrentHp-turn.DamageDone) {
+			t.Errorf("%s: a hit did %d damage, HP %d -> %d", tc.name, turn.DamageDone, target.CurrentHp, after.CurrentHp)
+		}
+		next, err := e.st.GetMatchByID(ctx, m.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if next.CurrentTurnNumber != m.CurrentTurnNumber+1 {
+			t.Errorf("%s: match on turn %d, want %d", tc.name, next.CurrentTurnNumber, m.CurrentTurnNumber+1)
+		}
+	}
+
+	//--a miss counts as a use in the analytics, but not as a hit
+	rows, err := e.st.ListMoveAnalytics(ctx, store.ListMoveAnalyticsParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range rows {
+		var wantUses, wantHits int64
+		switch row.ID {
+		case fireball.ID:
+			wantUses, wantHits = 2, 1
+		case tackle.ID:
+			wantUses, wantHits = 1, 1
+		}
+		if row.Uses != wantUses || row.Hits != wantHits {
+			t.Errorf("%s: %d uses and %d hits, want %d and %d", row.Name, row.Uses, row.Hits, wantUses, wantHits)
+		}
+	}
+	units, err := e.st.ListUnitAnalytics(ctx, store.ListUnitAnalyticsParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range units {
+		if row.ID == e.units[0].ID && (row.Uses != 3 || row.Hits != 2) {
+			t.Errorf("%s: %d uses and %d hits, want 3 and 2", row.Name, row.Uses, row.Hits)
+		}
+	}
+}
+
+// opponentActive returns the active unit of playerID's opponent in m.
+func opponentActive(t *testing.T, e *testEnv, m store.Match, playerID int64) store.MatchUnit {
+	t.Helper()
+	sides, err := e.st.GetMatchSidesByMatchID(context.Background(), m.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, side := range sides {
+		if side.PlayerID == playerID {
+			continue
+		}
+		mu, err := e.st.GetActiveMatchUnitForSide(context.Background(), side.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return mu
+	}
+	t.Fatalf("no opponent side for player %d", playerID)
+	return store.MatchUnit{}
+}
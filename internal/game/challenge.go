@@ -0,0 +1,159 @@
+package game
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/76dillon/battle_squads/internal/player"
+	"github.com/76dillon/battle_squads/internal/store"
+)
+
+// Challenge states as stored in challenges.state
+const (
+	ChallengePending   = "PENDING"
+	ChallengeAccepted  = "ACCEPTED"
+	ChallengeDeclined  = "DECLINED"
+	ChallengeCancelled = "CANCELLED"
+)
+
+// NewChallenge is a match one player offers a friend. The challenger's squad
+// must already have been checked with squad.Service.CheckMatchSquads.
+type NewChallenge struct {
+	ChallengerID      int64
+	ChallengerSquadID int64
+	OpponentID        int64
+	Public            bool
+	// RulesetID is the ruleset the match will be played under; the
+	// challenger's squad must fit its size limits
+	RulesetID int64
+}
+
+// CreateChallenge offers a match to a friend. Only accepted friends can be
+// challenged, which also rules out players who blocked each other.
+func (s *Service) CreateChallenge(ctx context.Context, nc NewChallenge) (store.Challenge, error) {
+	var c store.Challenge
+	err := s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		rows, err := qtx.ListFriendshipsBetween(ctx, store.ListFriendshipsBetweenParams{
+			PlayerID: nc.ChallengerID,
+			OtherID:  nc.OpponentID,
+		})
+		if err != nil {
+			return fmt.Errorf("list friendships: %w", err)
+		}
+		friends := len(rows) == 1 && rows[0].Status == player.StatusAccepted
+		if !friends {
+			return ErrForbidden{Msg: "you can only challenge your friends"}
+		}
+
+		c, err = qtx.CreateChallenge(ctx, store.CreateChallengeParams{
+			ChallengerID:      nc.ChallengerID,
+			OpponentID:        nc.OpponentID,
+			ChallengerSquadID: nc.ChallengerSquadID,
+			IsPublic:          nc.Public,
+			RulesetID:         nc.RulesetID,
+		})
+		if err != nil {
+			return fmt.Errorf("create challenge: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return store.Challenge{}, err
+	}
+	return c, nil
+}
+
+// GetChallenge returns a challenge playerID sent or received.
+func (s *Service) GetChallenge(ctx context.Context, challengeID, playerID int64) (store.Challenge, error) {
+	return getChallenge(ctx, s.store, challengeID, playerID, false)
+}
+
+// AcceptChallenge starts the match a pending challenge offered, with the
+// opponent fielding squadID, and returns its ID. Only the challenged player
+// may accept, and not once either player has blocked the other. checkSquads
+// checks both squads as NewMatch.CheckSquads does.
+func (s *Service) AcceptChallenge(ctx context.Context, challengeID, playerID, squadID int64, checkSquads func(q store.Querier) error) (int64, error) {
+	var matchID int64
+	err := s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		c, err := getChallenge(ctx, qtx, challengeID, playerID, true)
+		if err != nil {
+			return err
+		}
+		if c.OpponentID != playerID {
+			return ErrForbidden{Msg: "only the challenged player can accept"}
+		}
+		if c.State != ChallengePending {
+			return ErrChallengeClosed{Msg: "challenge is no longer pending"}
+		}
+
+		matchID, err = createMatch(ctx, qtx, NewMatch{
+			Player1ID:      c.ChallengerID,
+			Player1SquadID: c.ChallengerSquadID,
+			Player2ID:      c.OpponentID,
+			Player2SquadID: squadID,
+			Public:         c.IsPublic,
+			RulesetID:      c.RulesetID,
+			CheckSquads:    checkSquads,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = qtx.RespondToChallenge(ctx, store.RespondToChallengeParams{
+			ID:      c.ID,
+			State:   ChallengeAccepted,
+			MatchID: sql.NullInt64{Int64: matchID, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("accept challenge: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return matchID, nil
+}
+
+// DeclineChallenge closes a pending challenge without playing it: the
+// challenged player declines it, or the challenger withdraws it.
+func (s *Service) DeclineChallenge(ctx context.Context, challengeID, playerID int64) error {
+	return s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		c, err := getChallenge(ctx, qtx, challengeID, playerID, true)
+		if err != nil {
+			return err
+		}
+		if c.State != ChallengePending {
+			return ErrChallengeClosed{Msg: "challenge is no longer pending"}
+		}
+
+		state := ChallengeDeclined
+		if c.ChallengerID == playerID {
+			state = ChallengeCancelled
+		}
+		_, err = qtx.RespondToChallenge(ctx, store.RespondToChallengeParams{ID: c.ID, State: state})
+		if err != nil {
+			return fmt.Errorf("decline challenge: %w", err)
+		}
+		return nil
+	})
+}
+
+// getChallenge loads a challenge, locking it if forUpdate. Challenges between
+// other players are reported as not found.
+func getChallenge(ctx context.Context, q store.Querier, challengeID, playerID int64, forUpdate bool) (store.Challenge, error) {
+	get := q.GetChallengeByID
+	if forUpdate {
+		get = q.GetChallengeByIDForUpdate
+	}
+	c, err := get(ctx, challengeID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && c.ChallengerID != playerID && c.OpponentID != playerID) {
+		return store.Challenge{}, ErrChallengeNotFound{Msg: "challenge not found"}
+	}
+	if err != nil {
+		return store.Challenge{}, fmt.Errorf("get challenge: %w", err)
+	}
+	return c, nil
+}
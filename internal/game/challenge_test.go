@@ -0,0 +1,114 @@
+package game_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/76dillon/battle_squads/internal/game"
+	"github.com/76dillon/battle_squads/internal/player"
+	"github.com/76dillon/battle_squads/internal/store/storetest"
+)
+
+func TestChallengeBlocks(t *testing.T) {
+	for name, open := range storetest.Backends() {
+		t.Run(name, func(t *testing.T) {
+			testChallengeBlocks(t, newTestEnv(t, open(t)))
+		})
+	}
+}
+
+// testChallengeBlocks checks that a block stops every way of getting a match
+// against the blocker: a new challenge, one already sent and a match created
+// directly.
+func testChallengeBlocks(t *testing.T, e *testEnv) {
+	ctx := context.Background()
+	players := player.NewService(e.st)
+	alice, aliceSquad := e.newPlayer(t, "alice")
+	bob, bobSquad := e.newPlayer(t, "bob")
+	challenge := game.NewChallenge{
+		ChallengerID:      alice,
+		ChallengerSquadID: aliceSquad,
+		OpponentID:        bob,
+		RulesetID:         game.StandardRulesetID,
+	}
+	wantForbidden := func(what string, err error) {
+		t.Helper()
+		var forbidden game.ErrForbidden
+		if !errors.As(err, &forbidden) {
+			t.Errorf("%s: got %v, want ErrForbidden", what, err)
+		}
+	}
+
+	//--only accepted friends can be challenged
+	_, err := e.game.CreateChallenge(ctx, challenge)
+	wantForbidden("challenging a stranger", err)
+	if _, err := players.AddFriend(ctx, alice, bob); err != nil {
+		t.Fatal(err)
+	}
+	_, err = e.game.CreateChallenge(ctx, challenge)
+	wantForbidden("challenging before the request is accepted", err)
+	if status, err := players.AddFriend(ctx, bob, alice); err != nil || status != player.StatusAccepted {
+		t.Fatalf("accepting the request: status %q, %v", status, err)
+	}
+	c, err := e.game.CreateChallenge(ctx, challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//--bob blocks alice with her challenge pending: he can't accept it and
+	//  she can't send another
+	if err := players.Block(ctx, bob, alice); err != nil {
+		t.Fatal(err)
+	}
+	_, err = e.game.AcceptChallenge(ctx, c.ID, bob, bobSquad, nil)
+	wantForbidden("accepting a blocked player's challenge", err)
+	_, err = e.game.CreateChallenge(ctx, challenge)
+	wantForbidden("challenging a player who blocked you", err)
+	if _, err := players.AddFriend(ctx, alice, bob); err == nil {
+		t.Error("befriending a player who blocked you succeeded")
+	}
+
+	//--nor can a match be made either way round
+	for _, nm := range []game.NewMatch{
+		{Player1ID: alice, Player1SquadID: aliceSquad, Player2ID: bob, Player2SquadID: bobSquad},
+		{Player1ID: bob, Player1SquadID: bobSquad, Player2ID: alice, Player2SquadID: aliceSquad},
+	} {
+		nm.RulesetID = game.StandardRulesetID
+		_, err := e.game.CreateMatch(ctx, nm)
+		wantForbidden("matching blocked players", err)
+	}
+
+	//--the challenge is still pending and can be withdrawn
+	got, err := e.game.GetChallenge(ctx, c.ID, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.State != game.ChallengePending {
+		t.Errorf("challenge is %s, want %s", got.State, game.ChallengePending)
+	}
+	if err := e.game.DeclineChallenge(ctx, c.ID, alice); err != nil {
+		t.Fatal(err)
+	}
+	var closed game.ErrChallengeClosed
+	if _, err := e.game.AcceptChallenge(ctx, c.ID, bob, bobSquad, nil); !errors.As(err, &closed) {
+		t.Errorf("accepting a withdrawn challenge: got %v, want ErrChallengeClosed", err)
+	}
+
+	//--unblocking allows matches again, but not challenges: the block ended
+	//  the friendship
+	if err := players.Unblock(ctx, bob, alice); err != nil {
+		t.Fatal(err)
+	}
+	_, err = e.game.CreateChallenge(ctx, challenge)
+	wantForbidden("challenging an unblocked former friend", err)
+	if _, err := e.game.CreateMatch(ctx, game.NewMatch{
+		Player1ID:      alice,
+		Player1SquadID: aliceSquad,
+		Player2ID:      bob,
+		Player2SquadID: bobSquad,
+		RulesetID:      game.StandardRulesetID,
+	}); err != nil {
+		t.Errorf("matching after the block is lifted: %v", err)
+	}
+}
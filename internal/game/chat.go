@@ -0,0 +1,216 @@
+package game
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/76dillon/battle_squads/internal/store"
+	"github.com/76dillon/battle_squads/internal/validation"
+)
+
+// ChatRules are the limits on match chat.
+type ChatRules struct {
+	// MaxLength is in characters
+	MaxLength int
+	// A player may send at most RateLimit messages per RateWindow, across
+	// all their matches
+	RateLimit  int
+	RateWindow time.Duration
+	// Filter screens every message; nil lets everything through
+	Filter MessageFilter
+	// MaxReportReasonLength is in characters
+	MaxReportReasonLength int
+}
+
+// DefaultChatRules allows short messages, five every ten seconds.
+func DefaultChatRules() ChatRules {
+	return ChatRules{
+		MaxLength:             280,
+		RateLimit:             5,
+		RateWindow:            10 * time.Second,
+		MaxReportReasonLength: 200,
+	}
+}
+
+// PostMessage adds a message to a match's chat and wakes anyone watching the
+// match. Only the match's players can chat.
+func (s *Service) PostMessage(ctx context.Context, matchID, playerID int64, body string) (store.MatchMessage, error) {
+	// 1. Check the message itself
+	body = strings.TrimSpace(body)
+	verr := validation.New("message")
+	if body == "" {
+		verr.Add("body", "is required")
+	} else if n := utf8.RuneCountInString(body); n > s.chat.MaxLength {
+		verr.Add("body", "must be at most %d characters, got %d", s.chat.MaxLength, n)
+	}
+	if err := verr.Err(); err != nil {
+		return store.MatchMessage{}, err
+	}
+
+	// 2. Only players may post; spectators of public matches are refused,
+	//    anyone else can't see the match at all
+	match, err := s.visibleMatch(ctx, matchID, playerID)
+	if err != nil {
+		return store.MatchMessage{}, err
+	}
+	if match.Player1ID != playerID && match.Player2ID != playerID {
+		return store.MatchMessage{}, ErrForbidden{Msg: "only the match's players can chat"}
+	}
+
+	// 3. Filter, outside the transaction as a filter may be slow
+	body, err = s.chat.Filter.FilterMessage(ctx, body)
+	if err != nil {
+		return store.MatchMessage{}, err
+	}
+
+	// 4. Rate limit and store. Counting stored messages keeps the limit the
+	//    same across server instances; locking the player makes concurrent
+	//    posts, in any of their matches, count one after another.
+	var mm store.MatchMessage
+	err = s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		if err := qtx.LockPlayer(ctx, playerID); err != nil {
+			return fmt.Errorf("lock player: %w", err)
+		}
+		recent, err := qtx.CountRecentMatchMessagesByPlayer(ctx, store.CountRecentMatchMessagesByPlayerParams{
+			PlayerID:  playerID,
+			CreatedAt: time.Now().UTC().Add(-s.chat.RateWindow),
+		})
+		if err != nil {
+			return fmt.Errorf("count recent messages: %w", err)
+		}
+		if recent >= int64(s.chat.RateLimit) {
+			return ErrRateLimited{Msg: "you are sending messages too quickly"}
+		}
+		mm, err = qtx.CreateMatchMessage(ctx, store.CreateMatchMessageParams{
+			MatchID:  matchID,
+			PlayerID: playerID,
+			Body:     body,
+		})
+		if err != nil {
+			return fmt.Errorf("create message: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return store.MatchMessage{}, err
+	}
+
+	s.notify(matchID)
+	return mm, nil
+}
+
+// ListMessages returns up to limit visible messages after afterID (0 for the
+// start), oldest first. Anyone who can view the match can read its chat.
+func (s *Service) ListMessages(ctx context.Context, matchID, viewerID, afterID int64, limit int) ([]store.ListMatchMessagesRow, error) {
+	if _, err := s.visibleMatch(ctx, matchID, viewerID); err != nil {
+		return nil, err
+	}
+	rows, err := s.store.ListMatchMessages(ctx, store.ListMatchMessagesParams{
+		MatchID:  matchID,
+		AfterID:  sql.NullInt64{Int64: afterID, Valid: afterID > 0},
+		RowLimit: int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list messages: %w", err)
+	}
+	return rows, nil
+}
+
+// ReportMessage flags a message for admin review. Reporting the same message
+// twice is not an error.
+func (s *Service) ReportMessage(ctx context.Context, matchID, messageID, reporterID int64, reason string) error {
+	reason = strings.TrimSpace(reason)
+	verr := validation.New("report")
+	if n := utf8.RuneCountInString(reason); n > s.chat.MaxReportReasonLength {
+		verr.Add("reason", "must be at most %d characters, got %d", s.chat.MaxReportReasonLength, n)
+	}
+	if err := verr.Err(); err != nil {
+		return err
+	}
+
+	if _, err := s.visibleMatch(ctx, matchID, reporterID); err != nil {
+		return err
+	}
+	mm, err := s.store.GetMatchMessageByID(ctx, messageID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && (mm.MatchID != matchID || mm.Hidden)) {
+		return ErrMatchNotFound{Msg: fmt.Sprintf("message %d not found", messageID)}
+	}
+	if err != nil {
+		return fmt.Errorf("get message: %w", err)
+	}
+	if mm.PlayerID == reporterID {
+		return ErrForbidden{Msg: "you cannot report your own message"}
+	}
+
+	_, err = s.store.CreateMatchMessageReport(ctx, store.CreateMatchMessageReportParams{
+		MessageID:  messageID,
+		ReporterID: reporterID,
+		Reason:     reason,
+	})
+	if err != nil {
+		return fmt.Errorf("create report: %w", err)
+	}
+	return nil
+}
+
+// ReviewMessage settles a message's reports: hide removes it from the chat.
+// The decision is recorded in the admin audit log.
+func (s *Service) ReviewMessage(ctx context.Context, adminID, messageID int64, hide bool) (store.MatchMessage, error) {
+	var mm store.MatchMessage
+	err := s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		var err error
+		mm, err = qtx.ReviewMatchMessage(ctx, store.ReviewMatchMessageParams{ID: messageID, Hidden: hide})
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrMatchNotFound{Msg: fmt.Sprintf("message %d not found", messageID)}
+		}
+		if err != nil {
+			return fmt.Errorf("review message: %w", err)
+		}
+
+		details, err := json.Marshal(struct {
+			MatchID int64 `json:"match_id"`
+			Hidden  bool  `json:"hidden"`
+		}{mm.MatchID, hide})
+		if err != nil {
+			return fmt.Errorf("marshal audit details: %w", err)
+		}
+		_, err = qtx.CreateAdminAuditEntry(ctx, store.CreateAdminAuditEntryParams{
+			AdminPlayerID: adminID,
+			Action:        "review",
+			EntityType:    "match_message",
+			EntityID:      mm.ID,
+			Details:       string(details),
+		})
+		if err != nil {
+			return fmt.Errorf("create audit entry: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return store.MatchMessage{}, err
+	}
+	if hide {
+		s.notify(mm.MatchID)
+	}
+	return mm, nil
+}
+
+// visibleMatch loads a match viewerID may see: any public match, or a
+// private one they play in.
+func (s *Service) visibleMatch(ctx context.Context, matchID, viewerID int64) (store.Match, error) {
+	match, err := s.store.GetMatchByID(ctx, matchID)
+	plays := err == nil && (match.Player1ID == viewerID || match.Player2ID == viewerID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !match.IsPublic && !plays) {
+		return store.Match{}, ErrMatchNotFound{Msg: fmt.Sprintf("match %d not found", matchID)}
+	}
+	if err != nil {
+		return store.Match{}, fmt.Errorf("get match: %w", err)
+	}
+	return match, nil
+}
@@ -0,0 +1,65 @@
+package game
+
+import (
+	"context"
+	"strings"
+	"unicode"
+)
+
+// MessageFilter screens chat messages before they are stored. It returns the
+// text to store, which may have words masked, or ErrMessageRejected to refuse
+// the message. Any other error fails the request.
+type MessageFilter interface {
+	FilterMessage(ctx context.Context, text string) (string, error)
+}
+
+// NopFilter lets every message through unchanged.
+type NopFilter struct{}
+
+func (NopFilter) FilterMessage(ctx context.Context, text string) (string, error) {
+	return text, nil
+}
+
+// WordFilter masks listed words with asterisks, ignoring case. Only whole
+// words match, so "class" is left alone when "ass" is listed.
+type WordFilter struct {
+	words map[string]bool
+}
+
+func NewWordFilter(words []string) *WordFilter {
+	f := &WordFilter{words: make(map[string]bool, len(words))}
+	for _, w := range words {
+		if w = strings.ToLower(strings.TrimSpace(w)); w != "" {
+			f.words[w] = true
+		}
+	}
+	return f
+}
+
+func (f *WordFilter) FilterMessage(ctx context.Context, text string) (string, error) {
+	if len(f.words) == 0 {
+		return text, nil
+	}
+	runes := []rune(text)
+	for start := 0; start < len(runes); {
+		if !isWordRune(runes[start]) {
+			start++
+			continue
+		}
+		end := start
+		for end < len(runes) && isWordRune(runes[end]) {
+			end++
+		}
+		if f.words[strings.ToLower(string(runes[start:end]))] {
+			for i := start; i < end; i++ {
+				runes[i] = '*'
+			}
+		}
+		start = end
+	}
+	return string(runes), nil
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,54 @@
+package game_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/76dillon/battle_squads/internal/game"
+	"github.com/76dillon/battle_squads/internal/store/storetest"
+)
+
+func TestPostMessageConcurrent(t *testing.T) {
+	for name, open := range storetest.Backends() {
+		t.Run(name, func(t *testing.T) {
+			testPostMessageConcurrent(t, newTestEnv(t, open(t)))
+		})
+	}
+}
+
+// testPostMessageConcurrent sends a burst of messages at once. The rate
+// limit must let exactly its allowance through.
+func testPostMessageConcurrent(t *testing.T, e *testEnv) {
+	ctx := context.Background()
+	m := e.newMatch(t)
+	limit := game.DefaultChatRules().RateLimit
+
+	n := 3 * limit
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = e.game.PostMessage(ctx, m.ID, m.Player1ID, fmt.Sprintf("message %d", i))
+		}()
+	}
+	wg.Wait()
+
+	posted := 0
+	for _, err := range errs {
+		var limited game.ErrRateLimited
+		switch {
+		case err == nil:
+			posted++
+		case !errors.As(err, &limited):
+			t.Errorf("PostMessage: got %v, want nil or ErrRateLimited", err)
+		}
+	}
+	if posted != limit {
+		t.Errorf("%d messages posted, want %d", posted, limit)
+	}
+}
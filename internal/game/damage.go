@@ -0,0 +1,33 @@
+package game
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/76dillon/battle_squads/internal/store"
+)
+
+// applyTypeEffectiveness scales damage by the chart entry for an attack of
+// attackType against a unit of defendType. Pairs missing from the chart are
+// neutral. A non-zero multiplier always deals at least 1 damage; 0 means the
+// defender is immune.
+func applyTypeEffectiveness(ctx context.Context, q store.Querier, damage int32, attackType, defendType int64) (int32, error) {
+	te, err := q.GetTypeEffectiveness(ctx, store.GetTypeEffectivenessParams{
+		AttackTypeID: attackType,
+		DefendTypeID: defendType,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return damage, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("get type effectiveness: %w", err)
+	}
+
+	scaled := damage * te.MultiplierPercent / 100
+	if scaled < 1 && te.MultiplierPercent > 0 {
+		scaled = 1
+	}
+	return scaled, nil
+}
@@ -17,3 +17,66 @@ type ErrIllegalMove struct {
 }
 
 func (e ErrIllegalMove) Error() string { return e.Msg }
+
+type ErrStaleTurn struct {
+	Msg string
+}
+
+func (e ErrStaleTurn) Error() string { return e.Msg }
+
+type ErrMatchNotFound struct {
+	Msg string
+}
+
+func (e ErrMatchNotFound) Error() string { return e.Msg }
+
+type ErrForbidden struct {
+	Msg string
+}
+
+func (e ErrForbidden) Error() string { return e.Msg }
+
+type ErrChallengeNotFound struct {
+	Msg string
+}
+
+func (e ErrChallengeNotFound) Error() string { return e.Msg }
+
+type ErrChallengeClosed struct {
+	Msg string
+}
+
+func (e ErrChallengeClosed) Error() string { return e.Msg }
+
+type ErrRateLimited struct {
+	Msg string
+}
+
+func (e ErrRateLimited) Error() string { return e.Msg }
+
+// ErrMessageRejected is returned by a MessageFilter that refuses a message.
+type ErrMessageRejected struct {
+	Msg string
+}
+
+func (e ErrMessageRejected) Error() string { return e.Msg }
+
+type ErrTournamentNotFound struct {
+	Msg string
+}
+
+func (e ErrTournamentNotFound) Error() string { return e.Msg }
+
+// ErrTournamentConflict is returned when a tournament's state doesn't allow
+// the change, e.g. registering once it has started.
+type ErrTournamentConflict struct {
+	Msg string
+}
+
+func (e ErrTournamentConflict) Error() string { return e.Msg }
+
+type ErrRulesetNotFound struct {
+	Msg string
+}
+
+func (e ErrRulesetNotFound) Error() string { return e.Msg }
@@ -0,0 +1,21 @@
+package game
+
+import "time"
+
+// SetRoll replaces the random numbers s rolls accuracy with, so tests can
+// decide whether moves hit.
+func SetRoll(s *Service, roll func(n int) int) {
+	s.roll = roll
+}
+
+// SetNow replaces the clock s checks turn timers against.
+func SetNow(s *Service, now func() time.Time) {
+	s.now = now
+}
+
+// Watchers counts who is watching matchID.
+func Watchers(s *Service, matchID int64) int {
+	s.watchers.mu.Lock()
+	defer s.watchers.mu.Unlock()
+	return len(s.watchers.subs[matchID])
+}
@@ -0,0 +1,86 @@
+package game_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/76dillon/battle_squads/internal/devutil"
+	"github.com/76dillon/battle_squads/internal/game"
+	"github.com/76dillon/battle_squads/internal/squad"
+	"github.com/76dillon/battle_squads/internal/store"
+)
+
+// adminID is the dev admin the migrations seed.
+const adminID = 1
+
+// testEnv is a store with the demo content and the services over it.
+type testEnv struct {
+	st     store.Store
+	game   *game.Service
+	squads *squad.Service
+	units  []store.Unit
+}
+
+func newTestEnv(t *testing.T, st store.Store) *testEnv {
+	t.Helper()
+	ctx := context.Background()
+	if err := devutil.SeedDemoContent(ctx, st); err != nil {
+		t.Fatal(err)
+	}
+	units, err := st.ListUnits(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := game.NewService(st, game.DefaultChatRules())
+	// Every move hits unless a test says otherwise
+	game.SetRoll(svc, alwaysHit)
+	return &testEnv{
+		st:     st,
+		game:   svc,
+		squads: squad.NewService(st, squad.DefaultRules()),
+		units:  units,
+	}
+}
+
+// alwaysHit is the lowest accuracy roll, which hits with any accuracy.
+func alwaysHit(n int) int { return 0 }
+
+// newPlayer creates a player with a squad of the first demo unit and returns
+// both IDs.
+func (e *testEnv) newPlayer(t *testing.T, name string) (int64, int64) {
+	t.Helper()
+	ctx := context.Background()
+	p, err := e.st.CreatePlayer(ctx, store.CreatePlayerParams{Username: name, PasswordHash: "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sq, err := e.squads.Create(ctx, p.ID, fmt.Sprintf("%s's squad", name), []int64{e.units[0].ID}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p.ID, sq.ID
+}
+
+// newMatch starts a standard match between two new players and returns it.
+func (e *testEnv) newMatch(t *testing.T) store.Match {
+	t.Helper()
+	ctx := context.Background()
+	p1, s1 := e.newPlayer(t, "alice")
+	p2, s2 := e.newPlayer(t, "bob")
+	id, err := e.game.CreateMatch(ctx, game.NewMatch{
+		Player1ID:      p1,
+		Player1SquadID: s1,
+		Player2ID:      p2,
+		Player2SquadID: s2,
+		RulesetID:      game.StandardRulesetID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := e.st.GetMatchByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
@@ -0,0 +1,215 @@
+package game
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/76dillon/battle_squads/internal/store"
+)
+
+// Item kinds as stored in items.kind
+const (
+	// ItemHeld is equipped by a squad unit and works passively
+	ItemHeld = "held"
+	// ItemConsumable goes in a squad's bag and is used up as a turn action
+	ItemConsumable = "consumable"
+)
+
+// Item effects as stored in items.effect. An item's amount is a percentage
+// of the stat or of the max HP the effect works on.
+const (
+	// EffectAttackBoost raises the holder's attack
+	EffectAttackBoost = "attack_boost"
+	// EffectSpeedBoost raises the holder's speed
+	EffectSpeedBoost = "speed_boost"
+	// EffectHealEachTurn heals the holder after each turn its side takes
+	// while it is active
+	EffectHealEachTurn = "heal_each_turn"
+	// EffectHeal heals the side's active unit when used from the bag
+	EffectHeal = "heal"
+)
+
+// ItemEffects lists the effects an item of each kind can have.
+var ItemEffects = map[string][]string{
+	ItemHeld:       {EffectAttackBoost, EffectSpeedBoost, EffectHealEachTurn},
+	ItemConsumable: {EffectHeal},
+}
+
+// MaxItemAmount is the largest percentage an item's effect can have
+const MaxItemAmount = 100
+
+// heldItem returns the item mu is holding. ok is false if it holds none or
+// the ruleset has items turned off.
+func heldItem(ctx context.Context, q store.Querier, rs Ruleset, mu store.MatchUnit) (item store.Item, ok bool, err error) {
+	if !rs.ItemsEnabled || !mu.HeldItemID.Valid {
+		return store.Item{}, false, nil
+	}
+	item, err = q.GetItemByID(ctx, mu.HeldItemID.Int64)
+	if err != nil {
+		return store.Item{}, false, fmt.Errorf("get held item: %w", err)
+	}
+	return item, true, nil
+}
+
+// boosted returns stat raised by mu's held item if it has effect.
+func boosted(ctx context.Context, q store.Querier, rs Ruleset, mu store.MatchUnit, stat int32, effect string) (int32, error) {
+	item, ok, err := heldItem(ctx, q, rs, mu)
+	if err != nil || !ok || item.Effect != effect {
+		return stat, err
+	}
+	return stat * (100 + item.Amount) / 100, nil
+}
+
+// maxHP is mu's HP at full health under the ruleset.
+func maxHP(ctx context.Context, q store.Querier, rs Ruleset, mu store.MatchUnit) (int32, error) {
+	unit, err := q.GetUnitByID(ctx, mu.UnitID)
+	if err != nil {
+		return 0, fmt.Errorf("get unit: %w", err)
+	}
+	return rs.Stat(unit.BaseHp), nil
+}
+
+// heal restores percent of mu's max HP, at least 1 and never above the max.
+func heal(ctx context.Context, q store.Querier, rs Ruleset, mu store.MatchUnit, percent int32) (store.MatchUnit, error) {
+	max, err := maxHP(ctx, q, rs, mu)
+	if err != nil {
+		return store.MatchUnit{}, err
+	}
+	amount := max * percent / 100
+	if amount < 1 {
+		amount = 1
+	}
+	hp := mu.CurrentHp + amount
+	if hp > max {
+		hp = max
+	}
+	mu, err = q.UpdateMatchUnitHP(ctx, store.UpdateMatchUnitHPParams{ID: mu.ID, CurrentHp: hp})
+	if err != nil {
+		return store.MatchUnit{}, fmt.Errorf("update hp: %w", err)
+	}
+	return mu, nil
+}
+
+// checkItem returns ErrIllegalMove unless side can use itemID from its bag
+// on its active unit now.
+func checkItem(ctx context.Context, qtx store.Querier, rs Ruleset, side store.MatchSide, itemID int64) error {
+	if !rs.ItemsEnabled {
+		return ErrIllegalMove{Msg: "items are not allowed in this match"}
+	}
+	bagItem, err := qtx.GetMatchSideItem(ctx, store.GetMatchSideItemParams{
+		MatchSideID: side.ID,
+		ItemID:      itemID,
+	})
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && bagItem.Quantity == 0) {
+		return ErrIllegalMove{Msg: "you have none of that item left"}
+	}
+	if err != nil {
+		return fmt.Errorf("get bag item: %w", err)
+	}
+	//--a heal on a unit at full health would be wasted
+	active, err := qtx.GetActiveMatchUnitForSide(ctx, side.ID)
+	if err != nil {
+		return fmt.Errorf("get acting active unit: %w", err)
+	}
+	max, err := maxHP(ctx, qtx, rs, active)
+	if err != nil {
+		return err
+	}
+	if active.CurrentHp >= max {
+		return ErrIllegalMove{Msg: "your active unit is already at full hp"}
+	}
+	return nil
+}
+
+// useItem takes itemID out of side's bag, applies it to the active unit and
+// records it as an item turn.
+func useItem(ctx context.Context, qtx store.Querier, rs Ruleset, match store.Match, side store.MatchSide, active store.MatchUnit, itemID int64) error {
+	n, err := qtx.UseMatchSideItem(ctx, store.UseMatchSideItemParams{
+		MatchSideID: side.ID,
+		ItemID:      itemID,
+	})
+	if err != nil {
+		return fmt.Errorf("use bag item: %w", err)
+	}
+	if n == 0 {
+		return ErrIllegalMove{Msg: "you have none of that item left"}
+	}
+	item, err := qtx.GetItemByID(ctx, itemID)
+	if err != nil {
+		return fmt.Errorf("get item: %w", err)
+	}
+	if item.Effect == EffectHeal {
+		active, err = heal(ctx, qtx, rs, active, item.Amount)
+		if err != nil {
+			return err
+		}
+	}
+	return recordItemTurn(ctx, qtx, match, side.PlayerID, active, item.ID)
+}
+
+// healHeldItem heals side's active unit if it is holding a heal_each_turn
+// item, is still standing and has HP to recover. It runs after each turn
+// the side takes.
+func healHeldItem(ctx context.Context, qtx store.Querier, rs Ruleset, match store.Match, side store.MatchSide) error {
+	active, err := qtx.GetActiveMatchUnitForSide(ctx, side.ID)
+	if err != nil {
+		return fmt.Errorf("get active unit: %w", err)
+	}
+	item, ok, err := heldItem(ctx, qtx, rs, active)
+	if err != nil || !ok || item.Effect != EffectHealEachTurn || active.CurrentHp <= 0 {
+		return err
+	}
+	max, err := maxHP(ctx, qtx, rs, active)
+	if err != nil || active.CurrentHp >= max {
+		return err
+	}
+	active, err = heal(ctx, qtx, rs, active, item.Amount)
+	if err != nil {
+		return err
+	}
+	return recordItemTurn(ctx, qtx, match, side.PlayerID, active, item.ID)
+}
+
+// recordItemTurn records an item taking effect on mu, which is both the
+// acting unit and the target.
+func recordItemTurn(ctx context.Context, qtx store.Querier, match store.Match, playerID int64, mu store.MatchUnit, itemID int64) error {
+	_, err := qtx.CreateMatchTurn(ctx, store.CreateMatchTurnParams{
+		MatchID:           match.ID,
+		TurnNumber:        match.CurrentTurnNumber,
+		ActingPlayerID:    playerID,
+		ActingMatchUnitID: mu.ID,
+		TargetMatchUnitID: mu.ID,
+		TargetHpAfter:     mu.CurrentHp,
+		Action:            ActionItem,
+		ItemID:            sql.NullInt64{Int64: itemID, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("create match turn: %w", err)
+	}
+	return nil
+}
+
+// copyBag fills a match side's bag from the squad it was created from. The
+// bag stays empty when the ruleset has items turned off.
+func copyBag(ctx context.Context, qtx store.Querier, rs Ruleset, side store.MatchSide) error {
+	if !rs.ItemsEnabled {
+		return nil
+	}
+	bag, err := qtx.ListSquadBagItems(ctx, side.SquadID)
+	if err != nil {
+		return fmt.Errorf("list squad bag: %w", err)
+	}
+	for _, b := range bag {
+		_, err := qtx.CreateMatchSideItem(ctx, store.CreateMatchSideItemParams{
+			MatchSideID: side.ID,
+			ItemID:      b.ItemID,
+			Quantity:    b.Quantity,
+		})
+		if err != nil {
+			return fmt.Errorf("create match side item: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,271 @@
+package game_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/76dillon/battle_squads/internal/game"
+	"github.com/76dillon/battle_squads/internal/squad"
+	"github.com/76dillon/battle_squads/internal/store"
+	"github.com/76dillon/battle_squads/internal/store/storetest"
+)
+
+// newItem creates an item.
+func (e *testEnv) newItem(t *testing.T, name, kind, effect string, amount int32) store.Item {
+	t.Helper()
+	item, err := e.st.CreateItem(context.Background(), store.CreateItemParams{
+		Name:   name,
+		Kind:   kind,
+		Effect: effect,
+		Amount: amount,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return item
+}
+
+// newItemPlayer creates a player with a squad of one demo unit holding
+// held (none if 0) and carrying bag, and returns both IDs.
+func (e *testEnv) newItemPlayer(t *testing.T, name, unit string, held int64, bag ...squad.BagItem) (int64, int64) {
+	t.Helper()
+	ctx := context.Background()
+	p, err := e.st.CreatePlayer(ctx, store.CreatePlayerParams{Username: name, PasswordHash: "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var heldItemIDs []int64
+	if held != 0 {
+		heldItemIDs = []int64{held}
+	}
+	sq, err := e.squads.Create(ctx, p.ID, name+"'s squad", []int64{e.unit(t, unit).ID}, heldItemIDs, bag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p.ID, sq.ID
+}
+
+func TestHeldItemBoosts(t *testing.T) {
+	for name, open := range storetest.Backends() {
+		t.Run(name, func(t *testing.T) {
+			testHeldItemBoosts(t, newTestEnv(t, open(t)))
+		})
+	}
+}
+
+// testHeldItemBoosts has a Flame Wolf (attack 12) holding a +50% attack
+// item use Tackle (power 10) on another, and an Aqua Drake (speed 9)
+// holding a +100% speed item face a Leaf Sprite (speed 15).
+func testHeldItemBoosts(t *testing.T, e *testEnv) {
+	ctx := context.Background()
+	band := e.newItem(t, "Power Band", game.ItemHeld, game.EffectAttackBoost, 50)
+	scarf := e.newItem(t, "Quick Scarf", game.ItemHeld, game.EffectSpeedBoost, 100)
+	noItems := e.newRuleset(t, "No items", func(r *game.Ruleset) { r.ItemsEnabled = false })
+	fixed := e.newRuleset(t, "Fixed", func(r *game.Ruleset) { r.DamageModel = game.DamageFixed })
+	tackle := game.TurnAction{Kind: game.ActionMove, MoveID: e.move(t, "Tackle").ID}
+
+	for i, tc := range []struct {
+		name       string
+		rulesetID  int64
+		wantDamage int32
+	}{
+		{"boosted", game.StandardRulesetID, 10 + 18/2},
+		{"items turned off", noItems.ID, 10 + 12/2},
+		{"fixed damage", fixed.ID, 10},
+	} {
+		p1, s1 := e.newItemPlayer(t, string(rune('a'+i))+"-holder", "Flame Wolf", band.ID)
+		p2, s2 := e.newItemPlayer(t, string(rune('a'+i))+"-target", "Flame Wolf", 0)
+		m := e.startMatch(t, tc.rulesetID, p1, s1, p2, s2)
+		if m.CurrentActorPlayerID.Int64 != p1 {
+			// The match opens with the holder's opponent on turn
+			if err := e.game.ApplyTurn(ctx, m.ID, p2, game.TurnAction{Kind: game.ActionMove, MoveID: e.move(t, "Fireball").ID}, 1); err != nil {
+				t.Fatal(err)
+			}
+			m.CurrentTurnNumber++
+		}
+		if err := e.game.ApplyTurn(ctx, m.ID, p1, tackle, m.CurrentTurnNumber); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		turns := e.moveTurns(t, m.ID)
+		last := turns[len(turns)-1]
+		if last.ActingPlayerID != p1 || last.DamageDone != tc.wantDamage {
+			t.Errorf("%s: player %d did %d damage, want player %d doing %d", tc.name, last.ActingPlayerID, last.DamageDone, p1, tc.wantDamage)
+		}
+	}
+
+	//--the speed item makes the slower unit go first, unless items are off
+	for i, tc := range []struct {
+		name       string
+		rulesetID  int64
+		wantScarfy bool
+	}{
+		{"boosted", game.StandardRulesetID, true},
+		{"items turned off", noItems.ID, false},
+	} {
+		p1, s1 := e.newItemPlayer(t, string(rune('a'+i))+"-scarfed", "Aqua Drake", scarf.ID)
+		p2, s2 := e.newItemPlayer(t, string(rune('a'+i))+"-sprite", "Leaf Sprite", 0)
+		m := e.startMatch(t, tc.rulesetID, p1, s1, p2, s2)
+		if got := m.CurrentActorPlayerID.Int64 == p1; got != tc.wantScarfy {
+			t.Errorf("%s: scarfed unit goes first: %v, want %v", tc.name, got, tc.wantScarfy)
+		}
+	}
+}
+
+func TestHealEachTurn(t *testing.T) {
+	for name, open := range storetest.Backends() {
+		t.Run(name, func(t *testing.T) {
+			testHealEachTurn(t, newTestEnv(t, open(t)))
+		})
+	}
+}
+
+// testHealEachTurn has a Leaf Sprite (HP 30, faster than the Aqua Drake it
+// faces) hold a 50% heal_each_turn item. It heals after each of its own
+// turns, up to its max HP and not beyond.
+func testHealEachTurn(t *testing.T, e *testEnv) {
+	ctx := context.Background()
+	leftovers := e.newItem(t, "Leftovers", game.ItemHeld, game.EffectHealEachTurn, 50)
+	p1, s1 := e.newItemPlayer(t, "alice", "Leaf Sprite", leftovers.ID)
+	p2, s2 := e.newItemPlayer(t, "bob", "Aqua Drake", 0)
+	m := e.startMatch(t, game.StandardRulesetID, p1, s1, p2, s2)
+	tackle := game.TurnAction{Kind: game.ActionMove, MoveID: e.move(t, "Tackle").ID}
+
+	itemTurns := func() []store.MatchTurn {
+		turns, err := e.st.ListMatchTurns(ctx, m.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var items []store.MatchTurn
+		for _, turn := range turns {
+			if turn.Action == game.ActionItem {
+				items = append(items, turn)
+			}
+		}
+		return items
+	}
+
+	//--at full HP nothing happens
+	if err := e.game.ApplyTurn(ctx, m.ID, p1, tackle, 1); err != nil {
+		t.Fatal(err)
+	}
+	if items := itemTurns(); len(items) != 0 {
+		t.Errorf("%d heals at full HP, want 0", len(items))
+	}
+	//--the opponent's turn doesn't heal the holder
+	e.setHP(t, e.active(t, m.ID, p1), 28)
+	if err := e.game.ApplyTurn(ctx, m.ID, p2, tackle, 2); err != nil {
+		t.Fatal(err)
+	}
+	if items := itemTurns(); len(items) != 0 {
+		t.Errorf("%d heals on the opponent's turn, want 0", len(items))
+	}
+	//--a heal of 15 with 2 missing restores 2
+	e.setHP(t, e.active(t, m.ID, p1), 28)
+	if err := e.game.ApplyTurn(ctx, m.ID, p1, tackle, 3); err != nil {
+		t.Fatal(err)
+	}
+	items := itemTurns()
+	if len(items) != 1 || items[0].TargetHpAfter != 30 || items[0].ItemID.Int64 != leftovers.ID {
+		t.Fatalf("heals: got %+v, want one up to 30 HP", items)
+	}
+	if hp := e.active(t, m.ID, p1).CurrentHp; hp != 30 {
+		t.Errorf("holder has %d HP, want 30", hp)
+	}
+	//--well below the max it heals its amount
+	if err := e.game.ApplyTurn(ctx, m.ID, p2, tackle, 4); err != nil {
+		t.Fatal(err)
+	}
+	e.setHP(t, e.active(t, m.ID, p1), 5)
+	e.setHP(t, e.active(t, m.ID, p2), 35) // so this tackle doesn't end the match
+	if err := e.game.ApplyTurn(ctx, m.ID, p1, tackle, 5); err != nil {
+		t.Fatal(err)
+	}
+	if hp := e.active(t, m.ID, p1).CurrentHp; hp != 5+15 {
+		t.Errorf("holder has %d HP, want %d", hp, 5+15)
+	}
+}
+
+func TestConsumableHeal(t *testing.T) {
+	for name, open := range storetest.Backends() {
+		t.Run(name, func(t *testing.T) {
+			testConsumableHeal(t, newTestEnv(t, open(t)))
+		})
+	}
+}
+
+// testConsumableHeal uses a bag's only 50% potion on a Leaf Sprite (HP 30,
+// faster than the Aqua Drake it faces), then tries to use it again.
+func testConsumableHeal(t *testing.T, e *testEnv) {
+	ctx := context.Background()
+	potion := e.newItem(t, "Potion", game.ItemConsumable, game.EffectHeal, 50)
+	p1, s1 := e.newItemPlayer(t, "alice", "Leaf Sprite", 0, squad.BagItem{ItemID: potion.ID, Quantity: 1})
+	p2, s2 := e.newItemPlayer(t, "bob", "Aqua Drake", 0)
+	m := e.startMatch(t, game.StandardRulesetID, p1, s1, p2, s2)
+	use := game.TurnAction{Kind: game.ActionItem, ItemID: potion.ID}
+	tackle := game.TurnAction{Kind: game.ActionMove, MoveID: e.move(t, "Tackle").ID}
+	var illegal game.ErrIllegalMove
+
+	//--a heal at full HP would be wasted
+	if err := e.game.ApplyTurn(ctx, m.ID, p1, use, 1); !errors.As(err, &illegal) {
+		t.Errorf("heal at full HP: got %v, want ErrIllegalMove", err)
+	}
+	e.setHP(t, e.active(t, m.ID, p1), 10)
+	if err := e.game.ApplyTurn(ctx, m.ID, p1, use, 1); err != nil {
+		t.Fatal(err)
+	}
+	if hp := e.active(t, m.ID, p1).CurrentHp; hp != 10+15 {
+		t.Errorf("unit has %d HP after the potion, want %d", hp, 10+15)
+	}
+	sides, err := e.st.GetMatchSidesByMatchID(ctx, m.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	own, _ := splitSides(sides, p1)
+	left, err := e.st.GetMatchSideItem(ctx, store.GetMatchSideItemParams{MatchSideID: own.ID, ItemID: potion.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if left.Quantity != 0 {
+		t.Errorf("%d potions left, want 0", left.Quantity)
+	}
+	after, err := e.st.GetMatchByID(ctx, m.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.CurrentTurnNumber != 2 || after.CurrentActorPlayerID.Int64 != p2 {
+		t.Errorf("after the potion: turn %d for player %d, want turn 2 for %d", after.CurrentTurnNumber, after.CurrentActorPlayerID.Int64, p2)
+	}
+
+	//--an item the side doesn't have
+	if err := e.game.ApplyTurn(ctx, m.ID, p2, use, 2); !errors.As(err, &illegal) {
+		t.Errorf("item not in the bag: got %v, want ErrIllegalMove", err)
+	}
+
+	//--the used up potion is refused next turn, and the turn isn't spent
+	if err := e.game.ApplyTurn(ctx, m.ID, p2, tackle, 2); err != nil {
+		t.Fatal(err)
+	}
+	e.setHP(t, e.active(t, m.ID, p1), 10)
+	if err := e.game.ApplyTurn(ctx, m.ID, p1, use, 3); !errors.As(err, &illegal) {
+		t.Errorf("second potion: got %v, want ErrIllegalMove", err)
+	}
+	if hp := e.active(t, m.ID, p1).CurrentHp; hp != 10 {
+		t.Errorf("unit has %d HP after the refused potion, want 10", hp)
+	}
+	if err := e.game.ApplyTurn(ctx, m.ID, p1, tackle, 3); err != nil {
+		t.Errorf("turn after the refused potion: %v", err)
+	}
+}
+
+// splitSides returns playerID's side and their opponent's.
+func splitSides(sides []store.MatchSide, playerID int64) (own, opponent store.MatchSide) {
+	for _, side := range sides {
+		if side.PlayerID == playerID {
+			own = side
+		} else {
+			opponent = side
+		}
+	}
+	return own, opponent
+}
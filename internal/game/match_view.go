@@ -0,0 +1,162 @@
+package game
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/76dillon/battle_squads/internal/store"
+)
+
+// Perspective is who a MatchView was built for, which decides how much of
+// each side it shows.
+type Perspective string
+
+const (
+	// PerspectiveAdmin sees everything.
+	PerspectiveAdmin Perspective = "admin"
+	// PerspectivePlayer sees their own side in full and the opponent's side
+	// as a spectator would.
+	PerspectivePlayer Perspective = "player"
+	// PerspectiveSpectator sees each side's active unit and the units it has
+	// already revealed by switching them in, but no moves, held items or
+	// bags.
+	PerspectiveSpectator Perspective = "spectator"
+)
+
+// MatchView is everything needed to show a match: its sides and their units
+// with catalog names, types, abilities and held items, the moves of each active unit and
+// each side's bag.
+type MatchView struct {
+	Match       store.Match
+	Ruleset     Ruleset
+	Sides       []SideView
+	Perspective Perspective
+}
+
+type SideView struct {
+	store.MatchSide
+	Units []UnitView
+	// Bag is what is left of the side's consumables
+	Bag []store.ListMatchSideItemDetailsRow
+	// Hidden counts the units left out of Units because the viewer hasn't
+	// seen them yet
+	Hidden int
+}
+
+type UnitView struct {
+	store.ListMatchUnitDetailsRow
+	// Moves is only set for the side's active unit
+	Moves []store.ListActiveUnitMovesForMatchRow
+}
+
+// LoadMatchView loads a match in a fixed number of queries, however many
+// units it has. The view is complete; use ForViewer before showing it to a
+// player. A missing match returns ErrMatchNotFound.
+func (s *Service) LoadMatchView(ctx context.Context, matchID int64) (MatchView, error) {
+	//1. Match
+	match, err := s.store.GetMatchByID(ctx, matchID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return MatchView{}, ErrMatchNotFound{Msg: fmt.Sprintf("match %d not found", matchID)}
+	}
+	if err != nil {
+		return MatchView{}, fmt.Errorf("error retrieving match: %w", err)
+	}
+
+	rs, err := getRuleset(ctx, s.store, match.RulesetID)
+	if err != nil {
+		return MatchView{}, err
+	}
+
+	//2. Sides, then every unit, every active unit's moves and every bag item
+	//   in one query each
+	sides, err := s.store.GetMatchSidesByMatchID(ctx, matchID)
+	if err != nil {
+		return MatchView{}, fmt.Errorf("error retrieving match sides: %w", err)
+	}
+	units, err := s.store.ListMatchUnitDetails(ctx, matchID)
+	if err != nil {
+		return MatchView{}, fmt.Errorf("error retrieving match units: %w", err)
+	}
+	moves, err := s.store.ListActiveUnitMovesForMatch(ctx, matchID)
+	if err != nil {
+		return MatchView{}, fmt.Errorf("error retrieving active moves: %w", err)
+	}
+	bags, err := s.store.ListMatchSideItemDetails(ctx, matchID)
+	if err != nil {
+		return MatchView{}, fmt.Errorf("error retrieving bags: %w", err)
+	}
+
+	//3. Group units under their side and moves under their unit
+	movesByUnit := make(map[int64][]store.ListActiveUnitMovesForMatchRow)
+	for _, mv := range moves {
+		movesByUnit[mv.MatchUnitID] = append(movesByUnit[mv.MatchUnitID], mv)
+	}
+	view := MatchView{
+		Match:       match,
+		Ruleset:     rs,
+		Sides:       make([]SideView, 0, len(sides)),
+		Perspective: PerspectiveAdmin,
+	}
+	for _, side := range sides {
+		sv := SideView{MatchSide: side}
+		for _, u := range units {
+			if u.MatchSideID == side.ID {
+				sv.Units = append(sv.Units, UnitView{
+					ListMatchUnitDetailsRow: u,
+					Moves:                   movesByUnit[u.ID],
+				})
+			}
+		}
+		for _, b := range bags {
+			if b.MatchSideID == side.ID {
+				sv.Bag = append(sv.Bag, b)
+			}
+		}
+		view.Sides = append(view.Sides, sv)
+	}
+	return view, nil
+}
+
+// ForViewer returns the part of v that viewerID may see: their own side in
+// full if they are playing, and every other side as public. Anyone not
+// playing, including viewerID 0 for an anonymous request, is a spectator.
+func (v MatchView) ForViewer(viewerID int64) MatchView {
+	out := MatchView{
+		Match:       v.Match,
+		Ruleset:     v.Ruleset,
+		Sides:       make([]SideView, 0, len(v.Sides)),
+		Perspective: PerspectiveSpectator,
+	}
+	if viewerID == v.Match.Player1ID || viewerID == v.Match.Player2ID {
+		out.Perspective = PerspectivePlayer
+	}
+	for _, side := range v.Sides {
+		if out.Perspective == PerspectivePlayer && side.PlayerID == viewerID {
+			out.Sides = append(out.Sides, side)
+			continue
+		}
+		out.Sides = append(out.Sides, side.public())
+	}
+	return out
+}
+
+// public hides what the side's player hasn't shown yet: the bench until each
+// unit switches in, every unit's moves and held item, and the bag. Abilities
+// stay visible: a unit always has its catalog ability, which anyone can look
+// up in GET /units/{id} once they know the unit.
+func (sv SideView) public() SideView {
+	out := SideView{MatchSide: sv.MatchSide, Hidden: sv.Hidden}
+	for _, u := range sv.Units {
+		if !u.Revealed && u.Position != sv.ActiveIndex {
+			out.Hidden++
+			continue
+		}
+		u.Moves = nil
+		u.HeldItemID = sql.NullInt64{}
+		u.HeldItemName = sql.NullString{}
+		out.Units = append(out.Units, u)
+	}
+	return out
+}
@@ -0,0 +1,254 @@
+package game_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/76dillon/battle_squads/internal/game"
+	"github.com/76dillon/battle_squads/internal/store"
+	"github.com/76dillon/battle_squads/internal/store/storetest"
+)
+
+func TestMatchViewForViewer(t *testing.T) {
+	for name, open := range storetest.Backends() {
+		t.Run(name, func(t *testing.T) {
+			testMatchViewForViewer(t, newTestEnv(t, open(t)))
+		})
+	}
+}
+
+// testMatchViewForViewer has both leads hold an item, which only their own
+// player and admins may see.
+func testMatchViewForViewer(t *testing.T, e *testEnv) {
+	ctx := context.Background()
+	item, err := e.st.CreateItem(ctx, store.CreateItemParams{
+		Name:   "Power Band",
+		Kind:   game.ItemHeld,
+		Effect: game.EffectAttackBoost,
+		Amount: 10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var players, squads [2]int64
+	for i, name := range []string{"alice", "bob"} {
+		p, err := e.st.CreatePlayer(ctx, store.CreatePlayerParams{Username: name, PasswordHash: "x"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sq, err := e.squads.Create(ctx, p.ID, name+"'s squad", []int64{e.units[0].ID}, []int64{item.ID}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		players[i], squads[i] = p.ID, sq.ID
+	}
+	id, err := e.game.CreateMatch(ctx, game.NewMatch{
+		Player1ID:      players[0],
+		Player1SquadID: squads[0],
+		Player2ID:      players[1],
+		Player2SquadID: squads[1],
+		RulesetID:      game.StandardRulesetID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	full, err := e.game.LoadMatchView(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		view   game.MatchView
+		seesOf map[int64]bool
+	}{
+		{"admin", full, map[int64]bool{players[0]: true, players[1]: true}},
+		{"player", full.ForViewer(players[0]), map[int64]bool{players[0]: true}},
+		{"spectator", full.ForViewer(0), map[int64]bool{}},
+	} {
+		for _, side := range tc.view.Sides {
+			for _, u := range side.Units {
+				if got, want := u.HeldItemID.Valid, tc.seesOf[side.PlayerID]; got != want {
+					t.Errorf("%s: player %d's held item shown: %v, want %v", tc.name, side.PlayerID, got, want)
+				}
+				if got, want := u.HeldItemName.Valid, tc.seesOf[side.PlayerID]; got != want {
+					t.Errorf("%s: player %d's held item name shown: %v, want %v", tc.name, side.PlayerID, got, want)
+				}
+				if u.Ability != e.units[0].Ability {
+					t.Errorf("%s: player %d's unit has ability %q, want %q", tc.name, side.PlayerID, u.Ability, e.units[0].Ability)
+				}
+			}
+		}
+	}
+}
+
+// viewStore counts the queries LoadMatchView makes, checks each one gets the
+// caller's context and fails the one named in fail.
+type viewStore struct {
+	store.Store
+	t       *testing.T
+	queries map[string]int
+	fail    string
+}
+
+type ctxKey struct{}
+
+var errInjected = errors.New("injected")
+
+func (s *viewStore) query(ctx context.Context, name string) error {
+	s.t.Helper()
+	if ctx.Value(ctxKey{}) == nil {
+		s.t.Errorf("%s called without the caller's context", name)
+	}
+	s.queries[name]++
+	if name == s.fail {
+		return errInjected
+	}
+	return nil
+}
+
+func (s *viewStore) GetMatchByID(ctx context.Context, id int64) (store.Match, error) {
+	if err := s.query(ctx, "GetMatchByID"); err != nil {
+		return store.Match{}, err
+	}
+	return s.Store.GetMatchByID(ctx, id)
+}
+
+func (s *viewStore) GetRulesetByID(ctx context.Context, id int64) (store.Ruleset, error) {
+	if err := s.query(ctx, "GetRulesetByID"); err != nil {
+		return store.Ruleset{}, err
+	}
+	return s.Store.GetRulesetByID(ctx, id)
+}
+
+func (s *viewStore) GetMatchSidesByMatchID(ctx context.Context, id int64) ([]store.MatchSide, error) {
+	if err := s.query(ctx, "GetMatchSidesByMatchID"); err != nil {
+		return nil, err
+	}
+	return s.Store.GetMatchSidesByMatchID(ctx, id)
+}
+
+func (s *viewStore) ListMatchUnitDetails(ctx context.Context, id int64) ([]store.ListMatchUnitDetailsRow, error) {
+	if err := s.query(ctx, "ListMatchUnitDetails"); err != nil {
+		return nil, err
+	}
+	return s.Store.ListMatchUnitDetails(ctx, id)
+}
+
+func (s *viewStore) ListActiveUnitMovesForMatch(ctx context.Context, id int64) ([]store.ListActiveUnitMovesForMatchRow, error) {
+	if err := s.query(ctx, "ListActiveUnitMovesForMatch"); err != nil {
+		return nil, err
+	}
+	return s.Store.ListActiveUnitMovesForMatch(ctx, id)
+}
+
+func (s *viewStore) ListMatchSideItemDetails(ctx context.Context, id int64) ([]store.ListMatchSideItemDetailsRow, error) {
+	if err := s.query(ctx, "ListMatchSideItemDetails"); err != nil {
+		return nil, err
+	}
+	return s.Store.ListMatchSideItemDetails(ctx, id)
+}
+
+func (s *viewStore) GetMatchUnitsBySideID(ctx context.Context, id int64) ([]store.MatchUnit, error) {
+	s.query(ctx, "GetMatchUnitsBySideID")
+	return s.Store.GetMatchUnitsBySideID(ctx, id)
+}
+
+func (s *viewStore) ListMovesForUnit(ctx context.Context, id int64) ([]store.Move, error) {
+	s.query(ctx, "ListMovesForUnit")
+	return s.Store.ListMovesForUnit(ctx, id)
+}
+
+func TestLoadMatchView(t *testing.T) {
+	for name, open := range storetest.Backends() {
+		t.Run(name, func(t *testing.T) {
+			testLoadMatchView(t, newTestEnv(t, open(t)))
+		})
+	}
+}
+
+// testLoadMatchView loads a one-unit and a three-unit match through a
+// viewStore: both take the same queries, one each, and every unit comes
+// back with its catalog name and type in squad order.
+func testLoadMatchView(t *testing.T, e *testEnv) {
+	ctx := context.WithValue(context.Background(), ctxKey{}, true)
+	small := e.newMatch(t)
+	p1, s1 := e.newSquadPlayer(t, "carol", "Leaf Sprite", "Aqua Drake", "Flame Wolf")
+	p2, s2 := e.newSquadPlayer(t, "dave", "Aqua Drake", "Flame Wolf", "Leaf Sprite")
+	big := e.startMatch(t, game.StandardRulesetID, p1, s1, p2, s2)
+
+	vs := &viewStore{Store: e.st, t: t}
+	svc := game.NewService(vs, game.DefaultChatRules())
+	var counts []map[string]int
+	for _, id := range []int64{small.ID, big.ID} {
+		vs.queries = map[string]int{}
+		if _, err := svc.LoadMatchView(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+		counts = append(counts, vs.queries)
+	}
+	want := map[string]int{
+		"GetMatchByID":                1,
+		"GetRulesetByID":              1,
+		"GetMatchSidesByMatchID":      1,
+		"ListMatchUnitDetails":        1,
+		"ListActiveUnitMovesForMatch": 1,
+		"ListMatchSideItemDetails":    1,
+	}
+	for i, got := range counts {
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("match %d: queries %v, want %v", i+1, got, want)
+		}
+	}
+
+	//--units keep their squad order with names and types; only the active
+	//  unit has moves
+	v, err := e.game.LoadMatchView(ctx, big.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantUnits := map[int64][]string{
+		p1: {"Leaf Sprite/Grass", "Aqua Drake/Water", "Flame Wolf/Fire"},
+		p2: {"Aqua Drake/Water", "Flame Wolf/Fire", "Leaf Sprite/Grass"},
+	}
+	for _, side := range v.Sides {
+		var got []string
+		for _, u := range side.Units {
+			got = append(got, u.UnitName+"/"+u.TypeName)
+			if active := u.Position == side.ActiveIndex; active != (len(u.Moves) > 0) {
+				t.Errorf("player %d's %s: active %v with %d moves", side.PlayerID, u.UnitName, active, len(u.Moves))
+			}
+			for _, mv := range u.Moves {
+				if mv.Name == "" || mv.TypeName == "" {
+					t.Errorf("player %d's %s has move %+v without a name or type", side.PlayerID, u.UnitName, mv)
+				}
+			}
+		}
+		if !reflect.DeepEqual(got, wantUnits[side.PlayerID]) {
+			t.Errorf("player %d's units %v, want %v", side.PlayerID, got, wantUnits[side.PlayerID])
+		}
+	}
+
+	//--a failing query fails the whole view with its error
+	for name := range want {
+		vs.fail = name
+		if _, err := svc.LoadMatchView(ctx, big.ID); !errors.Is(err, errInjected) {
+			t.Errorf("%s failing: got %v, want the injected error", name, err)
+		}
+	}
+
+	//--a missing match is not found, and a cancelled request stops on the
+	//  SQL stores
+	var notFound game.ErrMatchNotFound
+	if _, err := e.game.LoadMatchView(ctx, 999); !errors.As(err, &notFound) {
+		t.Errorf("missing match: got %v, want ErrMatchNotFound", err)
+	}
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = e.game.LoadMatchView(cancelled, big.ID)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled: got %v, want context.Canceled", err)
+	}
+}
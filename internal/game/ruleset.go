@@ -0,0 +1,279 @@
+package game
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/76dillon/battle_squads/internal/store"
+	"github.com/76dillon/battle_squads/internal/validation"
+)
+
+// Damage models as stored in rulesets.damage_model
+const (
+	// DamageStandard is the move's power plus half the attacker's attack
+	DamageStandard = "standard"
+	// DamageFixed is the move's power alone
+	DamageFixed = "fixed"
+)
+
+// Turn modes as stored in rulesets.turn_mode
+const (
+	// TurnsAlternating has one player act at a time, faster unit first
+	TurnsAlternating = "alternating"
+	// TurnsSimultaneous has both players choose, then resolves both actions
+	TurnsSimultaneous = "simultaneous"
+)
+
+const (
+	// StandardRulesetID is the ruleset the migrations seed, used when a
+	// match doesn't name one.
+	StandardRulesetID = 1
+
+	// BaseLevel is the level the catalog's unit stats are given at
+	BaseLevel = 50
+
+	MaxRulesetNameLength = 64
+	MaxRulesetSquadSize  = 6
+	MaxLevelCap          = 100
+	MaxTurnTimerSeconds  = 24 * 60 * 60
+)
+
+// Ruleset is how a match is played. Every match is played under one, fixed
+// when the match is created.
+type Ruleset struct {
+	ID           int64  `json:"id"`
+	Name         string `json:"name"`
+	MinSquadSize int    `json:"min_squad_size"`
+	MaxSquadSize int    `json:"max_squad_size"`
+	// LevelCap is the level every unit plays at; stats scale from BaseLevel
+	LevelCap    int    `json:"level_cap"`
+	DamageModel string `json:"damage_model"`
+	// TurnTimerSeconds is how long a player has to act; 0 means no limit
+	TurnTimerSeconds         int    `json:"turn_timer_seconds"`
+	TurnMode                 string `json:"turn_mode"`
+	SwitchingEnabled         bool   `json:"switching_enabled"`
+	ItemsEnabled             bool   `json:"items_enabled"`
+	TypeEffectivenessEnabled bool   `json:"type_effectiveness_enabled"`
+}
+
+func newRuleset(r store.Ruleset) Ruleset {
+	return Ruleset{
+		ID:                       r.ID,
+		Name:                     r.Name,
+		MinSquadSize:             int(r.MinSquadSize),
+		MaxSquadSize:             int(r.MaxSquadSize),
+		LevelCap:                 int(r.LevelCap),
+		DamageModel:              r.DamageModel,
+		TurnTimerSeconds:         int(r.TurnTimerSeconds),
+		TurnMode:                 r.TurnMode,
+		SwitchingEnabled:         r.SwitchingEnabled,
+		ItemsEnabled:             r.ItemsEnabled,
+		TypeEffectivenessEnabled: r.TypeEffectivenessEnabled,
+	}
+}
+
+// Stat scales a catalog stat to the ruleset's level cap. It never drops
+// below 1.
+func (r Ruleset) Stat(base int32) int32 {
+	scaled := base * int32(r.LevelCap) / BaseLevel
+	if scaled < 1 {
+		scaled = 1
+	}
+	return scaled
+}
+
+// Damage is what a move of power deals when used by a unit with attack,
+// before type effectiveness. attack is the catalog stat; Damage scales it.
+func (r Ruleset) Damage(power, attack int32) int32 {
+	damage := power
+	if r.DamageModel == DamageStandard {
+		damage += r.Stat(attack) / 2
+	}
+	if damage < 1 {
+		damage = 1
+	}
+	return damage
+}
+
+// TurnDeadline is when the player (or, in simultaneous mode, players) the
+// match is waiting on run out of time. ok is false when the match isn't in
+// progress or the ruleset has no turn timer.
+func (r Ruleset) TurnDeadline(m store.Match) (deadline time.Time, ok bool) {
+	if r.TurnTimerSeconds == 0 || m.State != "IN_PROGRESS" || !m.TurnStartedAt.Valid {
+		return time.Time{}, false
+	}
+	return m.TurnStartedAt.Time.Add(time.Duration(r.TurnTimerSeconds) * time.Second), true
+}
+
+// validate trims the name and returns every problem with r.
+func (r *Ruleset) validate() error {
+	r.Name = strings.TrimSpace(r.Name)
+	verr := validation.New("ruleset")
+	if r.Name == "" {
+		verr.Add("name", "is required")
+	} else if n := utf8.RuneCountInString(r.Name); n > MaxRulesetNameLength {
+		verr.Add("name", "must be at most %d characters, got %d", MaxRulesetNameLength, n)
+	}
+	if r.MinSquadSize < 1 || r.MinSquadSize > MaxRulesetSquadSize {
+		verr.Add("min_squad_size", "must be between 1 and %d", MaxRulesetSquadSize)
+	}
+	if r.MaxSquadSize < r.MinSquadSize || r.MaxSquadSize > MaxRulesetSquadSize {
+		verr.Add("max_squad_size", "must be between min_squad_size and %d", MaxRulesetSquadSize)
+	}
+	if r.LevelCap < 1 || r.LevelCap > MaxLevelCap {
+		verr.Add("level_cap", "must be between 1 and %d", MaxLevelCap)
+	}
+	if r.DamageModel != DamageStandard && r.DamageModel != DamageFixed {
+		verr.Add("damage_model", "must be %q or %q", DamageStandard, DamageFixed)
+	}
+	if r.TurnTimerSeconds < 0 || r.TurnTimerSeconds > MaxTurnTimerSeconds {
+		verr.Add("turn_timer_seconds", "must be between 0 and %d", MaxTurnTimerSeconds)
+	}
+	if r.TurnMode != TurnsAlternating && r.TurnMode != TurnsSimultaneous {
+		verr.Add("turn_mode", "must be %q or %q", TurnsAlternating, TurnsSimultaneous)
+	}
+	return verr.Err()
+}
+
+// ListRulesets returns every ruleset, oldest first.
+func (s *Service) ListRulesets(ctx context.Context) ([]Ruleset, error) {
+	rows, err := s.store.ListRulesets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list rulesets: %w", err)
+	}
+	out := make([]Ruleset, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, newRuleset(r))
+	}
+	return out, nil
+}
+
+// GetRuleset returns a ruleset, or ErrRulesetNotFound.
+func (s *Service) GetRuleset(ctx context.Context, rulesetID int64) (Ruleset, error) {
+	return getRuleset(ctx, s.store, rulesetID)
+}
+
+// CreateRuleset adds a ruleset matches can be played under. Names are
+// unique. It is recorded in the admin audit log.
+func (s *Service) CreateRuleset(ctx context.Context, adminID int64, r Ruleset) (Ruleset, error) {
+	if err := r.validate(); err != nil {
+		return Ruleset{}, err
+	}
+	err := s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		if err := checkRulesetName(ctx, qtx, r.Name, 0); err != nil {
+			return err
+		}
+		row, err := qtx.CreateRuleset(ctx, store.CreateRulesetParams{
+			Name:                     r.Name,
+			MinSquadSize:             int32(r.MinSquadSize),
+			MaxSquadSize:             int32(r.MaxSquadSize),
+			LevelCap:                 int32(r.LevelCap),
+			DamageModel:              r.DamageModel,
+			TurnTimerSeconds:         int32(r.TurnTimerSeconds),
+			TurnMode:                 r.TurnMode,
+			SwitchingEnabled:         r.SwitchingEnabled,
+			ItemsEnabled:             r.ItemsEnabled,
+			TypeEffectivenessEnabled: r.TypeEffectivenessEnabled,
+		})
+		if err != nil {
+			return fmt.Errorf("create ruleset: %w", err)
+		}
+		r = newRuleset(row)
+		return auditRuleset(ctx, qtx, adminID, "create", r)
+	})
+	if err != nil {
+		return Ruleset{}, err
+	}
+	return r, nil
+}
+
+// UpdateRuleset replaces every setting of a ruleset. Matches already
+// started under it play on under the new settings. It is recorded in the
+// admin audit log.
+func (s *Service) UpdateRuleset(ctx context.Context, adminID int64, r Ruleset) (Ruleset, error) {
+	if err := r.validate(); err != nil {
+		return Ruleset{}, err
+	}
+	err := s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		if _, err := getRuleset(ctx, qtx, r.ID); err != nil {
+			return err
+		}
+		if err := checkRulesetName(ctx, qtx, r.Name, r.ID); err != nil {
+			return err
+		}
+		row, err := qtx.UpdateRuleset(ctx, store.UpdateRulesetParams{
+			ID:                       r.ID,
+			Name:                     r.Name,
+			MinSquadSize:             int32(r.MinSquadSize),
+			MaxSquadSize:             int32(r.MaxSquadSize),
+			LevelCap:                 int32(r.LevelCap),
+			DamageModel:              r.DamageModel,
+			TurnTimerSeconds:         int32(r.TurnTimerSeconds),
+			TurnMode:                 r.TurnMode,
+			SwitchingEnabled:         r.SwitchingEnabled,
+			ItemsEnabled:             r.ItemsEnabled,
+			TypeEffectivenessEnabled: r.TypeEffectivenessEnabled,
+		})
+		if err != nil {
+			return fmt.Errorf("update ruleset: %w", err)
+		}
+		r = newRuleset(row)
+		return auditRuleset(ctx, qtx, adminID, "update", r)
+	})
+	if err != nil {
+		return Ruleset{}, err
+	}
+	return r, nil
+}
+
+// checkRulesetName returns a validation error if a ruleset other than
+// exceptID already has name.
+func checkRulesetName(ctx context.Context, q store.Querier, name string, exceptID int64) error {
+	rows, err := q.ListRulesets(ctx)
+	if err != nil {
+		return fmt.Errorf("list rulesets: %w", err)
+	}
+	for _, r := range rows {
+		if r.ID != exceptID && strings.EqualFold(r.Name, name) {
+			verr := validation.New("ruleset")
+			verr.Add("name", "ruleset %q already exists", r.Name)
+			return verr.Err()
+		}
+	}
+	return nil
+}
+
+func getRuleset(ctx context.Context, q store.Querier, rulesetID int64) (Ruleset, error) {
+	r, err := q.GetRulesetByID(ctx, rulesetID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Ruleset{}, ErrRulesetNotFound{Msg: fmt.Sprintf("ruleset %d not found", rulesetID)}
+	}
+	if err != nil {
+		return Ruleset{}, fmt.Errorf("get ruleset: %w", err)
+	}
+	return newRuleset(r), nil
+}
+
+func auditRuleset(ctx context.Context, qtx store.Querier, adminID int64, action string, r Ruleset) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("marshal audit details: %w", err)
+	}
+	_, err = qtx.CreateAdminAuditEntry(ctx, store.CreateAdminAuditEntryParams{
+		AdminPlayerID: adminID,
+		Action:        action,
+		EntityType:    "ruleset",
+		EntityID:      r.ID,
+		Details:       string(b),
+	})
+	if err != nil {
+		return fmt.Errorf("create audit entry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,426 @@
+package game_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/76dillon/battle_squads/internal/game"
+	"github.com/76dillon/battle_squads/internal/store"
+	"github.com/76dillon/battle_squads/internal/store/storetest"
+)
+
+// newRuleset creates a ruleset like the standard one, changed by edit.
+func (e *testEnv) newRuleset(t *testing.T, name string, edit func(*game.Ruleset)) game.Ruleset {
+	t.Helper()
+	ctx := context.Background()
+	rs, err := e.game.GetRuleset(ctx, game.StandardRulesetID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs.Name = name
+	edit(&rs)
+	rs, err = e.game.CreateRuleset(ctx, adminID, rs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rs
+}
+
+// newSquadPlayer creates a player with a squad of the named demo units and
+// returns both IDs.
+func (e *testEnv) newSquadPlayer(t *testing.T, name string, units ...string) (int64, int64) {
+	t.Helper()
+	ctx := context.Background()
+	p, err := e.st.CreatePlayer(ctx, store.CreatePlayerParams{Username: name, PasswordHash: "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var unitIDs []int64
+	for _, u := range units {
+		unitIDs = append(unitIDs, e.unit(t, u).ID)
+	}
+	sq, err := e.squads.Create(ctx, p.ID, fmt.Sprintf("%s's squad", name), unitIDs, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p.ID, sq.ID
+}
+
+// unit returns the demo unit called name.
+func (e *testEnv) unit(t *testing.T, name string) store.Unit {
+	t.Helper()
+	for _, u := range e.units {
+		if u.Name == name {
+			return u
+		}
+	}
+	t.Fatalf("no demo unit %q", name)
+	return store.Unit{}
+}
+
+// move returns the demo move called name.
+func (e *testEnv) move(t *testing.T, name string) store.Move {
+	t.Helper()
+	moves, err := e.st.ListMoves(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mv := range moves {
+		if mv.Name == name {
+			return mv
+		}
+	}
+	t.Fatalf("no demo move %q", name)
+	return store.Move{}
+}
+
+// startMatch creates a match under rulesetID and returns it.
+func (e *testEnv) startMatch(t *testing.T, rulesetID, p1, s1, p2, s2 int64) store.Match {
+	t.Helper()
+	ctx := context.Background()
+	id, err := e.game.CreateMatch(ctx, game.NewMatch{
+		Player1ID:      p1,
+		Player1SquadID: s1,
+		Player2ID:      p2,
+		Player2SquadID: s2,
+		RulesetID:      rulesetID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := e.st.GetMatchByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// active returns playerID's active unit in matchID.
+func (e *testEnv) active(t *testing.T, matchID, playerID int64) store.MatchUnit {
+	t.Helper()
+	ctx := context.Background()
+	sides, err := e.st.GetMatchSidesByMatchID(ctx, matchID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, side := range sides {
+		if side.PlayerID != playerID {
+			continue
+		}
+		mu, err := e.st.GetActiveMatchUnitForSide(ctx, side.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return mu
+	}
+	t.Fatalf("player %d has no side in match %d", playerID, matchID)
+	return store.MatchUnit{}
+}
+
+// setHP sets a match unit's HP.
+func (e *testEnv) setHP(t *testing.T, mu store.MatchUnit, hp int32) {
+	t.Helper()
+	if _, err := e.st.UpdateMatchUnitHP(context.Background(), store.UpdateMatchUnitHPParams{ID: mu.ID, CurrentHp: hp}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// moveTurns returns matchID's move turns in the order they were played.
+func (e *testEnv) moveTurns(t *testing.T, matchID int64) []store.MatchTurn {
+	t.Helper()
+	turns, err := e.st.ListMatchTurns(context.Background(), matchID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var moves []store.MatchTurn
+	for _, turn := range turns {
+		if turn.Action == game.ActionMove {
+			moves = append(moves, turn)
+		}
+	}
+	return moves
+}
+
+func TestSimultaneousTurns(t *testing.T) {
+	for name, open := range storetest.Backends() {
+		t.Run(name, func(t *testing.T) {
+			testSimultaneousTurns(t, newTestEnv(t, open(t)))
+		})
+	}
+}
+
+// testSimultaneousTurns plays rounds where both players choose before
+// anything happens: a Leaf Sprite (speed 15) against a slower Aqua Drake
+// (speed 9) backed by a Flame Wolf.
+func testSimultaneousTurns(t *testing.T, e *testEnv) {
+	ctx := context.Background()
+	rs := e.newRuleset(t, "Simultaneous", func(r *game.Ruleset) { r.TurnMode = game.TurnsSimultaneous })
+	fast, fastSquad := e.newSquadPlayer(t, "fast", "Leaf Sprite")
+	slow, slowSquad := e.newSquadPlayer(t, "slow", "Aqua Drake", "Flame Wolf")
+	m := e.startMatch(t, rs.ID, fast, fastSquad, slow, slowSquad)
+	if m.CurrentActorPlayerID.Valid {
+		t.Fatalf("simultaneous match has actor %d", m.CurrentActorPlayerID.Int64)
+	}
+	tackle := game.TurnAction{Kind: game.ActionMove, MoveID: e.move(t, "Tackle").ID}
+
+	//--the slower player chooses first; nothing happens until both have
+	if err := e.game.ApplyTurn(ctx, m.ID, slow, tackle, 1); err != nil {
+		t.Fatal(err)
+	}
+	var wrongTurn game.ErrWrongTurn
+	if err := e.game.ApplyTurn(ctx, m.ID, slow, tackle, 1); !errors.As(err, &wrongTurn) {
+		t.Errorf("second choice for the same turn: got %v, want ErrWrongTurn", err)
+	}
+	if turns := e.moveTurns(t, m.ID); len(turns) != 0 {
+		t.Fatalf("%d moves resolved with one action chosen, want 0", len(turns))
+	}
+
+	//--once both have chosen the faster unit moves first
+	if err := e.game.ApplyTurn(ctx, m.ID, fast, tackle, 1); err != nil {
+		t.Fatal(err)
+	}
+	turns := e.moveTurns(t, m.ID)
+	if len(turns) != 2 || turns[0].ActingPlayerID != fast || turns[1].ActingPlayerID != slow {
+		t.Fatalf("round 1 moves: got %+v, want fast then slow", turns)
+	}
+	after, err := e.st.GetMatchByID(ctx, m.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.CurrentTurnNumber != 2 || after.CurrentActorPlayerID.Valid {
+		t.Errorf("after round 1: turn %d, actor %v; want turn 2 with no actor", after.CurrentTurnNumber, after.CurrentActorPlayerID)
+	}
+
+	//--a switch goes before moves, so the faster unit's move hits the unit
+	//  switching in
+	if err := e.game.ApplyTurn(ctx, m.ID, fast, tackle, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.game.ApplyTurn(ctx, m.ID, slow, game.TurnAction{Kind: game.ActionSwitch, SwitchTo: 1}, 2); err != nil {
+		t.Fatal(err)
+	}
+	wolf := e.active(t, m.ID, slow)
+	if wolf.UnitID != e.unit(t, "Flame Wolf").ID {
+		t.Fatalf("slow's active unit is %d after switching, want the Flame Wolf", wolf.UnitID)
+	}
+	turns = e.moveTurns(t, m.ID)
+	if len(turns) != 3 || turns[2].TargetMatchUnitID != wolf.ID {
+		t.Errorf("round 2 moves: got %+v, want one hitting the Flame Wolf", turns[2:])
+	}
+
+	//--a unit knocked out before it acts loses its action: both units would
+	//  knock each other out, but only the faster one gets to
+	e.setHP(t, wolf, 1)
+	e.setHP(t, e.active(t, m.ID, fast), 1)
+	if err := e.game.ApplyTurn(ctx, m.ID, slow, tackle, 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.game.ApplyTurn(ctx, m.ID, fast, tackle, 3); err != nil {
+		t.Fatal(err)
+	}
+	turns = e.moveTurns(t, m.ID)
+	if len(turns) != 4 || turns[3].ActingPlayerID != fast || !turns[3].DidKoTarget {
+		t.Fatalf("round 3 moves: got %+v, want only fast's KO", turns[3:])
+	}
+	drake := e.active(t, m.ID, slow)
+	if drake.UnitID != e.unit(t, "Aqua Drake").ID {
+		t.Errorf("slow's active unit is %d after the KO, want the Aqua Drake back", drake.UnitID)
+	}
+	if sprite := e.active(t, m.ID, fast); sprite.CurrentHp != 1 {
+		t.Errorf("fast's unit has %d HP, want 1: the knocked out unit must not act", sprite.CurrentHp)
+	}
+
+	//--the same with no unit left to send in ends the match
+	e.setHP(t, drake, 1)
+	if err := e.game.ApplyTurn(ctx, m.ID, slow, tackle, 4); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.game.ApplyTurn(ctx, m.ID, fast, tackle, 4); err != nil {
+		t.Fatal(err)
+	}
+	done, err := e.st.GetMatchByID(ctx, m.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if done.State != "COMPLETED" || done.WinnerPlayerID.Int64 != fast {
+		t.Errorf("match %s, winner %v; want completed and won by fast", done.State, done.WinnerPlayerID)
+	}
+	if turns := e.moveTurns(t, m.ID); len(turns) != 5 {
+		t.Errorf("%d moves played, want 5", len(turns))
+	}
+	sides, err := e.st.GetMatchSidesByMatchID(ctx, m.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, side := range sides {
+		if side.PendingMoveID.Valid || side.PendingSwitchTo.Valid || side.PendingItemID.Valid {
+			t.Errorf("player %d still has a pending action", side.PlayerID)
+		}
+	}
+}
+
+func TestClaimTimeout(t *testing.T) {
+	for name, open := range storetest.Backends() {
+		t.Run(name, func(t *testing.T) {
+			testClaimTimeout(t, newTestEnv(t, open(t)))
+		})
+	}
+}
+
+// testClaimTimeout claims matches just before and at their turn deadline.
+func testClaimTimeout(t *testing.T, e *testEnv) {
+	ctx := context.Background()
+	timed := e.newRuleset(t, "Timed", func(r *game.Ruleset) { r.TurnTimerSeconds = 60 })
+	timedSimultaneous := e.newRuleset(t, "Timed simultaneous", func(r *game.Ruleset) {
+		r.TurnTimerSeconds = 60
+		r.TurnMode = game.TurnsSimultaneous
+	})
+	alice, aliceSquad := e.newSquadPlayer(t, "alice", "Flame Wolf")
+	bob, bobSquad := e.newSquadPlayer(t, "bob", "Flame Wolf")
+	carol, _ := e.newSquadPlayer(t, "carol", "Flame Wolf")
+
+	// at sets the service's clock relative to m's deadline
+	at := func(m store.Match, rs game.Ruleset, offset time.Duration) {
+		t.Helper()
+		deadline, ok := rs.TurnDeadline(m)
+		if !ok {
+			t.Fatalf("match %d has no deadline", m.ID)
+		}
+		game.SetNow(e.game, func() time.Time { return deadline.Add(offset) })
+	}
+	var wrongTurn game.ErrWrongTurn
+	var forbidden game.ErrForbidden
+	var illegal game.ErrIllegalMove
+
+	//--no timer, no claim
+	m := e.startMatch(t, game.StandardRulesetID, alice, aliceSquad, bob, bobSquad)
+	if err := e.game.ClaimTimeout(ctx, m.ID, alice); !errors.As(err, &illegal) {
+		t.Errorf("claim without a timer: got %v, want ErrIllegalMove", err)
+	}
+
+	//--alternating: only the player not on turn, only once time is up
+	m = e.startMatch(t, timed.ID, alice, aliceSquad, bob, bobSquad)
+	waiting := m.CurrentActorPlayerID.Int64
+	claimer := alice
+	if waiting == alice {
+		claimer = bob
+	}
+	at(m, timed, -time.Second)
+	if err := e.game.ClaimTimeout(ctx, m.ID, claimer); !errors.As(err, &wrongTurn) {
+		t.Errorf("claim before the deadline: got %v, want ErrWrongTurn", err)
+	}
+	at(m, timed, 0)
+	if err := e.game.ClaimTimeout(ctx, m.ID, waiting); !errors.As(err, &wrongTurn) {
+		t.Errorf("claim by the player on turn: got %v, want ErrWrongTurn", err)
+	}
+	if err := e.game.ClaimTimeout(ctx, m.ID, carol); !errors.As(err, &forbidden) {
+		t.Errorf("claim by an outsider: got %v, want ErrForbidden", err)
+	}
+	if err := e.game.ClaimTimeout(ctx, m.ID, claimer); err != nil {
+		t.Fatalf("claim at the deadline: %v", err)
+	}
+	done, err := e.st.GetMatchByID(ctx, m.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if done.State != "COMPLETED" || done.WinnerPlayerID.Int64 != claimer {
+		t.Errorf("match %s, winner %v; want completed and won by %d", done.State, done.WinnerPlayerID, claimer)
+	}
+	var notInProgress game.ErrMatchNotInProgress
+	if err := e.game.ClaimTimeout(ctx, m.ID, claimer); !errors.As(err, &notInProgress) {
+		t.Errorf("second claim: got %v, want ErrMatchNotInProgress", err)
+	}
+
+	//--simultaneous: only a player who has chosen, against one who hasn't
+	m = e.startMatch(t, timedSimultaneous.ID, alice, aliceSquad, bob, bobSquad)
+	at(m, timedSimultaneous, time.Hour)
+	if err := e.game.ClaimTimeout(ctx, m.ID, alice); !errors.As(err, &wrongTurn) {
+		t.Errorf("claim before choosing: got %v, want ErrWrongTurn", err)
+	}
+	tackle := game.TurnAction{Kind: game.ActionMove, MoveID: e.move(t, "Tackle").ID}
+	if err := e.game.ApplyTurn(ctx, m.ID, alice, tackle, 1); err != nil {
+		t.Fatal(err)
+	}
+	at(m, timedSimultaneous, -time.Second)
+	if err := e.game.ClaimTimeout(ctx, m.ID, alice); !errors.As(err, &wrongTurn) {
+		t.Errorf("claim before the deadline: got %v, want ErrWrongTurn", err)
+	}
+	at(m, timedSimultaneous, time.Second)
+	if err := e.game.ClaimTimeout(ctx, m.ID, bob); !errors.As(err, &wrongTurn) {
+		t.Errorf("claim by the player the match waits on: got %v, want ErrWrongTurn", err)
+	}
+	if err := e.game.ClaimTimeout(ctx, m.ID, alice); err != nil {
+		t.Fatalf("claim after the deadline: %v", err)
+	}
+	done, err = e.st.GetMatchByID(ctx, m.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if done.State != "COMPLETED" || done.WinnerPlayerID.Int64 != alice {
+		t.Errorf("match %s, winner %v; want completed and won by alice", done.State, done.WinnerPlayerID)
+	}
+}
+
+func TestRulesetStats(t *testing.T) {
+	for name, open := range storetest.Backends() {
+		t.Run(name, func(t *testing.T) {
+			testRulesetStats(t, newTestEnv(t, open(t)))
+		})
+	}
+}
+
+// testRulesetStats plays a Tackle (power 10) between two Flame Wolves
+// (HP 40, attack 12 at level 50) under different level caps and damage
+// models.
+func testRulesetStats(t *testing.T, e *testEnv) {
+	ctx := context.Background()
+	tackle := e.move(t, "Tackle")
+	for i, tc := range []struct {
+		name       string
+		levelCap   int
+		model      string
+		wantHP     int32
+		wantDamage int32
+	}{
+		{"standard at level 50", 50, game.DamageStandard, 40, 10 + 12/2},
+		{"standard at level 100", 100, game.DamageStandard, 80, 10 + 24/2},
+		{"standard at level 25", 25, game.DamageStandard, 20, 10 + 6/2},
+		{"standard at level 1", 1, game.DamageStandard, 1, 10},
+		{"fixed at level 50", 50, game.DamageFixed, 40, 10},
+		{"fixed at level 100", 100, game.DamageFixed, 80, 10},
+	} {
+		rs := e.newRuleset(t, tc.name, func(r *game.Ruleset) {
+			r.LevelCap = tc.levelCap
+			r.DamageModel = tc.model
+		})
+		p1, s1 := e.newSquadPlayer(t, fmt.Sprintf("alice%d", i), "Flame Wolf")
+		p2, s2 := e.newSquadPlayer(t, fmt.Sprintf("bob%d", i), "Flame Wolf")
+		m := e.startMatch(t, rs.ID, p1, s1, p2, s2)
+
+		view, err := e.game.LoadMatchView(ctx, m.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, side := range view.Sides {
+			for _, u := range side.Units {
+				if maxHP := view.Ruleset.Stat(u.BaseHp); u.CurrentHp != tc.wantHP || maxHP != tc.wantHP {
+					t.Errorf("%s: unit has %d/%d HP, want %d", tc.name, u.CurrentHp, maxHP, tc.wantHP)
+				}
+			}
+		}
+
+		actor := m.CurrentActorPlayerID.Int64
+		action := game.TurnAction{Kind: game.ActionMove, MoveID: tackle.ID}
+		if err := e.game.ApplyTurn(ctx, m.ID, actor, action, m.CurrentTurnNumber); err != nil {
+			t.Fatal(err)
+		}
+		turns := e.moveTurns(t, m.ID)
+		want := min(tc.wantDamage, tc.wantHP)
+		if len(turns) != 1 || turns[0].DamageDone != want {
+			t.Errorf("%s: turns %+v, want one doing %d damage", tc.name, turns, want)
+		}
+	}
+}
@@ -1,19 +1,31 @@
 package game
 
 import (
-	"database/sql"
+	"math/rand"
+	"time"
 
 	"github.com/76dillon/battle_squads/internal/store"
 )
 
 type Service struct {
-	db *sql.DB
-	q  *store.Queries
+	store    store.Store
+	watchers *watchers
+	chat     ChatRules
+	// roll returns a random number in [0, n), for accuracy rolls
+	roll func(n int) int
+	// now is the time turn timers are checked against
+	now func() time.Time
 }
 
-func NewService(db *sql.DB) *Service {
+func NewService(st store.Store, chat ChatRules) *Service {
+	if chat.Filter == nil {
+		chat.Filter = NopFilter{}
+	}
 	return &Service{
-		db: db,
-		q:  store.New(db), // or whatever sqlc constructor is
+		store:    st,
+		watchers: newWatchers(),
+		chat:     chat,
+		roll:     rand.Intn,
+		now:      time.Now,
 	}
 }
@@ -0,0 +1,98 @@
+package game
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/76dillon/battle_squads/internal/store"
+)
+
+// watchers lets clients wait for a match to change. Notifications carry no
+// data: watchers reload the match through ViewMatch, so what each of them
+// may see is decided in one place. They only reach watchers in this process.
+type watchers struct {
+	mu   sync.Mutex
+	subs map[int64]map[chan struct{}]struct{}
+}
+
+func newWatchers() *watchers {
+	return &watchers{subs: make(map[int64]map[chan struct{}]struct{})}
+}
+
+// WatchMatch returns a channel that receives a value whenever the match
+// changes, and a func that stops watching. Changes are coalesced: a watcher
+// that falls behind sees one pending value, not one per change.
+func (s *Service) WatchMatch(matchID int64) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	w := s.watchers
+	w.mu.Lock()
+	if w.subs[matchID] == nil {
+		w.subs[matchID] = make(map[chan struct{}]struct{})
+	}
+	w.subs[matchID][ch] = struct{}{}
+	w.mu.Unlock()
+
+	return ch, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		delete(w.subs[matchID], ch)
+		if len(w.subs[matchID]) == 0 {
+			delete(w.subs, matchID)
+		}
+	}
+}
+
+// notify wakes every watcher of the match. Call it after the change has
+// been committed.
+func (s *Service) notify(matchID int64) {
+	w := s.watchers
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for ch := range w.subs[matchID] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// ViewMatch loads a match as viewerID sees it (0 for anonymous callers).
+// Private matches return ErrMatchNotFound to anyone but their players, so
+// they can't be found by guessing IDs.
+func (s *Service) ViewMatch(ctx context.Context, matchID, viewerID int64) (MatchView, error) {
+	v, err := s.LoadMatchView(ctx, matchID)
+	if err != nil {
+		return MatchView{}, err
+	}
+	v = v.ForViewer(viewerID)
+	if !v.Match.IsPublic && v.Perspective != PerspectivePlayer {
+		return MatchView{}, ErrMatchNotFound{Msg: fmt.Sprintf("match %d not found", matchID)}
+	}
+	return v, nil
+}
+
+// SetMatchPublic opens a match to spectators or closes it. Only the player
+// who created the match, player 1, may change it.
+func (s *Service) SetMatchPublic(ctx context.Context, matchID, playerID int64, public bool) (store.Match, error) {
+	match, err := s.store.GetMatchByID(ctx, matchID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !match.IsPublic && playerID != match.Player1ID && playerID != match.Player2ID) {
+		return store.Match{}, ErrMatchNotFound{Msg: fmt.Sprintf("match %d not found", matchID)}
+	}
+	if err != nil {
+		return store.Match{}, fmt.Errorf("error retrieving match: %w", err)
+	}
+	if playerID != match.Player1ID {
+		return store.Match{}, ErrForbidden{Msg: "only the player who created the match can change who may watch it"}
+	}
+
+	match, err = s.store.SetMatchPublic(ctx, store.SetMatchPublicParams{ID: matchID, IsPublic: public})
+	if err != nil {
+		return store.Match{}, fmt.Errorf("error updating match: %w", err)
+	}
+	// Spectators of a match that just went private find out on reload
+	s.notify(matchID)
+	return match, nil
+}
@@ -0,0 +1,157 @@
+package game_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/76dillon/battle_squads/internal/game"
+	"github.com/76dillon/battle_squads/internal/store/storetest"
+)
+
+func TestWatchMatch(t *testing.T) {
+	for name, open := range storetest.Backends() {
+		t.Run(name, func(t *testing.T) {
+			testWatchMatch(t, newTestEnv(t, open(t)))
+		})
+	}
+}
+
+// pending reports whether ch has a value waiting, taking it if so.
+func pending(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+// testWatchMatch checks that turns, chat and visibility changes wake every
+// watcher of the match, coalesced, and that stopping unsubscribes.
+func testWatchMatch(t *testing.T, e *testEnv) {
+	ctx := context.Background()
+	m := e.newMatch(t)
+	p1, s1 := e.newPlayer(t, "carol")
+	p2, s2 := e.newPlayer(t, "dave")
+	other := e.startMatch(t, game.StandardRulesetID, p1, s1, p2, s2)
+	tackle := game.TurnAction{Kind: game.ActionMove, MoveID: e.move(t, "Tackle").ID}
+	play := func(matchID int64) {
+		t.Helper()
+		m, err := e.st.GetMatchByID(ctx, matchID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.turn(t, matchID, m.CurrentActorPlayerID.Int64, tackle)
+	}
+
+	a, stopA := e.game.WatchMatch(m.ID)
+	b, stopB := e.game.WatchMatch(m.ID)
+	if n := game.Watchers(e.game, m.ID); n != 2 {
+		t.Fatalf("%d watchers, want 2", n)
+	}
+
+	//--two turns leave one value per watcher, and another match's turn none
+	play(m.ID)
+	play(m.ID)
+	play(other.ID)
+	for name, ch := range map[string]<-chan struct{}{"a": a, "b": b} {
+		if !pending(ch) {
+			t.Errorf("watcher %s not woken by a turn", name)
+		}
+		if pending(ch) {
+			t.Errorf("watcher %s woken once per turn, want changes coalesced", name)
+		}
+	}
+
+	//--chat and making the match private wake watchers too
+	if _, err := e.game.PostMessage(ctx, m.ID, m.Player1ID, "gg"); err != nil {
+		t.Fatal(err)
+	}
+	if !pending(a) || !pending(b) {
+		t.Error("chat message didn't wake both watchers")
+	}
+	if _, err := e.game.SetMatchPublic(ctx, m.ID, m.Player1ID, false); err != nil {
+		t.Fatal(err)
+	}
+	if !pending(a) || !pending(b) {
+		t.Error("making the match private didn't wake both watchers")
+	}
+
+	//--a stopped watcher hears nothing more and is forgotten
+	stopA()
+	play(m.ID)
+	if pending(a) || !pending(b) {
+		t.Error("after stopping a, a turn should only wake b")
+	}
+	stopB()
+	if n := game.Watchers(e.game, m.ID); n != 0 {
+		t.Errorf("%d watchers after both stopped, want 0", n)
+	}
+}
+
+func TestMatchVisibility(t *testing.T) {
+	for name, open := range storetest.Backends() {
+		t.Run(name, func(t *testing.T) {
+			testMatchVisibility(t, newTestEnv(t, open(t)))
+		})
+	}
+}
+
+// testMatchVisibility checks who may see a private match and who may change
+// its visibility.
+func testMatchVisibility(t *testing.T, e *testEnv) {
+	ctx := context.Background()
+	m := e.newMatch(t)
+	stranger, _ := e.newPlayer(t, "carol")
+	if m.IsPublic {
+		t.Fatal("a match created without Public is public")
+	}
+	if _, err := e.game.SetMatchPublic(ctx, m.ID, m.Player1ID, true); err != nil {
+		t.Fatal(err)
+	}
+
+	var forbidden game.ErrForbidden
+	if _, err := e.game.SetMatchPublic(ctx, m.ID, m.Player2ID, false); !errors.As(err, &forbidden) {
+		t.Errorf("player 2 making it private: got %v, want ErrForbidden", err)
+	}
+	if _, err := e.game.SetMatchPublic(ctx, m.ID, stranger, false); !errors.As(err, &forbidden) {
+		t.Errorf("a stranger making a public match private: got %v, want ErrForbidden", err)
+	}
+	if _, err := e.game.SetMatchPublic(ctx, m.ID, m.Player1ID, false); err != nil {
+		t.Fatal(err)
+	}
+
+	var notFound game.ErrMatchNotFound
+	for viewer, want := range map[int64]game.Perspective{
+		m.Player1ID: game.PerspectivePlayer,
+		m.Player2ID: game.PerspectivePlayer,
+		stranger:    "",
+		0:           "",
+	} {
+		v, err := e.game.ViewMatch(ctx, m.ID, viewer)
+		if want == "" {
+			if !errors.As(err, &notFound) {
+				t.Errorf("viewer %d of a private match: got %v, want ErrMatchNotFound", viewer, err)
+			}
+			continue
+		}
+		if err != nil || v.Perspective != want {
+			t.Errorf("viewer %d: perspective %q, error %v, want %q", viewer, v.Perspective, err, want)
+		}
+	}
+	if _, err := e.game.SetMatchPublic(ctx, m.ID, stranger, true); !errors.As(err, &notFound) {
+		t.Errorf("a stranger opening a private match: got %v, want ErrMatchNotFound", err)
+	}
+	if _, err := e.game.SetMatchPublic(ctx, 999, m.Player1ID, true); !errors.As(err, &notFound) {
+		t.Errorf("missing match: got %v, want ErrMatchNotFound", err)
+	}
+
+	//--opened again, strangers watch as spectators
+	if _, err := e.game.SetMatchPublic(ctx, m.ID, m.Player1ID, true); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := e.game.ViewMatch(ctx, m.ID, stranger); err != nil || v.Perspective != game.PerspectiveSpectator {
+		t.Errorf("stranger of a public match: perspective %q, error %v", v.Perspective, err)
+	}
+}
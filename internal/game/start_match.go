@@ -9,166 +9,195 @@ import (
 	"github.com/76dillon/battle_squads/internal/store"
 )
 
-// StartMatch sets up match_sides and match_units based on chosen squads.
-func (s *Service) StartMatch(
-	ctx context.Context,
-	matchID int64,
-	p1SquadID int64,
-	p2SquadID int64,
-) error {
-	// 1. Begin transaction
-	tx, err := s.db.BeginTx(ctx, nil)
+// NewMatch is who plays a match about to be created, and with which squads.
+// Squads must be checked by CheckSquads, usually with
+// squad.CheckMatchSquads.
+type NewMatch struct {
+	Player1ID      int64
+	Player1SquadID int64
+	Player2ID      int64
+	Player2SquadID int64
+	Public         bool
+	// RulesetID is the ruleset the match is played under; the squads must
+	// fit its size limits
+	RulesetID int64
+	// CheckSquads is called in the transaction that creates the match,
+	// before anything is written, so the squads can't change between being
+	// checked and being fielded
+	CheckSquads func(q store.Querier) error
+}
+
+// CreateMatch creates a match and starts it. Players who have blocked each
+// other cannot be matched.
+func (s *Service) CreateMatch(ctx context.Context, nm NewMatch) (int64, error) {
+	var matchID int64
+	err := s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		var err error
+		matchID, err = createMatch(ctx, qtx, nm)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("begin tx: %w", err)
+		return 0, err
 	}
+	return matchID, nil
+}
 
-	qtx := s.q.WithTx(tx)
-	// use qtx everywhere instead of s.q
-	// commit or rollback
+func createMatch(ctx context.Context, qtx store.Querier, nm NewMatch) (int64, error) {
+	if nm.CheckSquads != nil {
+		if err := nm.CheckSquads(qtx); err != nil {
+			return 0, err
+		}
+	}
+	blocks, err := qtx.CountBlocksBetween(ctx, store.CountBlocksBetweenParams{
+		PlayerID: nm.Player1ID,
+		OtherID:  nm.Player2ID,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("count blocks: %w", err)
+	}
+	if blocks > 0 {
+		return 0, ErrForbidden{Msg: "cannot start a match with this player"}
+	}
+
+	m, err := qtx.CreateMatch(ctx, store.CreateMatchParams{
+		Player1ID: nm.Player1ID,
+		Player2ID: nm.Player2ID,
+		IsPublic:  nm.Public,
+		RulesetID: nm.RulesetID,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("create match: %w", err)
+	}
+	if err := startMatch(ctx, qtx, m.ID, nm.Player1SquadID, nm.Player2SquadID); err != nil {
+		return 0, err
+	}
+	return m.ID, nil
+}
 
+// startMatch sets up match_sides and match_units based on chosen squads,
+// inside a transaction the caller already holds.
+func startMatch(ctx context.Context, qtx store.Querier, matchID, p1SquadID, p2SquadID int64) error {
 	match, err := qtx.GetMatchByID(ctx, matchID)
 	if err != nil {
-		_ = tx.Rollback()
 		return fmt.Errorf("error retrieving match information: %w", err)
 	}
+	rs, err := getRuleset(ctx, qtx, match.RulesetID)
+	if err != nil {
+		return err
+	}
 
-	// 2. For player 1:
-	//    - CreateMatchSide(matchID, p1ID, p1SquadID, active_index = 0)
-	p1MatchSide, err := qtx.CreateMatchSide(ctx, store.CreateMatchSideParams{
-		MatchID:  match.ID,
-		PlayerID: match.Player1ID,
-		SquadID:  p1SquadID,
-	})
+	// 2. Field each player's squad: a side, its units and its bag
+	p1MatchSide, err := fieldSide(ctx, qtx, rs, match.ID, match.Player1ID, p1SquadID)
 	if err != nil {
-		_ = tx.Rollback()
-		return fmt.Errorf("error creating match side: %w", err)
+		return err
 	}
-	//    - GetSquadUnits(p1SquadID)
-	p1SquadUnits, err := qtx.GetSquadUnits(ctx, p1SquadID)
+	p2MatchSide, err := fieldSide(ctx, qtx, rs, match.ID, match.Player2ID, p2SquadID)
 	if err != nil {
-		_ = tx.Rollback()
-		return fmt.Errorf("error retrieving squad units: %w", err)
+		return err
 	}
 
-	//    - For each squad unit:
-	for _, squadUnit := range p1SquadUnits {
-		//        - GetUnitByID(unit_id)
-		unit, err := qtx.GetUnitByID(ctx, squadUnit.UnitID)
-		if err != nil {
-			_ = tx.Rollback()
-			return fmt.Errorf("error retrieving unit info: %w", err)
+	// 3. Both leads come into battle, player 1's first; their switch-in
+	//    abilities are recorded before turn 1
+	sides := []store.MatchSide{p1MatchSide, p2MatchSide}
+	for _, side := range sides {
+		if err := switchIn(ctx, qtx, match, side); err != nil {
+			return err
 		}
-		//        - CreateMatchUnit(side1.ID, unit_id, position, current_hp = base_hp)
-		_, err = qtx.CreateMatchUnit(ctx, store.CreateMatchUnitParams{
-			MatchSideID: p1MatchSide.ID,
-			UnitID:      unit.ID,
-			Position:    squadUnit.Position,
-			CurrentHp:   unit.BaseHp,
-		})
-		if err != nil {
-			_ = tx.Rollback()
-			return fmt.Errorf("error creating match unit: %w", err)
+	}
+
+	// 4. Determine the speed of the initial active units (position 0),
+	//    held items and abilities included
+	p1Speed, err := activeSpeed(ctx, qtx, rs, sides, match.Player1ID)
+	if err != nil {
+		return err
+	}
+	p2Speed, err := activeSpeed(ctx, qtx, rs, sides, match.Player2ID)
+	if err != nil {
+		return err
+	}
+
+	// 5. Compare speeds, random tie-breaker:
+	//    - decide initialActorPlayerID; simultaneous turns have no actor,
+	//      both players choose at once
+	var initialActor sql.NullInt64
+	if rs.TurnMode == TurnsAlternating {
+		initialActor = sql.NullInt64{
+			Int64: faster(match.Player1ID, p1Speed, match.Player2ID, p2Speed),
+			Valid: true,
 		}
 	}
+	// 6. Call StartMatch (UPDATE matches SET state='IN_PROGRESS', ...)
+	_, err = qtx.StartMatch(ctx, store.StartMatchParams{
+		ID:                   match.ID,
+		CurrentActorPlayerID: initialActor,
+	})
+	if err != nil {
+		return fmt.Errorf("update match to in_progress: %w", err)
+	}
 
-	// 3. For player 2: same pattern as player 1
-	//    - CreateMatchSide(matchID, p2ID, p2SquadID, active_index = 0)
-	p2MatchSide, err := qtx.CreateMatchSide(ctx, store.CreateMatchSideParams{
-		MatchID:  match.ID,
-		PlayerID: match.Player2ID,
-		SquadID:  p2SquadID,
+	return nil
+}
+
+// fieldSide creates playerID's side of a match with squadID: a match unit
+// for each squad unit, with its HP at the ruleset's level cap, and a copy of
+// the squad's bag.
+func fieldSide(ctx context.Context, qtx store.Querier, rs Ruleset, matchID, playerID, squadID int64) (store.MatchSide, error) {
+	side, err := qtx.CreateMatchSide(ctx, store.CreateMatchSideParams{
+		MatchID:  matchID,
+		PlayerID: playerID,
+		SquadID:  squadID,
 	})
 	if err != nil {
-		_ = tx.Rollback()
-		return fmt.Errorf("error creating match side: %w", err)
+		return store.MatchSide{}, fmt.Errorf("error creating match side: %w", err)
 	}
-	//    - GetSquadUnits(p2SquadID)
-	p2SquadUnits, err := qtx.GetSquadUnits(ctx, p2SquadID)
+	squadUnits, err := qtx.GetSquadUnits(ctx, squadID)
 	if err != nil {
-		_ = tx.Rollback()
-		return fmt.Errorf("error retrieving squad units: %w", err)
+		return store.MatchSide{}, fmt.Errorf("error retrieving squad units: %w", err)
 	}
-
-	//    - For each squad unit:
-	for _, squadUnit := range p2SquadUnits {
-		//        - GetUnitByID(unit_id)
+	for _, squadUnit := range squadUnits {
 		unit, err := qtx.GetUnitByID(ctx, squadUnit.UnitID)
 		if err != nil {
-			_ = tx.Rollback()
-			return fmt.Errorf("error retrieving unit info: %w", err)
+			return store.MatchSide{}, fmt.Errorf("error retrieving unit info: %w", err)
 		}
-		//        - CreateMatchUnit(side1.ID, unit_id, position, current_hp = base_hp)
 		_, err = qtx.CreateMatchUnit(ctx, store.CreateMatchUnitParams{
-			MatchSideID: p2MatchSide.ID,
+			MatchSideID: side.ID,
 			UnitID:      unit.ID,
 			Position:    squadUnit.Position,
-			CurrentHp:   unit.BaseHp,
+			CurrentHp:   rs.Stat(unit.BaseHp),
+			// The first unit starts active, so the opponent sees it
+			Revealed:   squadUnit.Position == 0,
+			HeldItemID: heldItemID(rs, squadUnit),
 		})
 		if err != nil {
-			_ = tx.Rollback()
-			return fmt.Errorf("error creating match unit: %w", err)
+			return store.MatchSide{}, fmt.Errorf("error creating match unit: %w", err)
 		}
 	}
-
-	// 4. Determine initial active units:
-	//    - find match_units where side = side1 and position = 0
-	p1Active, err := qtx.GetActiveMatchUnitForSide(ctx, p1MatchSide.ID)
-	if err != nil {
-		_ = tx.Rollback()
-		return fmt.Errorf("get p1 active unit: %w", err)
+	if err := copyBag(ctx, qtx, rs, side); err != nil {
+		return store.MatchSide{}, err
 	}
+	return side, nil
+}
 
-	//    - find match_units where side = side2 and position = 0
-	p2Active, err := qtx.GetActiveMatchUnitForSide(ctx, p2MatchSide.ID)
-	if err != nil {
-		_ = tx.Rollback()
-		return fmt.Errorf("get p2 active unit: %w", err)
+// heldItemID is the item a squad unit brings into a match: none when the
+// ruleset has items turned off.
+func heldItemID(rs Ruleset, su store.SquadUnit) sql.NullInt64 {
+	if !rs.ItemsEnabled {
+		return sql.NullInt64{}
 	}
+	return su.HeldItemID
+}
 
-	//    - load corresponding units to get base_speed
-	p1Unit, err := qtx.GetUnitByID(ctx, p1Active.UnitID)
-	if err != nil {
-		_ = tx.Rollback()
-		return fmt.Errorf("get p1 unit: %w", err)
-	}
-	p2Unit, err := qtx.GetUnitByID(ctx, p2Active.UnitID)
-	if err != nil {
-		_ = tx.Rollback()
-		return fmt.Errorf("get p2 unit: %w", err)
-	}
-	p1Speed := p1Unit.BaseSpeed
-	p2Speed := p2Unit.BaseSpeed
-	// 5. Compare speeds, random tie-breaker:
-	//    - decide initialActorPlayerID
-	var initialActor int64
+// faster returns whichever player's active unit has the higher speed, or
+// either at random on a tie.
+func faster(p1ID int64, p1Speed int32, p2ID int64, p2Speed int32) int64 {
 	switch {
 	case p1Speed > p2Speed:
-		initialActor = match.Player1ID
+		return p1ID
 	case p2Speed > p1Speed:
-		initialActor = match.Player2ID
+		return p2ID
+	case rand.Intn(2) == 0:
+		return p1ID
 	default:
-		if rand.Intn(2) == 0 {
-			initialActor = match.Player1ID
-		} else {
-			initialActor = match.Player2ID
-		}
-	}
-	// 6. Call StartMatch (UPDATE matches SET state='IN_PROGRESS', ...)
-	_, err = qtx.StartMatch(ctx, store.StartMatchParams{
-		ID: match.ID,
-		CurrentActorPlayerID: sql.NullInt64{
-			Int64: initialActor,
-			Valid: true,
-		},
-	})
-	if err != nil {
-		_ = tx.Rollback()
-		return fmt.Errorf("update match to in_progress: %w", err)
-	}
-
-	// 7. Commit transaction
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("commit tx: %w", err)
+		return p2ID
 	}
-	return nil
 }
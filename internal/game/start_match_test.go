@@ -0,0 +1,132 @@
+package game_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/76dillon/battle_squads/internal/game"
+	"github.com/76dillon/battle_squads/internal/squad"
+	"github.com/76dillon/battle_squads/internal/store"
+	"github.com/76dillon/battle_squads/internal/store/storetest"
+	"github.com/76dillon/battle_squads/internal/validation"
+)
+
+func TestCreateMatchChecksSquads(t *testing.T) {
+	for name, open := range storetest.Backends() {
+		t.Run(name, func(t *testing.T) {
+			testCreateMatchChecksSquads(t, newTestEnv(t, open(t)))
+		})
+	}
+}
+
+// testCreateMatchChecksSquads fields the opponent's squad, which the squad
+// check must reject without creating anything.
+func testCreateMatchChecksSquads(t *testing.T, e *testEnv) {
+	ctx := context.Background()
+	p1, _ := e.newPlayer(t, "alice")
+	p2, s2 := e.newPlayer(t, "bob")
+	rs, err := e.game.GetRuleset(ctx, game.StandardRulesetID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checked := false
+	_, err = e.game.CreateMatch(ctx, game.NewMatch{
+		Player1ID:      p1,
+		Player1SquadID: s2,
+		Player2ID:      p2,
+		Player2SquadID: s2,
+		RulesetID:      rs.ID,
+		CheckSquads: func(q store.Querier) error {
+			checked = true
+			return squad.CheckMatchSquads(ctx, q, rs.MinSquadSize, rs.MaxSquadSize,
+				squad.SquadChoice{Field: "player1_squad_id", SquadID: s2, PlayerID: p1},
+				squad.SquadChoice{Field: "player2_squad_id", SquadID: s2, PlayerID: p2},
+			)
+		},
+	})
+	var verr *validation.Error
+	if !errors.As(err, &verr) {
+		t.Fatalf("CreateMatch: got %v, want a validation error", err)
+	}
+	if !checked {
+		t.Error("CheckSquads was not called")
+	}
+	if len(verr.Problems) != 1 || verr.Problems[0].Field != "player1_squad_id" {
+		t.Errorf("problems: %+v, want one with player1_squad_id", verr.Problems)
+	}
+
+	matches, err := e.st.ListMatchesForPlayer(ctx, store.ListMatchesForPlayerParams{PlayerID: p1, RowLimit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 0 {
+		t.Errorf("%d matches created, want 0", len(matches))
+	}
+}
+
+func TestCreateMatchDeleteSquadConcurrent(t *testing.T) {
+	for name, open := range storetest.Backends() {
+		t.Run(name, func(t *testing.T) {
+			testCreateMatchDeleteSquadConcurrent(t, newTestEnv(t, open(t)))
+		})
+	}
+}
+
+// testCreateMatchDeleteSquadConcurrent fields a squad while its owner
+// deletes it. Exactly one of the two must win: either the match is created
+// and the delete finds the squad in use, or the squad is deleted and the
+// match is refused.
+func testCreateMatchDeleteSquadConcurrent(t *testing.T, e *testEnv) {
+	ctx := context.Background()
+	p1, _ := e.newPlayer(t, "alice")
+	p2, s2 := e.newPlayer(t, "bob")
+	rs, err := e.game.GetRuleset(ctx, game.StandardRulesetID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for round := range 20 {
+		sq, err := e.squads.Create(ctx, p1, fmt.Sprintf("squad %d", round), []int64{e.units[0].ID}, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var createErr, deleteErr error
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, createErr = e.game.CreateMatch(ctx, game.NewMatch{
+				Player1ID:      p1,
+				Player1SquadID: sq.ID,
+				Player2ID:      p2,
+				Player2SquadID: s2,
+				RulesetID:      rs.ID,
+				CheckSquads: func(q store.Querier) error {
+					return squad.CheckMatchSquads(ctx, q, rs.MinSquadSize, rs.MaxSquadSize,
+						squad.SquadChoice{Field: "player1_squad_id", SquadID: sq.ID, PlayerID: p1},
+						squad.SquadChoice{Field: "player2_squad_id", SquadID: s2, PlayerID: p2},
+					)
+				},
+			})
+		}()
+		go func() {
+			defer wg.Done()
+			deleteErr = e.squads.Delete(ctx, p1, sq.ID)
+		}()
+		wg.Wait()
+
+		var verr *validation.Error
+		var inUse squad.ErrInUse
+		switch {
+		case createErr == nil && errors.As(deleteErr, &inUse):
+		case deleteErr == nil && errors.As(createErr, &verr):
+		default:
+			t.Fatalf("round %d: CreateMatch: %v, Delete: %v; want exactly one to succeed", round, createErr, deleteErr)
+		}
+	}
+}
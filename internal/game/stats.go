@@ -0,0 +1,126 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/76dillon/battle_squads/internal/store"
+)
+
+// eloK caps how many rating points one result can move.
+const eloK = 32
+
+// eloDelta returns the points the winner gains and the loser loses: few for
+// beating a weaker player, many for an upset.
+func eloDelta(winnerRating, loserRating int32) int32 {
+	expected := 1 / (1 + math.Pow(10, float64(loserRating-winnerRating)/400))
+	return int32(math.Round(eloK * (1 - expected)))
+}
+
+// statsDay is the UTC day that stats for something happening at t count
+// towards.
+func statsDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// recordTurnStats adds one applied turn to the acting player's totals. damage
+// is what the move took off the target, so never more than the HP it had.
+func recordTurnStats(ctx context.Context, q store.Querier, playerID, moveID int64, damage int32, didKO bool) error {
+	kos := int32(0)
+	if didKO {
+		kos = 1
+	}
+	day := statsDay(time.Now())
+	err := q.AddPlayerDailyStats(ctx, store.AddPlayerDailyStatsParams{
+		PlayerID:    playerID,
+		Day:         day,
+		DamageDealt: int64(damage),
+		Kos:         kos,
+	})
+	if err != nil {
+		return fmt.Errorf("add turn to player stats: %w", err)
+	}
+	if err := q.AddPlayerMoveUse(ctx, store.AddPlayerMoveUseParams{PlayerID: playerID, Day: day, MoveID: moveID}); err != nil {
+		return fmt.Errorf("add move use: %w", err)
+	}
+	return nil
+}
+
+// recordMatchResult adds a completed match to both players' totals and
+// moves their ratings. match is as it was before completing, so its
+// CurrentTurnNumber is the number of turns played.
+func recordMatchResult(ctx context.Context, q store.Querier, match store.Match, winnerID int64) error {
+	loserID := match.Player1ID
+	if winnerID == match.Player1ID {
+		loserID = match.Player2ID
+	}
+
+	//--wins, losses and turns
+	day := statsDay(time.Now())
+	for _, playerID := range []int64{winnerID, loserID} {
+		arg := store.AddPlayerDailyStatsParams{
+			PlayerID:      playerID,
+			Day:           day,
+			MatchesPlayed: 1,
+			TurnsPlayed:   match.CurrentTurnNumber,
+		}
+		if playerID == winnerID {
+			arg.Wins = 1
+		} else {
+			arg.Losses = 1
+		}
+		if err := q.AddPlayerDailyStats(ctx, arg); err != nil {
+			return fmt.Errorf("add result to player stats: %w", err)
+		}
+	}
+
+	//--every unit each player fielded
+	sides, err := q.GetMatchSidesByMatchID(ctx, match.ID)
+	if err != nil {
+		return fmt.Errorf("error retrieving match sides: %w", err)
+	}
+	sidePlayer := make(map[int64]int64, len(sides))
+	for _, side := range sides {
+		sidePlayer[side.ID] = side.PlayerID
+	}
+	units, err := q.ListMatchUnitDetails(ctx, match.ID)
+	if err != nil {
+		return fmt.Errorf("error retrieving match units: %w", err)
+	}
+	for _, u := range units {
+		err := q.AddPlayerUnitMatch(ctx, store.AddPlayerUnitMatchParams{
+			PlayerID: sidePlayer[u.MatchSideID],
+			Day:      day,
+			UnitID:   u.UnitID,
+		})
+		if err != nil {
+			return fmt.Errorf("add unit to player stats: %w", err)
+		}
+	}
+
+	//--ratings, locked in ID order so two matches finishing at once can't
+	//  deadlock on the same pair of players
+	first, second := winnerID, loserID
+	if second < first {
+		first, second = second, first
+	}
+	ratings := make(map[int64]int32, 2)
+	for _, playerID := range []int64{first, second} {
+		rating, err := q.GetPlayerRatingForUpdate(ctx, playerID)
+		if err != nil {
+			return fmt.Errorf("get player rating: %w", err)
+		}
+		ratings[playerID] = rating
+	}
+	delta := eloDelta(ratings[winnerID], ratings[loserID])
+	if err := q.UpdatePlayerRating(ctx, store.UpdatePlayerRatingParams{ID: winnerID, Rating: ratings[winnerID] + delta}); err != nil {
+		return fmt.Errorf("update winner rating: %w", err)
+	}
+	if err := q.UpdatePlayerRating(ctx, store.UpdatePlayerRatingParams{ID: loserID, Rating: ratings[loserID] - delta}); err != nil {
+		return fmt.Errorf("update loser rating: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package game_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/76dillon/battle_squads/internal/game"
+	"github.com/76dillon/battle_squads/internal/store"
+	"github.com/76dillon/battle_squads/internal/store/storetest"
+)
+
+func TestStats(t *testing.T) {
+	for name, open := range storetest.Backends() {
+		t.Run(name, func(t *testing.T) {
+			testStats(t, newTestEnv(t, open(t)))
+		})
+	}
+}
+
+// testStats knocks out a unit with 1 HP left, which ends the match, and
+// checks what the winner's stats count.
+func testStats(t *testing.T, e *testEnv) {
+	ctx := context.Background()
+	m := e.newMatch(t)
+	actor := m.CurrentActorPlayerID.Int64
+
+	sides, err := e.st.GetMatchSidesByMatchID(ctx, m.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var target store.MatchUnit
+	for _, side := range sides {
+		if side.PlayerID == actor {
+			continue
+		}
+		target, err = e.st.GetActiveMatchUnitForSide(ctx, side.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := e.st.UpdateMatchUnitHP(ctx, store.UpdateMatchUnitHPParams{ID: target.ID, CurrentHp: 1}); err != nil {
+		t.Fatal(err)
+	}
+	moves, err := e.st.ListMovesForUnit(ctx, e.units[0].ID)
+	if err != nil || len(moves) == 0 {
+		t.Fatalf("no moves for unit %d: %v", e.units[0].ID, err)
+	}
+	action := game.TurnAction{Kind: game.ActionMove, MoveID: moves[0].ID}
+	if err := e.game.ApplyTurn(ctx, m.ID, actor, action, m.CurrentTurnNumber); err != nil {
+		t.Fatal(err)
+	}
+
+	turns, err := e.st.ListMatchTurns(ctx, m.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, turn := range turns {
+		if turn.Action == game.ActionMove && turn.DamageDone != 1 {
+			t.Errorf("turn did %d damage, want 1", turn.DamageDone)
+		}
+	}
+	st, err := e.st.GetPlayerStats(ctx, store.GetPlayerStatsParams{PlayerID: actor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.DamageDealt != 1 || st.Kos != 1 || st.Wins != 1 {
+		t.Errorf("stats: %d damage, %d KOs, %d wins, want 1 each", st.DamageDealt, st.Kos, st.Wins)
+	}
+
+	//--top units and moves count only the days asked for
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	tomorrow := today.AddDate(0, 0, 1)
+	for _, tc := range []struct {
+		name     string
+		from, to sql.NullTime
+		want     int64
+	}{
+		{"all time", sql.NullTime{}, sql.NullTime{}, 1},
+		{"today", sql.NullTime{Time: today, Valid: true}, sql.NullTime{Time: tomorrow, Valid: true}, 1},
+		{"from tomorrow", sql.NullTime{Time: tomorrow, Valid: true}, sql.NullTime{}, 0},
+		{"before today", sql.NullTime{}, sql.NullTime{Time: today, Valid: true}, 0},
+	} {
+		units, err := e.st.ListPlayerTopUnits(ctx, store.ListPlayerTopUnitsParams{
+			PlayerID: actor,
+			FromDay:  tc.from,
+			ToDay:    tc.to,
+			RowLimit: 3,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var fielded int64
+		for _, u := range units {
+			fielded += u.MatchesFielded
+		}
+		if fielded != tc.want {
+			t.Errorf("%s: %d units fielded, want %d", tc.name, fielded, tc.want)
+		}
+
+		top, err := e.st.ListPlayerTopMoves(ctx, store.ListPlayerTopMovesParams{
+			PlayerID: actor,
+			FromDay:  tc.from,
+			ToDay:    tc.to,
+			RowLimit: 3,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var uses int64
+		for _, mv := range top {
+			uses += mv.Uses
+		}
+		if uses != tc.want {
+			t.Errorf("%s: %d moves used, want %d", tc.name, uses, tc.want)
+		}
+	}
+}
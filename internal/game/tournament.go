@@ -0,0 +1,691 @@
+package game
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/76dillon/battle_squads/internal/player"
+	"github.com/76dillon/battle_squads/internal/store"
+	"github.com/76dillon/battle_squads/internal/validation"
+)
+
+// Tournament formats as stored in tournaments.format
+const (
+	FormatSingleElimination = "single_elimination"
+	FormatRoundRobin        = "round_robin"
+)
+
+// Tournament states as stored in tournaments.state
+const (
+	TournamentRegistration = "REGISTRATION"
+	TournamentInProgress   = "IN_PROGRESS"
+	TournamentCompleted    = "COMPLETED"
+)
+
+const (
+	MinTournamentPlayers    = 2
+	MaxTournamentPlayers    = 64
+	MaxTournamentNameLength = 64
+)
+
+// NewTournament is a tournament an admin opens for registration.
+type NewTournament struct {
+	Name       string `json:"name"`
+	Format     string `json:"format"`
+	MaxPlayers int    `json:"max_players"`
+	// RulesetID is the ruleset every match is played under; the standard
+	// one when left out
+	RulesetID int64 `json:"ruleset_id"`
+}
+
+// TournamentView is a tournament with its entries, pairings and standings.
+type TournamentView struct {
+	Tournament store.Tournament
+	// By seed once the tournament has started, by registration before
+	Entries []store.ListTournamentEntriesRow
+	// By round, then slot
+	Matches   []store.TournamentMatch
+	Standings []Standing
+}
+
+// Standing is a player's record in the tournament's decided pairings;
+// walkovers count, byes don't. Standings are ordered by wins, then fewest losses, then seed.
+type Standing struct {
+	PlayerID int64
+	Username string
+	Played   int
+	Wins     int
+	Losses   int
+}
+
+// CreateTournament opens a tournament for registration. It is recorded in
+// the admin audit log.
+func (s *Service) CreateTournament(ctx context.Context, adminID int64, nt NewTournament) (store.Tournament, error) {
+	nt.Name = strings.TrimSpace(nt.Name)
+	verr := validation.New("tournament")
+	if nt.Name == "" {
+		verr.Add("name", "is required")
+	} else if n := utf8.RuneCountInString(nt.Name); n > MaxTournamentNameLength {
+		verr.Add("name", "must be at most %d characters, got %d", MaxTournamentNameLength, n)
+	}
+	if nt.Format != FormatSingleElimination && nt.Format != FormatRoundRobin {
+		verr.Add("format", "must be %q or %q", FormatSingleElimination, FormatRoundRobin)
+	}
+	if nt.MaxPlayers < MinTournamentPlayers || nt.MaxPlayers > MaxTournamentPlayers {
+		verr.Add("max_players", "must be between %d and %d", MinTournamentPlayers, MaxTournamentPlayers)
+	}
+	if nt.RulesetID == 0 {
+		nt.RulesetID = StandardRulesetID
+	}
+	if _, err := s.store.GetRulesetByID(ctx, nt.RulesetID); errors.Is(err, sql.ErrNoRows) {
+		verr.Add("ruleset_id", "ruleset %d does not exist", nt.RulesetID)
+	} else if err != nil {
+		return store.Tournament{}, fmt.Errorf("get ruleset: %w", err)
+	}
+	if err := verr.Err(); err != nil {
+		return store.Tournament{}, err
+	}
+
+	var t store.Tournament
+	err := s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		var err error
+		t, err = qtx.CreateTournament(ctx, store.CreateTournamentParams{
+			Name:       nt.Name,
+			Format:     nt.Format,
+			MaxPlayers: int32(nt.MaxPlayers),
+			CreatedBy:  adminID,
+			RulesetID:  nt.RulesetID,
+		})
+		if err != nil {
+			return fmt.Errorf("create tournament: %w", err)
+		}
+		return auditTournament(ctx, qtx, adminID, "create", t.ID, nt)
+	})
+	if err != nil {
+		return store.Tournament{}, err
+	}
+	return t, nil
+}
+
+// GetTournament returns a tournament with its bracket or round robin
+// pairings and the standings so far.
+func (s *Service) GetTournament(ctx context.Context, tournamentID int64) (TournamentView, error) {
+	t, err := getTournament(ctx, s.store, tournamentID, false)
+	if err != nil {
+		return TournamentView{}, err
+	}
+	entries, err := s.store.ListTournamentEntries(ctx, t.ID)
+	if err != nil {
+		return TournamentView{}, fmt.Errorf("list tournament entries: %w", err)
+	}
+	matches, err := s.store.ListTournamentMatches(ctx, t.ID)
+	if err != nil {
+		return TournamentView{}, fmt.Errorf("list tournament matches: %w", err)
+	}
+	return TournamentView{
+		Tournament: t,
+		Entries:    entries,
+		Matches:    matches,
+		Standings:  standings(entries, matches),
+	}, nil
+}
+
+// TournamentRuleset returns the ruleset a tournament's matches are played
+// under, which entrants' squads must fit.
+func (s *Service) TournamentRuleset(ctx context.Context, tournamentID int64) (Ruleset, error) {
+	t, err := getTournament(ctx, s.store, tournamentID, false)
+	if err != nil {
+		return Ruleset{}, err
+	}
+	return getRuleset(ctx, s.store, t.RulesetID)
+}
+
+// RegisterForTournament enters playerID with the squad they will play every
+// match with. The squad must already have been checked with
+// squad.Service.CheckMatchSquads against the TournamentRuleset; it stays
+// locked until the tournament is completed.
+func (s *Service) RegisterForTournament(ctx context.Context, tournamentID, playerID, squadID int64) (store.TournamentEntry, error) {
+	var e store.TournamentEntry
+	err := s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		t, err := getTournament(ctx, qtx, tournamentID, true)
+		if err != nil {
+			return err
+		}
+		if t.State != TournamentRegistration {
+			return ErrTournamentConflict{Msg: "registration is closed"}
+		}
+		entries, err := qtx.ListTournamentEntries(ctx, t.ID)
+		if err != nil {
+			return fmt.Errorf("list tournament entries: %w", err)
+		}
+		for _, entry := range entries {
+			if entry.PlayerID == playerID {
+				return ErrTournamentConflict{Msg: "you are already registered"}
+			}
+		}
+		if len(entries) >= int(t.MaxPlayers) {
+			return ErrTournamentConflict{Msg: "tournament is full"}
+		}
+
+		e, err = qtx.CreateTournamentEntry(ctx, store.CreateTournamentEntryParams{
+			TournamentID: t.ID,
+			PlayerID:     playerID,
+			SquadID:      squadID,
+		})
+		if err != nil {
+			return fmt.Errorf("create tournament entry: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return store.TournamentEntry{}, err
+	}
+	return e, nil
+}
+
+// WithdrawFromTournament removes playerID's entry, which unlocks their
+// squad. Players can only withdraw before the tournament starts.
+func (s *Service) WithdrawFromTournament(ctx context.Context, tournamentID, playerID int64) error {
+	return s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		t, err := getTournament(ctx, qtx, tournamentID, true)
+		if err != nil {
+			return err
+		}
+		if t.State != TournamentRegistration {
+			return ErrTournamentConflict{Msg: "tournament has already started"}
+		}
+		n, err := qtx.DeleteTournamentEntry(ctx, store.DeleteTournamentEntryParams{
+			TournamentID: t.ID,
+			PlayerID:     playerID,
+		})
+		if err != nil {
+			return fmt.Errorf("delete tournament entry: %w", err)
+		}
+		if n == 0 {
+			return ErrTournamentNotFound{Msg: "you are not registered in this tournament"}
+		}
+		return nil
+	})
+}
+
+// StartTournament closes registration, seeds the players by rating (ties go
+// to whoever registered first) and creates the first round's matches. It is
+// recorded in the admin audit log.
+func (s *Service) StartTournament(ctx context.Context, adminID, tournamentID int64) (store.Tournament, error) {
+	var t store.Tournament
+	err := s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		var err error
+		t, err = getTournament(ctx, qtx, tournamentID, true)
+		if err != nil {
+			return err
+		}
+		if t.State != TournamentRegistration {
+			return ErrTournamentConflict{Msg: "tournament has already started"}
+		}
+		entries, err := qtx.ListTournamentEntries(ctx, t.ID)
+		if err != nil {
+			return fmt.Errorf("list tournament entries: %w", err)
+		}
+		if len(entries) < MinTournamentPlayers {
+			return ErrTournamentConflict{Msg: fmt.Sprintf("at least %d players must be registered", MinTournamentPlayers)}
+		}
+
+		//--entries come in registration order, so the sort keeps it for ties
+		sort.SliceStable(entries, func(a, b int) bool { return entries[a].Rating > entries[b].Rating })
+		seeded := make([]int64, len(entries))
+		for i, e := range entries {
+			err := qtx.SetTournamentEntrySeed(ctx, store.SetTournamentEntrySeedParams{
+				TournamentID: t.ID,
+				PlayerID:     e.PlayerID,
+				Seed:         sql.NullInt32{Int32: int32(i + 1), Valid: true},
+			})
+			if err != nil {
+				return fmt.Errorf("set seed: %w", err)
+			}
+			seeded[i] = e.PlayerID
+		}
+
+		t, err = qtx.StartTournament(ctx, t.ID)
+		if err != nil {
+			return fmt.Errorf("start tournament: %w", err)
+		}
+		squads := entrySquads(entries)
+		switch t.Format {
+		case FormatSingleElimination:
+			err = startBracket(ctx, qtx, t, seeded, squads)
+		case FormatRoundRobin:
+			err = startRoundRobin(ctx, qtx, t, seeded, squads)
+		default:
+			err = fmt.Errorf("unknown tournament format %q", t.Format)
+		}
+		if err != nil {
+			return err
+		}
+
+		//--byes and walkovers may have decided the whole tournament already
+		t, err = qtx.GetTournamentByID(ctx, t.ID)
+		if err != nil {
+			return fmt.Errorf("get tournament: %w", err)
+		}
+		return auditTournament(ctx, qtx, adminID, "start", t.ID, struct {
+			Players int `json:"players"`
+		}{len(entries)})
+	})
+	if err != nil {
+		return store.Tournament{}, err
+	}
+	return t, nil
+}
+
+// startBracket lays out a single-elimination bracket. The bracket is sized
+// to the next power of two and seeded so the top seeds meet last; the
+// missing players are byes, which go to the top seeds. Every round's
+// pairings are created up front and filled in as winners advance.
+func startBracket(ctx context.Context, qtx store.Querier, t store.Tournament, seeded []int64, squads map[int64]int64) error {
+	size := 1
+	for size < len(seeded) {
+		size *= 2
+	}
+	//--later rounds first, so round 1 byes have somewhere to advance to
+	round := int32(2)
+	for slots := size / 4; slots >= 1; slots /= 2 {
+		for slot := 0; slot < slots; slot++ {
+			_, err := qtx.CreateTournamentMatch(ctx, store.CreateTournamentMatchParams{
+				TournamentID: t.ID,
+				Round:        round,
+				Slot:         int32(slot),
+			})
+			if err != nil {
+				return fmt.Errorf("create tournament match: %w", err)
+			}
+		}
+		round++
+	}
+
+	player := func(seed int) sql.NullInt64 {
+		if seed > len(seeded) {
+			return sql.NullInt64{}
+		}
+		return sql.NullInt64{Int64: seeded[seed-1], Valid: true}
+	}
+	order := bracketOrder(size)
+	for slot := 0; slot < size/2; slot++ {
+		p1, p2 := player(order[2*slot]), player(order[2*slot+1])
+		var bye sql.NullInt64
+		if !p2.Valid {
+			bye = p1
+		}
+		tm, err := qtx.CreateTournamentMatch(ctx, store.CreateTournamentMatchParams{
+			TournamentID:   t.ID,
+			Round:          1,
+			Slot:           int32(slot),
+			Player1ID:      p1,
+			Player2ID:      p2,
+			WinnerPlayerID: bye,
+		})
+		if err != nil {
+			return fmt.Errorf("create tournament match: %w", err)
+		}
+		if !bye.Valid {
+			if tm, err = startTournamentMatch(ctx, qtx, t, tm, squads); err != nil {
+				return err
+			}
+		}
+		if tm.WinnerPlayerID.Valid {
+			if err := advanceBracket(ctx, qtx, t, tm, squads); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// bracketOrder lists the seeds of a bracket of size players in slot order:
+// 1 v size, then the pairing the 1 seed meets in round 2, and so on.
+func bracketOrder(size int) []int {
+	order := []int{1}
+	for len(order) < size {
+		n := len(order) * 2
+		next := make([]int, 0, n)
+		for _, seed := range order {
+			next = append(next, seed, n+1-seed)
+		}
+		order = next
+	}
+	return order
+}
+
+// startRoundRobin pairs every player with every other once, using the
+// circle method, and creates the first round's matches. With an odd number
+// of players one sits out each round. If walkovers decide the whole first
+// round, the next one starts straight away.
+func startRoundRobin(ctx context.Context, qtx store.Querier, t store.Tournament, seeded []int64, squads map[int64]int64) error {
+	ids := append([]int64(nil), seeded...)
+	if len(ids)%2 == 1 {
+		ids = append(ids, 0) // the bye
+	}
+	n := len(ids)
+	walkover := false
+	for round := 1; round < n; round++ {
+		slot := int32(0)
+		for i := 0; i < n/2; i++ {
+			p1, p2 := ids[i], ids[n-1-i]
+			if p1 == 0 || p2 == 0 {
+				continue
+			}
+			tm, err := qtx.CreateTournamentMatch(ctx, store.CreateTournamentMatchParams{
+				TournamentID: t.ID,
+				Round:        int32(round),
+				Slot:         slot,
+				Player1ID:    sql.NullInt64{Int64: p1, Valid: true},
+				Player2ID:    sql.NullInt64{Int64: p2, Valid: true},
+			})
+			if err != nil {
+				return fmt.Errorf("create tournament match: %w", err)
+			}
+			if round == 1 {
+				if tm, err = startTournamentMatch(ctx, qtx, t, tm, squads); err != nil {
+					return err
+				}
+				walkover = walkover || tm.WinnerPlayerID.Valid
+			}
+			slot++
+		}
+		//--keep the first player in place and rotate the rest
+		ids = append([]int64{ids[0], ids[n-1]}, ids[1:n-1]...)
+	}
+	if !walkover {
+		return nil
+	}
+	entries, err := qtx.ListTournamentEntries(ctx, t.ID)
+	if err != nil {
+		return fmt.Errorf("list tournament entries: %w", err)
+	}
+	return advanceRoundRobin(ctx, qtx, t, entries, squads)
+}
+
+// advanceTournament records the winner of a tournament match that was just
+// completed and moves the tournament on. Matches outside tournaments are
+// left alone.
+func advanceTournament(ctx context.Context, qtx store.Querier, matchID, winnerID int64) error {
+	tm, err := qtx.GetTournamentMatchByMatchID(ctx, sql.NullInt64{Int64: matchID, Valid: true})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("get tournament match: %w", err)
+	}
+	//--lock the tournament so matches finishing together advance in turn
+	t, err := qtx.GetTournamentByIDForUpdate(ctx, tm.TournamentID)
+	if err != nil {
+		return fmt.Errorf("get tournament: %w", err)
+	}
+	tm.WinnerPlayerID = sql.NullInt64{Int64: winnerID, Valid: true}
+	tm, err = updateTournamentMatch(ctx, qtx, tm)
+	if err != nil {
+		return err
+	}
+
+	entries, err := qtx.ListTournamentEntries(ctx, t.ID)
+	if err != nil {
+		return fmt.Errorf("list tournament entries: %w", err)
+	}
+	squads := entrySquads(entries)
+	if t.Format == FormatRoundRobin {
+		return advanceRoundRobin(ctx, qtx, t, entries, squads)
+	}
+	return advanceBracket(ctx, qtx, t, tm, squads)
+}
+
+// advanceBracket moves the winner of tm into the next round, starting that
+// match once both its players are known. A walkover moves on again
+// straight away. The winner of the final wins the tournament.
+func advanceBracket(ctx context.Context, qtx store.Querier, t store.Tournament, tm store.TournamentMatch, squads map[int64]int64) error {
+	next, err := qtx.GetTournamentMatchBySlot(ctx, store.GetTournamentMatchBySlotParams{
+		TournamentID: t.ID,
+		Round:        tm.Round + 1,
+		Slot:         tm.Slot / 2,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return completeTournament(ctx, qtx, t, tm.WinnerPlayerID.Int64)
+	}
+	if err != nil {
+		return fmt.Errorf("get next tournament match: %w", err)
+	}
+
+	if tm.Slot%2 == 0 {
+		next.Player1ID = tm.WinnerPlayerID
+	} else {
+		next.Player2ID = tm.WinnerPlayerID
+	}
+	if !next.Player1ID.Valid || !next.Player2ID.Valid {
+		_, err = updateTournamentMatch(ctx, qtx, next)
+		return err
+	}
+	next, err = startTournamentMatch(ctx, qtx, t, next, squads)
+	if err != nil {
+		return err
+	}
+	if next.WinnerPlayerID.Valid {
+		return advanceBracket(ctx, qtx, t, next, squads)
+	}
+	return raiseTournamentRound(ctx, qtx, t, next.Round)
+}
+
+// advanceRoundRobin starts the next round once every match of the current
+// one is decided, and the one after if walkovers decide it too. After the
+// last round the standings leader wins.
+func advanceRoundRobin(ctx context.Context, qtx store.Querier, t store.Tournament, entries []store.ListTournamentEntriesRow, squads map[int64]int64) error {
+	matches, err := qtx.ListTournamentMatches(ctx, t.ID)
+	if err != nil {
+		return fmt.Errorf("list tournament matches: %w", err)
+	}
+	var next []store.TournamentMatch
+	for _, tm := range matches {
+		if tm.Round == t.CurrentRound && !tm.WinnerPlayerID.Valid {
+			return nil
+		}
+		if tm.Round == t.CurrentRound+1 {
+			next = append(next, tm)
+		}
+	}
+
+	if len(next) == 0 {
+		table := standings(entries, matches)
+		return completeTournament(ctx, qtx, t, table[0].PlayerID)
+	}
+	walkover := false
+	for _, tm := range next {
+		tm, err := startTournamentMatch(ctx, qtx, t, tm, squads)
+		if err != nil {
+			return err
+		}
+		walkover = walkover || tm.WinnerPlayerID.Valid
+	}
+	if err := raiseTournamentRound(ctx, qtx, t, t.CurrentRound+1); err != nil {
+		return err
+	}
+	if !walkover {
+		return nil
+	}
+	t.CurrentRound++
+	return advanceRoundRobin(ctx, qtx, t, entries, squads)
+}
+
+// startTournamentMatch creates and starts the public match for a pairing
+// whose players are both known. Players who have blocked each other can't
+// be matched, so their pairing is a walkover instead: it is decided without
+// a match, against the player who blocked. Callers advance the tournament
+// past a walkover.
+func startTournamentMatch(ctx context.Context, qtx store.Querier, t store.Tournament, tm store.TournamentMatch, squads map[int64]int64) (store.TournamentMatch, error) {
+	p1, p2 := tm.Player1ID.Int64, tm.Player2ID.Int64
+	matchID, err := createMatch(ctx, qtx, NewMatch{
+		Player1ID:      p1,
+		Player1SquadID: squads[p1],
+		Player2ID:      p2,
+		Player2SquadID: squads[p2],
+		Public:         true,
+		RulesetID:      t.RulesetID,
+	})
+	var forbidden ErrForbidden
+	switch {
+	case errors.As(err, &forbidden):
+		tm.WinnerPlayerID, err = walkoverWinner(ctx, qtx, tm)
+		if err != nil {
+			return store.TournamentMatch{}, err
+		}
+	case err != nil:
+		return store.TournamentMatch{}, err
+	default:
+		tm.MatchID = sql.NullInt64{Int64: matchID, Valid: true}
+	}
+	return updateTournamentMatch(ctx, qtx, tm)
+}
+
+// walkoverWinner decides a pairing between players who have blocked each
+// other: the player who didn't block wins, or player 1 if both did.
+func walkoverWinner(ctx context.Context, qtx store.Querier, tm store.TournamentMatch) (sql.NullInt64, error) {
+	rows, err := qtx.ListFriendshipsBetween(ctx, store.ListFriendshipsBetweenParams{
+		PlayerID: tm.Player1ID.Int64,
+		OtherID:  tm.Player2ID.Int64,
+	})
+	if err != nil {
+		return sql.NullInt64{}, fmt.Errorf("list friendships: %w", err)
+	}
+	blocked := map[int64]bool{}
+	for _, f := range rows {
+		if f.Status == player.StatusBlocked {
+			blocked[f.PlayerID] = true
+		}
+	}
+	if blocked[tm.Player1ID.Int64] && !blocked[tm.Player2ID.Int64] {
+		return tm.Player2ID, nil
+	}
+	return tm.Player1ID, nil
+}
+
+func updateTournamentMatch(ctx context.Context, qtx store.Querier, tm store.TournamentMatch) (store.TournamentMatch, error) {
+	tm, err := qtx.UpdateTournamentMatch(ctx, store.UpdateTournamentMatchParams{
+		ID:             tm.ID,
+		Player1ID:      tm.Player1ID,
+		Player2ID:      tm.Player2ID,
+		MatchID:        tm.MatchID,
+		WinnerPlayerID: tm.WinnerPlayerID,
+	})
+	if err != nil {
+		return store.TournamentMatch{}, fmt.Errorf("update tournament match: %w", err)
+	}
+	return tm, nil
+}
+
+// raiseTournamentRound records that a match of round has started. Bracket
+// rounds overlap, so current_round only ever goes up; it is read again
+// because walkovers can raise it while t is held.
+func raiseTournamentRound(ctx context.Context, qtx store.Querier, t store.Tournament, round int32) error {
+	t, err := qtx.GetTournamentByID(ctx, t.ID)
+	if err != nil {
+		return fmt.Errorf("get tournament: %w", err)
+	}
+	if round <= t.CurrentRound {
+		return nil
+	}
+	if _, err := qtx.UpdateTournamentRound(ctx, store.UpdateTournamentRoundParams{ID: t.ID, CurrentRound: round}); err != nil {
+		return fmt.Errorf("update tournament round: %w", err)
+	}
+	return nil
+}
+
+func completeTournament(ctx context.Context, qtx store.Querier, t store.Tournament, winnerID int64) error {
+	_, err := qtx.CompleteTournament(ctx, store.CompleteTournamentParams{
+		ID:             t.ID,
+		WinnerPlayerID: sql.NullInt64{Int64: winnerID, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("complete tournament: %w", err)
+	}
+	return nil
+}
+
+// standings tallies the decided matches of a tournament. entries must be in
+// seed order, which breaks ties.
+func standings(entries []store.ListTournamentEntriesRow, matches []store.TournamentMatch) []Standing {
+	table := make([]Standing, len(entries))
+	index := make(map[int64]int, len(entries))
+	for i, e := range entries {
+		table[i] = Standing{PlayerID: e.PlayerID, Username: e.Username}
+		index[e.PlayerID] = i
+	}
+	for _, tm := range matches {
+		if !tm.Player2ID.Valid || !tm.WinnerPlayerID.Valid {
+			continue
+		}
+		for _, p := range []sql.NullInt64{tm.Player1ID, tm.Player2ID} {
+			i, ok := index[p.Int64]
+			if !ok {
+				continue
+			}
+			table[i].Played++
+			if p.Int64 == tm.WinnerPlayerID.Int64 {
+				table[i].Wins++
+			} else {
+				table[i].Losses++
+			}
+		}
+	}
+	sort.SliceStable(table, func(a, b int) bool {
+		if table[a].Wins != table[b].Wins {
+			return table[a].Wins > table[b].Wins
+		}
+		return table[a].Losses < table[b].Losses
+	})
+	return table
+}
+
+// entrySquads maps each registered player to their locked squad.
+func entrySquads(entries []store.ListTournamentEntriesRow) map[int64]int64 {
+	squads := make(map[int64]int64, len(entries))
+	for _, e := range entries {
+		squads[e.PlayerID] = e.SquadID
+	}
+	return squads
+}
+
+// getTournament loads a tournament, locking it if forUpdate.
+func getTournament(ctx context.Context, q store.Querier, tournamentID int64, forUpdate bool) (store.Tournament, error) {
+	get := q.GetTournamentByID
+	if forUpdate {
+		get = q.GetTournamentByIDForUpdate
+	}
+	t, err := get(ctx, tournamentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return store.Tournament{}, ErrTournamentNotFound{Msg: fmt.Sprintf("tournament %d not found", tournamentID)}
+	}
+	if err != nil {
+		return store.Tournament{}, fmt.Errorf("get tournament: %w", err)
+	}
+	return t, nil
+}
+
+func auditTournament(ctx context.Context, qtx store.Querier, adminID int64, action string, tournamentID int64, details any) error {
+	b, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Errorf("marshal audit details: %w", err)
+	}
+	_, err = qtx.CreateAdminAuditEntry(ctx, store.CreateAdminAuditEntryParams{
+		AdminPlayerID: adminID,
+		Action:        action,
+		EntityType:    "tournament",
+		EntityID:      tournamentID,
+		Details:       string(b),
+	})
+	if err != nil {
+		return fmt.Errorf("create audit entry: %w", err)
+	}
+	return nil
+}
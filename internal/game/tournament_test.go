@@ -0,0 +1,418 @@
+package game_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/76dillon/battle_squads/internal/game"
+	"github.com/76dillon/battle_squads/internal/player"
+	"github.com/76dillon/battle_squads/internal/store"
+	"github.com/76dillon/battle_squads/internal/store/storetest"
+)
+
+func TestTournament(t *testing.T) {
+	for name, open := range storetest.Backends() {
+		t.Run(name, func(t *testing.T) {
+			testTournament(t, newTestEnv(t, open(t)))
+		})
+	}
+}
+
+func testTournament(t *testing.T, e *testEnv) {
+	ctx := context.Background()
+	alice, aliceSquad := e.newPlayer(t, "alice")
+	bob, bobSquad := e.newPlayer(t, "bob")
+
+	created, err := e.game.CreateTournament(ctx, adminID, game.NewTournament{
+		Name:       "Cup",
+		Format:     game.FormatSingleElimination,
+		MaxPlayers: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.RulesetID != game.StandardRulesetID {
+		t.Fatalf("ruleset = %d, want %d", created.RulesetID, game.StandardRulesetID)
+	}
+	if _, err := e.game.TournamentRuleset(ctx, created.ID); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []struct{ player, squad int64 }{{alice, aliceSquad}, {bob, bobSquad}} {
+		if _, err := e.game.RegisterForTournament(ctx, created.ID, p.player, p.squad); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	started, err := e.game.StartTournament(ctx, adminID, created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if started.State != game.TournamentInProgress {
+		t.Fatalf("state = %s, want %s", started.State, game.TournamentInProgress)
+	}
+
+	view, err := e.game.GetTournament(ctx, created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if view.Tournament.RulesetID != game.StandardRulesetID {
+		t.Errorf("view ruleset = %d, want %d", view.Tournament.RulesetID, game.StandardRulesetID)
+	}
+	if len(view.Entries) != 2 {
+		t.Errorf("got %d entries, want 2", len(view.Entries))
+	}
+	if len(view.Matches) != 1 || !view.Matches[0].MatchID.Valid {
+		t.Fatalf("matches = %+v, want one started final", view.Matches)
+	}
+
+	list, err := e.st.ListTournaments(ctx, store.ListTournamentsParams{
+		State:    sql.NullString{String: game.TournamentInProgress, Valid: true},
+		RowLimit: 10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].ID != created.ID || list[0].RulesetID != game.StandardRulesetID {
+		t.Errorf("ListTournaments = %+v", list)
+	}
+}
+
+// newTournament creates a tournament of format and registers n new players
+// named prefix1, prefix2 and so on. Their ratings are equal, so they are
+// seeded in that order.
+func (e *testEnv) newTournament(t *testing.T, prefix, format string, n int) (store.Tournament, []int64) {
+	t.Helper()
+	ctx := context.Background()
+	tour, err := e.game.CreateTournament(ctx, adminID, game.NewTournament{
+		Name:       prefix + " cup",
+		Format:     format,
+		MaxPlayers: n,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	players := make([]int64, n)
+	for i := range players {
+		id, sq := e.newPlayer(t, fmt.Sprintf("%s%d", prefix, i+1))
+		if _, err := e.game.RegisterForTournament(ctx, tour.ID, id, sq); err != nil {
+			t.Fatal(err)
+		}
+		players[i] = id
+	}
+	return tour, players
+}
+
+// finishMatch plays matchID through ApplyTurn until winnerID wins it.
+func (e *testEnv) finishMatch(t *testing.T, matchID, winnerID int64) {
+	t.Helper()
+	m, err := e.st.GetMatchByID(context.Background(), matchID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loser := m.Player1ID
+	if loser == winnerID {
+		loser = m.Player2ID
+	}
+	tackle := game.TurnAction{Kind: game.ActionMove, MoveID: e.move(t, "Tackle").ID}
+	e.setHP(t, e.active(t, matchID, loser), 1)
+	if m.CurrentActorPlayerID.Int64 == loser {
+		e.turn(t, matchID, loser, tackle)
+	}
+	e.turn(t, matchID, winnerID, tackle)
+	if m, err = e.st.GetMatchByID(context.Background(), matchID); err != nil {
+		t.Fatal(err)
+	}
+	if m.WinnerPlayerID.Int64 != winnerID {
+		t.Fatalf("match %d: winner %d, want %d", matchID, m.WinnerPlayerID.Int64, winnerID)
+	}
+}
+
+// pairing returns the tournament's pairing in round and slot.
+func pairing(t *testing.T, view game.TournamentView, round, slot int32) store.TournamentMatch {
+	t.Helper()
+	for _, tm := range view.Matches {
+		if tm.Round == round && tm.Slot == slot {
+			return tm
+		}
+	}
+	t.Fatalf("no pairing in round %d, slot %d", round, slot)
+	return store.TournamentMatch{}
+}
+
+func TestTournamentWalkovers(t *testing.T) {
+	for name, open := range storetest.Backends() {
+		t.Run(name, func(t *testing.T) {
+			testTournamentWalkovers(t, newTestEnv(t, open(t)))
+		})
+	}
+}
+
+// testTournamentWalkovers checks that players who have blocked each other
+// are never matched in a tournament: the pairing goes to the player who
+// didn't block, whether the block was made before the draw or during the
+// tournament, and the tournament moves on.
+func testTournamentWalkovers(t *testing.T, e *testEnv) {
+	ctx := context.Background()
+	players := player.NewService(e.st)
+	view := func(id int64) game.TournamentView {
+		t.Helper()
+		v, err := e.game.GetTournament(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	//--single elimination of three: seed 1 has a bye, seed 2 blocked seed 3
+	//  before the start, so seed 3 walks over and meets seed 1 at once
+	bracket, seeds := e.newTournament(t, "bracket", game.FormatSingleElimination, 3)
+	if err := players.Block(ctx, seeds[1], seeds[2]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.game.StartTournament(ctx, adminID, bracket.ID); err != nil {
+		t.Fatal(err)
+	}
+	v := view(bracket.ID)
+	walkover := pairing(t, v, 1, 1)
+	if walkover.MatchID.Valid || walkover.WinnerPlayerID.Int64 != seeds[2] {
+		t.Errorf("blocked pairing %+v, want a walkover to seed 3", walkover)
+	}
+	final := pairing(t, v, 2, 0)
+	if !final.MatchID.Valid || final.Player1ID.Int64 != seeds[0] || final.Player2ID.Int64 != seeds[2] {
+		t.Fatalf("final %+v, want seed 1 against seed 3 started", final)
+	}
+	if v.Tournament.CurrentRound != 2 {
+		t.Errorf("current round %d, want 2", v.Tournament.CurrentRound)
+	}
+
+	//--a block during the tournament: seed 3 blocks seed 1 and wins, and
+	//  the rematch is a walkover to seed 1
+	rematch, rematchSeeds := e.newTournament(t, "rematch", game.FormatSingleElimination, 3)
+	if _, err := e.game.StartTournament(ctx, adminID, rematch.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := players.Block(ctx, rematchSeeds[2], rematchSeeds[0]); err != nil {
+		t.Fatal(err)
+	}
+	e.finishMatch(t, pairing(t, view(rematch.ID), 1, 1).MatchID.Int64, rematchSeeds[2])
+	v = view(rematch.ID)
+	if final := pairing(t, v, 2, 0); final.MatchID.Valid || final.WinnerPlayerID.Int64 != rematchSeeds[0] {
+		t.Errorf("final %+v, want a walkover to seed 1", final)
+	}
+	if v.Tournament.State != game.TournamentCompleted || v.Tournament.WinnerPlayerID.Int64 != rematchSeeds[0] {
+		t.Errorf("tournament %s won by %d, want completed and won by seed 1", v.Tournament.State, v.Tournament.WinnerPlayerID.Int64)
+	}
+
+	//--round robin of three where everyone blocked everyone: every round is
+	//  a walkover to player 1 of the pairing, and walkovers count in the
+	//  standings
+	rr, rrSeeds := e.newTournament(t, "rr", game.FormatRoundRobin, 3)
+	for _, a := range rrSeeds {
+		for _, b := range rrSeeds {
+			if a != b {
+				if err := players.Block(ctx, a, b); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+	}
+	if _, err := e.game.StartTournament(ctx, adminID, rr.ID); err != nil {
+		t.Fatal(err)
+	}
+	v = view(rr.ID)
+	if v.Tournament.State != game.TournamentCompleted {
+		t.Fatalf("tournament %s, want every round decided by walkovers", v.Tournament.State)
+	}
+	for _, tm := range v.Matches {
+		if tm.MatchID.Valid || tm.WinnerPlayerID != tm.Player1ID {
+			t.Errorf("pairing %+v, want a walkover to player 1", tm)
+		}
+	}
+	played := 0
+	for _, st := range v.Standings {
+		played += st.Played
+	}
+	if played != 6 || v.Tournament.WinnerPlayerID.Int64 != v.Standings[0].PlayerID {
+		t.Errorf("standings %+v, won by %d; want 3 pairings played and the leader winning", v.Standings, v.Tournament.WinnerPlayerID.Int64)
+	}
+}
+
+func TestSingleElimination(t *testing.T) {
+	for name, open := range storetest.Backends() {
+		t.Run(name, func(t *testing.T) {
+			testSingleElimination(t, newTestEnv(t, open(t)))
+		})
+	}
+}
+
+// testSingleElimination plays brackets of three and five players through
+// ApplyTurn, checking that byes go to the top seeds, that winners advance
+// into the right slot and that the final's winner takes the tournament.
+func testSingleElimination(t *testing.T, e *testEnv) {
+	ctx := context.Background()
+	view := func(id int64) game.TournamentView {
+		t.Helper()
+		v, err := e.game.GetTournament(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	wantPairing := func(v game.TournamentView, round, slot int32, p1, p2 int64, started bool) store.TournamentMatch {
+		t.Helper()
+		tm := pairing(t, v, round, slot)
+		if tm.Player1ID.Int64 != p1 || tm.Player2ID.Int64 != p2 || tm.MatchID.Valid != started {
+			t.Fatalf("round %d, slot %d: %+v, want %d against %d, started %v", round, slot, tm, p1, p2, started)
+		}
+		return tm
+	}
+	wantBye := func(v game.TournamentView, slot int32, p int64) {
+		t.Helper()
+		tm := pairing(t, v, 1, slot)
+		if tm.Player1ID.Int64 != p || tm.Player2ID.Valid || tm.MatchID.Valid || tm.WinnerPlayerID.Int64 != p {
+			t.Errorf("round 1, slot %d: %+v, want a bye to %d", slot, tm, p)
+		}
+	}
+
+	//--three players in a bracket of four: 1 has a bye, 2 plays 3
+	three, s := e.newTournament(t, "three", game.FormatSingleElimination, 3)
+	if _, err := e.game.StartTournament(ctx, adminID, three.ID); err != nil {
+		t.Fatal(err)
+	}
+	v := view(three.ID)
+	if len(v.Matches) != 3 || v.Tournament.CurrentRound != 1 {
+		t.Fatalf("%d pairings in round %d, want 3 in round 1", len(v.Matches), v.Tournament.CurrentRound)
+	}
+	wantBye(v, 0, s[0])
+	semi := wantPairing(v, 1, 1, s[1], s[2], true)
+	wantPairing(v, 2, 0, s[0], 0, false)
+
+	//--3 knocks out 2 and meets the bye winner in the final
+	e.finishMatch(t, semi.MatchID.Int64, s[2])
+	v = view(three.ID)
+	final := wantPairing(v, 2, 0, s[0], s[2], true)
+	if v.Tournament.CurrentRound != 2 || v.Tournament.State != game.TournamentInProgress {
+		t.Errorf("tournament %s in round %d, want in progress in round 2", v.Tournament.State, v.Tournament.CurrentRound)
+	}
+	e.finishMatch(t, final.MatchID.Int64, s[0])
+	v = view(three.ID)
+	if v.Tournament.State != game.TournamentCompleted || v.Tournament.WinnerPlayerID.Int64 != s[0] {
+		t.Errorf("tournament %s won by %d, want completed and won by seed 1", v.Tournament.State, v.Tournament.WinnerPlayerID.Int64)
+	}
+
+	//--five players in a bracket of eight: 1, 2 and 3 have byes, so 2 and 3
+	//  meet in round 2 at once while 1 waits for the winner of 4 and 5
+	five, s := e.newTournament(t, "five", game.FormatSingleElimination, 5)
+	if _, err := e.game.StartTournament(ctx, adminID, five.ID); err != nil {
+		t.Fatal(err)
+	}
+	v = view(five.ID)
+	if len(v.Matches) != 7 || v.Tournament.CurrentRound != 2 {
+		t.Fatalf("%d pairings in round %d, want 7 in round 2", len(v.Matches), v.Tournament.CurrentRound)
+	}
+	wantBye(v, 0, s[0])
+	first := wantPairing(v, 1, 1, s[3], s[4], true)
+	wantBye(v, 2, s[1])
+	wantBye(v, 3, s[2])
+	wantPairing(v, 2, 0, s[0], 0, false)
+	second := wantPairing(v, 2, 1, s[1], s[2], true)
+	wantPairing(v, 3, 0, 0, 0, false)
+
+	//--the round 2 match that started early finishes first, and its winner
+	//  waits in the final's second slot
+	e.finishMatch(t, second.MatchID.Int64, s[2])
+	wantPairing(view(five.ID), 3, 0, 0, s[2], false)
+	e.finishMatch(t, first.MatchID.Int64, s[4])
+	semi = wantPairing(view(five.ID), 2, 0, s[0], s[4], true)
+	e.finishMatch(t, semi.MatchID.Int64, s[4])
+	final = wantPairing(view(five.ID), 3, 0, s[4], s[2], true)
+	e.finishMatch(t, final.MatchID.Int64, s[2])
+	v = view(five.ID)
+	if v.Tournament.State != game.TournamentCompleted || v.Tournament.WinnerPlayerID.Int64 != s[2] || v.Tournament.CurrentRound != 3 {
+		t.Errorf("tournament %s in round %d won by %d, want completed in round 3 and won by seed 3",
+			v.Tournament.State, v.Tournament.CurrentRound, v.Tournament.WinnerPlayerID.Int64)
+	}
+	for _, st := range v.Standings {
+		if st.PlayerID == s[0] && (st.Played != 1 || st.Losses != 1) {
+			t.Errorf("seed 1 standing %+v, want the bye left out", st)
+		}
+	}
+}
+
+func TestRoundRobin(t *testing.T) {
+	for name, open := range storetest.Backends() {
+		t.Run(name, func(t *testing.T) {
+			testRoundRobin(t, newTestEnv(t, open(t)))
+		})
+	}
+}
+
+// testRoundRobin plays round robins of three and five players through
+// ApplyTurn. In each match the player further down the field wins, so the
+// last seed wins everything and the standings come out in reverse seed
+// order.
+func testRoundRobin(t *testing.T, e *testEnv) {
+	ctx := context.Background()
+	for _, n := range []int{3, 5} {
+		tour, s := e.newTournament(t, fmt.Sprintf("rr%d-", n), game.FormatRoundRobin, n)
+		if _, err := e.game.StartTournament(ctx, adminID, tour.ID); err != nil {
+			t.Fatal(err)
+		}
+		seed := make(map[int64]int, n)
+		for i, id := range s {
+			seed[id] = i
+		}
+
+		var v game.TournamentView
+		for round := int32(1); ; round++ {
+			var err error
+			if v, err = e.game.GetTournament(ctx, tour.ID); err != nil {
+				t.Fatal(err)
+			}
+			if v.Tournament.State == game.TournamentCompleted {
+				break
+			}
+			if v.Tournament.CurrentRound != round {
+				t.Fatalf("%d players: current round %d, want %d", n, v.Tournament.CurrentRound, round)
+			}
+			//--every match of the round has started, and none of a later one
+			playing := 0
+			for _, tm := range v.Matches {
+				if tm.MatchID.Valid != (tm.Round <= round) {
+					t.Fatalf("%d players, round %d: pairing %+v", n, round, tm)
+				}
+				if tm.Round != round {
+					continue
+				}
+				playing++
+				winner := tm.Player1ID.Int64
+				if seed[tm.Player2ID.Int64] > seed[winner] {
+					winner = tm.Player2ID.Int64
+				}
+				e.finishMatch(t, tm.MatchID.Int64, winner)
+			}
+			if playing != n/2 {
+				t.Errorf("%d players, round %d: %d matches, want %d", n, round, playing, n/2)
+			}
+		}
+
+		//--with an odd number of players there are n rounds
+		if want := int32(n); v.Tournament.CurrentRound != want {
+			t.Errorf("%d players: finished in round %d, want %d", n, v.Tournament.CurrentRound, want)
+		}
+		if len(v.Matches) != n*(n-1)/2 {
+			t.Errorf("%d players: %d pairings, want every pair once", n, len(v.Matches))
+		}
+		for i, st := range v.Standings {
+			want := s[n-1-i]
+			if st.PlayerID != want || st.Played != n-1 || st.Wins != n-1-i || st.Losses != i {
+				t.Errorf("%d players: standing %d is %+v, want player %d with %d wins and %d losses", n, i+1, st, want, n-1-i, i)
+			}
+		}
+		if v.Tournament.WinnerPlayerID.Int64 != s[n-1] {
+			t.Errorf("%d players: won by %d, want the last seed %d", n, v.Tournament.WinnerPlayerID.Int64, s[n-1])
+		}
+	}
+}
@@ -0,0 +1,471 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/76dillon/battle_squads/internal/content"
+	"github.com/76dillon/battle_squads/internal/store"
+)
+
+func newCatalogUnitView(u store.Unit) CatalogUnitView {
+	return CatalogUnitView{
+		ID:         u.ID,
+		Name:       u.Name,
+		TypeID:     u.TypeID,
+		BaseHP:     u.BaseHp,
+		BaseAttack: u.BaseAttack,
+		BaseSpeed:  u.BaseSpeed,
+		Ability:    u.Ability,
+	}
+}
+
+func newCatalogMoveView(mv store.Move) CatalogMoveView {
+	return CatalogMoveView{
+		ID:       mv.ID,
+		Name:     mv.Name,
+		Power:    mv.Power,
+		Accuracy: mv.Accuracy,
+		TypeID:   mv.TypeID,
+	}
+}
+
+func newItemView(it store.Item) ItemView {
+	return ItemView{
+		ID:     it.ID,
+		Name:   it.Name,
+		Kind:   it.Kind,
+		Effect: it.Effect,
+		Amount: it.Amount,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// Unit types
+
+type unitTypeRequest struct {
+	Name string `json:"name"`
+}
+
+// GET /admin/unit-types
+func (s *Server) handleAdminListUnitTypes(w http.ResponseWriter, r *http.Request) {
+	types, err := s.q.ListUnitTypes(r.Context())
+	if err != nil {
+		writeInternalError(w, "could not list unit types", err)
+		return
+	}
+	out := make([]UnitTypeView, 0, len(types))
+	for _, t := range types {
+		out = append(out, UnitTypeView{ID: t.ID, Name: t.Name})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// POST /admin/unit-types
+func (s *Server) handleAdminCreateUnitType(w http.ResponseWriter, r *http.Request) {
+	var req unitTypeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+	t, err := s.content.CreateUnitType(r.Context(), requestPlayerID(r), req.Name)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, UnitTypeView{ID: t.ID, Name: t.Name})
+}
+
+// PUT /admin/unit-types/{id}
+func (s *Server) handleAdminUpdateUnitType(w http.ResponseWriter, r *http.Request) {
+	typeID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req unitTypeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+	t, err := s.content.UpdateUnitType(r.Context(), requestPlayerID(r), typeID, req.Name)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, UnitTypeView{ID: t.ID, Name: t.Name})
+}
+
+// DELETE /admin/unit-types/{id}
+func (s *Server) handleAdminDeleteUnitType(w http.ResponseWriter, r *http.Request) {
+	typeID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := s.content.DeleteUnitType(r.Context(), requestPlayerID(r), typeID); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Units
+
+// POST /admin/units
+func (s *Server) handleCreateUnit(w http.ResponseWriter, r *http.Request) {
+	var req content.UnitInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+
+	u, err := s.content.CreateUnit(r.Context(), requestPlayerID(r), req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newCatalogUnitView(u))
+}
+
+// GET /admin/units/{id}
+func (s *Server) handleAdminGetUnit(w http.ResponseWriter, r *http.Request) {
+	unitID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	u, err := s.q.GetUnitByID(r.Context(), unitID)
+	if err != nil || u.DeletedAt.Valid {
+		writeError(w, http.StatusNotFound, CodeNotFound, "unit not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, newCatalogUnitView(u))
+}
+
+// PUT /admin/units/{id}
+func (s *Server) handleAdminUpdateUnit(w http.ResponseWriter, r *http.Request) {
+	unitID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req content.UnitInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+	u, err := s.content.UpdateUnit(r.Context(), requestPlayerID(r), unitID, req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newCatalogUnitView(u))
+}
+
+// DELETE /admin/units/{id}
+func (s *Server) handleAdminDeleteUnit(w http.ResponseWriter, r *http.Request) {
+	unitID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := s.content.DeleteUnit(r.Context(), requestPlayerID(r), unitID); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type addUnitMoveRequest struct {
+	MoveID int64 `json:"move_id"`
+}
+
+// GET /admin/units/{id}/moves
+func (s *Server) handleAdminListUnitMoves(w http.ResponseWriter, r *http.Request) {
+	unitID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	u, err := s.q.GetUnitByID(ctx, unitID)
+	if err != nil || u.DeletedAt.Valid {
+		writeError(w, http.StatusNotFound, CodeNotFound, "unit not found")
+		return
+	}
+	moves, err := s.q.ListMovesForUnit(ctx, unitID)
+	if err != nil {
+		writeInternalError(w, "could not list moves", err)
+		return
+	}
+	out := make([]CatalogMoveView, 0, len(moves))
+	for _, mv := range moves {
+		out = append(out, newCatalogMoveView(mv))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// POST /admin/units/{id}/moves
+func (s *Server) handleAdminAddUnitMove(w http.ResponseWriter, r *http.Request) {
+	unitID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req addUnitMoveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+	if err := s.content.AddUnitMove(r.Context(), requestPlayerID(r), unitID, req.MoveID); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+// DELETE /admin/units/{id}/moves/{move_id}
+func (s *Server) handleAdminRemoveUnitMove(w http.ResponseWriter, r *http.Request) {
+	unitID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	moveID, ok := pathID(w, r, "move_id")
+	if !ok {
+		return
+	}
+
+	if err := s.content.RemoveUnitMove(r.Context(), requestPlayerID(r), unitID, moveID); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Moves
+
+type createMoveRequest struct {
+	content.MoveInput
+	UnitIDs []int64 `json:"unit_ids"`
+}
+
+// GET /admin/moves
+func (s *Server) handleAdminListMoves(w http.ResponseWriter, r *http.Request) {
+	moves, err := s.q.ListMoves(r.Context())
+	if err != nil {
+		writeInternalError(w, "could not list moves", err)
+		return
+	}
+	out := make([]CatalogMoveView, 0, len(moves))
+	for _, mv := range moves {
+		out = append(out, newCatalogMoveView(mv))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// POST /admin/moves
+func (s *Server) handleAdminCreateMove(w http.ResponseWriter, r *http.Request) {
+	var req createMoveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+	mv, err := s.content.CreateMove(r.Context(), requestPlayerID(r), req.MoveInput, req.UnitIDs)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newCatalogMoveView(mv))
+}
+
+// GET /admin/moves/{id}
+func (s *Server) handleAdminGetMove(w http.ResponseWriter, r *http.Request) {
+	moveID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	mv, err := s.q.GetMoveByID(r.Context(), moveID)
+	if err != nil || mv.DeletedAt.Valid {
+		writeError(w, http.StatusNotFound, CodeNotFound, "move not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, newCatalogMoveView(mv))
+}
+
+// PUT /admin/moves/{id}
+func (s *Server) handleAdminUpdateMove(w http.ResponseWriter, r *http.Request) {
+	moveID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req content.MoveInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+	mv, err := s.content.UpdateMove(r.Context(), requestPlayerID(r), moveID, req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newCatalogMoveView(mv))
+}
+
+// DELETE /admin/moves/{id}
+func (s *Server) handleAdminDeleteMove(w http.ResponseWriter, r *http.Request) {
+	moveID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := s.content.DeleteMove(r.Context(), requestPlayerID(r), moveID); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Items
+
+// POST /admin/items
+func (s *Server) handleAdminCreateItem(w http.ResponseWriter, r *http.Request) {
+	var req content.ItemInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+	it, err := s.content.CreateItem(r.Context(), requestPlayerID(r), req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newItemView(it))
+}
+
+// PUT /admin/items/{id}
+func (s *Server) handleAdminUpdateItem(w http.ResponseWriter, r *http.Request) {
+	itemID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req content.ItemInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+	it, err := s.content.UpdateItem(r.Context(), requestPlayerID(r), itemID, req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newItemView(it))
+}
+
+// DELETE /admin/items/{id}
+func (s *Server) handleAdminDeleteItem(w http.ResponseWriter, r *http.Request) {
+	itemID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := s.content.DeleteItem(r.Context(), requestPlayerID(r), itemID); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Content packs
+
+// POST /admin/content/import?dry_run=true
+func (s *Server) handleContentImport(w http.ResponseWriter, r *http.Request) {
+	adminID := requestPlayerID(r)
+
+	dryRun := false
+	if v := r.URL.Query().Get("dry_run"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, CodeBadRequest, "invalid dry_run")
+			return
+		}
+		dryRun = b
+	}
+
+	pack, err := content.ReadPack(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, err.Error())
+		return
+	}
+
+	result, err := s.content.Import(r.Context(), adminID, pack, dryRun)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// GET /admin/content/export?name=my-pack&version=1.0.0
+func (s *Server) handleContentExport(w http.ResponseWriter, r *http.Request) {
+
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		name = "export"
+	}
+	pack, err := s.content.Export(r.Context(), name, r.URL.Query().Get("version"))
+	if err != nil {
+		writeInternalError(w, "could not export content", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, pack)
+}
+
+// Audit log
+
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 500
+)
+
+// GET /admin/audit-log?limit=N returns the most recent admin changes first.
+func (s *Server) handleAdminAuditLog(w http.ResponseWriter, r *http.Request) {
+
+	limit := defaultAuditLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxAuditLimit {
+			writeError(w, http.StatusBadRequest, CodeBadRequest, "limit must be between 1 and 500")
+			return
+		}
+		limit = n
+	}
+
+	entries, err := s.q.ListAdminAuditEntries(r.Context(), int32(limit))
+	if err != nil {
+		writeInternalError(w, "could not list audit log", err)
+		return
+	}
+	out := make([]AuditEntryView, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, AuditEntryView{
+			ID:            e.ID,
+			AdminPlayerID: e.AdminPlayerID,
+			Action:        e.Action,
+			EntityType:    e.EntityType,
+			EntityID:      e.EntityID,
+			Details:       json.RawMessage(e.Details),
+			CreatedAt:     e.CreatedAt,
+		})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
@@ -0,0 +1,151 @@
+package httpapi
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/76dillon/battle_squads/internal/store"
+	"github.com/76dillon/battle_squads/internal/validation"
+)
+
+// parseAnalyticsQuery reads ?from=, ?to= and ?format= (json or csv).
+func parseAnalyticsQuery(query url.Values) (from, to sql.NullTime, asCSV bool, err error) {
+	verr := validation.New("query")
+	from, to = parseDayRange(query, verr)
+
+	switch query.Get("format") {
+	case "", "json":
+	case "csv":
+		asCSV = true
+	default:
+		verr.Add("format", "must be json or csv")
+	}
+	return from, to, asCSV, verr.Err()
+}
+
+// writeCSV responds with a CSV attachment: a header row, then rows.
+func writeCSV(w http.ResponseWriter, filename string, header []string, rows [][]string) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.WriteHeader(http.StatusOK)
+	cw := csv.NewWriter(w)
+	_ = cw.Write(header)
+	_ = cw.WriteAll(rows)
+}
+
+func formatRate(f float64) string {
+	return strconv.FormatFloat(f, 'f', 4, 64)
+}
+
+// GET /admin/analytics/units?from=2025-01-01&to=2025-01-31&format=csv
+// reports how each unit performs in real games.
+func (s *Server) handleAdminUnitAnalytics(w http.ResponseWriter, r *http.Request) {
+	from, to, asCSV, err := parseAnalyticsQuery(r.URL.Query())
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	rows, err := s.q.ListUnitAnalytics(r.Context(), store.ListUnitAnalyticsParams{FromTime: from, ToTime: to})
+	if err != nil {
+		writeInternalError(w, "could not load analytics", err)
+		return
+	}
+
+	out := make([]UnitAnalyticsView, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, UnitAnalyticsView{
+			UnitID:        row.ID,
+			Name:          row.Name,
+			SquadsPicked:  row.SquadsPicked,
+			PickRate:      ratio(row.SquadsPicked, row.TotalSquads),
+			TimesFielded:  row.TimesFielded,
+			WinRate:       ratio(row.Wins, row.TimesFielded),
+			Uses:          row.Uses,
+			HitRate:       ratio(row.Hits, row.Uses),
+			AverageDamage: ratio(row.DamageDealt, row.Uses),
+			KORate:        ratio(row.Kos, row.Uses),
+		})
+	}
+	if !asCSV {
+		writeJSON(w, http.StatusOK, out)
+		return
+	}
+
+	records := make([][]string, 0, len(out))
+	for _, u := range out {
+		records = append(records, []string{
+			strconv.FormatInt(u.UnitID, 10),
+			u.Name,
+			strconv.FormatInt(u.SquadsPicked, 10),
+			formatRate(u.PickRate),
+			strconv.FormatInt(u.TimesFielded, 10),
+			formatRate(u.WinRate),
+			strconv.FormatInt(u.Uses, 10),
+			formatRate(u.HitRate),
+			formatRate(u.AverageDamage),
+			formatRate(u.KORate),
+		})
+	}
+	writeCSV(w, "unit-analytics.csv", []string{
+		"unit_id", "name", "squads_picked", "pick_rate", "times_fielded", "win_rate",
+		"uses", "hit_rate", "average_damage", "ko_rate",
+	}, records)
+}
+
+// GET /admin/analytics/moves?from=2025-01-01&to=2025-01-31&format=csv
+// reports how each move performs in real games.
+func (s *Server) handleAdminMoveAnalytics(w http.ResponseWriter, r *http.Request) {
+	from, to, asCSV, err := parseAnalyticsQuery(r.URL.Query())
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	rows, err := s.q.ListMoveAnalytics(r.Context(), store.ListMoveAnalyticsParams{FromTime: from, ToTime: to})
+	if err != nil {
+		writeInternalError(w, "could not load analytics", err)
+		return
+	}
+
+	out := make([]MoveAnalyticsView, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, MoveAnalyticsView{
+			MoveID:        row.ID,
+			Name:          row.Name,
+			Uses:          row.Uses,
+			UseRate:       ratio(row.Uses, row.TotalUses),
+			MatchesUsed:   row.MatchesUsed,
+			WinRate:       ratio(row.Wins, row.MatchesUsed),
+			HitRate:       ratio(row.Hits, row.Uses),
+			AverageDamage: ratio(row.DamageDealt, row.Uses),
+			KORate:        ratio(row.Kos, row.Uses),
+		})
+	}
+	if !asCSV {
+		writeJSON(w, http.StatusOK, out)
+		return
+	}
+
+	records := make([][]string, 0, len(out))
+	for _, m := range out {
+		records = append(records, []string{
+			strconv.FormatInt(m.MoveID, 10),
+			m.Name,
+			strconv.FormatInt(m.Uses, 10),
+			formatRate(m.UseRate),
+			strconv.FormatInt(m.MatchesUsed, 10),
+			formatRate(m.WinRate),
+			formatRate(m.HitRate),
+			formatRate(m.AverageDamage),
+			formatRate(m.KORate),
+		})
+	}
+	writeCSV(w, "move-analytics.csv", []string{
+		"move_id", "name", "uses", "use_rate", "matches_used", "win_rate",
+		"hit_rate", "average_damage", "ko_rate",
+	}, records)
+}
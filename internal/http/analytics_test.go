@@ -0,0 +1,113 @@
+package httpapi
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"net/http"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/76dillon/battle_squads/internal/store"
+)
+
+func TestAnalyticsHitRate(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, c *testClient) {
+		ctx := context.Background()
+		m := c.newMatch()
+		attacker := activeUnit(t, m, m.Match.Player1ID)
+		target := activeUnit(t, m, m.Match.Player2ID)
+		move := attacker.Moves[0].ID
+
+		// One move that hit for 10 and one that missed
+		for _, missed := range []bool{false, true} {
+			damage := int32(10)
+			if missed {
+				damage = 0
+			}
+			_, err := c.api.q.CreateMatchTurn(ctx, store.CreateMatchTurnParams{
+				MatchID:           m.Match.ID,
+				TurnNumber:        1,
+				ActingPlayerID:    m.Match.Player1ID,
+				ActingMatchUnitID: attacker.MatchUnitID,
+				MoveID:            sql.NullInt64{Int64: move, Valid: true},
+				TargetMatchUnitID: target.MatchUnitID,
+				DamageDone:        damage,
+				TargetHpAfter:     target.CurrentHP - 10,
+				Action:            "move",
+				Missed:            missed,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		var moves []MoveAnalyticsView
+		c.wantStatus(c.do("GET", "/admin/analytics/moves", adminID, nil, &moves), http.StatusOK)
+		for _, mv := range moves {
+			want := MoveAnalyticsView{MoveID: mv.MoveID, Name: mv.Name}
+			if mv.MoveID == move {
+				want.Uses, want.UseRate, want.HitRate, want.AverageDamage = 2, 1, 0.5, 5
+			}
+			if mv != want {
+				t.Errorf("move %d: got %+v, want %+v", mv.MoveID, mv, want)
+			}
+		}
+
+		//--turns outside the range don't count
+		tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format(time.DateOnly)
+		c.wantStatus(c.do("GET", "/admin/analytics/moves?from="+tomorrow, adminID, nil, &moves), http.StatusOK)
+		for _, mv := range moves {
+			if mv.Uses != 0 || mv.HitRate != 0 {
+				t.Errorf("move %d from tomorrow: %d uses, hit rate %v, want none", mv.MoveID, mv.Uses, mv.HitRate)
+			}
+		}
+
+		//--the CSV has the same figures
+		records := c.getCSV("/admin/analytics/units?format=csv")
+		col := slices.Index(records[0], "hit_rate")
+		if col < 0 {
+			t.Fatalf("CSV header %v has no hit_rate", records[0])
+		}
+		for _, rec := range records[1:] {
+			want := "0.0000"
+			if rec[0] == strconv.FormatInt(attacker.UnitID, 10) {
+				want = "0.5000"
+			}
+			if rec[col] != want {
+				t.Errorf("unit %s: hit_rate %s, want %s", rec[0], rec[col], want)
+			}
+		}
+
+		var bad ErrorBody
+		c.wantError(c.do("GET", "/admin/analytics/units?format=xml", adminID, nil, &bad), bad, http.StatusBadRequest, CodeValidationFailed)
+		c.wantError(c.do("GET", "/admin/analytics/units?from=2025-02-01&to=2025-01-31", adminID, nil, &bad), bad, http.StatusBadRequest, CodeValidationFailed)
+		c.wantError(c.do("GET", "/admin/analytics/units", m.Match.Player1ID, nil, &bad), bad, http.StatusForbidden, CodeForbidden)
+	})
+}
+
+// getCSV fetches path as the admin and returns its records, header first.
+func (c *testClient) getCSV(path string) [][]string {
+	c.t.Helper()
+	req, err := http.NewRequest("GET", c.srv.URL+path, nil)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	req.Header.Set("X-Player-ID", strconv.FormatInt(adminID, 10))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	c.wantStatus(resp, http.StatusOK)
+	records, err := csv.NewReader(resp.Body).ReadAll()
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if len(records) == 0 {
+		c.t.Fatalf("GET %s: empty CSV", path)
+	}
+	return records
+}
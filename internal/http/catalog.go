@@ -0,0 +1,143 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/76dillon/battle_squads/internal/content"
+	"github.com/76dillon/battle_squads/internal/game"
+)
+
+// typeNames maps unit type IDs to names for filling in views.
+func (s *Server) typeNames(ctx context.Context) (map[int64]string, error) {
+	types, err := s.q.ListUnitTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int64]string, len(types))
+	for _, t := range types {
+		names[t.ID] = t.Name
+	}
+	return names, nil
+}
+
+// GET /units?type=Fire&name=wolf&sort=-base_attack
+func (s *Server) handleListUnits(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+	units, err := s.content.ListUnits(ctx, content.UnitFilter{
+		Type: query.Get("type"),
+		Name: query.Get("name"),
+		Sort: query.Get("sort"),
+	})
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	names, err := s.typeNames(ctx)
+	if err != nil {
+		writeInternalError(w, "could not list units", err)
+		return
+	}
+
+	out := make([]CatalogUnitView, 0, len(units))
+	for _, u := range units {
+		v := newCatalogUnitView(u)
+		v.TypeName = names[u.TypeID]
+		out = append(out, v)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// GET /units/{id} returns a unit with its type name and learnset.
+func (s *Server) handleGetUnit(w http.ResponseWriter, r *http.Request) {
+	unitID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	u, moves, err := s.content.GetUnit(ctx, unitID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	names, err := s.typeNames(ctx)
+	if err != nil {
+		writeInternalError(w, "could not get unit", err)
+		return
+	}
+
+	out := UnitDetailView{
+		CatalogUnitView: newCatalogUnitView(u),
+		Moves:           make([]CatalogMoveView, 0, len(moves)),
+	}
+	out.TypeName = names[u.TypeID]
+	for _, mv := range moves {
+		v := newCatalogMoveView(mv)
+		v.TypeName = names[mv.TypeID]
+		out.Moves = append(out.Moves, v)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// GET /moves?type=Fire
+func (s *Server) handleListMoves(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	moves, err := s.content.ListMoves(ctx, r.URL.Query().Get("type"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	names, err := s.typeNames(ctx)
+	if err != nil {
+		writeInternalError(w, "could not list moves", err)
+		return
+	}
+
+	out := make([]CatalogMoveView, 0, len(moves))
+	for _, mv := range moves {
+		v := newCatalogMoveView(mv)
+		v.TypeName = names[mv.TypeID]
+		out = append(out, v)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// GET /abilities
+func (s *Server) handleListAbilities(w http.ResponseWriter, r *http.Request) {
+	abilities := game.Abilities()
+	out := make([]AbilityView, 0, len(abilities))
+	for _, a := range abilities {
+		out = append(out, AbilityView{Key: a.Key(), Description: a.Description()})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// GET /items and GET /admin/items
+func (s *Server) handleListItems(w http.ResponseWriter, r *http.Request) {
+	items, err := s.content.ListItems(r.Context())
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	out := make([]ItemView, 0, len(items))
+	for _, it := range items {
+		out = append(out, newItemView(it))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// GET /unit-types
+func (s *Server) handleListUnitTypes(w http.ResponseWriter, r *http.Request) {
+	types, err := s.q.ListUnitTypes(r.Context())
+	if err != nil {
+		writeInternalError(w, "could not list unit types", err)
+		return
+	}
+	out := make([]UnitTypeView, 0, len(types))
+	for _, t := range types {
+		out = append(out, UnitTypeView{ID: t.ID, Name: t.Name})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
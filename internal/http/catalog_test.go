@@ -0,0 +1,106 @@
+package httpapi
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestCatalogUnits(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, c *testClient) {
+		names := func(path string) []string {
+			t.Helper()
+			var units []CatalogUnitView
+			c.wantStatus(c.do("GET", path, 0, nil, &units), http.StatusOK)
+			out := make([]string, len(units))
+			for i, u := range units {
+				if u.TypeName == "" {
+					t.Errorf("GET %s: unit %s has no type name", path, u.Name)
+				}
+				out[i] = u.Name
+			}
+			return out
+		}
+		var types []UnitTypeView
+		c.wantStatus(c.do("GET", "/unit-types", 0, nil, &types), http.StatusOK)
+		if len(types) != 3 {
+			t.Fatalf("%d unit types, want the 3 demo types", len(types))
+		}
+		fire := types[slices.IndexFunc(types, func(ut UnitTypeView) bool { return ut.Name == "Fire" })].ID
+
+		//--filters by type name in any case or by ID, and by part of the name
+		for path, want := range map[string][]string{
+			"/units":                            {"Flame Wolf", "Aqua Drake", "Leaf Sprite"},
+			"/units?type=fire":                  {"Flame Wolf"},
+			fmt.Sprintf("/units?type=%d", fire): {"Flame Wolf"},
+			"/units?name=WOLF":                  {"Flame Wolf"},
+			"/units?name=a&type=Water":          {"Aqua Drake"},
+			"/units?name=nothing":               {},
+			"/units?sort=name":                  {"Aqua Drake", "Flame Wolf", "Leaf Sprite"},
+			"/units?sort=-base_speed":           {"Leaf Sprite", "Flame Wolf", "Aqua Drake"},
+			"/units?sort=base_hp":               {"Leaf Sprite", "Aqua Drake", "Flame Wolf"},
+		} {
+			if got := names(path); !slices.Equal(got, want) {
+				t.Errorf("GET %s: %v, want %v", path, got, want)
+			}
+		}
+
+		var body ErrorBody
+		for path, field := range map[string]string{
+			"/units?sort=ability": "sort",
+			"/units?sort=--name":  "sort",
+			"/units?type=Plasma":  "type",
+		} {
+			c.wantError(c.do("GET", path, 0, nil, &body), body, http.StatusBadRequest, CodeValidationFailed)
+			if fields := problemFields(t, body); !slices.Equal(fields, []string{field}) {
+				t.Errorf("GET %s: problems on %v, want %s", path, fields, field)
+			}
+		}
+
+		//--a unit's details include its learnset with type names
+		var units []CatalogUnitView
+		c.wantStatus(c.do("GET", "/units?name=Flame", 0, nil, &units), http.StatusOK)
+		var wolf UnitDetailView
+		c.wantStatus(c.do("GET", fmt.Sprintf("/units/%d", units[0].ID), 0, nil, &wolf), http.StatusOK)
+		var learnset []string
+		for _, mv := range wolf.Moves {
+			learnset = append(learnset, mv.Name+"/"+mv.TypeName)
+		}
+		slices.Sort(learnset)
+		if wolf.TypeName != "Fire" || !slices.Equal(learnset, []string{"Fireball/Fire", "Tackle/Fire"}) {
+			t.Errorf("Flame Wolf: type %q, learnset %v", wolf.TypeName, learnset)
+		}
+		c.wantError(c.do("GET", "/units/999", 0, nil, &body), body, http.StatusNotFound, CodeNotFound)
+
+		//--deleted units drop out of the catalog
+		c.wantStatus(c.do("DELETE", fmt.Sprintf("/admin/units/%d", units[0].ID), adminID, nil, nil), http.StatusNoContent)
+		c.wantError(c.do("GET", fmt.Sprintf("/units/%d", units[0].ID), 0, nil, &body), body, http.StatusNotFound, CodeNotFound)
+		if got := names("/units?type=Fire"); len(got) != 0 {
+			t.Errorf("after deleting Flame Wolf, Fire units are %v", got)
+		}
+	})
+}
+
+func TestCatalogMoves(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, c *testClient) {
+		for path, want := range map[string][]string{
+			"/moves":            {"Fireball/Fire", "Leaf Blade/Grass", "Tackle/Fire", "Water Jet/Water"},
+			"/moves?type=grass": {"Leaf Blade/Grass"},
+			"/moves?type=Fire":  {"Fireball/Fire", "Tackle/Fire"},
+		} {
+			var moves []CatalogMoveView
+			c.wantStatus(c.do("GET", path, 0, nil, &moves), http.StatusOK)
+			var got []string
+			for _, mv := range moves {
+				got = append(got, mv.Name+"/"+mv.TypeName)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, want) {
+				t.Errorf("GET %s: %v, want %v", path, got, want)
+			}
+		}
+		var body ErrorBody
+		c.wantError(c.do("GET", "/moves?type=Plasma", 0, nil, &body), body, http.StatusBadRequest, CodeValidationFailed)
+	})
+}
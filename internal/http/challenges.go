@@ -0,0 +1,148 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/76dillon/battle_squads/internal/game"
+	"github.com/76dillon/battle_squads/internal/squad"
+	"github.com/76dillon/battle_squads/internal/store"
+)
+
+type createChallengeRequest struct {
+	OpponentPlayerID int64 `json:"opponent_player_id"`
+	SquadID          int64 `json:"squad_id"`
+	// Public defaults to true when left out
+	Public *bool `json:"public"`
+	// RulesetID defaults to the standard ruleset when left out
+	RulesetID int64 `json:"ruleset_id"`
+}
+
+type acceptChallengeRequest struct {
+	SquadID int64 `json:"squad_id"`
+}
+
+// GET /me/challenges lists pending challenges the caller sent or received.
+func (s *Server) handleListChallenges(w http.ResponseWriter, r *http.Request) {
+	rows, err := s.q.ListPendingChallengesForPlayer(r.Context(), requestPlayerID(r))
+	if err != nil {
+		writeInternalError(w, "could not list challenges", err)
+		return
+	}
+	out := make([]ChallengeView, 0, len(rows))
+	for _, c := range rows {
+		out = append(out, ChallengeView{
+			ID:                 c.ID,
+			ChallengerID:       c.ChallengerID,
+			ChallengerUsername: c.ChallengerUsername,
+			OpponentID:         c.OpponentID,
+			OpponentUsername:   c.OpponentUsername,
+			Public:             c.IsPublic,
+			RulesetID:          c.RulesetID,
+			State:              game.ChallengePending,
+			CreatedAt:          c.CreatedAt,
+		})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// POST /me/challenges offers a friend a match with the caller's squad and
+// settings.
+func (s *Server) handleCreateChallenge(w http.ResponseWriter, r *http.Request) {
+	playerID := requestPlayerID(r)
+
+	var req createChallengeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+
+	ctx := r.Context()
+	rs, err := s.requestRuleset(ctx, "challenge", req.RulesetID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	err = s.squads.CheckMatchSquads(ctx, rs.MinSquadSize, rs.MaxSquadSize,
+		squad.SquadChoice{Field: "squad_id", SquadID: req.SquadID, PlayerID: playerID},
+	)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	c, err := s.svc.CreateChallenge(ctx, game.NewChallenge{
+		ChallengerID:      playerID,
+		ChallengerSquadID: req.SquadID,
+		OpponentID:        req.OpponentPlayerID,
+		Public:            req.Public == nil || *req.Public,
+		RulesetID:         rs.ID,
+	})
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, ChallengeView{
+		ID:           c.ID,
+		ChallengerID: c.ChallengerID,
+		OpponentID:   c.OpponentID,
+		Public:       c.IsPublic,
+		RulesetID:    c.RulesetID,
+		State:        c.State,
+		CreatedAt:    c.CreatedAt,
+	})
+}
+
+// POST /me/challenges/{id}/accept starts the match with the caller's squad.
+func (s *Server) handleAcceptChallenge(w http.ResponseWriter, r *http.Request) {
+	challengeID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	playerID := requestPlayerID(r)
+
+	var req acceptChallengeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+
+	// The challenger's squad was checked when they sent the challenge, but
+	// may have been edited or deleted since
+	ctx := r.Context()
+	c, err := s.svc.GetChallenge(ctx, challengeID, playerID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	rs, err := s.svc.GetRuleset(ctx, c.RulesetID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	matchID, err := s.svc.AcceptChallenge(ctx, challengeID, playerID, req.SquadID, func(q store.Querier) error {
+		return squad.CheckMatchSquads(ctx, q, rs.MinSquadSize, rs.MaxSquadSize,
+			squad.SquadChoice{Field: "challenger_squad_id", SquadID: c.ChallengerSquadID, PlayerID: c.ChallengerID},
+			squad.SquadChoice{Field: "squad_id", SquadID: req.SquadID, PlayerID: playerID},
+		)
+	})
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	s.writeMatch(w, r, http.StatusOK, matchID)
+}
+
+// POST /me/challenges/{id}/decline declines a challenge, or withdraws it
+// when called by the challenger.
+func (s *Server) handleDeclineChallenge(w http.ResponseWriter, r *http.Request) {
+	challengeID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	if err := s.svc.DeclineChallenge(r.Context(), challengeID, requestPlayerID(r)); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,182 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/76dillon/battle_squads/internal/store"
+	"github.com/76dillon/battle_squads/internal/validation"
+)
+
+type postMessageRequest struct {
+	Body string `json:"body"`
+}
+
+type reportMessageRequest struct {
+	Reason string `json:"reason"`
+}
+
+type reviewMessageRequest struct {
+	Hide bool `json:"hide"`
+}
+
+func newMessageView(m store.ListMatchMessagesRow) MessageView {
+	return MessageView{
+		ID:        m.ID,
+		PlayerID:  m.PlayerID,
+		Username:  m.Username,
+		Body:      m.Body,
+		CreatedAt: m.CreatedAt,
+	}
+}
+
+// GET /matches/{id}/messages?after=12&limit=50 lists the chat oldest first.
+// after is the last message ID the client already has.
+func (s *Server) handleListMessages(w http.ResponseWriter, r *http.Request) {
+	matchID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	verr := validation.New("query")
+	limit := parseLimit(query, verr)
+	var afterID int64
+	if v := query.Get("after"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			verr.Add("after", "must be a message ID")
+		}
+		afterID = n
+	}
+	if err := verr.Err(); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	rows, err := s.svc.ListMessages(r.Context(), matchID, requestPlayerID(r), afterID, limit)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	out := make([]MessageView, 0, len(rows))
+	for _, m := range rows {
+		out = append(out, newMessageView(m))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// POST /matches/{id}/messages
+func (s *Server) handlePostMessage(w http.ResponseWriter, r *http.Request) {
+	matchID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	var req postMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+
+	m, err := s.svc.PostMessage(r.Context(), matchID, requestPlayerID(r), req.Body)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, MessageView{
+		ID:        m.ID,
+		PlayerID:  m.PlayerID,
+		Body:      m.Body,
+		CreatedAt: m.CreatedAt,
+	})
+}
+
+// POST /matches/{id}/messages/{message_id}/report flags a message for admins.
+func (s *Server) handleReportMessage(w http.ResponseWriter, r *http.Request) {
+	matchID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	messageID, ok := pathID(w, r, "message_id")
+	if !ok {
+		return
+	}
+	var req reportMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+
+	if err := s.svc.ReportMessage(r.Context(), matchID, messageID, requestPlayerID(r), req.Reason); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /admin/message-reports?limit=20 lists reported messages that haven't
+// been reviewed, oldest message first. limit counts reports.
+func (s *Server) handleAdminListMessageReports(w http.ResponseWriter, r *http.Request) {
+	verr := validation.New("query")
+	limit := parseLimit(r.URL.Query(), verr)
+	if err := verr.Err(); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	rows, err := s.q.ListUnreviewedMessageReports(r.Context(), int32(limit))
+	if err != nil {
+		writeInternalError(w, "could not list reports", err)
+		return
+	}
+
+	// Reports arrive grouped by message
+	out := []ReportedMessageView{}
+	for _, row := range rows {
+		n := len(out)
+		if n == 0 || out[n-1].MessageID != row.MessageID {
+			out = append(out, ReportedMessageView{
+				MessageID:      row.MessageID,
+				MatchID:        row.MatchID,
+				PlayerID:       row.PlayerID,
+				AuthorUsername: row.AuthorUsername,
+				Body:           row.Body,
+				CreatedAt:      row.CreatedAt,
+			})
+			n++
+		}
+		out[n-1].Reports = append(out[n-1].Reports, MessageReportView{
+			ReporterID:       row.ReporterID,
+			ReporterUsername: row.ReporterUsername,
+			Reason:           row.Reason,
+			ReportedAt:       row.ReportedAt,
+		})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// POST /admin/messages/{id}/review closes a message's reports, hiding it
+// from the chat if hide is set.
+func (s *Server) handleAdminReviewMessage(w http.ResponseWriter, r *http.Request) {
+	messageID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	var req reviewMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+
+	m, err := s.svc.ReviewMessage(r.Context(), requestPlayerID(r), messageID, req.Hide)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, MessageView{
+		ID:        m.ID,
+		PlayerID:  m.PlayerID,
+		Body:      m.Body,
+		CreatedAt: m.CreatedAt,
+	})
+}
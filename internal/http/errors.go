@@ -0,0 +1,165 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/76dillon/battle_squads/internal/content"
+	"github.com/76dillon/battle_squads/internal/game"
+	"github.com/76dillon/battle_squads/internal/player"
+	"github.com/76dillon/battle_squads/internal/squad"
+	"github.com/76dillon/battle_squads/internal/validation"
+)
+
+// ErrorCode is a stable, machine-readable error identifier. Clients should
+// branch on codes; messages are for people and may change.
+type ErrorCode string
+
+const (
+	CodeBadRequest        ErrorCode = "BAD_REQUEST"
+	CodeInvalidJSON       ErrorCode = "INVALID_JSON"
+	CodeInvalidPlayerID   ErrorCode = "INVALID_PLAYER_ID"
+	CodeValidationFailed  ErrorCode = "VALIDATION_FAILED"
+	CodeInvalidCredential ErrorCode = "INVALID_CREDENTIALS"
+	CodeForbidden         ErrorCode = "FORBIDDEN"
+	CodeNotFound          ErrorCode = "NOT_FOUND"
+	CodeMethodNotAllowed  ErrorCode = "METHOD_NOT_ALLOWED"
+	CodeConflict          ErrorCode = "CONFLICT"
+	CodeInUse             ErrorCode = "IN_USE"
+	CodeInternal          ErrorCode = "INTERNAL"
+
+	// Turns
+	CodeWrongTurn          ErrorCode = "WRONG_TURN"
+	CodeStaleTurn          ErrorCode = "STALE_TURN"
+	CodeIllegalMove        ErrorCode = "ILLEGAL_MOVE"
+	CodeMatchNotInProgress ErrorCode = "MATCH_NOT_IN_PROGRESS"
+
+	// Chat
+	CodeRateLimited     ErrorCode = "RATE_LIMITED"
+	CodeMessageRejected ErrorCode = "MESSAGE_REJECTED"
+
+	// Idempotency keys
+	CodeIdempotencyKeyReused ErrorCode = "IDEMPOTENCY_KEY_REUSED"
+	CodeRequestInProgress    ErrorCode = "REQUEST_IN_PROGRESS"
+)
+
+// ErrorBody is the JSON body of every error response:
+//
+//	{"error": {"code": "WRONG_TURN", "message": "not your turn"}}
+type ErrorBody struct {
+	Error ErrorDetail `json:"error"`
+}
+
+type ErrorDetail struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+	Details any       `json:"details,omitempty"`
+}
+
+// writeError responds with status and an error envelope.
+func writeError(w http.ResponseWriter, status int, code ErrorCode, msg string) {
+	writeErrorDetails(w, status, code, msg, nil)
+}
+
+func writeErrorDetails(w http.ResponseWriter, status int, code ErrorCode, msg string, details any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(ErrorBody{Error: ErrorDetail{
+		Code:    code,
+		Message: msg,
+		Details: details,
+	}})
+}
+
+// validationDetails is the details of a VALIDATION_FAILED error.
+type validationDetails struct {
+	Problems []validation.Problem `json:"problems"`
+}
+
+// writeValidationError responds 400 with every problem found in the request.
+func writeValidationError(w http.ResponseWriter, verr *validation.Error) {
+	writeErrorDetails(w, http.StatusBadRequest, CodeValidationFailed, "invalid "+verr.Subject,
+		validationDetails{Problems: verr.Problems})
+}
+
+// writeServiceError maps an error returned by one of the services to a
+// response. Errors are matched with errors.As, so they may be wrapped.
+// Anything unrecognised is a 500 whose message reveals nothing.
+func writeServiceError(w http.ResponseWriter, err error) {
+	var (
+		verr          *validation.Error
+		wrongTurn     game.ErrWrongTurn
+		staleTurn     game.ErrStaleTurn
+		illegalMove   game.ErrIllegalMove
+		notInProgress game.ErrMatchNotInProgress
+		matchNotFound game.ErrMatchNotFound
+		forbidden     game.ErrForbidden
+		chNotFound    game.ErrChallengeNotFound
+		chClosed      game.ErrChallengeClosed
+		tnNotFound    game.ErrTournamentNotFound
+		tnConflict    game.ErrTournamentConflict
+		rsNotFound    game.ErrRulesetNotFound
+		rateLimited   game.ErrRateLimited
+		rejected      game.ErrMessageRejected
+		plNotFound    player.ErrNotFound
+		plForbidden   player.ErrForbidden
+		squadNotFound squad.ErrNotFound
+		squadInUse    squad.ErrInUse
+		contNotFound  content.ErrNotFound
+		contInUse     content.ErrInUse
+	)
+	switch {
+	case errors.As(err, &verr):
+		writeValidationError(w, verr)
+	case errors.As(err, &wrongTurn):
+		writeError(w, http.StatusConflict, CodeWrongTurn, wrongTurn.Error())
+	case errors.As(err, &staleTurn):
+		writeError(w, http.StatusConflict, CodeStaleTurn, staleTurn.Error())
+	case errors.As(err, &illegalMove):
+		writeError(w, http.StatusBadRequest, CodeIllegalMove, illegalMove.Error())
+	case errors.As(err, &notInProgress):
+		writeError(w, http.StatusBadRequest, CodeMatchNotInProgress, notInProgress.Error())
+	case errors.As(err, &matchNotFound):
+		writeError(w, http.StatusNotFound, CodeNotFound, matchNotFound.Error())
+	case errors.As(err, &forbidden):
+		writeError(w, http.StatusForbidden, CodeForbidden, forbidden.Error())
+	case errors.As(err, &chNotFound):
+		writeError(w, http.StatusNotFound, CodeNotFound, chNotFound.Error())
+	case errors.As(err, &chClosed):
+		writeError(w, http.StatusConflict, CodeConflict, chClosed.Error())
+	case errors.As(err, &tnNotFound):
+		writeError(w, http.StatusNotFound, CodeNotFound, tnNotFound.Error())
+	case errors.As(err, &tnConflict):
+		writeError(w, http.StatusConflict, CodeConflict, tnConflict.Error())
+	case errors.As(err, &rsNotFound):
+		writeError(w, http.StatusNotFound, CodeNotFound, rsNotFound.Error())
+	case errors.As(err, &rateLimited):
+		writeError(w, http.StatusTooManyRequests, CodeRateLimited, rateLimited.Error())
+	case errors.As(err, &rejected):
+		writeError(w, http.StatusBadRequest, CodeMessageRejected, rejected.Error())
+	case errors.As(err, &plNotFound):
+		writeError(w, http.StatusNotFound, CodeNotFound, plNotFound.Error())
+	case errors.As(err, &plForbidden):
+		writeError(w, http.StatusForbidden, CodeForbidden, plForbidden.Error())
+	case errors.As(err, &squadNotFound):
+		writeError(w, http.StatusNotFound, CodeNotFound, squadNotFound.Error())
+	case errors.As(err, &squadInUse):
+		writeError(w, http.StatusConflict, CodeInUse, squadInUse.Error())
+	case errors.As(err, &contNotFound):
+		writeError(w, http.StatusNotFound, CodeNotFound, contNotFound.Error())
+	case errors.As(err, &contInUse):
+		writeError(w, http.StatusConflict, CodeInUse, contInUse.Error())
+	default:
+		writeInternalError(w, "internal error", err)
+	}
+}
+
+// writeInternalError logs err, which the client never sees, and writes msg
+// as a 500.
+func writeInternalError(w http.ResponseWriter, msg string, err error) {
+	log.Printf("%s: %v", msg, err)
+	writeError(w, http.StatusInternalServerError, CodeInternal, msg)
+}
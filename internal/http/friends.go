@@ -0,0 +1,135 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/76dillon/battle_squads/internal/player"
+	"github.com/76dillon/battle_squads/internal/store"
+)
+
+const (
+	// presenceWriteInterval is how often a busy player's last_seen_at is
+	// written
+	presenceWriteInterval = time.Minute
+	// onlineWindow is how recently a player must have been seen to count
+	// as online
+	onlineWindow = 5 * time.Minute
+)
+
+// Presence values shown for friends
+const (
+	presenceOffline = "offline"
+	presenceOnline  = "online"
+	presenceInMatch = "in_match"
+)
+
+type friendRequest struct {
+	PlayerID int64 `json:"player_id"`
+}
+
+// GET /me/friends lists friends, friend requests both ways and blocked
+// players. Presence is only shared between friends.
+func (s *Server) handleListFriends(w http.ResponseWriter, r *http.Request) {
+	playerID := requestPlayerID(r)
+
+	rows, err := s.q.ListFriendships(r.Context(), playerID)
+	if err != nil {
+		writeInternalError(w, "could not list friends", err)
+		return
+	}
+
+	now := time.Now()
+	out := make([]FriendView, 0, len(rows))
+	for _, f := range rows {
+		v := FriendView{
+			PlayerSummary: PlayerSummary{
+				ID:          f.OtherID,
+				Username:    f.Username,
+				DisplayName: f.DisplayName,
+				AvatarKey:   f.AvatarKey,
+				Rating:      f.Rating,
+				JoinedAt:    f.CreatedAt,
+			},
+			Status: f.Status,
+			Since:  f.UpdatedAt,
+		}
+		switch {
+		case f.Status == player.StatusRequested && f.FriendID == playerID:
+			v.Status = "incoming"
+		case f.Status == player.StatusAccepted:
+			v.Presence = presence(f, now)
+		}
+		out = append(out, v)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// presence is how a friend appears in the friends list at now.
+func presence(f store.ListFriendshipsRow, now time.Time) string {
+	if !f.LastSeenAt.Valid || now.Sub(f.LastSeenAt.Time) > onlineWindow {
+		return presenceOffline
+	}
+	if f.InMatch {
+		return presenceInMatch
+	}
+	return presenceOnline
+}
+
+// POST /me/friends sends a friend request, or accepts one the other player
+// already sent.
+func (s *Server) handleAddFriend(w http.ResponseWriter, r *http.Request) {
+	var req friendRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+	status, err := s.players.AddFriend(r.Context(), requestPlayerID(r), req.PlayerID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, FriendshipView{PlayerID: req.PlayerID, Status: status})
+}
+
+// DELETE /me/friends/{id} unfriends a player, or withdraws or declines a
+// friend request.
+func (s *Server) handleRemoveFriend(w http.ResponseWriter, r *http.Request) {
+	otherID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	if err := s.players.RemoveFriend(r.Context(), requestPlayerID(r), otherID); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /me/blocks
+func (s *Server) handleBlockPlayer(w http.ResponseWriter, r *http.Request) {
+	var req friendRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+	if err := s.players.Block(r.Context(), requestPlayerID(r), req.PlayerID); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DELETE /me/blocks/{id}
+func (s *Server) handleUnblockPlayer(w http.ResponseWriter, r *http.Request) {
+	otherID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	if err := s.players.Unblock(r.Context(), requestPlayerID(r), otherID); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,78 @@
+package httpapi
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestFriends(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, c *testClient) {
+		alice, aliceSquad := c.signup("alice")
+		bob, bobSquad := c.signup("bob")
+		friends := func(playerID int64) map[int64]FriendView {
+			t.Helper()
+			var list []FriendView
+			c.wantStatus(c.do("GET", "/me/friends", playerID, nil, &list), http.StatusOK)
+			out := make(map[int64]FriendView, len(list))
+			for _, f := range list {
+				out[f.ID] = f
+			}
+			return out
+		}
+
+		//--a request is "requested" one way and "incoming" the other, until
+		//  it is accepted by asking back
+		var fs FriendshipView
+		c.wantStatus(c.do("POST", "/me/friends", alice, friendRequest{PlayerID: bob}, &fs), http.StatusOK)
+		if fs.Status != "requested" {
+			t.Errorf("request: status %q, want requested", fs.Status)
+		}
+		if got := friends(alice)[bob]; got.Status != "requested" || got.Presence != "" {
+			t.Errorf("alice sees bob as %q/%q, want requested with no presence", got.Status, got.Presence)
+		}
+		if got := friends(bob)[alice]; got.Status != "incoming" {
+			t.Errorf("bob sees alice as %q, want incoming", got.Status)
+		}
+		c.wantStatus(c.do("POST", "/me/friends", bob, friendRequest{PlayerID: alice}, &fs), http.StatusOK)
+		if fs.Status != "accepted" {
+			t.Errorf("asking back: status %q, want accepted", fs.Status)
+		}
+
+		//--friends share presence: bob has just made requests, then starts
+		//  a match
+		if got := friends(alice)[bob]; got.Status != "accepted" || got.Presence != presenceOnline {
+			t.Errorf("alice sees bob as %q/%q, want accepted/online", got.Status, got.Presence)
+		}
+		c.wantStatus(c.do("POST", "/matches", bob, createMatchRequest{
+			OpponentPlayerID: alice,
+			Player1SquadID:   bobSquad,
+			Player2SquadID:   aliceSquad,
+		}, nil), http.StatusOK)
+		if got := friends(alice)[bob]; got.Presence != presenceInMatch {
+			t.Errorf("alice sees bob as %q, want in_match", got.Presence)
+		}
+
+		//--a block ends the friendship and only the blocker sees it
+		c.wantStatus(c.do("POST", "/me/blocks", bob, friendRequest{PlayerID: alice}, nil), http.StatusNoContent)
+		if got, ok := friends(bob)[alice]; !ok || got.Status != "blocked" || got.Presence != "" {
+			t.Errorf("bob sees alice as %+v, want blocked with no presence", got)
+		}
+		if got, ok := friends(alice)[bob]; ok {
+			t.Errorf("alice still sees bob as %q", got.Status)
+		}
+		var body ErrorBody
+		resp := c.do("POST", "/me/challenges", alice, createChallengeRequest{OpponentPlayerID: bob, SquadID: aliceSquad}, &body)
+		c.wantError(resp, body, http.StatusForbidden, CodeForbidden)
+		resp = c.do("POST", "/me/friends", alice, friendRequest{PlayerID: bob}, &body)
+		c.wantError(resp, body, http.StatusForbidden, CodeForbidden)
+
+		//--unblocking leaves the two as strangers
+		c.wantStatus(c.do("DELETE", fmt.Sprintf("/me/blocks/%d", alice), bob, nil, nil), http.StatusNoContent)
+		if got, ok := friends(bob)[alice]; ok {
+			t.Errorf("after unblocking, bob sees alice as %q", got.Status)
+		}
+		resp = c.do("DELETE", fmt.Sprintf("/me/blocks/%d", alice), bob, nil, &body)
+		c.wantError(resp, body, http.StatusNotFound, CodeNotFound)
+	})
+}
@@ -1,654 +1,462 @@
 package httpapi
 
 import (
+	"database/sql"
 	"encoding/json"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/76dillon/battle_squads/internal/content"
 	"github.com/76dillon/battle_squads/internal/game"
+	"github.com/76dillon/battle_squads/internal/player"
+	"github.com/76dillon/battle_squads/internal/squad"
 	"github.com/76dillon/battle_squads/internal/store"
+	"github.com/76dillon/battle_squads/internal/validation"
 )
 
 type Server struct {
-	mux *http.ServeMux
-	q   *store.Queries
-	svc *game.Service
+	mux     *http.ServeMux
+	handler http.HandlerFunc
+	q       store.Store
+	svc     *game.Service
+	squads  *squad.Service
+	players *player.Service
+	content *content.Service
 }
 
+// postTurnRequest is one action: move_id to use a move, or
+// switch_to_position to switch units.
 type postTurnRequest struct {
-	MoveID int64 `json:"move_id"`
+	MoveID             int64  `json:"move_id"`
+	SwitchToPosition   *int32 `json:"switch_to_position"`
+	ItemID             int64  `json:"item_id"`
+	ExpectedTurnNumber int32  `json:"expected_turn_number"`
 }
 
-func NewServer(q *store.Queries, svc *game.Service) *Server {
+func NewServer(q store.Store, svc *game.Service, squads *squad.Service, players *player.Service, content *content.Service) *Server {
 	s := &Server{
-		mux: http.NewServeMux(),
-		q:   q,
-		svc: svc,
+		mux:     http.NewServeMux(),
+		q:       q,
+		svc:     svc,
+		squads:  squads,
+		players: players,
+		content: content,
 	}
 
 	s.routes()
+	s.handler = chain(s.serveMux, logRequests, recoverPanics, cors, s.trackPresence)
 	return s
 }
 
-func (s *Server) routes() {
-	s.mux.HandleFunc("/health", s.handleHealth)
-	s.mux.HandleFunc("/login", s.handleLogin)
-	s.mux.HandleFunc("/units", s.handleListUnits)
-	s.mux.HandleFunc("/me/squads", s.handleSquads)
-	s.mux.HandleFunc("/signup", s.handleSignup)
-	s.mux.HandleFunc("/matches/", s.handleMatch)
-	s.mux.HandleFunc("/me/matches", s.handleListMyMatches)
-	s.mux.HandleFunc("/matches", s.handleCreateMatch) // for POST /matches
-	s.mux.HandleFunc("/admin/units", s.handleCreateUnit)
-	s.mux.HandleFunc("/admin/moves", s.handleCreateMove)
-	// we'll add /matches/{id} soon
+// handle registers h for pattern, wrapped in mws (outermost first).
+func (s *Server) handle(pattern string, h http.HandlerFunc, mws ...middleware) {
+	s.mux.HandleFunc(pattern, chain(h, mws...))
 }
 
-func (s *Server) handleMatch(w http.ResponseWriter, r *http.Request) {
-	// URL is something like:
-	//   /matches/1
-	//   /matches/1/turns
-
-	// Strip the prefix
-	path := strings.TrimPrefix(r.URL.Path, "/matches/")
-	if path == "" {
-		http.NotFound(w, r)
-		return
-	}
-
-	// POST /matches/{id}/turns
-	if r.Method == http.MethodPost && strings.HasSuffix(path, "/turns") {
-		s.handlePostTurn(w, r)
-		return
-	}
-
-	// GET /matches/{id}
-	if r.Method == http.MethodGet && !strings.Contains(path, "/") {
-		s.handleGetMatch(w, r)
-		return
-	}
-
-	http.NotFound(w, r)
+func (s *Server) routes() {
+	admin := s.withAdmin
+
+	s.handle("GET /health", s.handleHealth)
+	s.handle("POST /signup", s.handleSignup)
+	s.handle("POST /login", s.handleLogin)
+
+	// Catalog
+	s.handle("GET /units", s.handleListUnits)
+	s.handle("GET /units/{id}", s.handleGetUnit)
+	s.handle("GET /moves", s.handleListMoves)
+	s.handle("GET /unit-types", s.handleListUnitTypes)
+	s.handle("GET /items", s.handleListItems)
+	s.handle("GET /abilities", s.handleListAbilities)
+
+	// Players
+	s.handle("GET /players", s.handleSearchPlayers)
+	s.handle("GET /players/{id}", s.handleGetPlayer)
+	s.handle("PATCH /me", s.handleUpdateProfile, withPlayer)
+
+	// Friends
+	s.handle("GET /me/friends", s.handleListFriends, withPlayer)
+	s.handle("POST /me/friends", s.handleAddFriend, withPlayer)
+	s.handle("DELETE /me/friends/{id}", s.handleRemoveFriend, withPlayer)
+	s.handle("POST /me/blocks", s.handleBlockPlayer, withPlayer)
+	s.handle("DELETE /me/blocks/{id}", s.handleUnblockPlayer, withPlayer)
+	s.handle("GET /me/challenges", s.handleListChallenges, withPlayer)
+	s.handle("POST /me/challenges", s.handleCreateChallenge, withPlayer, s.idempotent)
+	s.handle("POST /me/challenges/{id}/accept", s.handleAcceptChallenge, withPlayer, s.idempotent)
+	s.handle("POST /me/challenges/{id}/decline", s.handleDeclineChallenge, withPlayer)
+
+	// Squads
+	s.handle("GET /me/squads", s.handleListMySquads, withPlayer)
+	s.handle("POST /me/squads", s.handleCreateSquad, withPlayer, s.idempotent)
+	s.handle("GET /me/squads/{id}", s.handleGetSquad, withPlayer)
+	s.handle("PUT /me/squads/{id}", s.handleUpdateSquad, withPlayer)
+	s.handle("PATCH /me/squads/{id}", s.handleUpdateSquad, withPlayer)
+	s.handle("DELETE /me/squads/{id}", s.handleDeleteSquad, withPlayer)
+
+	// Matches
+	s.handle("GET /me/matches", s.handleListMyMatches, withPlayer)
+	s.handle("POST /matches", s.handleCreateMatch, withPlayer, s.idempotent)
+	s.handle("GET /matches/live", s.handleListLiveMatches)
+	s.handle("GET /matches/{id}", s.handleGetMatch, withOptionalPlayer)
+	s.handle("PATCH /matches/{id}", s.handleUpdateMatch, withPlayer)
+	s.handle("GET /matches/{id}/events", s.handleWatchMatch, withOptionalPlayer)
+	s.handle("POST /matches/{id}/turns", s.handlePostTurn, withPlayer, s.idempotent)
+	s.handle("POST /matches/{id}/timeout", s.handleClaimTimeout, withPlayer)
+	s.handle("GET /matches/{id}/messages", s.handleListMessages, withOptionalPlayer)
+	s.handle("POST /matches/{id}/messages", s.handlePostMessage, withPlayer, s.idempotent)
+	s.handle("POST /matches/{id}/messages/{message_id}/report", s.handleReportMessage, withPlayer)
+
+	// Rulesets
+	s.handle("GET /rulesets", s.handleListRulesets)
+	s.handle("GET /rulesets/{id}", s.handleGetRuleset)
+
+	// Tournaments
+	s.handle("GET /tournaments", s.handleListTournaments)
+	s.handle("GET /tournaments/{id}", s.handleGetTournament)
+	s.handle("POST /tournaments/{id}/entries", s.handleRegisterForTournament, withPlayer)
+	s.handle("DELETE /tournaments/{id}/entries", s.handleWithdrawFromTournament, withPlayer)
+
+	// Stats
+	s.handle("GET /leaderboard", s.handleLeaderboard)
+	s.handle("GET /players/{id}/stats", s.handlePlayerStats)
+
+	// Admin
+	s.handle("GET /admin/unit-types", s.handleAdminListUnitTypes, admin)
+	s.handle("POST /admin/unit-types", s.handleAdminCreateUnitType, admin)
+	s.handle("PUT /admin/unit-types/{id}", s.handleAdminUpdateUnitType, admin)
+	s.handle("DELETE /admin/unit-types/{id}", s.handleAdminDeleteUnitType, admin)
+	s.handle("POST /admin/units", s.handleCreateUnit, admin)
+	s.handle("GET /admin/units/{id}", s.handleAdminGetUnit, admin)
+	s.handle("PUT /admin/units/{id}", s.handleAdminUpdateUnit, admin)
+	s.handle("DELETE /admin/units/{id}", s.handleAdminDeleteUnit, admin)
+	s.handle("GET /admin/units/{id}/moves", s.handleAdminListUnitMoves, admin)
+	s.handle("POST /admin/units/{id}/moves", s.handleAdminAddUnitMove, admin)
+	s.handle("DELETE /admin/units/{id}/moves/{move_id}", s.handleAdminRemoveUnitMove, admin)
+	s.handle("GET /admin/moves", s.handleAdminListMoves, admin)
+	s.handle("POST /admin/moves", s.handleAdminCreateMove, admin)
+	s.handle("GET /admin/moves/{id}", s.handleAdminGetMove, admin)
+	s.handle("PUT /admin/moves/{id}", s.handleAdminUpdateMove, admin)
+	s.handle("DELETE /admin/moves/{id}", s.handleAdminDeleteMove, admin)
+	s.handle("GET /admin/items", s.handleListItems, admin)
+	s.handle("POST /admin/items", s.handleAdminCreateItem, admin)
+	s.handle("PUT /admin/items/{id}", s.handleAdminUpdateItem, admin)
+	s.handle("DELETE /admin/items/{id}", s.handleAdminDeleteItem, admin)
+	s.handle("GET /admin/matches/{id}", s.handleAdminGetMatch, admin)
+	s.handle("GET /admin/analytics/units", s.handleAdminUnitAnalytics, admin)
+	s.handle("GET /admin/analytics/moves", s.handleAdminMoveAnalytics, admin)
+	s.handle("POST /admin/rulesets", s.handleAdminCreateRuleset, admin)
+	s.handle("PUT /admin/rulesets/{id}", s.handleAdminUpdateRuleset, admin)
+	s.handle("POST /admin/tournaments", s.handleAdminCreateTournament, admin)
+	s.handle("POST /admin/tournaments/{id}/start", s.handleAdminStartTournament, admin)
+	s.handle("GET /admin/message-reports", s.handleAdminListMessageReports, admin)
+	s.handle("POST /admin/messages/{id}/review", s.handleAdminReviewMessage, admin)
+	s.handle("GET /admin/audit-log", s.handleAdminAuditLog, admin)
+	s.handle("POST /admin/content/import", s.handleContentImport, admin)
+	s.handle("GET /admin/content/export", s.handleContentExport, admin)
 }
 
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	// Simple CORS for local dev
-	w.Header().Set("Access-Control-Allow-Origin", "http://localhost:8000")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-Player-ID")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+	s.handler(w, r)
+}
 
-	if r.Method == http.MethodOptions {
-		w.WriteHeader(http.StatusNoContent)
+// serveMux routes r. A request that matches no route gets the mux's answer,
+// 404 or 405 with an Allow header, as a JSON error.
+func (s *Server) serveMux(w http.ResponseWriter, r *http.Request) {
+	h, pattern := s.mux.Handler(r)
+	if pattern != "" {
+		s.mux.ServeHTTP(w, r)
 		return
 	}
 
-	s.mux.ServeHTTP(w, r)
+	dw := &discardWriter{header: http.Header{}, status: http.StatusOK}
+	h.ServeHTTP(dw, r)
+	if dw.status == http.StatusMethodNotAllowed {
+		w.Header().Set("Allow", dw.header.Get("Allow"))
+		writeError(w, http.StatusMethodNotAllowed, CodeMethodNotAllowed, "method not allowed")
+		return
+	}
+	writeError(w, http.StatusNotFound, CodeNotFound, "not found")
 }
 
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
 	w.Header().Set("Content-Type", "application/json")
 	w.Write([]byte(`{"status":"ok"}`))
 }
 
+// GET /matches/{id}
 func (s *Server) handleGetMatch(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	// URL: /matches/{id}
-	// strip prefix and parse id
-	idStr := strings.TrimPrefix(r.URL.Path, "/matches/")
-	if idStr == "" {
-		http.Error(w, "missing id", http.StatusBadRequest)
-		return
-	}
-	matchID, err := strconv.ParseInt(idStr, 10, 64)
-	if err != nil {
-		http.Error(w, "invalid id", http.StatusBadRequest)
+	matchID, ok := pathID(w, r, "id")
+	if !ok {
 		return
 	}
+	s.writeMatch(w, r, http.StatusOK, matchID)
+}
 
-	ctx := r.Context()
-
-	match, err := s.q.GetMatchByID(ctx, matchID)
-	if err != nil {
-		// ideally check for sql.ErrNoRows
-		http.Error(w, "match not found", http.StatusNotFound)
+// POST /matches/{id}/turns
+func (s *Server) handlePostTurn(w http.ResponseWriter, r *http.Request) {
+	matchID, ok := pathID(w, r, "id")
+	if !ok {
 		return
 	}
+	actingPlayerID := requestPlayerID(r)
 
-	sides, err := s.q.GetMatchSidesByMatchID(ctx, matchID)
-	if err != nil {
-		http.Error(w, "error loading sides", http.StatusInternalServerError)
+	// decode JSON body
+	var req postTurnRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
 		return
 	}
-
-	unitsBySide := make(map[int64][]store.MatchUnit, len(sides))
-	for _, side := range sides {
-		units, err := s.q.GetMatchUnitsBySideID(ctx, side.ID)
-		if err != nil {
-			http.Error(w, "error loading units", http.StatusInternalServerError)
-			return
-		}
-		unitsBySide[side.ID] = units
+	var action game.TurnAction
+	set := 0
+	if req.MoveID != 0 {
+		action = game.TurnAction{Kind: game.ActionMove, MoveID: req.MoveID}
+		set++
 	}
-
-	resp := s.buildMatchResponse(match, sides, unitsBySide)
-
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		http.Error(w, "encode error", http.StatusInternalServerError)
-		return
+	if req.SwitchToPosition != nil {
+		action = game.TurnAction{Kind: game.ActionSwitch, SwitchTo: *req.SwitchToPosition}
+		set++
 	}
-}
-
-func (s *Server) handlePostTurn(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
+	if req.ItemID != 0 {
+		action = game.TurnAction{Kind: game.ActionItem, ItemID: req.ItemID}
+		set++
 	}
-
-	// parse match ID from URL: /matches/{id}/turns
-	path := strings.TrimPrefix(r.URL.Path, "/matches/")
-	parts := strings.SplitN(path, "/", 2)
-	if len(parts) != 2 || parts[1] != "turns" {
-		http.Error(w, "bad path", http.StatusBadRequest)
+	if set != 1 {
+		writeError(w, http.StatusBadRequest, CodeBadRequest, "exactly one of move_id, switch_to_position and item_id is required")
 		return
 	}
-	matchID, err := strconv.ParseInt(parts[0], 10, 64)
-	if err != nil {
-		http.Error(w, "invalid match id", http.StatusBadRequest)
+	if req.ExpectedTurnNumber == 0 {
+		writeError(w, http.StatusBadRequest, CodeBadRequest, "expected_turn_number is required")
 		return
 	}
 
-	// TEMP: acting player from header
-	playerIDStr := r.Header.Get("X-Player-ID")
-	if playerIDStr == "" {
-		http.Error(w, "missing X-Player-ID header", http.StatusBadRequest)
-		return
-	}
-	actingPlayerID, err := strconv.ParseInt(playerIDStr, 10, 64)
-	if err != nil {
-		http.Error(w, "invalid X-Player-ID", http.StatusBadRequest)
+	ctx := r.Context()
+
+	// apply turn
+	if err := s.svc.ApplyTurn(ctx, matchID, actingPlayerID, action, req.ExpectedTurnNumber); err != nil {
+		writeServiceError(w, err)
 		return
 	}
 
-	// decode JSON body
-	var req postTurnRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
+	s.writeMatch(w, r, http.StatusOK, matchID)
+}
+
+// POST /matches/{id}/timeout claims the win once the opponent has let the
+// turn timer run out.
+func (s *Server) handleClaimTimeout(w http.ResponseWriter, r *http.Request) {
+	matchID, ok := pathID(w, r, "id")
+	if !ok {
 		return
 	}
-	if req.MoveID == 0 {
-		http.Error(w, "move_id is required", http.StatusBadRequest)
+	if err := s.svc.ClaimTimeout(r.Context(), matchID, requestPlayerID(r)); err != nil {
+		writeServiceError(w, err)
 		return
 	}
+	s.writeMatch(w, r, http.StatusOK, matchID)
+}
 
-	ctx := r.Context()
+// matchStates are the values accepted by ?state=.
+var matchStates = map[string]bool{"PENDING": true, "IN_PROGRESS": true, "COMPLETED": true}
 
-	// apply turn
-	if err := s.svc.ApplyTurn(ctx, matchID, actingPlayerID, req.MoveID); err != nil {
-		switch e := err.(type) {
-		case game.ErrWrongTurn:
-			http.Error(w, e.Error(), http.StatusConflict) // 409
-			return
-		case game.ErrMatchNotInProgress:
-			http.Error(w, e.Error(), http.StatusBadRequest)
-			return
-		case game.ErrIllegalMove:
-			http.Error(w, e.Error(), http.StatusBadRequest)
-			return
-		default:
-			http.Error(w, "internal error", http.StatusInternalServerError)
-			return
-		}
+// parseMatchQuery reads the filters and page of GET /me/matches.
+func parseMatchQuery(query url.Values, playerID int64) (store.ListMatchesForPlayerParams, error) {
+	verr := validation.New("query")
+	limit, cursor := parsePage(query, verr)
+	arg := store.ListMatchesForPlayerParams{
+		PlayerID: playerID,
+		BeforeID: sql.NullInt64{Int64: cursor, Valid: cursor != 0},
+		// One extra row tells us whether there is a next page
+		RowLimit: int32(limit + 1),
 	}
 
-	// load updated match view (reuse logic from handleGetMatch)
-	match, err := s.q.GetMatchByID(ctx, matchID)
-	if err != nil {
-		http.Error(w, "match not found after turn", http.StatusInternalServerError)
-		return
+	if v := query.Get("state"); v != "" {
+		v = strings.ToUpper(v)
+		if !matchStates[v] {
+			verr.Add("state", "must be PENDING, IN_PROGRESS or COMPLETED")
+		}
+		arg.State = sql.NullString{String: v, Valid: true}
 	}
-
-	sides, err := s.q.GetMatchSidesByMatchID(ctx, matchID)
-	if err != nil {
-		http.Error(w, "error loading sides", http.StatusInternalServerError)
-		return
+	if v := query.Get("opponent"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			verr.Add("opponent", "must be a player ID")
+		}
+		arg.OpponentID = sql.NullInt64{Int64: id, Valid: true}
 	}
-
-	unitsBySide := make(map[int64][]store.MatchUnit, len(sides))
-	for _, side := range sides {
-		units, err := s.q.GetMatchUnitsBySideID(ctx, side.ID)
+	if v := query.Get("from"); v != "" {
+		t, _, err := parseQueryTime(v)
 		if err != nil {
-			http.Error(w, "error loading units", http.StatusInternalServerError)
-			return
+			verr.Add("from", "must be a date (2006-01-02) or RFC 3339 time")
 		}
-		unitsBySide[side.ID] = units
+		arg.CreatedFrom = sql.NullTime{Time: t, Valid: true}
 	}
-
-	resp := s.buildMatchResponse(match, sides, unitsBySide)
-
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		http.Error(w, "encode error", http.StatusInternalServerError)
-		return
+	if v := query.Get("to"); v != "" {
+		t, dateOnly, err := parseQueryTime(v)
+		if err != nil {
+			verr.Add("to", "must be a date (2006-01-02) or RFC 3339 time")
+		}
+		// A date includes the whole day
+		if dateOnly {
+			t = t.AddDate(0, 0, 1)
+		}
+		arg.CreatedTo = sql.NullTime{Time: t, Valid: true}
+	}
+	if v := query.Get("my_turn"); v != "" {
+		myTurn, err := strconv.ParseBool(v)
+		if err != nil {
+			verr.Add("my_turn", "must be true or false")
+		}
+		arg.ActorID = sql.NullInt64{Int64: playerID, Valid: myTurn}
 	}
+	return arg, verr.Err()
 }
 
-func (s *Server) handleListMyMatches(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
+// parseQueryTime parses a date or RFC 3339 time as UTC, reporting which it was.
+func parseQueryTime(v string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.DateOnly, v); err == nil {
+		return t, true, nil
 	}
+	t, err := time.Parse(time.RFC3339, v)
+	return t.UTC(), false, err
+}
 
-	playerIDStr := r.Header.Get("X-Player-ID")
-	if playerIDStr == "" {
-		http.Error(w, "missing X-Player-ID", http.StatusBadRequest)
+// GET /me/matches?state=IN_PROGRESS&opponent=2&from=2025-01-01&to=2025-01-31&my_turn=true&limit=20&cursor=...
+// returns the caller's matches newest first, one page at a time.
+func (s *Server) handleListMyMatches(w http.ResponseWriter, r *http.Request) {
+	arg, err := parseMatchQuery(r.URL.Query(), requestPlayerID(r))
+	if err != nil {
+		writeServiceError(w, err)
 		return
 	}
 
-	playerID, err := strconv.ParseInt(playerIDStr, 10, 64)
+	matches, err := s.q.ListMatchesForPlayer(r.Context(), arg)
 	if err != nil {
-		http.Error(w, "invalid X-Player-ID", http.StatusBadRequest)
+		writeInternalError(w, "could not list matches", err)
 		return
 	}
 
-	ctx := r.Context()
-	matches, err := s.q.ListMatchesForPlayer(ctx, playerID)
-	if err != nil {
-		http.Error(w, "could not list matches", http.StatusInternalServerError)
-		return
+	page := MatchPage{Matches: make([]MatchView, 0, len(matches))}
+	if len(matches) == int(arg.RowLimit) {
+		matches = matches[:len(matches)-1]
+		page.NextCursor = encodeCursor(matches[len(matches)-1].ID)
 	}
 
-	// You can reuse MatchView here
-	out := make([]MatchView, 0, len(matches))
 	for _, m := range matches {
-		// convert to MatchView like in buildMatchResponse
-		var winnerID *int64
-		if m.WinnerPlayerID.Valid {
-			winnerID = &m.WinnerPlayerID.Int64
-		}
-		var startedAt *time.Time
-		if m.StartedAt.Valid {
-			startedAt = &m.StartedAt.Time
-		}
-		var completedAt *time.Time
-		if m.CompletedAt.Valid {
-			completedAt = &m.CompletedAt.Time
-		}
-		var actor *int64
-		if m.CurrentActorPlayerID.Valid {
-			actor = &m.CurrentActorPlayerID.Int64
-		}
-
-		out = append(out, MatchView{
-			ID:                   m.ID,
-			State:                MatchState(m.State),
-			CreatedAt:            m.CreatedAt,
-			StartedAt:            startedAt,
-			CompletedAt:          completedAt,
-			Player1ID:            m.Player1ID,
-			Player2ID:            m.Player2ID,
-			WinnerPlayerID:       winnerID,
-			CurrentTurnNumber:    int(m.CurrentTurnNumber),
-			CurrentActorPlayerID: actor,
-		})
+		page.Matches = append(page.Matches, newMatchView(m))
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(out)
+	_ = json.NewEncoder(w).Encode(page)
 }
 
 type createMatchRequest struct {
 	OpponentPlayerID int64 `json:"opponent_player_id"`
 	Player1SquadID   int64 `json:"player1_squad_id"`
 	Player2SquadID   int64 `json:"player2_squad_id"`
+	// Public defaults to true when left out
+	Public *bool `json:"public"`
+	// RulesetID defaults to the standard ruleset when left out
+	RulesetID int64 `json:"ruleset_id"`
 }
 
 func (s *Server) handleCreateMatch(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	playerIDStr := r.Header.Get("X-Player-ID")
-	if playerIDStr == "" {
-		http.Error(w, "missing X-Player-ID", http.StatusBadRequest)
-		return
-	}
-	playerID, err := strconv.ParseInt(playerIDStr, 10, 64)
-	if err != nil {
-		http.Error(w, "invalid X-Player-ID", http.StatusBadRequest)
-		return
-	}
+	playerID := requestPlayerID(r)
 
 	var req createMatchRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
 		return
 	}
 
 	ctx := r.Context()
 
-	// Create match with sqlc
-	m, err := s.q.CreateMatch(ctx, store.CreateMatchParams{
-		Player1ID: playerID,
-		Player2ID: req.OpponentPlayerID,
+	rs, err := s.requestRuleset(ctx, "match", req.RulesetID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	// Create and start the match with the chosen squads, which must exist,
+	// belong to the player fielding them and fit the ruleset
+	matchID, err := s.svc.CreateMatch(ctx, game.NewMatch{
+		Player1ID:      playerID,
+		Player1SquadID: req.Player1SquadID,
+		Player2ID:      req.OpponentPlayerID,
+		Player2SquadID: req.Player2SquadID,
+		Public:         req.Public == nil || *req.Public,
+		RulesetID:      rs.ID,
+		CheckSquads: func(q store.Querier) error {
+			return squad.CheckMatchSquads(ctx, q, rs.MinSquadSize, rs.MaxSquadSize,
+				squad.SquadChoice{Field: "player1_squad_id", SquadID: req.Player1SquadID, PlayerID: playerID},
+				squad.SquadChoice{Field: "player2_squad_id", SquadID: req.Player2SquadID, PlayerID: req.OpponentPlayerID},
+			)
+		},
 	})
 	if err != nil {
-		http.Error(w, "could not create match", http.StatusInternalServerError)
-		return
-	}
-
-	// Start match using chosen squads
-	if err := s.svc.StartMatch(ctx, m.ID, req.Player1SquadID, req.Player2SquadID); err != nil {
-		http.Error(w, "could not start match", http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 
 	// Load full view and return
-	match, err := s.q.GetMatchByID(ctx, m.ID)
-	if err != nil {
-		http.Error(w, "match not found after create", http.StatusInternalServerError)
-		return
-	}
-
-	sides, err := s.q.GetMatchSidesByMatchID(ctx, m.ID)
-	if err != nil {
-		http.Error(w, "error loading sides", http.StatusInternalServerError)
-		return
-	}
-
-	unitsBySide := make(map[int64][]store.MatchUnit, len(sides))
-	for _, side := range sides {
-		units, err := s.q.GetMatchUnitsBySideID(ctx, side.ID)
-		if err != nil {
-			http.Error(w, "error loading units", http.StatusInternalServerError)
-			return
-		}
-		unitsBySide[side.ID] = units
-	}
-
-	resp := s.buildMatchResponse(match, sides, unitsBySide) // if buildMatchResponse is a method
-
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(resp)
-}
-
-// GET /units
-func (s *Server) handleListUnits(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	ctx := r.Context()
-	units, err := s.q.ListUnits(ctx)
-	if err != nil {
-		http.Error(w, "could not list units", http.StatusInternalServerError)
-		return
-	}
-
-	// Simple DTO
-	type UnitDTO struct {
-		ID         int64  `json:"id"`
-		Name       string `json:"name"`
-		TypeID     int64  `json:"type_id"`
-		BaseHP     int32  `json:"base_hp"`
-		BaseAttack int32  `json:"base_attack"`
-		BaseSpeed  int32  `json:"base_speed"`
-	}
-
-	out := make([]UnitDTO, 0, len(units))
-	for _, u := range units {
-		out = append(out, UnitDTO{
-			ID:         u.ID,
-			Name:       u.Name,
-			TypeID:     u.TypeID,
-			BaseHP:     u.BaseHp,
-			BaseAttack: u.BaseAttack,
-			BaseSpeed:  u.BaseSpeed,
-		})
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(out)
-}
-
-func (s *Server) handleSquads(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		s.handleListMySquads(w, r)
-	case http.MethodPost:
-		s.handleCreateSquad(w, r)
-	default:
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-	}
+	s.writeMatch(w, r, http.StatusOK, matchID)
 }
 
 func (s *Server) handleListMySquads(w http.ResponseWriter, r *http.Request) {
-	playerIDStr := r.Header.Get("X-Player-ID")
-	if playerIDStr == "" {
-		http.Error(w, "missing X-Player-ID", http.StatusBadRequest)
-		return
-	}
-	playerID, err := strconv.ParseInt(playerIDStr, 10, 64)
-	if err != nil {
-		http.Error(w, "invalid X-Player-ID", http.StatusBadRequest)
-		return
-	}
+	playerID := requestPlayerID(r)
 
 	ctx := r.Context()
 	squads, err := s.q.GetSquadsForPlayer(ctx, playerID)
 	if err != nil {
-		http.Error(w, "could not list squads", http.StatusInternalServerError)
+		writeInternalError(w, "could not list squads", err)
 		return
 	}
 
-	type SquadDTO struct {
-		ID    int64   `json:"id"`
-		Name  string  `json:"name"`
-		Units []int64 `json:"units"` // unit IDs in order
-	}
-
-	out := make([]SquadDTO, 0, len(squads))
+	out := make([]SquadView, 0, len(squads))
 	for _, sq := range squads {
 		sus, err := s.q.GetSquadUnits(ctx, sq.ID)
 		if err != nil {
-			http.Error(w, "could not load squad units", http.StatusInternalServerError)
+			writeInternalError(w, "could not load squad units", err)
 			return
 		}
-		units := make([]int64, len(sus))
-		for i, su := range sus {
-			units[i] = su.UnitID
+		bag, err := s.q.ListSquadBagItems(ctx, sq.ID)
+		if err != nil {
+			writeInternalError(w, "could not load squad bag", err)
+			return
 		}
-		out = append(out, SquadDTO{
-			ID:    sq.ID,
-			Name:  sq.Name,
-			Units: units,
-		})
+		out = append(out, newSquadView(sq, sus, bag))
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(out)
 }
 
+// createSquadRequest is the body of POST /me/squads. held_item_ids is
+// parallel to unit_ids, with 0 for a unit holding nothing.
 type createSquadRequest struct {
-	Name    string  `json:"name"`
-	UnitIDs []int64 `json:"unit_ids"`
+	Name        string          `json:"name"`
+	UnitIDs     []int64         `json:"unit_ids"`
+	HeldItemIDs []int64         `json:"held_item_ids"`
+	Bag         []squad.BagItem `json:"bag"`
 }
 
 func (s *Server) handleCreateSquad(w http.ResponseWriter, r *http.Request) {
-	playerIDStr := r.Header.Get("X-Player-ID")
-	if playerIDStr == "" {
-		http.Error(w, "missing X-Player-ID", http.StatusBadRequest)
-		return
-	}
-	playerID, err := strconv.ParseInt(playerIDStr, 10, 64)
-	if err != nil {
-		http.Error(w, "invalid X-Player-ID", http.StatusBadRequest)
-		return
-	}
+	playerID := requestPlayerID(r)
 
 	var req createSquadRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
-		return
-	}
-	if req.Name == "" || len(req.UnitIDs) == 0 {
-		http.Error(w, "name and unit_ids are required", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
 		return
 	}
 
 	ctx := r.Context()
 
-	// Create squad
-	sq, err := s.q.CreateSquad(ctx, store.CreateSquadParams{
-		PlayerID: playerID,
-		Name:     req.Name,
-	})
+	// Validate and create the squad with its units in one transaction
+	_, err := s.squads.Create(ctx, playerID, req.Name, req.UnitIDs, req.HeldItemIDs, req.Bag)
 	if err != nil {
-		http.Error(w, "could not create squad", http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 
-	// Create squad_units in order
-	for pos, unitID := range req.UnitIDs {
-		_, err := s.q.CreateSquadUnit(ctx, store.CreateSquadUnitParams{
-			SquadID:  sq.ID,
-			UnitID:   unitID,
-			Position: int32(pos),
-		})
-		if err != nil {
-			http.Error(w, "could not create squad units", http.StatusInternalServerError)
-			return
-		}
-	}
-
 	w.WriteHeader(http.StatusCreated)
 }
-
-type createUnitRequest struct {
-	Name       string `json:"name"`
-	TypeID     int64  `json:"type_id"`
-	BaseHP     int32  `json:"base_hp"`
-	BaseAttack int32  `json:"base_attack"`
-	BaseSpeed  int32  `json:"base_speed"`
-}
-
-func (s *Server) handleCreateUnit(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	playerIDStr := r.Header.Get("X-Player-ID")
-	if playerIDStr == "" {
-		http.Error(w, "missing X-Player-ID", http.StatusBadRequest)
-		return
-	}
-	playerID, err := strconv.ParseInt(playerIDStr, 10, 64)
-	if err != nil {
-		http.Error(w, "invalid X-Player-ID", http.StatusBadRequest)
-		return
-	}
-
-	ctx := r.Context()
-	if _, err := s.requireAdmin(ctx, playerID); err != nil {
-		http.Error(w, "forbidden", http.StatusForbidden)
-		return
-	}
-
-	var req createUnitRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
-		return
-	}
-
-	u, err := s.q.CreateUnit(ctx, store.CreateUnitParams{
-		Name:       req.Name,
-		TypeID:     req.TypeID,
-		BaseHp:     req.BaseHP,
-		BaseAttack: req.BaseAttack,
-		BaseSpeed:  req.BaseSpeed,
-	})
-	if err != nil {
-		http.Error(w, "could not create unit", http.StatusBadRequest)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(u)
-}
-
-type createMoveRequest struct {
-	Name     string  `json:"name"`
-	Power    int32   `json:"power"`
-	Accuracy int32   `json:"accuracy"`
-	TypeID   int64   `json:"type_id"`
-	UnitIDs  []int64 `json:"unit_ids"`
-}
-
-func (s *Server) handleCreateMove(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	playerIDStr := r.Header.Get("X-Player-ID")
-	if playerIDStr == "" {
-		http.Error(w, "missing X-Player-ID", http.StatusBadRequest)
-		return
-	}
-	playerID, err := strconv.ParseInt(playerIDStr, 10, 64)
-	if err != nil {
-		http.Error(w, "invalid X-Player-ID", http.StatusBadRequest)
-		return
-	}
-
-	ctx := r.Context()
-	if _, err := s.requireAdmin(ctx, playerID); err != nil {
-		http.Error(w, "forbidden", http.StatusForbidden)
-		return
-	}
-
-	var req createMoveRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
-		return
-	}
-
-	mv, err := s.q.CreateMove(ctx, store.CreateMoveParams{
-		Name:     req.Name,
-		Power:    req.Power,
-		Accuracy: req.Accuracy,
-		TypeID:   req.TypeID,
-	})
-	if err != nil {
-		http.Error(w, "could not create move", http.StatusBadRequest)
-		return
-	}
-
-	// assign move to units
-	for _, unitID := range req.UnitIDs {
-		_, err := s.q.CreateUnitMove(ctx, store.CreateUnitMoveParams{
-			UnitID: unitID,
-			MoveID: mv.ID,
-		})
-		if err != nil {
-			http.Error(w, "could not assign move to units", http.StatusBadRequest)
-			return
-		}
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(mv)
-}
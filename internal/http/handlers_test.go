@@ -0,0 +1,91 @@
+package httpapi
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestPlayTurns(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, c *testClient) {
+		m := c.newMatch()
+		if m.Match.State != "IN_PROGRESS" || m.Match.CurrentActorPlayerID == nil {
+			t.Fatalf("new match: got %+v", m.Match)
+		}
+		actor := *m.Match.CurrentActorPlayerID
+		path := fmt.Sprintf("/matches/%d", m.Match.ID)
+
+		var view MatchResponse
+		c.wantStatus(c.do("GET", path, actor, nil, &view), http.StatusOK)
+		move := activeUnit(t, view, actor).Moves[0].ID
+		turn := postTurnRequest{MoveID: move, ExpectedTurnNumber: int32(view.Match.CurrentTurnNumber)}
+
+		var after MatchResponse
+		c.wantStatus(c.do("POST", path+"/turns", actor, turn, &after), http.StatusOK)
+		if after.Match.CurrentTurnNumber != view.Match.CurrentTurnNumber+1 {
+			t.Errorf("after a turn: on turn %d, want %d", after.Match.CurrentTurnNumber, view.Match.CurrentTurnNumber+1)
+		}
+
+		// The same turn again is out of date
+		var stale ErrorBody
+		c.wantError(c.do("POST", path+"/turns", actor, turn, &stale), stale, http.StatusConflict, CodeStaleTurn)
+	})
+}
+
+func TestCreateMatchIdempotent(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, c *testClient) {
+		first := c.newMatch("Idempotency-Key", "create-1")
+
+		// A retry with the same key replays the first response
+		req := createMatchRequest{
+			OpponentPlayerID: first.Match.Player2ID,
+			Player1SquadID:   first.Sides[0].SquadID,
+			Player2SquadID:   first.Sides[1].SquadID,
+		}
+		var replay MatchResponse
+		resp := c.do("POST", "/matches", first.Match.Player1ID, req, &replay, "Idempotency-Key", "create-1")
+		c.wantStatus(resp, http.StatusOK)
+		if resp.Header.Get("Idempotent-Replayed") != "true" || replay.Match.ID != first.Match.ID {
+			t.Errorf("retry: got match %d, replayed %q; want match %d replayed", replay.Match.ID, resp.Header.Get("Idempotent-Replayed"), first.Match.ID)
+		}
+		var mine MatchPage
+		c.wantStatus(c.do("GET", "/me/matches", first.Match.Player1ID, nil, &mine), http.StatusOK)
+		if len(mine.Matches) != 1 {
+			t.Errorf("player has %d matches, want 1", len(mine.Matches))
+		}
+
+		// The key can't be reused for a different request
+		req.Public = new(bool)
+		var reused ErrorBody
+		resp = c.do("POST", "/matches", first.Match.Player1ID, req, &reused, "Idempotency-Key", "create-1")
+		c.wantError(resp, reused, http.StatusUnprocessableEntity, CodeIdempotencyKeyReused)
+	})
+}
+
+func TestRequestErrors(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, c *testClient) {
+		alice, _ := c.signup("alice")
+		var body ErrorBody
+		c.wantError(c.do("GET", "/me/squads", 0, nil, &body), body, http.StatusBadRequest, CodeInvalidPlayerID)
+		c.wantError(c.do("GET", "/matches/999", alice, nil, &body), body, http.StatusNotFound, CodeNotFound)
+		c.wantError(c.do("GET", "/admin/audit-log", alice, nil, &body), body, http.StatusForbidden, CodeForbidden)
+		c.wantError(c.do("POST", "/me/squads", alice, createSquadRequest{Name: "Empty"}, &body), body, http.StatusBadRequest, CodeValidationFailed)
+	})
+}
+
+// activeUnit returns playerID's active unit in m.
+func activeUnit(t *testing.T, m MatchResponse, playerID int64) UnitView {
+	t.Helper()
+	for _, side := range m.Sides {
+		if side.PlayerID != playerID {
+			continue
+		}
+		for _, u := range side.Units {
+			if u.IsActive {
+				return u
+			}
+		}
+	}
+	t.Fatalf("player %d has no active unit", playerID)
+	return UnitView{}
+}
@@ -0,0 +1,186 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/76dillon/battle_squads/internal/store"
+)
+
+const (
+	// idempotencyKeyTTL is how long a stored response can be replayed for.
+	// Expired keys are swept every idempotencyCleanupInterval, so a response
+	// may be replayed for up to that much longer.
+	idempotencyKeyTTL          = 24 * time.Hour
+	idempotencyCleanupInterval = time.Hour
+
+	// idempotencyLease is how long a claimed key stays claimed without a
+	// response. A request normally completes or releases its key, but one
+	// whose server died mid-request can't; after the lease a retry claims
+	// the key again instead of being told the request is in progress. It is
+	// well beyond how long any request takes.
+	idempotencyLease = 5 * time.Minute
+)
+
+// ExpireIdempotencyKeys deletes expired idempotency keys now and then every
+// idempotencyCleanupInterval, until ctx is done. Run it in its own goroutine
+// alongside the server.
+func (s *Server) ExpireIdempotencyKeys(ctx context.Context) {
+	ticker := time.NewTicker(idempotencyCleanupInterval)
+	defer ticker.Stop()
+	for {
+		if err := s.q.DeleteExpiredIdempotencyKeys(ctx, time.Now().UTC().Add(-idempotencyKeyTTL)); err != nil {
+			log.Printf("delete expired idempotency keys: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// idempotent wraps a POST handler so that requests carrying an
+// Idempotency-Key header are executed at most once per player and key. A
+// retry with the same body gets the stored response back; the same key with a
+// different body is rejected with 422. It must run after withPlayer.
+func (s *Server) idempotent(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" {
+			next(w, r)
+			return
+		}
+		playerID := requestPlayerID(r)
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, CodeBadRequest, "could not read body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		sum := sha256.New()
+		sum.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+		sum.Write(body)
+		hash := hex.EncodeToString(sum.Sum(nil))
+
+		ctx := r.Context()
+
+		// Claim the key, or reclaim it from a request whose lease ran out. If
+		// another request still holds it we either replay its response or
+		// reject this one.
+		_, err = s.q.CreateIdempotencyKey(ctx, store.CreateIdempotencyKeyParams{
+			PlayerID:    playerID,
+			Key:         key,
+			Method:      r.Method,
+			Path:        r.URL.Path,
+			RequestHash: hash,
+			StaleBefore: time.Now().UTC().Add(-idempotencyLease),
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			existing, err := s.q.GetIdempotencyKey(ctx, store.GetIdempotencyKeyParams{
+				PlayerID: playerID,
+				Key:      key,
+			})
+			if err != nil {
+				writeInternalError(w, "internal error", err)
+				return
+			}
+			if existing.RequestHash != hash {
+				writeError(w, http.StatusUnprocessableEntity, CodeIdempotencyKeyReused, "Idempotency-Key was already used with a different request")
+				return
+			}
+			if existing.StatusCode == 0 {
+				writeError(w, http.StatusConflict, CodeRequestInProgress, "a request with this Idempotency-Key is still in progress")
+				return
+			}
+			if existing.ContentType != "" {
+				w.Header().Set("Content-Type", existing.ContentType)
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(int(existing.StatusCode))
+			w.Write(existing.ResponseBody)
+			return
+		}
+		if err != nil {
+			writeInternalError(w, "internal error", err)
+			return
+		}
+
+		// The key is finished even if the client has gone away meanwhile: the
+		// request has run, and a retry must see its outcome
+		finishCtx := context.WithoutCancel(ctx)
+
+		// A panicking handler is a server error too; the panic carries on to
+		// recoverPanics once the key is released
+		defer func() {
+			if v := recover(); v != nil {
+				s.releaseIdempotencyKey(finishCtx, playerID, key)
+				panic(v)
+			}
+		}()
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next(rec, r)
+
+		// Server errors are not stored so the client can retry with the same key
+		if rec.status >= http.StatusInternalServerError {
+			s.releaseIdempotencyKey(finishCtx, playerID, key)
+			return
+		}
+		err = s.q.CompleteIdempotencyKey(finishCtx, store.CompleteIdempotencyKeyParams{
+			PlayerID:     playerID,
+			Key:          key,
+			StatusCode:   int32(rec.status),
+			ContentType:  rec.Header().Get("Content-Type"),
+			ResponseBody: rec.body.Bytes(),
+		})
+		if err != nil {
+			log.Printf("complete idempotency key %q of player %d: %v", key, playerID, err)
+		}
+	}
+}
+
+// releaseIdempotencyKey deletes a claimed key so the request can be retried
+// with it.
+func (s *Server) releaseIdempotencyKey(ctx context.Context, playerID int64, key string) {
+	err := s.q.DeleteIdempotencyKey(ctx, store.DeleteIdempotencyKeyParams{
+		PlayerID: playerID,
+		Key:      key,
+	})
+	if err != nil {
+		log.Printf("release idempotency key %q of player %d: %v", key, playerID, err)
+	}
+}
+
+// responseRecorder passes a response through to the client while keeping a
+// copy of the status and body.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rr *responseRecorder) WriteHeader(status int) {
+	if !rr.wroteHeader {
+		rr.status = status
+		rr.wroteHeader = true
+	}
+	rr.ResponseWriter.WriteHeader(status)
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	rr.wroteHeader = true
+	rr.body.Write(b)
+	return rr.ResponseWriter.Write(b)
+}
@@ -0,0 +1,107 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/76dillon/battle_squads/internal/store"
+)
+
+// idempotentCall sends a POST with key through the idempotency middleware
+// to h, as the admin.
+func idempotentCall(c *testClient, h http.HandlerFunc, ctx context.Context, key string) *httptest.ResponseRecorder {
+	c.t.Helper()
+	r := httptest.NewRequestWithContext(ctx, "POST", "/things", strings.NewReader(`{"n":1}`))
+	r.Header.Set("X-Player-ID", "1")
+	r.Header.Set("Idempotency-Key", key)
+	w := httptest.NewRecorder()
+	chain(h, recoverPanics, withPlayer, c.api.idempotent)(w, r)
+	return w
+}
+
+func TestIdempotencyReleasesKeyOnPanic(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, c *testClient) {
+		calls := 0
+		h := func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls == 1 {
+				panic("boom")
+			}
+			w.WriteHeader(http.StatusCreated)
+		}
+		if w := idempotentCall(c, h, context.Background(), "k"); w.Code != http.StatusInternalServerError {
+			t.Fatalf("panicking call: got status %d, want 500", w.Code)
+		}
+		// The retry runs the handler again instead of finding the key in
+		// progress
+		if w := idempotentCall(c, h, context.Background(), "k"); w.Code != http.StatusCreated {
+			t.Fatalf("retry: got status %d, want 201", w.Code)
+		}
+		if calls != 2 {
+			t.Errorf("handler ran %d times, want 2", calls)
+		}
+	})
+}
+
+func TestIdempotencyCompletesAfterClientGoesAway(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, c *testClient) {
+		calls := 0
+		ctx, cancel := context.WithCancel(context.Background())
+		h := func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			// The client disconnects while the handler runs
+			cancel()
+			w.WriteHeader(http.StatusCreated)
+		}
+		if w := idempotentCall(c, h, ctx, "k"); w.Code != http.StatusCreated {
+			t.Fatalf("first call: got status %d, want 201", w.Code)
+		}
+		w := idempotentCall(c, h, context.Background(), "k")
+		if w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "true" {
+			t.Fatalf("retry: got status %d, replayed %q; want the stored 201", w.Code, w.Header().Get("Idempotent-Replayed"))
+		}
+		if calls != 1 {
+			t.Errorf("handler ran %d times, want 1", calls)
+		}
+	})
+}
+
+func TestIdempotencyLease(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, c *testClient) {
+		calls := 0
+		h := func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls == 1 {
+				// The request dies without completing or releasing its key
+				runtime.Goexit()
+			}
+			w.WriteHeader(http.StatusCreated)
+		}
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			idempotentCall(c, h, context.Background(), "k")
+		}()
+		<-done
+
+		// Within the lease a retry is told the request is in progress
+		if w := idempotentCall(c, h, context.Background(), "k"); w.Code != http.StatusConflict {
+			t.Fatalf("retry within the lease: got status %d, want 409", w.Code)
+		}
+		if calls != 1 {
+			t.Errorf("handler ran %d times, want 1", calls)
+		}
+		key, err := c.api.q.GetIdempotencyKey(context.Background(), store.GetIdempotencyKeyParams{PlayerID: 1, Key: "k"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key.StatusCode != 0 || time.Since(key.ClaimedAt) > idempotencyLease {
+			t.Errorf("key: status %d claimed at %v, want in flight and claimed within the lease", key.StatusCode, key.ClaimedAt)
+		}
+	})
+}
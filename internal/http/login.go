@@ -21,14 +21,9 @@ type loginResponse struct {
 }
 
 func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	var req loginRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
 		return
 	}
 
@@ -36,13 +31,13 @@ func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 	p, err := s.q.GetPlayerByUsername(ctx, req.Username)
 	if err != nil {
 		// you might check for sql.ErrNoRows
-		http.Error(w, "invalid credentials", http.StatusUnauthorized)
+		writeError(w, http.StatusUnauthorized, CodeInvalidCredential, "invalid credentials")
 		return
 	}
 
 	// For now, assume password_hash stores plain text (dev only!)
 	if req.Password != p.PasswordHash {
-		http.Error(w, "invalid credentials", http.StatusUnauthorized)
+		writeError(w, http.StatusUnauthorized, CodeInvalidCredential, "invalid credentials")
 		return
 	}
 
@@ -66,19 +61,14 @@ type signupResponse struct {
 }
 
 func (s *Server) handleSignup(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	var req signupRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
 		return
 	}
 	req.Username = strings.TrimSpace(req.Username)
 	if req.Username == "" || req.Password == "" {
-		http.Error(w, "username and password are required", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, CodeBadRequest, "username and password are required")
 		return
 	}
 
@@ -90,7 +80,7 @@ func (s *Server) handleSignup(w http.ResponseWriter, r *http.Request) {
 	})
 	if err != nil {
 		// you can check for unique violation here if needed
-		http.Error(w, "could not create user", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, CodeBadRequest, "could not create user")
 		return
 	}
 
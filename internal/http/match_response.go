@@ -1,17 +1,14 @@
 package httpapi
 
 import (
-	"context"
+	"net/http"
 	"time"
 
+	"github.com/76dillon/battle_squads/internal/game"
 	"github.com/76dillon/battle_squads/internal/store"
 )
 
-func (s *Server) buildMatchResponse(
-	m store.Match,
-	sides []store.MatchSide,
-	unitsBySide map[int64][]store.MatchUnit,
-) MatchResponse {
+func newMatchView(m store.Match) MatchView {
 	var winnerID *int64
 	if m.WinnerPlayerID.Valid {
 		winnerID = &m.WinnerPlayerID.Int64
@@ -32,7 +29,7 @@ func (s *Server) buildMatchResponse(
 		currentActor = &m.CurrentActorPlayerID.Int64
 	}
 
-	mv := MatchView{
+	return MatchView{
 		ID:                   m.ID,
 		State:                MatchState(m.State),
 		CreatedAt:            m.CreatedAt,
@@ -43,49 +40,97 @@ func (s *Server) buildMatchResponse(
 		WinnerPlayerID:       winnerID,
 		CurrentTurnNumber:    int(m.CurrentTurnNumber),
 		CurrentActorPlayerID: currentActor,
+		Public:               m.IsPublic,
+		RulesetID:            m.RulesetID,
 	}
+}
 
-	svs := make([]SideView, 0, len(sides))
-	for _, side := range sides {
-		units := unitsBySide[side.ID]
-		uvs := make([]UnitView, 0, len(units))
-		for _, u := range units {
+func newMatchResponse(v game.MatchView) MatchResponse {
+	svs := make([]SideView, 0, len(v.Sides))
+	for _, side := range v.Sides {
+		uvs := make([]UnitView, 0, len(side.Units))
+		for _, u := range side.Units {
 			uv := UnitView{
 				MatchUnitID: u.ID,
 				UnitID:      u.UnitID,
+				Name:        u.UnitName,
+				TypeID:      u.TypeID,
+				TypeName:    u.TypeName,
 				Position:    u.Position,
 				CurrentHP:   u.CurrentHp,
-				IsActive:    (u.Position == side.ActiveIndex),
+				MaxHP:       v.Ruleset.Stat(u.BaseHp),
+				IsActive:    u.Position == side.ActiveIndex,
+				Ability:     u.Ability,
+				AttackBonus: u.AttackBonus,
+				SpeedBonus:  u.SpeedBonus,
 			}
-
-			if uv.IsActive {
-				moves, err := s.q.ListMovesForUnit(context.Background(), u.UnitID)
-				// in real code, use ctx and handle err, but here conceptually:
-				if err == nil {
-					mv := make([]MoveView, 0, len(moves))
-					for _, m := range moves {
-						mv = append(mv, MoveView{
-							ID:       m.ID,
-							Name:     m.Name,
-							Power:    m.Power,
-							Accuracy: m.Accuracy,
-						})
-					}
-					uv.Moves = mv
-				}
+			if u.HeldItemID.Valid {
+				uv.HeldItem = &HeldItemView{ID: u.HeldItemID.Int64, Name: u.HeldItemName.String}
+			}
+			for _, m := range u.Moves {
+				uv.Moves = append(uv.Moves, MoveView{
+					ID:       m.ID,
+					Name:     m.Name,
+					Power:    m.Power,
+					Accuracy: m.Accuracy,
+					TypeID:   m.TypeID,
+					TypeName: m.TypeName,
+				})
 			}
 			uvs = append(uvs, uv)
 		}
-		svs = append(svs, SideView{
-			PlayerID:  side.PlayerID,
-			SquadID:   side.SquadID,
-			ActivePos: side.ActiveIndex,
-			Units:     uvs,
-		})
+		sv := SideView{
+			PlayerID:     side.PlayerID,
+			SquadID:      side.SquadID,
+			ActivePos:    side.ActiveIndex,
+			Units:        uvs,
+			HiddenUnits:  side.Hidden,
+			ActionChosen: side.PendingMoveID.Valid || side.PendingSwitchTo.Valid || side.PendingItemID.Valid,
+		}
+		for _, b := range side.Bag {
+			sv.Bag = append(sv.Bag, BagItemView{
+				ItemID:   b.ItemID,
+				Name:     b.Name,
+				Effect:   b.Effect,
+				Amount:   b.Amount,
+				Quantity: b.Quantity,
+			})
+		}
+		svs = append(svs, sv)
 	}
 
+	mv := newMatchView(v.Match)
+	if deadline, ok := v.Ruleset.TurnDeadline(v.Match); ok {
+		mv.TurnDeadline = &deadline
+	}
 	return MatchResponse{
+		View:  string(v.Perspective),
 		Match: mv,
 		Sides: svs,
 	}
 }
+
+// writeMatch responds with the current state of a match as the caller may
+// see it. Requests without X-Player-ID get the spectator view.
+func (s *Server) writeMatch(w http.ResponseWriter, r *http.Request, status int, matchID int64) {
+	v, err := s.svc.ViewMatch(r.Context(), matchID, requestPlayerID(r))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, status, newMatchResponse(v))
+}
+
+// GET /admin/matches/{id} shows both sides in full.
+func (s *Server) handleAdminGetMatch(w http.ResponseWriter, r *http.Request) {
+	matchID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	v, err := s.svc.LoadMatchView(r.Context(), matchID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newMatchResponse(v))
+}
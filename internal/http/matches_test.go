@@ -0,0 +1,148 @@
+package httpapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+)
+
+// startMatch starts a match between two signed up players and returns it.
+func (c *testClient) startMatch(p1, s1, p2, s2 int64) MatchResponse {
+	c.t.Helper()
+	var m MatchResponse
+	req := createMatchRequest{OpponentPlayerID: p2, Player1SquadID: s1, Player2SquadID: s2}
+	c.wantStatus(c.do("POST", "/matches", p1, req, &m), http.StatusOK)
+	return m
+}
+
+// listMatches pages through GET /me/matches with query as playerID and
+// returns the match IDs of every page.
+func (c *testClient) listMatches(playerID int64, query string) [][]int64 {
+	c.t.Helper()
+	var pages [][]int64
+	cursor := ""
+	for {
+		q, err := url.ParseQuery(query)
+		if err != nil {
+			c.t.Fatal(err)
+		}
+		if cursor != "" {
+			q.Set("cursor", cursor)
+		}
+		var page MatchPage
+		c.wantStatus(c.do("GET", "/me/matches?"+q.Encode(), playerID, nil, &page), http.StatusOK)
+		var ids []int64
+		for _, m := range page.Matches {
+			ids = append(ids, m.ID)
+		}
+		pages = append(pages, ids)
+		if page.NextCursor == "" {
+			return pages
+		}
+		if len(pages) > 20 {
+			c.t.Fatalf("GET /me/matches?%s: more than 20 pages", query)
+		}
+		cursor = page.NextCursor
+	}
+}
+
+func TestListMyMatches(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, c *testClient) {
+		alice, aliceSquad := c.signup("alice")
+		bob, bobSquad := c.signup("bob")
+		carol, carolSquad := c.signup("carol")
+		var ids []int64
+		for i := range 5 {
+			opponent, squad := bob, bobSquad
+			if i%2 == 1 {
+				opponent, squad = carol, carolSquad
+			}
+			ids = append(ids, c.startMatch(alice, aliceSquad, opponent, squad).Match.ID)
+		}
+		slices.Reverse(ids) // newest first
+
+		//--pages of two cover every match once, newest first, and the last
+		//  page has no cursor
+		pages := c.listMatches(alice, "limit=2")
+		if want := [][]int64{ids[:2], ids[2:4], ids[4:]}; fmt.Sprint(pages) != fmt.Sprint(want) {
+			t.Errorf("pages %v, want %v", pages, want)
+		}
+		if pages := c.listMatches(alice, "limit=5"); len(pages) != 1 || len(pages[0]) != 5 {
+			t.Errorf("a limit of exactly five: pages %v, want one page of five", pages)
+		}
+
+		//--the cursor stays put when a match is created between pages: the
+		//  new one is only on a fresh first page
+		var first MatchPage
+		c.wantStatus(c.do("GET", "/me/matches?limit=2", alice, nil, &first), http.StatusOK)
+		newest := c.startMatch(alice, aliceSquad, bob, bobSquad).Match.ID
+		var second MatchPage
+		c.wantStatus(c.do("GET", "/me/matches?limit=2&cursor="+first.NextCursor, alice, nil, &second), http.StatusOK)
+		if len(second.Matches) != 2 || second.Matches[0].ID != ids[2] || second.Matches[1].ID != ids[3] {
+			t.Errorf("second page after a new match: %+v, want matches %v", second.Matches, ids[2:4])
+		}
+		if pages := c.listMatches(alice, "limit=2"); pages[0][0] != newest {
+			t.Errorf("first page %v, want the new match %d first", pages[0], newest)
+		}
+		ids = append([]int64{newest}, ids...)
+
+		//--filters page too, and each player only sees their own matches
+		if pages := c.listMatches(alice, fmt.Sprintf("opponent=%d&limit=1", carol)); fmt.Sprint(pages) != fmt.Sprint([][]int64{{ids[2]}, {ids[4]}}) {
+			t.Errorf("against carol: pages %v, want %v and %v", pages, ids[2], ids[4])
+		}
+		if pages := c.listMatches(carol, ""); len(pages[0]) != 2 {
+			t.Errorf("carol has matches %v, want 2", pages[0])
+		}
+		if pages := c.listMatches(carol, fmt.Sprintf("opponent=%d", bob)); len(pages[0]) != 0 {
+			t.Errorf("carol against bob: %v, want none", pages[0])
+		}
+
+		//--state and my_turn
+		done := c.finishMatch(c.startMatch(alice, aliceSquad, bob, bobSquad))
+		if pages := c.listMatches(alice, "state=completed"); fmt.Sprint(pages) != fmt.Sprint([][]int64{{done.Match.ID}}) {
+			t.Errorf("completed: %v, want only match %d", pages, done.Match.ID)
+		}
+		var all MatchPage
+		c.wantStatus(c.do("GET", "/me/matches?state=IN_PROGRESS&limit=100", alice, nil, &all), http.StatusOK)
+		var mine []int64
+		for _, m := range all.Matches {
+			if *m.CurrentActorPlayerID == alice {
+				mine = append(mine, m.ID)
+			}
+		}
+		if pages := c.listMatches(alice, "my_turn=true"); fmt.Sprint(pages[0]) != fmt.Sprint(mine) {
+			t.Errorf("my turn: %v, want %v", pages[0], mine)
+		}
+		if pages := c.listMatches(alice, "my_turn=false"); len(pages[0]) != len(ids)+1 {
+			t.Errorf("my_turn=false: %d matches, want all %d", len(pages[0]), len(ids)+1)
+		}
+
+		//--dates: to is inclusive of the whole day
+		today := time.Now().UTC().Format(time.DateOnly)
+		tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format(time.DateOnly)
+		yesterday := time.Now().UTC().AddDate(0, 0, -1).Format(time.DateOnly)
+		for query, want := range map[string]int{
+			"from=" + today:                    len(ids) + 1,
+			"to=" + today:                      len(ids) + 1,
+			"from=" + tomorrow:                 0,
+			"to=" + yesterday:                  0,
+			"from=" + yesterday + "T00:00:00Z": len(ids) + 1,
+		} {
+			if pages := c.listMatches(alice, query+"&limit=100"); len(pages[0]) != want {
+				t.Errorf("%s: %d matches, want %d", query, len(pages[0]), want)
+			}
+		}
+
+		//--bad parameters are reported together
+		var body ErrorBody
+		c.wantError(c.do("GET", "/me/matches?limit=0&cursor=nope&state=DONE&opponent=bob&from=soon&my_turn=maybe", alice, nil, &body),
+			body, http.StatusBadRequest, CodeValidationFailed)
+		if fields := problemFields(t, body); !slices.Equal(fields, []string{"limit", "cursor", "state", "opponent", "from", "my_turn"}) {
+			t.Errorf("problems on %v", fields)
+		}
+		c.wantError(c.do("GET", fmt.Sprintf("/me/matches?limit=%d", maxPageLimit+1), alice, nil, &body), body, http.StatusBadRequest, CodeValidationFailed)
+	})
+}
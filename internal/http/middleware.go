@@ -0,0 +1,185 @@
+package httpapi
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"time"
+
+	"github.com/76dillon/battle_squads/internal/store"
+)
+
+// middleware wraps a handler with behaviour shared by several routes.
+type middleware func(http.HandlerFunc) http.HandlerFunc
+
+// chain wraps h so that the first middleware is the outermost:
+// chain(h, a, b) runs a, then b, then h.
+func chain(h http.HandlerFunc, mws ...middleware) http.HandlerFunc {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// logRequests logs one line per request with its status and duration.
+func logRequests(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next(sw, r)
+		log.Printf("%s %s %d %s", r.Method, r.URL.Path, sw.status, time.Since(start).Round(time.Microsecond))
+	}
+}
+
+// recoverPanics turns a panicking handler into a 500 instead of a dropped
+// connection. The stack goes to the log, never to the client.
+func recoverPanics(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if v := recover(); v != nil {
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+				log.Printf("panic serving %s %s: %v\n%s", r.Method, r.URL.Path, v, debug.Stack())
+				writeError(w, http.StatusInternalServerError, CodeInternal, "internal error")
+			}
+		}()
+		next(w, r)
+	}
+}
+
+// cors allows the dev web client to call the API.
+func cors(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Simple CORS for local dev
+		w.Header().Set("Access-Control-Allow-Origin", "http://localhost:8000")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-Player-ID, Idempotency-Key")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// trackPresence records when each caller was last seen, which friends lists
+// show as presence. Writes are throttled to one per player per
+// presenceWriteInterval, and a failed write never fails the request.
+func (s *Server) trackPresence(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if id, err := strconv.ParseInt(r.Header.Get("X-Player-ID"), 10, 64); err == nil {
+			now := time.Now().UTC()
+			err := s.q.TouchPlayer(r.Context(), store.TouchPlayerParams{
+				SeenAt:     sql.NullTime{Time: now, Valid: true},
+				ID:         id,
+				SeenBefore: sql.NullTime{Time: now.Add(-presenceWriteInterval), Valid: true},
+			})
+			if err != nil {
+				log.Printf("record presence of player %d: %v", id, err)
+			}
+		}
+		next(w, r)
+	}
+}
+
+type playerIDKey struct{}
+
+// withPlayer requires an X-Player-ID header and makes the ID available to
+// the handler through requestPlayerID.
+func withPlayer(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		playerIDStr := r.Header.Get("X-Player-ID")
+		if playerIDStr == "" {
+			writeError(w, http.StatusBadRequest, CodeInvalidPlayerID, "missing X-Player-ID")
+			return
+		}
+		id, err := strconv.ParseInt(playerIDStr, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, CodeInvalidPlayerID, "invalid X-Player-ID")
+			return
+		}
+		next(w, r.WithContext(context.WithValue(r.Context(), playerIDKey{}, id)))
+	}
+}
+
+// withOptionalPlayer is withPlayer for routes that anonymous callers may
+// also use. Without the header requestPlayerID returns 0.
+func withOptionalPlayer(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Player-ID") == "" {
+			next(w, r)
+			return
+		}
+		withPlayer(next)(w, r)
+	}
+}
+
+// withAdmin is withPlayer for routes that only admins may use.
+func (s *Server) withAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return withPlayer(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := s.requireAdmin(r.Context(), requestPlayerID(r)); err != nil {
+			writeError(w, http.StatusForbidden, CodeForbidden, "forbidden")
+			return
+		}
+		next(w, r)
+	})
+}
+
+// requestPlayerID returns the caller set by withPlayer, withOptionalPlayer or
+// withAdmin.
+func requestPlayerID(r *http.Request) int64 {
+	id, _ := r.Context().Value(playerIDKey{}).(int64)
+	return id
+}
+
+// pathID parses the numeric path value name, e.g. {id} in /matches/{id}.
+func pathID(w http.ResponseWriter, r *http.Request, name string) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue(name), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, CodeBadRequest, "invalid "+name)
+		return 0, false
+	}
+	return id, true
+}
+
+// statusWriter remembers the status written through it.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (sw *statusWriter) WriteHeader(status int) {
+	if !sw.wroteHeader {
+		sw.status = status
+		sw.wroteHeader = true
+	}
+	sw.ResponseWriter.WriteHeader(status)
+}
+
+func (sw *statusWriter) Write(b []byte) (int, error) {
+	sw.wroteHeader = true
+	return sw.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, e.g. to
+// flush event streams.
+func (sw *statusWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
+
+// discardWriter records the status and headers of a response and drops the
+// body. It is used to ask the mux how it would answer an unmatched request.
+type discardWriter struct {
+	header http.Header
+	status int
+}
+
+func (dw *discardWriter) Header() http.Header         { return dw.header }
+func (dw *discardWriter) WriteHeader(status int)      { dw.status = status }
+func (dw *discardWriter) Write(b []byte) (int, error) { return len(b), nil }
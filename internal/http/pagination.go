@@ -0,0 +1,60 @@
+package httpapi
+
+import (
+	"encoding/base64"
+	"net/url"
+	"strconv"
+
+	"github.com/76dillon/battle_squads/internal/validation"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// encodeCursor turns the last ID of a page into an opaque cursor. Clients
+// should pass it back unchanged rather than build their own.
+func encodeCursor(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(id, 10)))
+}
+
+func decodeCursor(cursor string) (int64, bool) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil || id < 1 {
+		return 0, false
+	}
+	return id, true
+}
+
+// parseLimit reads ?limit=, reporting a bad value to verr.
+func parseLimit(query url.Values, verr *validation.Error) int {
+	v := query.Get("limit")
+	if v == "" {
+		return defaultPageLimit
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 || n > maxPageLimit {
+		verr.Add("limit", "must be between 1 and %d", maxPageLimit)
+		return defaultPageLimit
+	}
+	return n
+}
+
+// parsePage reads ?limit= and ?cursor=, reporting bad values to verr. A zero
+// cursor means the first page.
+func parsePage(query url.Values, verr *validation.Error) (limit int, cursor int64) {
+	limit = parseLimit(query, verr)
+	if v := query.Get("cursor"); v != "" {
+		id, ok := decodeCursor(v)
+		if !ok {
+			verr.Add("cursor", "is not a valid cursor")
+		}
+		cursor = id
+	}
+	return limit, cursor
+}
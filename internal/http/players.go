@@ -0,0 +1,157 @@
+package httpapi
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/76dillon/battle_squads/internal/player"
+	"github.com/76dillon/battle_squads/internal/store"
+	"github.com/76dillon/battle_squads/internal/validation"
+)
+
+// maxSearchLength is in characters
+const maxSearchLength = 64
+
+// likeEscaper escapes the LIKE wildcards in a search term, so "50%" finds
+// "50%" rather than everything starting with "50".
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// GET /players?search=ali&limit=20&cursor=... finds players whose username
+// or display name contains search, ignoring case.
+func (s *Server) handleSearchPlayers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	verr := validation.New("query")
+	search := strings.TrimSpace(query.Get("search"))
+	if n := utf8.RuneCountInString(search); n > maxSearchLength {
+		verr.Add("search", "must be at most %d characters, got %d", maxSearchLength, n)
+	}
+	limit, cursor := parsePage(query, verr)
+	if err := verr.Err(); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	// One extra row tells us whether there is a next page
+	rows, err := s.q.SearchPlayers(r.Context(), store.SearchPlayersParams{
+		Pattern:  "%" + likeEscaper.Replace(strings.ToLower(search)) + "%",
+		AfterID:  sql.NullInt64{Int64: cursor, Valid: cursor > 0},
+		RowLimit: int32(limit + 1),
+	})
+	if err != nil {
+		writeInternalError(w, "could not search players", err)
+		return
+	}
+
+	page := PlayerPage{Players: make([]PlayerSummary, 0, len(rows))}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		page.NextCursor = encodeCursor(rows[len(rows)-1].ID)
+	}
+	for _, p := range rows {
+		page.Players = append(page.Players, PlayerSummary{
+			ID:          p.ID,
+			Username:    p.Username,
+			DisplayName: p.DisplayName,
+			AvatarKey:   p.AvatarKey,
+			Rating:      p.Rating,
+			JoinedAt:    p.CreatedAt,
+		})
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// GET /players/{id}
+func (s *Server) handleGetPlayer(w http.ResponseWriter, r *http.Request) {
+	playerID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	s.writeProfile(w, r, playerID)
+}
+
+type updateProfileRequest struct {
+	DisplayName      *string `json:"display_name"`
+	AvatarKey        *string `json:"avatar_key"`
+	ShowcaseSquadIDs []int64 `json:"showcase_squad_ids"`
+}
+
+// PATCH /me changes the caller's display name, avatar and showcase squads.
+// Fields left out are unchanged.
+func (s *Server) handleUpdateProfile(w http.ResponseWriter, r *http.Request) {
+	playerID := requestPlayerID(r)
+
+	var req updateProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+	_, err := s.players.UpdateProfile(r.Context(), playerID, player.ProfileUpdate{
+		DisplayName:      req.DisplayName,
+		AvatarKey:        req.AvatarKey,
+		ShowcaseSquadIDs: req.ShowcaseSquadIDs,
+	})
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	s.writeProfile(w, r, playerID)
+}
+
+// writeProfile responds with playerID's public profile. Stats are all-time.
+func (s *Server) writeProfile(w http.ResponseWriter, r *http.Request, playerID int64) {
+	ctx := r.Context()
+	p, err := s.q.GetPlayerByID(ctx, playerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, CodeNotFound, "player not found")
+		return
+	}
+	if err != nil {
+		writeInternalError(w, "could not load player", err)
+		return
+	}
+	st, err := s.q.GetPlayerStats(ctx, store.GetPlayerStatsParams{PlayerID: playerID})
+	if err != nil {
+		writeInternalError(w, "could not load player", err)
+		return
+	}
+	units, err := s.q.ListShowcaseSquadUnits(ctx, playerID)
+	if err != nil {
+		writeInternalError(w, "could not load player", err)
+		return
+	}
+
+	out := PlayerProfile{
+		PlayerSummary: PlayerSummary{
+			ID:          p.ID,
+			Username:    p.Username,
+			DisplayName: p.DisplayName,
+			AvatarKey:   p.AvatarKey,
+			Rating:      p.Rating,
+			JoinedAt:    p.CreatedAt,
+		},
+		Stats: PublicStatsView{
+			MatchesPlayed: st.MatchesPlayed,
+			Wins:          st.Wins,
+			Losses:        st.Losses,
+			WinRate:       ratio(st.Wins, st.MatchesPlayed),
+			DamageDealt:   st.DamageDealt,
+			KOs:           st.Kos,
+		},
+		ShowcaseSquads: []ShowcaseSquadView{},
+	}
+	// Units arrive grouped by squad
+	for _, u := range units {
+		n := len(out.ShowcaseSquads)
+		if n == 0 || out.ShowcaseSquads[n-1].ID != u.SquadID {
+			out.ShowcaseSquads = append(out.ShowcaseSquads, ShowcaseSquadView{ID: u.SquadID, Name: u.SquadName})
+			n++
+		}
+		sq := &out.ShowcaseSquads[n-1]
+		sq.Units = append(sq.Units, ShowcaseUnitView{UnitID: u.UnitID, Name: u.UnitName, Type: u.TypeName})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
@@ -0,0 +1,161 @@
+package httpapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSearchPlayers(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, c *testClient) {
+		for _, name := range []string{"alice", "Alicia", "bob", "under_score", "fifty%"} {
+			c.signup(name)
+		}
+		bob, _ := c.signup("bobby")
+		c.wantStatus(c.do("PATCH", "/me", bob, map[string]any{"display_name": "Alien Bob"}, nil), http.StatusOK)
+
+		search := func(query string) []string {
+			t.Helper()
+			var names []string
+			cursor := ""
+			for range 10 {
+				var page PlayerPage
+				c.wantStatus(c.do("GET", "/players?"+query+cursor, 0, nil, &page), http.StatusOK)
+				for _, p := range page.Players {
+					names = append(names, p.Username)
+				}
+				if page.NextCursor == "" {
+					return names
+				}
+				cursor = "&cursor=" + page.NextCursor
+			}
+			t.Fatalf("GET /players?%s: more than 10 pages", query)
+			return nil
+		}
+
+		//--any case, by username or display name, in signup order, and
+		//  wildcards are matched literally
+		for query, want := range map[string][]string{
+			"search=ALI":                           {"alice", "Alicia", "bobby"},
+			"search=ali&limit=1":                   {"alice", "Alicia", "bobby"},
+			"search=" + url.QueryEscape("  bob  "): {"bob", "bobby"},
+			"search=alien+bob":                     {"bobby"},
+			"search=_":                             {"under_score"},
+			"search=" + url.QueryEscape("%"):       {"fifty%"},
+			"search=nobody":                        nil,
+		} {
+			if got := search(query); !slices.Equal(got, want) {
+				t.Errorf("GET /players?%s: %v, want %v", query, got, want)
+			}
+		}
+		if got := search("limit=2"); len(got) != 7 {
+			t.Errorf("an empty search found %v, want all 7 players", got)
+		}
+
+		var body ErrorBody
+		long := url.QueryEscape(strings.Repeat("é", maxSearchLength+1))
+		c.wantError(c.do("GET", "/players?search="+long+"&limit=0", 0, nil, &body), body, http.StatusBadRequest, CodeValidationFailed)
+		if fields := problemFields(t, body); !slices.Equal(fields, []string{"search", "limit"}) {
+			t.Errorf("problems on %v, want search and limit", fields)
+		}
+	})
+}
+
+func TestPlayerProfile(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, c *testClient) {
+		m := c.newMatch()
+		done := c.finishMatch(m)
+		winner := *done.Match.WinnerPlayerID
+		loser := m.Match.Player1ID
+		if loser == winner {
+			loser = m.Match.Player2ID
+		}
+		profile := func(playerID int64) PlayerProfile {
+			t.Helper()
+			var p PlayerProfile
+			c.wantStatus(c.do("GET", fmt.Sprintf("/players/%d", playerID), 0, nil, &p), http.StatusOK)
+			return p
+		}
+
+		//--public stats count the finished match for both players
+		for playerID, wins := range map[int64]int64{winner: 1, loser: 0} {
+			p := profile(playerID)
+			if p.Stats.MatchesPlayed != 1 || p.Stats.Wins != wins || p.Stats.Losses != 1-wins || p.JoinedAt.IsZero() {
+				t.Errorf("player %d: %+v", playerID, p)
+			}
+			if p.ShowcaseSquads == nil || len(p.ShowcaseSquads) != 0 {
+				t.Errorf("player %d showcases %v, want an empty list", playerID, p.ShowcaseSquads)
+			}
+		}
+		var body ErrorBody
+		c.wantError(c.do("GET", "/players/999", 0, nil, &body), body, http.StatusNotFound, CodeNotFound)
+
+		//--display name and avatar are trimmed, kept when left out and
+		//  cleared when empty
+		var units []CatalogUnitView
+		c.wantStatus(c.do("GET", "/units?sort=name", 0, nil, &units), http.StatusOK)
+		var p PlayerProfile
+		c.wantStatus(c.do("PATCH", "/me", winner, map[string]any{"display_name": "  Champ  ", "avatar_key": "wolf-2"}, &p), http.StatusOK)
+		if p.DisplayName != "Champ" || p.AvatarKey != "wolf-2" {
+			t.Errorf("after update: %q, %q", p.DisplayName, p.AvatarKey)
+		}
+		c.wantStatus(c.do("PATCH", "/me", winner, map[string]any{"avatar_key": ""}, &p), http.StatusOK)
+		if p.DisplayName != "Champ" || p.AvatarKey != "" {
+			t.Errorf("after clearing the avatar: %q, %q", p.DisplayName, p.AvatarKey)
+		}
+
+		//--showcased squads list their units in squad order; deleted and
+		//  unlisted squads drop off
+		first := c.createSquad(winner, "First", []int64{units[2].ID, units[0].ID})
+		second := c.createSquad(winner, "Second", []int64{units[1].ID})
+		c.wantStatus(c.do("PATCH", "/me", winner, map[string]any{"showcase_squad_ids": []int64{second, first}}, &p), http.StatusOK)
+		var shown []string
+		for _, sq := range p.ShowcaseSquads {
+			for _, u := range sq.Units {
+				shown = append(shown, sq.Name+":"+u.Name+"/"+u.Type)
+			}
+		}
+		want := []string{
+			"First:" + units[2].Name + "/" + units[2].TypeName,
+			"First:" + units[0].Name + "/" + units[0].TypeName,
+			"Second:" + units[1].Name + "/" + units[1].TypeName,
+		}
+		if !slices.Equal(shown, want) {
+			t.Errorf("showcase %v, want %v", shown, want)
+		}
+		c.wantStatus(c.do("DELETE", fmt.Sprintf("/me/squads/%d", second), winner, nil, nil), http.StatusNoContent)
+		if p := profile(winner); len(p.ShowcaseSquads) != 1 || p.ShowcaseSquads[0].ID != first {
+			t.Errorf("after deleting a showcased squad: %+v", p.ShowcaseSquads)
+		}
+		c.wantStatus(c.do("PATCH", "/me", winner, map[string]any{"showcase_squad_ids": []int64{}}, &p), http.StatusOK)
+		if len(p.ShowcaseSquads) != 0 {
+			t.Errorf("after clearing the showcase: %+v", p.ShowcaseSquads)
+		}
+
+		//--every problem is reported and a refused update changes nothing
+		theirs := c.createSquad(loser, "Theirs", []int64{units[0].ID})
+		c.wantStatus(c.do("PATCH", "/me", winner, map[string]any{"showcase_squad_ids": []int64{first}}, nil), http.StatusOK)
+		for _, tc := range []struct {
+			body   map[string]any
+			fields []string
+		}{
+			{map[string]any{"display_name": strings.Repeat("x", 33), "avatar_key": "Wolf Two"}, []string{"display_name", "avatar_key"}},
+			{map[string]any{"display_name": "tab\there"}, []string{"display_name"}},
+			{map[string]any{"display_name": "New", "showcase_squad_ids": []int64{theirs}}, []string{"showcase_squad_ids[0]"}},
+			{map[string]any{"showcase_squad_ids": []int64{first, first}}, []string{"showcase_squad_ids[1]"}},
+			{map[string]any{"showcase_squad_ids": []int64{first, second, theirs, 999}}, []string{"showcase_squad_ids"}},
+		} {
+			c.wantError(c.do("PATCH", "/me", winner, tc.body, &body), body, http.StatusBadRequest, CodeValidationFailed)
+			if fields := problemFields(t, body); !slices.Equal(fields, tc.fields) {
+				t.Errorf("PATCH /me %v: problems on %v, want %v", tc.body, fields, tc.fields)
+			}
+		}
+		if p := profile(winner); p.DisplayName != "Champ" || len(p.ShowcaseSquads) != 1 || p.ShowcaseSquads[0].ID != first {
+			t.Errorf("after refused updates: %+v", p)
+		}
+		c.wantError(c.do("PATCH", "/me", 0, map[string]any{"display_name": "Nobody"}, &body), body, http.StatusBadRequest, CodeInvalidPlayerID)
+	})
+}
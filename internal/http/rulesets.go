@@ -0,0 +1,107 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/76dillon/battle_squads/internal/game"
+	"github.com/76dillon/battle_squads/internal/validation"
+)
+
+func newRulesetView(r game.Ruleset) RulesetView {
+	return RulesetView{
+		ID:                       r.ID,
+		Name:                     r.Name,
+		MinSquadSize:             r.MinSquadSize,
+		MaxSquadSize:             r.MaxSquadSize,
+		LevelCap:                 r.LevelCap,
+		DamageModel:              r.DamageModel,
+		TurnTimerSeconds:         r.TurnTimerSeconds,
+		TurnMode:                 r.TurnMode,
+		SwitchingEnabled:         r.SwitchingEnabled,
+		ItemsEnabled:             r.ItemsEnabled,
+		TypeEffectivenessEnabled: r.TypeEffectivenessEnabled,
+	}
+}
+
+// requestRuleset returns the ruleset a request picked with ruleset_id, or
+// the standard one when it was left out. An unknown ID is a validation
+// problem with subject.
+func (s *Server) requestRuleset(ctx context.Context, subject string, rulesetID int64) (game.Ruleset, error) {
+	if rulesetID == 0 {
+		rulesetID = game.StandardRulesetID
+	}
+	rs, err := s.svc.GetRuleset(ctx, rulesetID)
+	var notFound game.ErrRulesetNotFound
+	if errors.As(err, &notFound) {
+		verr := validation.New(subject)
+		verr.Add("ruleset_id", "ruleset %d does not exist", rulesetID)
+		return game.Ruleset{}, verr.Err()
+	}
+	return rs, err
+}
+
+// GET /rulesets
+func (s *Server) handleListRulesets(w http.ResponseWriter, r *http.Request) {
+	rulesets, err := s.svc.ListRulesets(r.Context())
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	out := make([]RulesetView, 0, len(rulesets))
+	for _, rs := range rulesets {
+		out = append(out, newRulesetView(rs))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// GET /rulesets/{id}
+func (s *Server) handleGetRuleset(w http.ResponseWriter, r *http.Request) {
+	rulesetID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	rs, err := s.svc.GetRuleset(r.Context(), rulesetID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newRulesetView(rs))
+}
+
+// POST /admin/rulesets
+func (s *Server) handleAdminCreateRuleset(w http.ResponseWriter, r *http.Request) {
+	var req game.Ruleset
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+	rs, err := s.svc.CreateRuleset(r.Context(), requestPlayerID(r), req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, newRulesetView(rs))
+}
+
+// PUT /admin/rulesets/{id} replaces every setting.
+func (s *Server) handleAdminUpdateRuleset(w http.ResponseWriter, r *http.Request) {
+	rulesetID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	var req game.Ruleset
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+	req.ID = rulesetID
+	rs, err := s.svc.UpdateRuleset(r.Context(), requestPlayerID(r), req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newRulesetView(rs))
+}
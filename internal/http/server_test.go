@@ -0,0 +1,143 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/76dillon/battle_squads/internal/content"
+	"github.com/76dillon/battle_squads/internal/devutil"
+	"github.com/76dillon/battle_squads/internal/game"
+	"github.com/76dillon/battle_squads/internal/player"
+	"github.com/76dillon/battle_squads/internal/squad"
+	"github.com/76dillon/battle_squads/internal/store"
+	"github.com/76dillon/battle_squads/internal/store/storetest"
+)
+
+// adminID is the dev admin the migrations seed.
+const adminID = 1
+
+// forEachBackend runs fn against a server over each store, seeded with the
+// demo content the way the server seeds its memory store.
+func forEachBackend(t *testing.T, fn func(*testing.T, *testClient)) {
+	for name, open := range storetest.Backends() {
+		t.Run(name, func(t *testing.T) {
+			fn(t, newTestClient(t, open(t)))
+		})
+	}
+}
+
+type testClient struct {
+	t       *testing.T
+	api     *Server
+	srv     *httptest.Server
+	matches int
+}
+
+func newTestClient(t *testing.T, st store.Store) *testClient {
+	t.Helper()
+	if err := devutil.SeedDemoContent(context.Background(), st); err != nil {
+		t.Fatal(err)
+	}
+	api := NewServer(st, game.NewService(st, game.DefaultChatRules()), squad.NewService(st, squad.DefaultRules()),
+		player.NewService(st), content.NewService(st))
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+	return &testClient{t: t, api: api, srv: srv}
+}
+
+// do sends a request as playerID, or anonymously if it is 0, and decodes
+// the JSON response into out unless it is nil. header is extra header
+// name/value pairs.
+func (c *testClient) do(method, path string, playerID int64, body, out any, header ...string) *http.Response {
+	c.t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			c.t.Fatal(err)
+		}
+	}
+	req, err := http.NewRequest(method, c.srv.URL+path, &buf)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if playerID != 0 {
+		req.Header.Set("X-Player-ID", strconv.FormatInt(playerID, 10))
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			c.t.Fatalf("%s %s: decode %d response: %v", method, path, resp.StatusCode, err)
+		}
+	}
+	return resp
+}
+
+// wantStatus fails the test unless resp has the given status.
+func (c *testClient) wantStatus(resp *http.Response, status int) {
+	c.t.Helper()
+	if resp.StatusCode != status {
+		c.t.Fatalf("%s %s: got status %d, want %d", resp.Request.Method, resp.Request.URL.Path, resp.StatusCode, status)
+	}
+}
+
+// wantError fails the test unless resp is an error with the given status
+// and code.
+func (c *testClient) wantError(resp *http.Response, body ErrorBody, status int, code ErrorCode) {
+	c.t.Helper()
+	c.wantStatus(resp, status)
+	if body.Error.Code != code {
+		c.t.Fatalf("%s %s: got code %s, want %s", resp.Request.Method, resp.Request.URL.Path, body.Error.Code, code)
+	}
+}
+
+// signup creates a player with a one-unit squad and returns both IDs.
+func (c *testClient) signup(name string) (int64, int64) {
+	c.t.Helper()
+	var p signupResponse
+	c.wantStatus(c.do("POST", "/signup", 0, signupRequest{Username: name, Password: "pw"}, &p), http.StatusOK)
+
+	var units []CatalogUnitView
+	c.wantStatus(c.do("GET", "/units", 0, nil, &units), http.StatusOK)
+	if len(units) == 0 {
+		c.t.Fatal("no units in the catalog")
+	}
+	sq := createSquadRequest{Name: name + "'s squad", UnitIDs: []int64{units[0].ID}}
+	c.wantStatus(c.do("POST", "/me/squads", p.PlayerID, sq, nil), http.StatusCreated)
+
+	var squads []SquadView
+	c.wantStatus(c.do("GET", "/me/squads", p.PlayerID, nil, &squads), http.StatusOK)
+	if len(squads) != 1 {
+		c.t.Fatalf("%s has %d squads, want 1", name, len(squads))
+	}
+	return p.PlayerID, squads[0].ID
+}
+
+// newMatch signs up two players and starts a match between them. header is
+// sent with the request that creates it.
+func (c *testClient) newMatch(header ...string) MatchResponse {
+	c.t.Helper()
+	c.matches++
+	alice, aliceSquad := c.signup(fmt.Sprintf("alice%d", c.matches))
+	bob, bobSquad := c.signup(fmt.Sprintf("bob%d", c.matches))
+	var m MatchResponse
+	resp := c.do("POST", "/matches", alice, createMatchRequest{
+		OpponentPlayerID: bob,
+		Player1SquadID:   aliceSquad,
+		Player2SquadID:   bobSquad,
+	}, &m, header...)
+	c.wantStatus(resp, http.StatusOK)
+	return m
+}
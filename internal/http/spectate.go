@@ -0,0 +1,184 @@
+package httpapi
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/76dillon/battle_squads/internal/store"
+	"github.com/76dillon/battle_squads/internal/validation"
+)
+
+// GET /matches/live?limit=20&cursor=... lists in-progress public matches,
+// newest first.
+func (s *Server) handleListLiveMatches(w http.ResponseWriter, r *http.Request) {
+	verr := validation.New("query")
+	limit, cursor := parsePage(r.URL.Query(), verr)
+	if err := verr.Err(); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	// One extra row tells us whether there is a next page
+	rows, err := s.q.ListLiveMatches(r.Context(), store.ListLiveMatchesParams{
+		BeforeID: sql.NullInt64{Int64: cursor, Valid: cursor > 0},
+		RowLimit: int32(limit + 1),
+	})
+	if err != nil {
+		writeInternalError(w, "could not list matches", err)
+		return
+	}
+
+	page := LiveMatchPage{Matches: make([]LiveMatchView, 0, len(rows))}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		page.NextCursor = encodeCursor(rows[len(rows)-1].ID)
+	}
+	for _, m := range rows {
+		var startedAt *time.Time
+		if m.StartedAt.Valid {
+			startedAt = &m.StartedAt.Time
+		}
+		page.Matches = append(page.Matches, LiveMatchView{
+			ID:                m.ID,
+			StartedAt:         startedAt,
+			CurrentTurnNumber: int(m.CurrentTurnNumber),
+			Player1ID:         m.Player1ID,
+			Player1Username:   m.Player1Username,
+			Player2ID:         m.Player2ID,
+			Player2Username:   m.Player2Username,
+		})
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+type updateMatchRequest struct {
+	Public *bool `json:"public"`
+}
+
+// PATCH /matches/{id} makes a match public or private. Only the player who
+// created it may.
+func (s *Server) handleUpdateMatch(w http.ResponseWriter, r *http.Request) {
+	matchID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req updateMatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+	if req.Public == nil {
+		writeError(w, http.StatusBadRequest, CodeBadRequest, "public is required")
+		return
+	}
+
+	if _, err := s.svc.SetMatchPublic(r.Context(), matchID, requestPlayerID(r), *req.Public); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	s.writeMatch(w, r, http.StatusOK, matchID)
+}
+
+// sseKeepAlive is how often an idle event stream sends a comment, so
+// proxies don't close it.
+const sseKeepAlive = 25 * time.Second
+
+// GET /matches/{id}/events streams the match as server-sent events. Each
+// "match" event carries the same body as GET /matches/{id}, projected for
+// the caller, and is sent once on connect and again after every change. Each
+// "message" event carries one chat message, starting with the backlog on
+// connect. The stream ends when the match is completed or stops being
+// visible.
+func (s *Server) handleWatchMatch(w http.ResponseWriter, r *http.Request) {
+	matchID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	ctx := r.Context()
+	viewerID := requestPlayerID(r)
+
+	// Watch before the first load so no change can slip in between
+	changed, stop := s.svc.WatchMatch(matchID)
+	defer stop()
+
+	v, err := s.svc.ViewMatch(ctx, matchID, viewerID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+	var lastBody []byte
+	var lastMessageID int64
+	for {
+		body, err := json.Marshal(newMatchResponse(v))
+		if err != nil {
+			return
+		}
+		// A chat message wakes us too; don't resend an unchanged match
+		if !bytes.Equal(body, lastBody) {
+			if _, err := fmt.Fprintf(w, "event: match\ndata: %s\n\n", body); err != nil {
+				return
+			}
+			lastBody = body
+		}
+		for {
+			msgs, err := s.svc.ListMessages(ctx, matchID, viewerID, lastMessageID, maxPageLimit)
+			if err != nil {
+				return
+			}
+			for _, m := range msgs {
+				data, err := json.Marshal(newMessageView(m))
+				if err != nil {
+					return
+				}
+				if _, err := fmt.Fprintf(w, "event: message\ndata: %s\n\n", data); err != nil {
+					return
+				}
+				lastMessageID = m.ID
+			}
+			if len(msgs) < maxPageLimit {
+				break
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+		if v.Match.State == "COMPLETED" {
+			return
+		}
+
+	wait:
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-keepAlive.C:
+				if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+					return
+				}
+				if err := rc.Flush(); err != nil {
+					return
+				}
+			case <-changed:
+				break wait
+			}
+		}
+
+		v, err = s.svc.ViewMatch(ctx, matchID, viewerID)
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package httpapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sseEvent is one server-sent event.
+type sseEvent struct {
+	name string
+	data string
+}
+
+// watch opens GET /matches/{id}/events as playerID and returns its events
+// as they arrive. The channel is closed when the server ends the stream;
+// cancel disconnects.
+func (c *testClient) watch(matchID, playerID int64) (<-chan sseEvent, context.CancelFunc) {
+	c.t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	c.t.Cleanup(cancel)
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/matches/%d/events", c.srv.URL, matchID), nil)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if playerID != 0 {
+		req.Header.Set("X-Player-ID", strconv.FormatInt(playerID, 10))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		resp.Body.Close()
+		c.t.Fatalf("watch match %d: status %d, content type %q", matchID, resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	events := make(chan sseEvent)
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+		var ev sseEvent
+		sc := bufio.NewScanner(resp.Body)
+		sc.Buffer(nil, 1<<20)
+		for sc.Scan() {
+			line := sc.Text()
+			switch {
+			case line == "":
+				if ev.name != "" {
+					select {
+					case events <- ev:
+					case <-ctx.Done():
+						return
+					}
+				}
+				ev = sseEvent{}
+			case strings.HasPrefix(line, ":"):
+				// keep-alive comment
+			case strings.HasPrefix(line, "event: "):
+				ev.name = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				ev.data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}()
+	return events, cancel
+}
+
+// nextEvent waits for the next event, failing the test if none comes or the
+// stream ends.
+func nextEvent(t *testing.T, events <-chan sseEvent) sseEvent {
+	t.Helper()
+	select {
+	case ev, ok := <-events:
+		if !ok {
+			t.Fatal("stream ended, want another event")
+		}
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event within 5s")
+	}
+	return sseEvent{}
+}
+
+// matchEvent waits for the next event, which must be a match event.
+func matchEvent(t *testing.T, events <-chan sseEvent) MatchResponse {
+	t.Helper()
+	ev := nextEvent(t, events)
+	if ev.name != "match" {
+		t.Fatalf("got a %q event, want match", ev.name)
+	}
+	var m MatchResponse
+	if err := json.Unmarshal([]byte(ev.data), &m); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// wantEnd waits for the server to end the stream, failing on any event.
+func wantEnd(t *testing.T, events <-chan sseEvent) {
+	t.Helper()
+	select {
+	case ev, ok := <-events:
+		if ok {
+			t.Fatalf("got a %q event, want the stream to end", ev.name)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream still open after 5s")
+	}
+}
+
+func TestLiveMatches(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, c *testClient) {
+		older := c.newMatch()
+		newer := c.newMatch()
+		c.finishMatch(c.newMatch())
+		private := false
+		carol, carolSquad := c.signup("carol")
+		dave, daveSquad := c.signup("dave")
+		req := createMatchRequest{OpponentPlayerID: dave, Player1SquadID: carolSquad, Player2SquadID: daveSquad, Public: &private}
+		c.wantStatus(c.do("POST", "/matches", carol, req, nil), http.StatusOK)
+
+		//--only public matches in progress, newest first, one per page
+		var live []LiveMatchView
+		cursor := ""
+		for range 3 {
+			var page LiveMatchPage
+			c.wantStatus(c.do("GET", "/matches/live?limit=1"+cursor, 0, nil, &page), http.StatusOK)
+			live = append(live, page.Matches...)
+			if page.NextCursor == "" {
+				break
+			}
+			cursor = "&cursor=" + page.NextCursor
+		}
+		if len(live) != 2 || live[0].ID != newer.Match.ID || live[1].ID != older.Match.ID {
+			t.Fatalf("live matches %+v, want %d then %d", live, newer.Match.ID, older.Match.ID)
+		}
+		if live[1].Player1Username != "alice1" || live[1].Player2Username != "bob1" || live[1].StartedAt == nil {
+			t.Errorf("live match %+v, want alice1 against bob1 with a start time", live[1])
+		}
+
+		//--turn counts follow play
+		actor := *older.Match.CurrentActorPlayerID
+		var m MatchResponse
+		c.wantStatus(c.do("GET", fmt.Sprintf("/matches/%d", older.Match.ID), actor, nil, &m), http.StatusOK)
+		turn := postTurnRequest{MoveID: activeUnit(t, m, actor).Moves[0].ID, ExpectedTurnNumber: int32(m.Match.CurrentTurnNumber)}
+		c.wantStatus(c.do("POST", fmt.Sprintf("/matches/%d/turns", older.Match.ID), actor, turn, nil), http.StatusOK)
+		var page LiveMatchPage
+		c.wantStatus(c.do("GET", "/matches/live", 0, nil, &page), http.StatusOK)
+		if got := page.Matches[1].CurrentTurnNumber; got != m.Match.CurrentTurnNumber+1 {
+			t.Errorf("turn number %d after a turn, want %d", got, m.Match.CurrentTurnNumber+1)
+		}
+
+		var body ErrorBody
+		c.wantError(c.do("GET", "/matches/live?limit=0", 0, nil, &body), body, http.StatusBadRequest, CodeValidationFailed)
+	})
+}
+
+func TestSpectateMatch(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, c *testClient) {
+		m := c.newMatch()
+		alice, bob := m.Match.Player1ID, m.Match.Player2ID
+		carol, _ := c.signup("carol")
+		path := fmt.Sprintf("/matches/%d", m.Match.ID)
+
+		//--spectators see the active units without moves or held items
+		for _, viewer := range []int64{0, carol} {
+			var got MatchResponse
+			c.wantStatus(c.do("GET", path, viewer, nil, &got), http.StatusOK)
+			if got.View != "spectator" {
+				t.Errorf("viewer %d: view %q, want spectator", viewer, got.View)
+			}
+			for _, side := range got.Sides {
+				for _, u := range side.Units {
+					if len(u.Moves) != 0 || u.HeldItem != nil || u.Name == "" {
+						t.Errorf("viewer %d sees player %d's unit as %+v", viewer, side.PlayerID, u)
+					}
+				}
+			}
+		}
+
+		//--only player 1 can change who may watch
+		var body ErrorBody
+		c.wantError(c.do("PATCH", path, alice, map[string]any{}, &body), body, http.StatusBadRequest, CodeBadRequest)
+		c.wantError(c.do("PATCH", path, bob, updateMatchRequest{Public: new(bool)}, &body), body, http.StatusForbidden, CodeForbidden)
+		c.wantError(c.do("PATCH", path, carol, updateMatchRequest{Public: new(bool)}, &body), body, http.StatusForbidden, CodeForbidden)
+		var got MatchResponse
+		c.wantStatus(c.do("PATCH", path, alice, updateMatchRequest{Public: new(bool)}, &got), http.StatusOK)
+		if got.Match.Public || got.View != "player" {
+			t.Errorf("after making it private: %+v", got.Match)
+		}
+
+		//--a private match can't be found by anyone else, nor watched
+		for _, viewer := range []int64{0, carol} {
+			c.wantError(c.do("GET", path, viewer, nil, &body), body, http.StatusNotFound, CodeNotFound)
+			c.wantError(c.do("GET", path+"/events", viewer, nil, &body), body, http.StatusNotFound, CodeNotFound)
+		}
+		c.wantError(c.do("PATCH", path, carol, updateMatchRequest{Public: new(bool)}, &body), body, http.StatusNotFound, CodeNotFound)
+		c.wantStatus(c.do("GET", path, bob, nil, &got), http.StatusOK)
+		c.wantError(c.do("GET", "/matches/999/events", alice, nil, &body), body, http.StatusNotFound, CodeNotFound)
+	})
+}
+
+// TestWatchMatch follows a match over server-sent events as a player and as
+// a spectator: events arrive in order, chat doesn't resend the match, and
+// streams end when the match is over, when it is made private, or when the
+// client goes away.
+func TestWatchMatch(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, c *testClient) {
+		m := c.newMatch()
+		alice := m.Match.Player1ID
+		path := fmt.Sprintf("/matches/%d", m.Match.ID)
+		c.wantStatus(c.do("POST", path+"/messages", alice, postMessageRequest{Body: "before"}, nil), http.StatusCreated)
+
+		//--each stream starts with the match as its viewer sees it, then the
+		//  chat backlog
+		player, _ := c.watch(m.Match.ID, alice)
+		spectator, _ := c.watch(m.Match.ID, 0)
+		for name, events := range map[string]<-chan sseEvent{"player": player, "spectator": spectator} {
+			first := matchEvent(t, events)
+			if first.View != name || first.Match.CurrentTurnNumber != m.Match.CurrentTurnNumber {
+				t.Errorf("%s: first event is view %q at turn %d", name, first.View, first.Match.CurrentTurnNumber)
+			}
+			if ev := nextEvent(t, events); ev.name != "message" || !strings.Contains(ev.data, `"before"`) {
+				t.Errorf("%s: second event %+v, want the chat backlog", name, ev)
+			}
+		}
+
+		//--a new message comes alone, without the unchanged match
+		c.wantStatus(c.do("POST", path+"/messages", alice, postMessageRequest{Body: "after"}, nil), http.StatusCreated)
+		for name, events := range map[string]<-chan sseEvent{"player": player, "spectator": spectator} {
+			if ev := nextEvent(t, events); ev.name != "message" || !strings.Contains(ev.data, `"after"`) {
+				t.Errorf("%s: got %+v, want the new message", name, ev)
+			}
+		}
+
+		//--a client that disconnects doesn't hold up play
+		_, disconnect := c.watch(m.Match.ID, 0)
+		disconnect()
+
+		//--turns arrive in order and the stream ends with the result
+		done := c.finishMatch(m)
+		for name, events := range map[string]<-chan sseEvent{"player": player, "spectator": spectator} {
+			last := m.Match.CurrentTurnNumber
+			for {
+				got := matchEvent(t, events)
+				// The winning turn completes the match without moving on
+				if got.Match.CurrentTurnNumber < last || got.Match.CurrentTurnNumber == last && got.Match.State != "COMPLETED" {
+					t.Fatalf("%s: turn %d (%s) after turn %d", name, got.Match.CurrentTurnNumber, got.Match.State, last)
+				}
+				last = got.Match.CurrentTurnNumber
+				if got.Match.State == "COMPLETED" {
+					break
+				}
+			}
+			if last != done.Match.CurrentTurnNumber {
+				t.Errorf("%s: ended at turn %d, want %d", name, last, done.Match.CurrentTurnNumber)
+			}
+			wantEnd(t, events)
+		}
+
+		//--making a match private ends spectators' streams but not players'
+		m = c.newMatch()
+		path = fmt.Sprintf("/matches/%d", m.Match.ID)
+		player, _ = c.watch(m.Match.ID, m.Match.Player1ID)
+		spectator, _ = c.watch(m.Match.ID, 0)
+		matchEvent(t, player)
+		matchEvent(t, spectator)
+		c.wantStatus(c.do("PATCH", path, m.Match.Player1ID, updateMatchRequest{Public: new(bool)}, nil), http.StatusOK)
+		if got := matchEvent(t, player); got.Match.Public {
+			t.Error("player's stream still shows the match as public")
+		}
+		wantEnd(t, spectator)
+
+		//--every handler returns once its client is gone
+		closed := make(chan struct{})
+		go func() {
+			c.srv.CloseClientConnections()
+			c.srv.Close()
+			close(closed)
+		}()
+		select {
+		case <-closed:
+		case <-time.After(5 * time.Second):
+			t.Error("event streams still running 5s after their clients went away")
+		}
+	})
+}
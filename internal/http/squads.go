@@ -0,0 +1,108 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/76dillon/battle_squads/internal/squad"
+	"github.com/76dillon/battle_squads/internal/store"
+)
+
+func newSquadView(sq store.Squad, sus []store.SquadUnit, bag []store.SquadBagItem) SquadView {
+	units := make([]int64, len(sus))
+	held := make([]int64, len(sus))
+	for i, su := range sus {
+		units[i] = su.UnitID
+		held[i] = su.HeldItemID.Int64
+	}
+	items := make([]squad.BagItem, len(bag))
+	for i, b := range bag {
+		items[i] = squad.BagItem{ItemID: b.ItemID, Quantity: b.Quantity}
+	}
+	return SquadView{
+		ID:          sq.ID,
+		Name:        sq.Name,
+		Units:       units,
+		HeldItemIDs: held,
+		Bag:         items,
+	}
+}
+
+// GET /me/squads/{id}
+func (s *Server) handleGetSquad(w http.ResponseWriter, r *http.Request) {
+	squadID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	sq, sus, bag, err := s.squads.Get(r.Context(), requestPlayerID(r), squadID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(newSquadView(sq, sus, bag))
+}
+
+// updateSquadRequest is the body of PUT and PATCH. PUT requires name and
+// unit_ids; PATCH changes only the fields that are present. held_item_ids
+// goes with unit_ids, and a missing bag is left as it is.
+type updateSquadRequest struct {
+	Name        *string         `json:"name"`
+	UnitIDs     []int64         `json:"unit_ids"`
+	HeldItemIDs []int64         `json:"held_item_ids"`
+	Bag         []squad.BagItem `json:"bag"`
+}
+
+// PUT and PATCH /me/squads/{id}
+func (s *Server) handleUpdateSquad(w http.ResponseWriter, r *http.Request) {
+	squadID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	playerID := requestPlayerID(r)
+
+	var req updateSquadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+	if r.Method == http.MethodPut && (req.Name == nil || req.UnitIDs == nil) {
+		writeError(w, http.StatusBadRequest, CodeBadRequest, "name and unit_ids are required")
+		return
+	}
+	if req.Name == nil && req.UnitIDs == nil && req.HeldItemIDs == nil && req.Bag == nil {
+		writeError(w, http.StatusBadRequest, CodeBadRequest, "name, unit_ids, held_item_ids or bag is required")
+		return
+	}
+
+	ctx := r.Context()
+	if _, err := s.squads.Update(ctx, playerID, squadID, req.Name, req.UnitIDs, req.HeldItemIDs, req.Bag); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	sq, sus, bag, err := s.squads.Get(ctx, playerID, squadID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(newSquadView(sq, sus, bag))
+}
+
+// DELETE /me/squads/{id}
+func (s *Server) handleDeleteSquad(w http.ResponseWriter, r *http.Request) {
+	squadID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := s.squads.Delete(r.Context(), requestPlayerID(r), squadID); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
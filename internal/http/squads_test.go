@@ -0,0 +1,179 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+// problemFields returns the fields named in a validation error.
+func problemFields(t *testing.T, body ErrorBody) []string {
+	t.Helper()
+	raw, err := json.Marshal(body.Error.Details)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var details struct {
+		Problems []struct {
+			Field string `json:"field"`
+		} `json:"problems"`
+	}
+	if err := json.Unmarshal(raw, &details); err != nil {
+		t.Fatal(err)
+	}
+	fields := make([]string, len(details.Problems))
+	for i, p := range details.Problems {
+		fields[i] = p.Field
+	}
+	return fields
+}
+
+// finishMatch has both players Tackle until m is over and returns it.
+func (c *testClient) finishMatch(m MatchResponse) MatchResponse {
+	c.t.Helper()
+	path := fmt.Sprintf("/matches/%d", m.Match.ID)
+	for range 20 {
+		if m.Match.State == "COMPLETED" {
+			return m
+		}
+		// Only the actor's own view lists their moves
+		actor := *m.Match.CurrentActorPlayerID
+		c.wantStatus(c.do("GET", path, actor, nil, &m), http.StatusOK)
+		var tackle int64
+		for _, mv := range activeUnit(c.t, m, actor).Moves {
+			if mv.Name == "Tackle" {
+				tackle = mv.ID
+			}
+		}
+		turn := postTurnRequest{MoveID: tackle, ExpectedTurnNumber: int32(m.Match.CurrentTurnNumber)}
+		c.wantStatus(c.do("POST", path+"/turns", actor, turn, &m), http.StatusOK)
+	}
+	c.t.Fatalf("match %d still %s after 20 turns", m.Match.ID, m.Match.State)
+	return m
+}
+
+// createSquad creates a squad for playerID and returns its ID.
+func (c *testClient) createSquad(playerID int64, name string, unitIDs []int64) int64 {
+	c.t.Helper()
+	c.wantStatus(c.do("POST", "/me/squads", playerID, createSquadRequest{Name: name, UnitIDs: unitIDs}, nil), http.StatusCreated)
+	var squads []SquadView
+	c.wantStatus(c.do("GET", "/me/squads", playerID, nil, &squads), http.StatusOK)
+	for _, sq := range squads {
+		if sq.Name == name {
+			return sq.ID
+		}
+	}
+	c.t.Fatalf("squad %q not listed", name)
+	return 0
+}
+
+func TestEditSquad(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, c *testClient) {
+		alice, _ := c.signup("alice")
+		bob, _ := c.signup("bob")
+		var units []CatalogUnitView
+		c.wantStatus(c.do("GET", "/units", 0, nil, &units), http.StatusOK)
+		ids := []int64{units[0].ID, units[1].ID, units[2].ID}
+
+		squadID := c.createSquad(alice, "Team", ids)
+		path := fmt.Sprintf("/me/squads/%d", squadID)
+
+		//--only the owner sees it
+		var body ErrorBody
+		c.wantError(c.do("GET", path, bob, nil, &body), body, http.StatusNotFound, CodeNotFound)
+		c.wantError(c.do("PATCH", path, bob, map[string]any{"name": "Mine"}, &body), body, http.StatusNotFound, CodeNotFound)
+		c.wantError(c.do("DELETE", path, bob, nil, &body), body, http.StatusNotFound, CodeNotFound)
+		c.wantError(c.do("GET", "/me/squads/999", alice, nil, &body), body, http.StatusNotFound, CodeNotFound)
+
+		//--renaming keeps the units, and the name is trimmed
+		var got SquadView
+		c.wantStatus(c.do("PATCH", path, alice, map[string]any{"name": "  Renamed  "}, &got), http.StatusOK)
+		if got.Name != "Renamed" || !slices.Equal(got.Units, ids) {
+			t.Errorf("after rename: %+v, want Renamed with units %v", got, ids)
+		}
+		c.wantError(c.do("PATCH", path, alice, map[string]any{"name": "   "}, &body), body, http.StatusBadRequest, CodeValidationFailed)
+		if fields := problemFields(t, body); !slices.Contains(fields, "name") {
+			t.Errorf("blank name: problems on %v, want name", fields)
+		}
+
+		//--the same units in a new order reorder the squad
+		reordered := []int64{ids[2], ids[0], ids[1]}
+		c.wantStatus(c.do("PATCH", path, alice, map[string]any{"unit_ids": reordered}, &got), http.StatusOK)
+		c.wantStatus(c.do("GET", path, alice, nil, &got), http.StatusOK)
+		if !slices.Equal(got.Units, reordered) || got.Name != "Renamed" {
+			t.Errorf("after reorder: %+v, want Renamed with units %v", got, reordered)
+		}
+
+		//--PUT needs both name and units; PATCH needs something to change;
+		//  held items only go with units; a failed edit changes nothing
+		c.wantError(c.do("PUT", path, alice, map[string]any{"name": "Only a name"}, &body), body, http.StatusBadRequest, CodeBadRequest)
+		c.wantError(c.do("PATCH", path, alice, map[string]any{}, &body), body, http.StatusBadRequest, CodeBadRequest)
+		c.wantError(c.do("PATCH", path, alice, map[string]any{"held_item_ids": []int64{0}}, &body), body, http.StatusBadRequest, CodeValidationFailed)
+		if fields := problemFields(t, body); !slices.Contains(fields, "held_item_ids") {
+			t.Errorf("held items alone: problems on %v, want held_item_ids", fields)
+		}
+		c.wantError(c.do("PUT", path, alice, map[string]any{"name": "Dupes", "unit_ids": []int64{ids[0], ids[0]}}, &body), body, http.StatusBadRequest, CodeValidationFailed)
+		c.wantStatus(c.do("GET", path, alice, nil, &got), http.StatusOK)
+		if got.Name != "Renamed" || !slices.Equal(got.Units, reordered) {
+			t.Errorf("after failed edits: %+v, want it unchanged", got)
+		}
+		c.wantStatus(c.do("PUT", path, alice, map[string]any{"name": "Solo", "unit_ids": ids[:1]}, &got), http.StatusOK)
+		if got.Name != "Solo" || !slices.Equal(got.Units, ids[:1]) {
+			t.Errorf("after PUT: %+v, want Solo with unit %d", got, ids[0])
+		}
+
+		//--deleting hides it everywhere, and it can't be deleted twice
+		c.wantStatus(c.do("DELETE", path, alice, nil, nil), http.StatusNoContent)
+		c.wantError(c.do("GET", path, alice, nil, &body), body, http.StatusNotFound, CodeNotFound)
+		c.wantError(c.do("PATCH", path, alice, map[string]any{"name": "Back"}, &body), body, http.StatusNotFound, CodeNotFound)
+		c.wantError(c.do("DELETE", path, alice, nil, &body), body, http.StatusNotFound, CodeNotFound)
+		var list []SquadView
+		c.wantStatus(c.do("GET", "/me/squads", alice, nil, &list), http.StatusOK)
+		for _, s := range list {
+			if s.ID == squadID {
+				t.Errorf("deleted squad %d still listed", squadID)
+			}
+		}
+	})
+}
+
+func TestEditSquadInMatch(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, c *testClient) {
+		m := c.newMatch()
+		alice, bob := m.Match.Player1ID, m.Match.Player2ID
+		squads := map[int64]int64{}
+		for _, side := range m.Sides {
+			squads[side.PlayerID] = side.SquadID
+		}
+		aliceSquad := squads[alice]
+		path := fmt.Sprintf("/me/squads/%d", aliceSquad)
+		var units []CatalogUnitView
+		c.wantStatus(c.do("GET", "/units", 0, nil, &units), http.StatusOK)
+
+		//--a squad in a running match can't be changed or deleted
+		var body ErrorBody
+		c.wantError(c.do("PATCH", path, alice, map[string]any{"unit_ids": []int64{units[1].ID}}, &body), body, http.StatusConflict, CodeInUse)
+		c.wantError(c.do("DELETE", path, alice, nil, &body), body, http.StatusConflict, CodeInUse)
+
+		//--nor can a deleted squad be used for a new match
+		spare := c.createSquad(alice, "Spare", []int64{units[0].ID})
+		c.wantStatus(c.do("DELETE", fmt.Sprintf("/me/squads/%d", spare), alice, nil, nil), http.StatusNoContent)
+		req := createMatchRequest{OpponentPlayerID: bob, Player1SquadID: spare, Player2SquadID: squads[bob]}
+		c.wantError(c.do("POST", "/matches", alice, req, &body), body, http.StatusBadRequest, CodeValidationFailed)
+
+		//--once the match is over the squad can be edited and deleted, and
+		//  the finished match keeps the units it was played with
+		done := c.finishMatch(m)
+		c.wantStatus(c.do("PATCH", path, alice, map[string]any{"unit_ids": []int64{units[1].ID}}, nil), http.StatusOK)
+		c.wantStatus(c.do("DELETE", path, alice, nil, nil), http.StatusNoContent)
+		var after MatchResponse
+		c.wantStatus(c.do("GET", fmt.Sprintf("/matches/%d", m.Match.ID), alice, nil, &after), http.StatusOK)
+		for _, side := range after.Sides {
+			if side.PlayerID == alice && (side.SquadID != aliceSquad || activeUnit(t, after, alice).UnitID != activeUnit(t, done, alice).UnitID) {
+				t.Errorf("finished match side %+v changed with its squad", side)
+			}
+		}
+	})
+}
@@ -0,0 +1,171 @@
+package httpapi
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/76dillon/battle_squads/internal/store"
+	"github.com/76dillon/battle_squads/internal/validation"
+)
+
+// statsTopN is how many favourite units and moves player stats list.
+const statsTopN = 3
+
+// leaderboardSorts are the values accepted by ?sort=.
+var leaderboardSorts = map[string]bool{"rating": true, "wins": true, "win_rate": true}
+
+// parseDayRange reads ?from= and ?to= as dates, both inclusive, and returns
+// them as the half-open range [from, to+1 day) that stats are kept in.
+func parseDayRange(query url.Values, verr *validation.Error) (from, to sql.NullTime) {
+	if v := query.Get("from"); v != "" {
+		t, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			verr.Add("from", "must be a date (2006-01-02)")
+		}
+		from = sql.NullTime{Time: t, Valid: err == nil}
+	}
+	if v := query.Get("to"); v != "" {
+		t, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			verr.Add("to", "must be a date (2006-01-02)")
+		}
+		to = sql.NullTime{Time: t.AddDate(0, 0, 1), Valid: err == nil}
+	}
+	if from.Valid && to.Valid && !from.Time.Before(to.Time) {
+		verr.Add("to", "must not be before from")
+	}
+	return from, to
+}
+
+// ratio is n/d, or 0 when there is nothing to divide by yet.
+func ratio(n, d int64) float64 {
+	if d == 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+}
+
+// GET /leaderboard?sort=rating&from=2025-01-01&to=2025-01-31&min_matches=5&limit=20
+// ranks players who completed at least min_matches (default 1) matches in
+// the range.
+func (s *Server) handleLeaderboard(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	verr := validation.New("query")
+
+	arg := store.ListLeaderboardParams{SortBy: "rating", MinMatches: 1}
+	if v := query.Get("sort"); v != "" {
+		if !leaderboardSorts[v] {
+			verr.Add("sort", "must be rating, wins or win_rate")
+		}
+		arg.SortBy = v
+	}
+	if v := query.Get("min_matches"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 1 {
+			verr.Add("min_matches", "must be a positive number")
+		}
+		arg.MinMatches = n
+	}
+	arg.FromDay, arg.ToDay = parseDayRange(query, verr)
+	arg.RowLimit = int32(parseLimit(query, verr))
+	if err := verr.Err(); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	rows, err := s.q.ListLeaderboard(r.Context(), arg)
+	if err != nil {
+		writeInternalError(w, "could not load leaderboard", err)
+		return
+	}
+
+	out := make([]LeaderboardEntry, 0, len(rows))
+	for i, row := range rows {
+		out = append(out, LeaderboardEntry{
+			Rank:          i + 1,
+			PlayerID:      row.ID,
+			Username:      row.Username,
+			Rating:        row.Rating,
+			MatchesPlayed: row.MatchesPlayed,
+			Wins:          row.Wins,
+			Losses:        row.Losses,
+			WinRate:       ratio(row.Wins, row.MatchesPlayed),
+		})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// GET /players/{id}/stats?from=2025-01-01&to=2025-01-31
+func (s *Server) handlePlayerStats(w http.ResponseWriter, r *http.Request) {
+	playerID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	verr := validation.New("query")
+	from, to := parseDayRange(r.URL.Query(), verr)
+	if err := verr.Err(); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	ctx := r.Context()
+	st, err := s.q.GetPlayerStats(ctx, store.GetPlayerStatsParams{
+		FromDay:  from,
+		ToDay:    to,
+		PlayerID: playerID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, CodeNotFound, "player not found")
+		return
+	}
+	if err != nil {
+		writeInternalError(w, "could not load stats", err)
+		return
+	}
+	units, err := s.q.ListPlayerTopUnits(ctx, store.ListPlayerTopUnitsParams{
+		PlayerID: playerID,
+		FromDay:  from,
+		ToDay:    to,
+		RowLimit: statsTopN,
+	})
+	if err != nil {
+		writeInternalError(w, "could not load stats", err)
+		return
+	}
+	moves, err := s.q.ListPlayerTopMoves(ctx, store.ListPlayerTopMovesParams{
+		PlayerID: playerID,
+		FromDay:  from,
+		ToDay:    to,
+		RowLimit: statsTopN,
+	})
+	if err != nil {
+		writeInternalError(w, "could not load stats", err)
+		return
+	}
+
+	out := PlayerStatsView{
+		PlayerID:       st.ID,
+		Username:       st.Username,
+		Rating:         st.Rating,
+		MatchesPlayed:  st.MatchesPlayed,
+		Wins:           st.Wins,
+		Losses:         st.Losses,
+		WinRate:        ratio(st.Wins, st.MatchesPlayed),
+		AverageTurns:   ratio(st.TurnsPlayed, st.MatchesPlayed),
+		DamageDealt:    st.DamageDealt,
+		KOs:            st.Kos,
+		FavouriteUnits: make([]UnitUsageView, 0, len(units)),
+		TopMoves:       make([]MoveUsageView, 0, len(moves)),
+	}
+	for _, u := range units {
+		out.FavouriteUnits = append(out.FavouriteUnits, UnitUsageView{UnitID: u.UnitID, Name: u.Name, Matches: u.MatchesFielded})
+	}
+	for _, m := range moves {
+		out.TopMoves = append(out.TopMoves, MoveUsageView{MoveID: m.MoveID, Name: m.Name, Uses: m.Uses})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
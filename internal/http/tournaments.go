@@ -0,0 +1,226 @@
+package httpapi
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	"github.com/76dillon/battle_squads/internal/game"
+	"github.com/76dillon/battle_squads/internal/squad"
+	"github.com/76dillon/battle_squads/internal/store"
+	"github.com/76dillon/battle_squads/internal/validation"
+)
+
+type registerTournamentRequest struct {
+	SquadID int64 `json:"squad_id"`
+}
+
+func newTournamentView(t store.Tournament) TournamentView {
+	v := TournamentView{
+		ID:             t.ID,
+		Name:           t.Name,
+		Format:         t.Format,
+		State:          t.State,
+		MaxPlayers:     int(t.MaxPlayers),
+		RulesetID:      t.RulesetID,
+		CurrentRound:   int(t.CurrentRound),
+		WinnerPlayerID: nullableID(t.WinnerPlayerID),
+		CreatedAt:      t.CreatedAt,
+	}
+	if t.StartedAt.Valid {
+		v.StartedAt = &t.StartedAt.Time
+	}
+	if t.CompletedAt.Valid {
+		v.CompletedAt = &t.CompletedAt.Time
+	}
+	return v
+}
+
+func newTournamentDetailView(tv game.TournamentView) TournamentDetailView {
+	out := TournamentDetailView{
+		TournamentView: newTournamentView(tv.Tournament),
+		Entries:        make([]TournamentEntryView, 0, len(tv.Entries)),
+		Rounds:         []TournamentRoundView{},
+		Standings:      make([]StandingView, 0, len(tv.Standings)),
+	}
+	for _, e := range tv.Entries {
+		var seed *int
+		if e.Seed.Valid {
+			n := int(e.Seed.Int32)
+			seed = &n
+		}
+		out.Entries = append(out.Entries, TournamentEntryView{
+			PlayerID: e.PlayerID,
+			Username: e.Username,
+			Seed:     seed,
+		})
+	}
+	// Matches arrive ordered by round, then slot
+	for _, tm := range tv.Matches {
+		n := len(out.Rounds)
+		if n == 0 || out.Rounds[n-1].Round != int(tm.Round) {
+			out.Rounds = append(out.Rounds, TournamentRoundView{Round: int(tm.Round)})
+			n++
+		}
+		out.Rounds[n-1].Matches = append(out.Rounds[n-1].Matches, TournamentPairingView{
+			Slot:           int(tm.Slot),
+			Player1ID:      nullableID(tm.Player1ID),
+			Player2ID:      nullableID(tm.Player2ID),
+			MatchID:        nullableID(tm.MatchID),
+			WinnerPlayerID: nullableID(tm.WinnerPlayerID),
+			Bye:            tm.WinnerPlayerID.Valid && !tm.Player2ID.Valid,
+			Walkover:       tm.WinnerPlayerID.Valid && tm.Player2ID.Valid && !tm.MatchID.Valid,
+		})
+	}
+	for _, st := range tv.Standings {
+		out.Standings = append(out.Standings, StandingView{
+			PlayerID: st.PlayerID,
+			Username: st.Username,
+			Played:   st.Played,
+			Wins:     st.Wins,
+			Losses:   st.Losses,
+		})
+	}
+	return out
+}
+
+func nullableID(n sql.NullInt64) *int64 {
+	if !n.Valid {
+		return nil
+	}
+	return &n.Int64
+}
+
+// GET /tournaments?state=REGISTRATION&limit=20&cursor=... lists tournaments,
+// newest first.
+func (s *Server) handleListTournaments(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	verr := validation.New("query")
+	limit, cursor := parsePage(query, verr)
+	var state sql.NullString
+	switch v := query.Get("state"); v {
+	case "":
+	case game.TournamentRegistration, game.TournamentInProgress, game.TournamentCompleted:
+		state = sql.NullString{String: v, Valid: true}
+	default:
+		verr.Add("state", "must be %s, %s or %s", game.TournamentRegistration, game.TournamentInProgress, game.TournamentCompleted)
+	}
+	if err := verr.Err(); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	// One extra row tells us whether there is a next page
+	rows, err := s.q.ListTournaments(r.Context(), store.ListTournamentsParams{
+		State:    state,
+		BeforeID: sql.NullInt64{Int64: cursor, Valid: cursor > 0},
+		RowLimit: int32(limit + 1),
+	})
+	if err != nil {
+		writeInternalError(w, "could not list tournaments", err)
+		return
+	}
+
+	page := TournamentPage{Tournaments: make([]TournamentView, 0, len(rows))}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		page.NextCursor = encodeCursor(rows[len(rows)-1].ID)
+	}
+	for _, t := range rows {
+		page.Tournaments = append(page.Tournaments, newTournamentView(t))
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// GET /tournaments/{id} shows the bracket or round robin and the standings.
+func (s *Server) handleGetTournament(w http.ResponseWriter, r *http.Request) {
+	tournamentID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	s.writeTournament(w, r, http.StatusOK, tournamentID)
+}
+
+// POST /tournaments/{id}/entries registers the caller with one of their
+// squads.
+func (s *Server) handleRegisterForTournament(w http.ResponseWriter, r *http.Request) {
+	tournamentID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	var req registerTournamentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+
+	ctx := r.Context()
+	playerID := requestPlayerID(r)
+	rs, err := s.svc.TournamentRuleset(ctx, tournamentID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	err = s.squads.CheckMatchSquads(ctx, rs.MinSquadSize, rs.MaxSquadSize,
+		squad.SquadChoice{Field: "squad_id", SquadID: req.SquadID, PlayerID: playerID})
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	if _, err := s.svc.RegisterForTournament(ctx, tournamentID, playerID, req.SquadID); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	s.writeTournament(w, r, http.StatusCreated, tournamentID)
+}
+
+// DELETE /tournaments/{id}/entries withdraws the caller before the start.
+func (s *Server) handleWithdrawFromTournament(w http.ResponseWriter, r *http.Request) {
+	tournamentID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	if err := s.svc.WithdrawFromTournament(r.Context(), tournamentID, requestPlayerID(r)); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /admin/tournaments
+func (s *Server) handleAdminCreateTournament(w http.ResponseWriter, r *http.Request) {
+	var req game.NewTournament
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+
+	t, err := s.svc.CreateTournament(r.Context(), requestPlayerID(r), req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	s.writeTournament(w, r, http.StatusCreated, t.ID)
+}
+
+// POST /admin/tournaments/{id}/start
+func (s *Server) handleAdminStartTournament(w http.ResponseWriter, r *http.Request) {
+	tournamentID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	if _, err := s.svc.StartTournament(r.Context(), requestPlayerID(r), tournamentID); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	s.writeTournament(w, r, http.StatusOK, tournamentID)
+}
+
+func (s *Server) writeTournament(w http.ResponseWriter, r *http.Request, status int, tournamentID int64) {
+	tv, err := s.svc.GetTournament(r.Context(), tournamentID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, status, newTournamentDetailView(tv))
+}
@@ -1,9 +1,20 @@
 package httpapi
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/76dillon/battle_squads/internal/squad"
+)
 
 type MatchState string
 
+// MatchPage is one page of a match list. NextCursor is empty on the last page.
+type MatchPage struct {
+	Matches    []MatchView `json:"matches"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
 type MatchView struct {
 	ID                   int64      `json:"id"`
 	State                MatchState `json:"state"`
@@ -15,15 +26,62 @@ type MatchView struct {
 	WinnerPlayerID       *int64     `json:"winner_player_id,omitempty"`
 	CurrentTurnNumber    int        `json:"current_turn_number"`
 	CurrentActorPlayerID *int64     `json:"current_actor_player_id,omitempty"`
+	Public               bool       `json:"public"`
+	RulesetID            int64      `json:"ruleset_id"`
+	// TurnDeadline is only set on a single match, when its ruleset has a
+	// turn timer
+	TurnDeadline *time.Time `json:"turn_deadline,omitempty"`
+}
+
+// LiveMatchPage is one page of GET /matches/live.
+type LiveMatchPage struct {
+	Matches    []LiveMatchView `json:"matches"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+type LiveMatchView struct {
+	ID                int64      `json:"id"`
+	StartedAt         *time.Time `json:"started_at,omitempty"`
+	CurrentTurnNumber int        `json:"current_turn_number"`
+	Player1ID         int64      `json:"player1_id"`
+	Player1Username   string     `json:"player1_username"`
+	Player2ID         int64      `json:"player2_id"`
+	Player2Username   string     `json:"player2_username"`
 }
 
 type UnitView struct {
 	MatchUnitID int64      `json:"match_unit_id"`
 	UnitID      int64      `json:"unit_id"`
+	Name        string     `json:"name"`
+	TypeID      int64      `json:"type_id"`
+	TypeName    string     `json:"type_name"`
 	Position    int32      `json:"position"`
 	CurrentHP   int32      `json:"current_hp"`
+	MaxHP       int32      `json:"max_hp"`
 	IsActive    bool       `json:"is_active"`
 	Moves       []MoveView `json:"moves,omitempty"`
+	// HeldItem is only set when the unit brought an item into the match and
+	// the viewer may see it: on their own side, or as an admin
+	HeldItem *HeldItemView `json:"held_item,omitempty"`
+	Ability  string        `json:"ability,omitempty"`
+	// AttackBonus and SpeedBonus are the percentages abilities have added
+	// to the unit's stats while it is active
+	AttackBonus int32 `json:"attack_bonus,omitempty"`
+	SpeedBonus  int32 `json:"speed_bonus,omitempty"`
+}
+
+type HeldItemView struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// BagItemView is a consumable in a match side's bag and how many are left.
+type BagItemView struct {
+	ItemID   int64  `json:"item_id"`
+	Name     string `json:"name"`
+	Effect   string `json:"effect"`
+	Amount   int32  `json:"amount"`
+	Quantity int32  `json:"quantity"`
 }
 
 type SideView struct {
@@ -31,9 +89,19 @@ type SideView struct {
 	SquadID   int64      `json:"squad_id"`
 	ActivePos int32      `json:"active_position"`
 	Units     []UnitView `json:"units"`
+	// HiddenUnits counts bench units the viewer hasn't seen yet
+	HiddenUnits int `json:"hidden_units"`
+	// ActionChosen is set in a simultaneous match once the side has chosen
+	// this turn's action, which stays hidden until both have
+	ActionChosen bool `json:"action_chosen"`
+	// Bag is only shown to the side's own player
+	Bag []BagItemView `json:"bag,omitempty"`
 }
 
+// MatchResponse is a match as one viewer sees it. View is "player",
+// "spectator" or "admin".
 type MatchResponse struct {
+	View  string     `json:"view"`
 	Match MatchView  `json:"match"`
 	Sides []SideView `json:"sides"`
 }
@@ -43,4 +111,314 @@ type MoveView struct {
 	Name     string `json:"name"`
 	Power    int32  `json:"power"`
 	Accuracy int32  `json:"accuracy"`
+	TypeID   int64  `json:"type_id"`
+	TypeName string `json:"type_name"`
+}
+
+type SquadView struct {
+	ID    int64   `json:"id"`
+	Name  string  `json:"name"`
+	Units []int64 `json:"units"` // unit IDs in order
+	// HeldItemIDs is parallel to Units, with 0 for a unit holding nothing
+	HeldItemIDs []int64         `json:"held_item_ids"`
+	Bag         []squad.BagItem `json:"bag"`
+}
+
+type UnitTypeView struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// CatalogUnitView is a unit as defined by admins, as opposed to a unit in a
+// match (UnitView).
+type CatalogUnitView struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	TypeID     int64  `json:"type_id"`
+	TypeName   string `json:"type_name,omitempty"`
+	BaseHP     int32  `json:"base_hp"`
+	BaseAttack int32  `json:"base_attack"`
+	BaseSpeed  int32  `json:"base_speed"`
+	Ability    string `json:"ability,omitempty"`
+}
+
+type CatalogMoveView struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	Power    int32  `json:"power"`
+	Accuracy int32  `json:"accuracy"`
+	TypeID   int64  `json:"type_id"`
+	TypeName string `json:"type_name,omitempty"`
+}
+
+type AbilityView struct {
+	Key         string `json:"key"`
+	Description string `json:"description"`
+}
+
+type ItemView struct {
+	ID     int64  `json:"id"`
+	Name   string `json:"name"`
+	Kind   string `json:"kind"`
+	Effect string `json:"effect"`
+	Amount int32  `json:"amount"`
+}
+
+// UnitDetailView is a catalog unit with its learnset.
+type UnitDetailView struct {
+	CatalogUnitView
+	Moves []CatalogMoveView `json:"moves"`
+}
+
+type AuditEntryView struct {
+	ID            int64           `json:"id"`
+	AdminPlayerID int64           `json:"admin_player_id"`
+	Action        string          `json:"action"`
+	EntityType    string          `json:"entity_type"`
+	EntityID      int64           `json:"entity_id"`
+	Details       json.RawMessage `json:"details"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+type LeaderboardEntry struct {
+	Rank          int     `json:"rank"`
+	PlayerID      int64   `json:"player_id"`
+	Username      string  `json:"username"`
+	Rating        int32   `json:"rating"`
+	MatchesPlayed int64   `json:"matches_played"`
+	Wins          int64   `json:"wins"`
+	Losses        int64   `json:"losses"`
+	WinRate       float64 `json:"win_rate"`
+}
+
+// PlayerStatsView is GET /players/{id}/stats. Totals cover the requested
+// days; favourite units and top moves are all-time.
+type PlayerStatsView struct {
+	PlayerID       int64           `json:"player_id"`
+	Username       string          `json:"username"`
+	Rating         int32           `json:"rating"`
+	MatchesPlayed  int64           `json:"matches_played"`
+	Wins           int64           `json:"wins"`
+	Losses         int64           `json:"losses"`
+	WinRate        float64         `json:"win_rate"`
+	AverageTurns   float64         `json:"average_turns"`
+	DamageDealt    int64           `json:"damage_dealt"`
+	KOs            int64           `json:"kos"`
+	FavouriteUnits []UnitUsageView `json:"favourite_units"`
+	TopMoves       []MoveUsageView `json:"top_moves"`
+}
+
+// PlayerSummary is one player in GET /players. An empty display name means
+// clients should show the username.
+type PlayerSummary struct {
+	ID          int64     `json:"id"`
+	Username    string    `json:"username"`
+	DisplayName string    `json:"display_name"`
+	AvatarKey   string    `json:"avatar_key"`
+	Rating      int32     `json:"rating"`
+	JoinedAt    time.Time `json:"joined_at"`
+}
+
+// PlayerPage is one page of GET /players. NextCursor is empty on the last page.
+type PlayerPage struct {
+	Players    []PlayerSummary `json:"players"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+// PlayerProfile is GET /players/{id}.
+type PlayerProfile struct {
+	PlayerSummary
+	Stats          PublicStatsView     `json:"stats"`
+	ShowcaseSquads []ShowcaseSquadView `json:"showcase_squads"`
+}
+
+// PublicStatsView is a player's all-time record as shown on their profile.
+type PublicStatsView struct {
+	MatchesPlayed int64   `json:"matches_played"`
+	Wins          int64   `json:"wins"`
+	Losses        int64   `json:"losses"`
+	WinRate       float64 `json:"win_rate"`
+	DamageDealt   int64   `json:"damage_dealt"`
+	KOs           int64   `json:"kos"`
+}
+
+type ShowcaseSquadView struct {
+	ID    int64              `json:"id"`
+	Name  string             `json:"name"`
+	Units []ShowcaseUnitView `json:"units"`
+}
+
+type ShowcaseUnitView struct {
+	UnitID int64  `json:"unit_id"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+}
+
+// FriendView is one player in GET /me/friends. Status is "accepted",
+// "requested" (sent by the caller), "incoming" (sent to the caller) or
+// "blocked" (by the caller). Presence is set for accepted friends only.
+type FriendView struct {
+	PlayerSummary
+	Status   string    `json:"status"`
+	Presence string    `json:"presence,omitempty"`
+	Since    time.Time `json:"since"`
+}
+
+// FriendshipView is the answer to POST /me/friends.
+type FriendshipView struct {
+	PlayerID int64  `json:"player_id"`
+	Status   string `json:"status"`
+}
+
+type ChallengeView struct {
+	ID                 int64     `json:"id"`
+	ChallengerID       int64     `json:"challenger_id"`
+	ChallengerUsername string    `json:"challenger_username,omitempty"`
+	OpponentID         int64     `json:"opponent_id"`
+	OpponentUsername   string    `json:"opponent_username,omitempty"`
+	Public             bool      `json:"public"`
+	RulesetID          int64     `json:"ruleset_id"`
+	State              string    `json:"state"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+type MessageView struct {
+	ID        int64     `json:"id"`
+	PlayerID  int64     `json:"player_id"`
+	Username  string    `json:"username,omitempty"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReportedMessageView is one message in GET /admin/message-reports with
+// every report it has received.
+type ReportedMessageView struct {
+	MessageID      int64               `json:"message_id"`
+	MatchID        int64               `json:"match_id"`
+	PlayerID       int64               `json:"player_id"`
+	AuthorUsername string              `json:"author_username"`
+	Body           string              `json:"body"`
+	CreatedAt      time.Time           `json:"created_at"`
+	Reports        []MessageReportView `json:"reports"`
+}
+
+type MessageReportView struct {
+	ReporterID       int64     `json:"reporter_id"`
+	ReporterUsername string    `json:"reporter_username"`
+	Reason           string    `json:"reason"`
+	ReportedAt       time.Time `json:"reported_at"`
+}
+
+// TournamentPage is one page of GET /tournaments.
+type TournamentPage struct {
+	Tournaments []TournamentView `json:"tournaments"`
+	NextCursor  string           `json:"next_cursor,omitempty"`
+}
+
+type TournamentView struct {
+	ID             int64      `json:"id"`
+	Name           string     `json:"name"`
+	Format         string     `json:"format"`
+	State          string     `json:"state"`
+	MaxPlayers     int        `json:"max_players"`
+	RulesetID      int64      `json:"ruleset_id"`
+	CurrentRound   int        `json:"current_round"`
+	WinnerPlayerID *int64     `json:"winner_player_id,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	StartedAt      *time.Time `json:"started_at,omitempty"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty"`
+}
+
+// TournamentDetailView is GET /tournaments/{id}.
+type TournamentDetailView struct {
+	TournamentView
+	Entries   []TournamentEntryView `json:"entries"`
+	Rounds    []TournamentRoundView `json:"rounds"`
+	Standings []StandingView        `json:"standings"`
+}
+
+type TournamentEntryView struct {
+	PlayerID int64  `json:"player_id"`
+	Username string `json:"username"`
+	Seed     *int   `json:"seed,omitempty"`
+}
+
+type TournamentRoundView struct {
+	Round   int                     `json:"round"`
+	Matches []TournamentPairingView `json:"matches"`
+}
+
+// TournamentPairingView is one pairing. Players are left out until they are
+// known; a bye has a winner and no second player, a walkover both players,
+// a winner and no match.
+type TournamentPairingView struct {
+	Slot           int    `json:"slot"`
+	Player1ID      *int64 `json:"player1_id,omitempty"`
+	Player2ID      *int64 `json:"player2_id,omitempty"`
+	MatchID        *int64 `json:"match_id,omitempty"`
+	WinnerPlayerID *int64 `json:"winner_player_id,omitempty"`
+	Bye            bool   `json:"bye,omitempty"`
+	Walkover       bool   `json:"walkover,omitempty"`
+}
+
+type StandingView struct {
+	PlayerID int64  `json:"player_id"`
+	Username string `json:"username"`
+	Played   int    `json:"played"`
+	Wins     int    `json:"wins"`
+	Losses   int    `json:"losses"`
+}
+
+type RulesetView struct {
+	ID                       int64  `json:"id"`
+	Name                     string `json:"name"`
+	MinSquadSize             int    `json:"min_squad_size"`
+	MaxSquadSize             int    `json:"max_squad_size"`
+	LevelCap                 int    `json:"level_cap"`
+	DamageModel              string `json:"damage_model"`
+	TurnTimerSeconds         int    `json:"turn_timer_seconds"`
+	TurnMode                 string `json:"turn_mode"`
+	SwitchingEnabled         bool   `json:"switching_enabled"`
+	ItemsEnabled             bool   `json:"items_enabled"`
+	TypeEffectivenessEnabled bool   `json:"type_effectiveness_enabled"`
+}
+
+type UnitUsageView struct {
+	UnitID  int64  `json:"unit_id"`
+	Name    string `json:"name"`
+	Matches int64  `json:"matches"`
+}
+
+type MoveUsageView struct {
+	MoveID int64  `json:"move_id"`
+	Name   string `json:"name"`
+	Uses   int64  `json:"uses"`
+}
+
+// UnitAnalyticsView is one unit in GET /admin/analytics/units. Rates are
+// fractions between 0 and 1.
+type UnitAnalyticsView struct {
+	UnitID        int64   `json:"unit_id"`
+	Name          string  `json:"name"`
+	SquadsPicked  int64   `json:"squads_picked"`
+	PickRate      float64 `json:"pick_rate"`
+	TimesFielded  int64   `json:"times_fielded"`
+	WinRate       float64 `json:"win_rate"`
+	Uses          int64   `json:"uses"`
+	HitRate       float64 `json:"hit_rate"`
+	AverageDamage float64 `json:"average_damage"`
+	KORate        float64 `json:"ko_rate"`
+}
+
+// MoveAnalyticsView is one move in GET /admin/analytics/moves.
+type MoveAnalyticsView struct {
+	MoveID        int64   `json:"move_id"`
+	Name          string  `json:"name"`
+	Uses          int64   `json:"uses"`
+	UseRate       float64 `json:"use_rate"`
+	MatchesUsed   int64   `json:"matches_used"`
+	WinRate       float64 `json:"win_rate"`
+	HitRate       float64 `json:"hit_rate"`
+	AverageDamage float64 `json:"average_damage"`
+	KORate        float64 `json:"ko_rate"`
 }
@@ -0,0 +1,102 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/pressly/goose/v3"
+
+	sqlfiles "github.com/76dillon/battle_squads/sql"
+)
+
+// Migrator applies the embedded goose migrations for one database driver.
+// It uses goose's own version table, so databases migrated by hand with the
+// goose CLI are picked up where they left off.
+type Migrator struct {
+	provider *goose.Provider
+}
+
+// New returns a Migrator for db. driver is the database/sql driver name,
+// "postgres" or "sqlite".
+func New(db *sql.DB, driver string) (*Migrator, error) {
+	var (
+		dialect goose.Dialect
+		fsys    fs.FS
+		err     error
+	)
+	switch driver {
+	case "postgres":
+		dialect = goose.DialectPostgres
+		fsys, err = fs.Sub(sqlfiles.PostgresSchema, "schema")
+	case "sqlite":
+		dialect = goose.DialectSQLite3
+		fsys, err = fs.Sub(sqlfiles.SQLiteSchema, "sqlite/schema")
+	default:
+		return nil, fmt.Errorf("no migrations for driver %q", driver)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open embedded migrations: %w", err)
+	}
+
+	p, err := goose.NewProvider(dialect, db, fsys)
+	if err != nil {
+		return nil, fmt.Errorf("create migration provider: %w", err)
+	}
+	return &Migrator{provider: p}, nil
+}
+
+// Up applies all pending migrations and writes one line per migration to w.
+func (m *Migrator) Up(ctx context.Context, w io.Writer) error {
+	results, err := m.provider.Up(ctx)
+	for _, r := range results {
+		fmt.Fprintln(w, r)
+	}
+	if err != nil {
+		return fmt.Errorf("migrate up: %w", err)
+	}
+	return nil
+}
+
+// Down rolls back the most recent migration.
+func (m *Migrator) Down(ctx context.Context, w io.Writer) error {
+	r, err := m.provider.Down(ctx)
+	if r != nil {
+		fmt.Fprintln(w, r)
+	}
+	if err != nil {
+		return fmt.Errorf("migrate down: %w", err)
+	}
+	return nil
+}
+
+// Status writes the state of every known migration to w.
+func (m *Migrator) Status(ctx context.Context, w io.Writer) error {
+	statuses, err := m.provider.Status(ctx)
+	if err != nil {
+		return fmt.Errorf("migration status: %w", err)
+	}
+	for _, s := range statuses {
+		appliedAt := "-"
+		if s.State == goose.StateApplied {
+			appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%-8s %-20s %s\n", s.State, appliedAt, s.Source.Path)
+	}
+	return nil
+}
+
+// Check returns an error if the database schema is behind the embedded
+// migrations.
+func (m *Migrator) Check(ctx context.Context) error {
+	current, target, err := m.provider.GetVersions(ctx)
+	if err != nil {
+		return fmt.Errorf("read schema version: %w", err)
+	}
+	if current < target {
+		return fmt.Errorf("database schema is at version %d but the server needs %d: run `migrate up` or start with --migrate", current, target)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package migrate_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	_ "modernc.org/sqlite" // register sqlite driver
+
+	"github.com/76dillon/battle_squads/internal/config"
+	"github.com/76dillon/battle_squads/internal/migrate"
+	sqlfiles "github.com/76dillon/battle_squads/sql"
+)
+
+// openSQLite opens an empty SQLite database that is removed when t ends.
+func openSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+	driver, dsn, err := config.ParseDatabaseURL("sqlite://" + filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// migrationNames lists the file names of the migrations under dir in fsys.
+func migrationNames(t *testing.T, fsys fs.FS, dir string) []string {
+	t.Helper()
+	paths, err := fs.Glob(fsys, dir+"/*.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, len(paths))
+	for i, p := range paths {
+		names[i] = path.Base(p)
+	}
+	return names
+}
+
+// TestSchemasMatch checks that every Postgres migration has a SQLite
+// counterpart with the same version and name, so both databases end up at
+// the same version.
+func TestSchemasMatch(t *testing.T) {
+	pg := migrationNames(t, sqlfiles.PostgresSchema, "schema")
+	lite := migrationNames(t, sqlfiles.SQLiteSchema, "sqlite/schema")
+	if len(pg) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+	if strings.Join(pg, "\n") != strings.Join(lite, "\n") {
+		t.Errorf("Postgres migrations\n%s\ndon't match SQLite migrations\n%s", strings.Join(pg, "\n"), strings.Join(lite, "\n"))
+	}
+}
+
+func TestUnknownDriver(t *testing.T) {
+	if _, err := migrate.New(openSQLite(t), "mysql"); err == nil {
+		t.Error("New accepted a driver without migrations")
+	}
+}
+
+// TestMigrateSQLite runs the embedded migrations up, all the way down and
+// up again on SQLite, checking Check and Status along the way.
+func TestMigrateSQLite(t *testing.T) {
+	ctx := context.Background()
+	m, err := migrate.New(openSQLite(t), "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := len(migrationNames(t, sqlfiles.SQLiteSchema, "sqlite/schema"))
+	status := func() string {
+		t.Helper()
+		var out bytes.Buffer
+		if err := m.Status(ctx, &out); err != nil {
+			t.Fatal(err)
+		}
+		return out.String()
+	}
+
+	//--an empty database is behind, with everything pending
+	if err := m.Check(ctx); err == nil {
+		t.Error("Check passed on an empty database")
+	}
+	if got := strings.Count(status(), "pending"); got != n {
+		t.Errorf("%d migrations pending on an empty database, want %d", got, n)
+	}
+
+	//--up applies everything once; a second up has nothing to do
+	var out bytes.Buffer
+	if err := m.Up(ctx, &out); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(out.String(), "\n"); got != n {
+		t.Errorf("up reported %d migrations, want %d", got, n)
+	}
+	if err := m.Check(ctx); err != nil {
+		t.Errorf("Check after up: %v", err)
+	}
+	if got := strings.Count(status(), "applied"); got != n {
+		t.Errorf("%d migrations applied, want %d", got, n)
+	}
+	out.Reset()
+	if err := m.Up(ctx, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("second up applied:\n%s", out.String())
+	}
+
+	//--down rolls back one migration at a time, which puts the schema behind
+	if err := m.Down(ctx, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Check(ctx); err == nil || !strings.Contains(err.Error(), "migrate up") {
+		t.Errorf("Check after down: got %v, want an error saying to migrate up", err)
+	}
+	if got := strings.Count(status(), "pending"); got != 1 {
+		t.Errorf("%d migrations pending after one down, want 1", got)
+	}
+
+	//--every migration can be rolled back and applied again
+	for i := 1; i < n; i++ {
+		if err := m.Down(ctx, io.Discard); err != nil {
+			t.Fatalf("down %d: %v", i+1, err)
+		}
+	}
+	if got := strings.Count(status(), "pending"); got != n {
+		t.Errorf("%d migrations pending after rolling back all, want %d", got, n)
+	}
+	if err := m.Down(ctx, io.Discard); err == nil {
+		t.Error("down with nothing applied succeeded")
+	}
+	if err := m.Up(ctx, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Check(ctx); err != nil {
+		t.Errorf("Check after migrating up again: %v", err)
+	}
+}
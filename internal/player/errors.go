@@ -0,0 +1,13 @@
+package player
+
+type ErrNotFound struct {
+	Msg string
+}
+
+func (e ErrNotFound) Error() string { return e.Msg }
+
+type ErrForbidden struct {
+	Msg string
+}
+
+func (e ErrForbidden) Error() string { return e.Msg }
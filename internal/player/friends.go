@@ -0,0 +1,203 @@
+package player
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/76dillon/battle_squads/internal/store"
+	"github.com/76dillon/battle_squads/internal/validation"
+)
+
+// Friendship statuses as stored in friendships.status
+const (
+	StatusRequested = "requested"
+	StatusAccepted  = "accepted"
+	StatusBlocked   = "blocked"
+)
+
+// relationship is what the rows between two players say, seen from one of
+// them.
+type relationship struct {
+	rows []store.Friendship
+	// mine and theirs are the row from each side, if any
+	mine, theirs *store.Friendship
+}
+
+func (rel relationship) blockedByMe() bool {
+	return rel.mine != nil && rel.mine.Status == StatusBlocked
+}
+
+func (rel relationship) blockedByThem() bool {
+	return rel.theirs != nil && rel.theirs.Status == StatusBlocked
+}
+
+// AddFriend sends otherID a friend request, or accepts theirs if they already
+// sent one. It returns the resulting status, which is StatusRequested until
+// the other player accepts. Asking again is not an error.
+func (s *Service) AddFriend(ctx context.Context, playerID, otherID int64) (string, error) {
+	var status string
+	err := s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		rel, err := loadRelationship(ctx, qtx, playerID, otherID)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case rel.blockedByMe():
+			return ErrForbidden{Msg: "unblock this player before sending a friend request"}
+		case rel.blockedByThem():
+			return ErrForbidden{Msg: "cannot send a friend request to this player"}
+		case rel.mine != nil:
+			// Already asked, or already friends
+			status = rel.mine.Status
+			return nil
+		case rel.theirs != nil && rel.theirs.Status == StatusRequested:
+			// They asked first: accept
+			status = StatusAccepted
+			err := qtx.UpdateFriendshipStatus(ctx, store.UpdateFriendshipStatusParams{
+				PlayerID: otherID,
+				FriendID: playerID,
+				Status:   StatusAccepted,
+			})
+			if err != nil {
+				return fmt.Errorf("accept friend request: %w", err)
+			}
+			return nil
+		case rel.theirs != nil:
+			status = rel.theirs.Status
+			return nil
+		}
+
+		f, err := qtx.CreateFriendship(ctx, store.CreateFriendshipParams{
+			PlayerID: playerID,
+			FriendID: otherID,
+			Status:   StatusRequested,
+		})
+		if err != nil {
+			return fmt.Errorf("create friend request: %w", err)
+		}
+		status = f.Status
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return status, nil
+}
+
+// RemoveFriend ends a friendship, withdraws a request playerID sent or
+// declines one they received. Blocks are left alone; see Unblock.
+func (s *Service) RemoveFriend(ctx context.Context, playerID, otherID int64) error {
+	return s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		rel, err := loadRelationship(ctx, qtx, playerID, otherID)
+		if err != nil {
+			return err
+		}
+		removed, err := deleteFriendRows(ctx, qtx, rel)
+		if err != nil {
+			return err
+		}
+		if removed == 0 {
+			return ErrNotFound{Msg: "no friendship or friend request with this player"}
+		}
+		return nil
+	})
+}
+
+// Block stops otherID from challenging or being matched against playerID,
+// and ends any friendship or request between them.
+func (s *Service) Block(ctx context.Context, playerID, otherID int64) error {
+	return s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		rel, err := loadRelationship(ctx, qtx, playerID, otherID)
+		if err != nil {
+			return err
+		}
+		if rel.blockedByMe() {
+			return nil
+		}
+		if _, err := deleteFriendRows(ctx, qtx, rel); err != nil {
+			return err
+		}
+		_, err = qtx.CreateFriendship(ctx, store.CreateFriendshipParams{
+			PlayerID: playerID,
+			FriendID: otherID,
+			Status:   StatusBlocked,
+		})
+		if err != nil {
+			return fmt.Errorf("block player: %w", err)
+		}
+		return nil
+	})
+}
+
+// Unblock lifts playerID's block on otherID. It does not restore the
+// friendship the block ended.
+func (s *Service) Unblock(ctx context.Context, playerID, otherID int64) error {
+	return s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		rel, err := loadRelationship(ctx, qtx, playerID, otherID)
+		if err != nil {
+			return err
+		}
+		if !rel.blockedByMe() {
+			return ErrNotFound{Msg: "player is not blocked"}
+		}
+		err = qtx.DeleteFriendship(ctx, store.DeleteFriendshipParams{PlayerID: playerID, FriendID: otherID})
+		if err != nil {
+			return fmt.Errorf("unblock player: %w", err)
+		}
+		return nil
+	})
+}
+
+// loadRelationship checks that otherID is someone else who exists and reads
+// the rows between the two players.
+func loadRelationship(ctx context.Context, q store.Querier, playerID, otherID int64) (relationship, error) {
+	if playerID == otherID {
+		verr := validation.New("request")
+		verr.Add("player_id", "must be another player")
+		return relationship{}, verr
+	}
+	_, err := q.GetPlayerByID(ctx, otherID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return relationship{}, ErrNotFound{Msg: "player not found"}
+	}
+	if err != nil {
+		return relationship{}, fmt.Errorf("get player: %w", err)
+	}
+
+	rows, err := q.ListFriendshipsBetween(ctx, store.ListFriendshipsBetweenParams{
+		PlayerID: playerID,
+		OtherID:  otherID,
+	})
+	if err != nil {
+		return relationship{}, fmt.Errorf("list friendships: %w", err)
+	}
+	rel := relationship{rows: rows}
+	for i := range rows {
+		if rows[i].PlayerID == playerID {
+			rel.mine = &rows[i]
+		} else {
+			rel.theirs = &rows[i]
+		}
+	}
+	return rel, nil
+}
+
+// deleteFriendRows deletes the requests and friendships in rel, but not
+// blocks, and reports how many there were.
+func deleteFriendRows(ctx context.Context, q store.Querier, rel relationship) (int, error) {
+	removed := 0
+	for _, f := range rel.rows {
+		if f.Status == StatusBlocked {
+			continue
+		}
+		err := q.DeleteFriendship(ctx, store.DeleteFriendshipParams{PlayerID: f.PlayerID, FriendID: f.FriendID})
+		if err != nil {
+			return 0, fmt.Errorf("delete friendship: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
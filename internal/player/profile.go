@@ -0,0 +1,115 @@
+package player
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/76dillon/battle_squads/internal/store"
+	"github.com/76dillon/battle_squads/internal/validation"
+)
+
+// avatarKeyPattern is what an avatar key may look like. Keys name an image
+// the client ships with, so they are never turned into paths or URLs here.
+var avatarKeyPattern = regexp.MustCompile(`^[a-z0-9_-]{0,64}$`)
+
+// ProfileUpdate is a change to a player's profile. Nil fields are left
+// unchanged; an empty display name or avatar key clears it, and an empty
+// (non-nil) showcase list hides every squad.
+type ProfileUpdate struct {
+	DisplayName      *string
+	AvatarKey        *string
+	ShowcaseSquadIDs []int64
+}
+
+// UpdateProfile applies u to playerID's profile and returns the player as
+// it is afterwards.
+func (s *Service) UpdateProfile(ctx context.Context, playerID int64, u ProfileUpdate) (store.Player, error) {
+	var p store.Player
+	err := s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		// 1. Load the player
+		var err error
+		p, err = qtx.GetPlayerByID(ctx, playerID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound{Msg: "player not found"}
+		}
+		if err != nil {
+			return fmt.Errorf("get player: %w", err)
+		}
+
+		// 2. Validate the parts being changed
+		verr := validation.New("profile")
+		if u.DisplayName != nil {
+			p.DisplayName = strings.TrimSpace(*u.DisplayName)
+			validateDisplayName(verr, p.DisplayName)
+		}
+		if u.AvatarKey != nil {
+			p.AvatarKey = *u.AvatarKey
+			if !avatarKeyPattern.MatchString(p.AvatarKey) {
+				verr.Add("avatar_key", "must be at most 64 lowercase letters, digits, '-' or '_'")
+			}
+		}
+		if u.ShowcaseSquadIDs != nil {
+			validateShowcase(verr, u.ShowcaseSquadIDs)
+		}
+		if err := verr.Err(); err != nil {
+			return err
+		}
+
+		// 3. Apply them
+		err = qtx.UpdatePlayerProfile(ctx, store.UpdatePlayerProfileParams{
+			ID:          p.ID,
+			DisplayName: p.DisplayName,
+			AvatarKey:   p.AvatarKey,
+		})
+		if err != nil {
+			return fmt.Errorf("update profile: %w", err)
+		}
+		if u.ShowcaseSquadIDs != nil {
+			if err := qtx.ClearShowcaseSquads(ctx, p.ID); err != nil {
+				return fmt.Errorf("clear showcase: %w", err)
+			}
+			for i, squadID := range u.ShowcaseSquadIDs {
+				n, err := qtx.ShowcaseSquad(ctx, store.ShowcaseSquadParams{ID: squadID, PlayerID: p.ID})
+				if err != nil {
+					return fmt.Errorf("showcase squad: %w", err)
+				}
+				if n == 0 {
+					verr.Add(fmt.Sprintf("showcase_squad_ids[%d]", i), "squad %d does not exist", squadID)
+				}
+			}
+		}
+		return verr.Err()
+	})
+	if err != nil {
+		return store.Player{}, err
+	}
+	return p, nil
+}
+
+func validateDisplayName(verr *validation.Error, name string) {
+	if n := utf8.RuneCountInString(name); n > MaxDisplayNameLength {
+		verr.Add("display_name", "must be at most %d characters, got %d", MaxDisplayNameLength, n)
+	}
+	if strings.IndexFunc(name, unicode.IsControl) >= 0 {
+		verr.Add("display_name", "must not contain control characters")
+	}
+}
+
+func validateShowcase(verr *validation.Error, squadIDs []int64) {
+	if len(squadIDs) > MaxShowcaseSquads {
+		verr.Add("showcase_squad_ids", "must contain at most %d squads, got %d", MaxShowcaseSquads, len(squadIDs))
+	}
+	seen := make(map[int64]bool, len(squadIDs))
+	for i, id := range squadIDs {
+		if seen[id] {
+			verr.Add(fmt.Sprintf("showcase_squad_ids[%d]", i), "squad %d is listed more than once", id)
+		}
+		seen[id] = true
+	}
+}
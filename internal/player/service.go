@@ -0,0 +1,20 @@
+package player
+
+import (
+	"github.com/76dillon/battle_squads/internal/store"
+)
+
+const (
+	// MaxDisplayNameLength is in characters
+	MaxDisplayNameLength = 32
+	// MaxShowcaseSquads is how many squads a profile can show off
+	MaxShowcaseSquads = 3
+)
+
+type Service struct {
+	store store.Store
+}
+
+func NewService(st store.Store) *Service {
+	return &Service{store: st}
+}
@@ -0,0 +1,115 @@
+package squad
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/76dillon/battle_squads/internal/store"
+	"github.com/76dillon/battle_squads/internal/validation"
+)
+
+// Create validates and stores a new squad for playerID. heldItemIDs is
+// parallel to unitIDs, with 0 for a unit holding nothing; nil means no unit
+// holds an item. The squad row, its squad_units and its bag are written in one
+// transaction, so a rejected or failed request never leaves a half-built
+// squad behind.
+func (s *Service) Create(
+	ctx context.Context,
+	playerID int64,
+	name string,
+	unitIDs []int64,
+	heldItemIDs []int64,
+	bag []BagItem,
+) (store.Squad, error) {
+	name = strings.TrimSpace(name)
+
+	var sq store.Squad
+	err := s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		// 1. Validate everything up front and report all problems together
+		if err := s.validate(ctx, qtx, name, unitIDs, heldItemIDs, bag); err != nil {
+			return err
+		}
+
+		// 2. Create squad
+		var err error
+		sq, err = qtx.CreateSquad(ctx, store.CreateSquadParams{
+			PlayerID: playerID,
+			Name:     name,
+		})
+		if err != nil {
+			return fmt.Errorf("create squad: %w", err)
+		}
+
+		// 3. Create squad_units in order, then the bag
+		if err := createUnits(ctx, qtx, sq.ID, unitIDs, heldItemIDs); err != nil {
+			return err
+		}
+		return createBag(ctx, qtx, sq.ID, bag)
+	})
+	if err != nil {
+		return store.Squad{}, err
+	}
+	return sq, nil
+}
+
+// validate checks a new squad against the rules and the units and items
+// tables.
+func (s *Service) validate(ctx context.Context, q store.Querier, name string, unitIDs, heldItemIDs []int64, bag []BagItem) error {
+	verr := validation.New("squad")
+	s.validateName(verr, name)
+	if err := s.validateUnits(ctx, q, verr, unitIDs); err != nil {
+		return err
+	}
+	if err := s.validateHeldItems(ctx, q, verr, unitIDs, heldItemIDs); err != nil {
+		return err
+	}
+	if err := s.validateBag(ctx, q, verr, bag); err != nil {
+		return err
+	}
+	return verr.Err()
+}
+
+func (s *Service) validateName(verr *validation.Error, name string) {
+	if name == "" {
+		verr.Add("name", "is required")
+	} else if n := utf8.RuneCountInString(name); n > s.rules.MaxNameLength {
+		verr.Add("name", "must be at most %d characters, got %d", s.rules.MaxNameLength, n)
+	}
+}
+
+// validateUnits records size, duplicate and unknown-unit problems in verr. It
+// only returns an error if the units could not be looked up.
+func (s *Service) validateUnits(ctx context.Context, q store.Querier, verr *validation.Error, unitIDs []int64) error {
+	//--size
+	if len(unitIDs) < s.rules.MinSize {
+		verr.Add("unit_ids", "must contain at least %d units, got %d", s.rules.MinSize, len(unitIDs))
+	}
+	if len(unitIDs) > s.rules.MaxSize {
+		verr.Add("unit_ids", "must contain at most %d units, got %d", s.rules.MaxSize, len(unitIDs))
+	}
+
+	//--each unit must exist, and appear once unless duplicates are allowed
+	seen := make(map[int64]bool, len(unitIDs))
+	for i, unitID := range unitIDs {
+		field := fmt.Sprintf("unit_ids[%d]", i)
+		if seen[unitID] && !s.rules.AllowDuplicateUnits {
+			verr.Add(field, "unit %d is already in the squad", unitID)
+			continue
+		}
+		seen[unitID] = true
+
+		unit, err := q.GetUnitByID(ctx, unitID)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && unit.DeletedAt.Valid) {
+			verr.Add(field, "unit %d does not exist", unitID)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("get unit %d: %w", unitID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package squad
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/76dillon/battle_squads/internal/store"
+	"github.com/76dillon/battle_squads/internal/validation"
+)
+
+// Get returns one of playerID's squads with its units in order and its bag.
+// Deleted squads and squads owned by someone else are reported as
+// ErrNotFound.
+func (s *Service) Get(ctx context.Context, playerID, squadID int64) (store.Squad, []store.SquadUnit, []store.SquadBagItem, error) {
+	sq, err := s.getOwned(ctx, s.store, playerID, squadID, false)
+	if err != nil {
+		return store.Squad{}, nil, nil, err
+	}
+	sus, err := s.store.GetSquadUnits(ctx, sq.ID)
+	if err != nil {
+		return store.Squad{}, nil, nil, fmt.Errorf("get squad units: %w", err)
+	}
+	bag, err := s.store.ListSquadBagItems(ctx, sq.ID)
+	if err != nil {
+		return store.Squad{}, nil, nil, fmt.Errorf("list squad bag: %w", err)
+	}
+	return sq, sus, bag, nil
+}
+
+// Update renames a squad, replaces its units and/or replaces its bag. A nil
+// name, unitIDs or bag leaves that part unchanged; passing the same units in
+// a new order reorders the squad. heldItemIDs goes with unitIDs as in Create
+// and can only be given along with them.
+//
+// Squads fielded in an in-progress match cannot be changed. Matches copy
+// their units into match_units when they start, so edits never reach a
+// running match, but refusing them keeps match_sides.squad_id meaningful.
+func (s *Service) Update(
+	ctx context.Context,
+	playerID int64,
+	squadID int64,
+	name *string,
+	unitIDs []int64,
+	heldItemIDs []int64,
+	bag []BagItem,
+) (store.Squad, error) {
+	var sq store.Squad
+	err := s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		// 1. Load the squad and make sure it can be edited
+		var err error
+		sq, err = s.getOwned(ctx, qtx, playerID, squadID, true)
+		if err != nil {
+			return err
+		}
+		if err := checkNotInUse(ctx, qtx, sq.ID); err != nil {
+			return err
+		}
+
+		// 2. Validate the parts being changed
+		verr := validation.New("squad")
+		if name != nil {
+			trimmed := strings.TrimSpace(*name)
+			name = &trimmed
+			s.validateName(verr, *name)
+		}
+		if unitIDs != nil {
+			if err := s.validateUnits(ctx, qtx, verr, unitIDs); err != nil {
+				return err
+			}
+			if err := s.validateHeldItems(ctx, qtx, verr, unitIDs, heldItemIDs); err != nil {
+				return err
+			}
+		} else if heldItemIDs != nil {
+			verr.Add("held_item_ids", "can only be given with unit_ids")
+		}
+		if bag != nil {
+			if err := s.validateBag(ctx, qtx, verr, bag); err != nil {
+				return err
+			}
+		}
+		if err := verr.Err(); err != nil {
+			return err
+		}
+
+		// 3. Apply them
+		if name != nil {
+			sq, err = qtx.UpdateSquadName(ctx, store.UpdateSquadNameParams{
+				ID:   sq.ID,
+				Name: *name,
+			})
+			if err != nil {
+				return fmt.Errorf("rename squad: %w", err)
+			}
+		}
+		if unitIDs != nil {
+			// Positions are unique per squad, so rewrite the whole list
+			if err := qtx.DeleteSquadUnits(ctx, sq.ID); err != nil {
+				return fmt.Errorf("clear squad units: %w", err)
+			}
+			if err := createUnits(ctx, qtx, sq.ID, unitIDs, heldItemIDs); err != nil {
+				return err
+			}
+		}
+		if bag != nil {
+			if err := qtx.DeleteSquadBagItems(ctx, sq.ID); err != nil {
+				return fmt.Errorf("clear squad bag: %w", err)
+			}
+			if err := createBag(ctx, qtx, sq.ID, bag); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return store.Squad{}, err
+	}
+	return sq, nil
+}
+
+// Delete soft-deletes a squad: it disappears from the player's list and can
+// no longer be picked for a match, but finished matches that used it keep
+// their reference.
+func (s *Service) Delete(ctx context.Context, playerID, squadID int64) error {
+	return s.store.ExecTx(ctx, func(qtx store.Querier) error {
+		sq, err := s.getOwned(ctx, qtx, playerID, squadID, true)
+		if err != nil {
+			return err
+		}
+		if err := checkNotInUse(ctx, qtx, sq.ID); err != nil {
+			return err
+		}
+		if err := qtx.SoftDeleteSquad(ctx, sq.ID); err != nil {
+			return fmt.Errorf("delete squad: %w", err)
+		}
+		return nil
+	})
+}
+
+// getOwned loads one of playerID's squads, locking it if forUpdate so that
+// a match can't be created with it until the edit is done. Other players'
+// squads and deleted ones are reported as not found.
+func (s *Service) getOwned(ctx context.Context, q store.Querier, playerID, squadID int64, forUpdate bool) (store.Squad, error) {
+	get := q.GetSquadByID
+	if forUpdate {
+		get = q.GetSquadByIDForUpdate
+	}
+	sq, err := get(ctx, squadID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && (sq.PlayerID != playerID || sq.DeletedAt.Valid)) {
+		return store.Squad{}, ErrNotFound{Msg: "squad not found"}
+	}
+	if err != nil {
+		return store.Squad{}, fmt.Errorf("get squad: %w", err)
+	}
+	return sq, nil
+}
+
+func checkNotInUse(ctx context.Context, q store.Querier, squadID int64) error {
+	n, err := q.CountInProgressMatchesForSquad(ctx, squadID)
+	if err != nil {
+		return fmt.Errorf("count matches for squad: %w", err)
+	}
+	if n > 0 {
+		return ErrInUse{Msg: "squad is being used in a match that is in progress"}
+	}
+	// Tournament squads are locked from registration until the end
+	n, err = q.CountOpenTournamentEntriesForSquad(ctx, squadID)
+	if err != nil {
+		return fmt.Errorf("count tournament entries for squad: %w", err)
+	}
+	if n > 0 {
+		return ErrInUse{Msg: "squad is registered in a tournament that is not completed"}
+	}
+	return nil
+}
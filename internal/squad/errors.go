@@ -0,0 +1,13 @@
+package squad
+
+type ErrNotFound struct {
+	Msg string
+}
+
+func (e ErrNotFound) Error() string { return e.Msg }
+
+type ErrInUse struct {
+	Msg string
+}
+
+func (e ErrInUse) Error() string { return e.Msg }
@@ -0,0 +1,116 @@
+package squad
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/76dillon/battle_squads/internal/game"
+	"github.com/76dillon/battle_squads/internal/store"
+	"github.com/76dillon/battle_squads/internal/validation"
+)
+
+// BagItem is a consumable a squad takes into its matches, and how many.
+type BagItem struct {
+	ItemID   int64 `json:"item_id"`
+	Quantity int32 `json:"quantity"`
+}
+
+// validateHeldItems records problems with the items held by the units in
+// unitIDs. heldItemIDs is parallel to unitIDs; 0 means the unit holds none.
+func (s *Service) validateHeldItems(ctx context.Context, q store.Querier, verr *validation.Error, unitIDs, heldItemIDs []int64) error {
+	if heldItemIDs == nil {
+		return nil
+	}
+	if len(heldItemIDs) != len(unitIDs) {
+		verr.Add("held_item_ids", "must have one entry per unit, got %d for %d units", len(heldItemIDs), len(unitIDs))
+		return nil
+	}
+	for i, itemID := range heldItemIDs {
+		if itemID == 0 {
+			continue
+		}
+		field := fmt.Sprintf("held_item_ids[%d]", i)
+		if err := checkItem(ctx, q, verr, field, itemID, game.ItemHeld); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateBag records problems with a squad's bag: unknown or non-consumable
+// items, repeats, and more than MaxBagSize items in total.
+func (s *Service) validateBag(ctx context.Context, q store.Querier, verr *validation.Error, bag []BagItem) error {
+	seen := make(map[int64]bool, len(bag))
+	var total int32
+	for i, b := range bag {
+		field := fmt.Sprintf("bag[%d]", i)
+		if b.Quantity < 1 {
+			verr.Add(field+".quantity", "must be at least 1, got %d", b.Quantity)
+		}
+		total += b.Quantity
+		if seen[b.ItemID] {
+			verr.Add(field+".item_id", "item %d is already in the bag", b.ItemID)
+			continue
+		}
+		seen[b.ItemID] = true
+		if err := checkItem(ctx, q, verr, field+".item_id", b.ItemID, game.ItemConsumable); err != nil {
+			return err
+		}
+	}
+	if total > int32(s.rules.MaxBagSize) {
+		verr.Add("bag", "must hold at most %d items, got %d", s.rules.MaxBagSize, total)
+	}
+	return nil
+}
+
+// checkItem records in verr if itemID is not a live item of kind.
+func checkItem(ctx context.Context, q store.Querier, verr *validation.Error, field string, itemID int64, kind string) error {
+	item, err := q.GetItemByID(ctx, itemID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && item.DeletedAt.Valid) {
+		verr.Add(field, "item %d does not exist", itemID)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("get item %d: %w", itemID, err)
+	}
+	if item.Kind != kind {
+		verr.Add(field, "item %d is not a %s item", itemID, kind)
+	}
+	return nil
+}
+
+// createUnits writes a squad's units in order with the items they hold.
+func createUnits(ctx context.Context, qtx store.Querier, squadID int64, unitIDs, heldItemIDs []int64) error {
+	for pos, unitID := range unitIDs {
+		var held sql.NullInt64
+		if heldItemIDs != nil && heldItemIDs[pos] != 0 {
+			held = sql.NullInt64{Int64: heldItemIDs[pos], Valid: true}
+		}
+		_, err := qtx.CreateSquadUnit(ctx, store.CreateSquadUnitParams{
+			SquadID:    squadID,
+			UnitID:     unitID,
+			Position:   int32(pos),
+			HeldItemID: held,
+		})
+		if err != nil {
+			return fmt.Errorf("create squad unit: %w", err)
+		}
+	}
+	return nil
+}
+
+func createBag(ctx context.Context, qtx store.Querier, squadID int64, bag []BagItem) error {
+	for _, b := range bag {
+		_, err := qtx.CreateSquadBagItem(ctx, store.CreateSquadBagItemParams{
+			SquadID:  squadID,
+			ItemID:   b.ItemID,
+			Quantity: b.Quantity,
+		})
+		if err != nil {
+			return fmt.Errorf("create squad bag item: %w", err)
+		}
+	}
+	return nil
+}
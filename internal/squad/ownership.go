@@ -0,0 +1,60 @@
+package squad
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/76dillon/battle_squads/internal/store"
+	"github.com/76dillon/battle_squads/internal/validation"
+)
+
+// SquadChoice is a squad a player wants to field in a match.
+type SquadChoice struct {
+	// Field is the request field the squad ID came from, used in problems
+	Field    string
+	SquadID  int64
+	PlayerID int64
+}
+
+// CheckMatchSquads verifies that every chosen squad exists, belongs to the
+// player fielding it and has between minSize and maxSize units, the limits
+// of the match's ruleset (which may differ from the service's own). All
+// problems are returned together.
+func (s *Service) CheckMatchSquads(ctx context.Context, minSize, maxSize int, choices ...SquadChoice) error {
+	return CheckMatchSquads(ctx, s.store, minSize, maxSize, choices...)
+}
+
+// CheckMatchSquads is Service.CheckMatchSquads over q, for checking squads
+// inside the transaction that fields them. Each squad is locked for share,
+// so Update and Delete, which lock it for update, wait for that transaction
+// to finish and then see the match.
+func CheckMatchSquads(ctx context.Context, q store.Querier, minSize, maxSize int, choices ...SquadChoice) error {
+	verr := validation.New("squad")
+
+	for _, c := range choices {
+		sq, err := q.GetSquadByIDForShare(ctx, c.SquadID)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && sq.DeletedAt.Valid) {
+			verr.Add(c.Field, "squad %d does not exist", c.SquadID)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("get squad %d: %w", c.SquadID, err)
+		}
+		if sq.PlayerID != c.PlayerID {
+			verr.Add(c.Field, "squad %d does not belong to player %d", c.SquadID, c.PlayerID)
+			continue
+		}
+
+		sus, err := q.GetSquadUnits(ctx, sq.ID)
+		if err != nil {
+			return fmt.Errorf("get squad units %d: %w", sq.ID, err)
+		}
+		if len(sus) < minSize || len(sus) > maxSize {
+			verr.Add(c.Field, "squad %d has %d units, must have between %d and %d", sq.ID, len(sus), minSize, maxSize)
+		}
+	}
+
+	return verr.Err()
+}
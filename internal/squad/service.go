@@ -0,0 +1,47 @@
+package squad
+
+import (
+	"github.com/76dillon/battle_squads/internal/store"
+)
+
+// Rules are the limits every squad must satisfy.
+type Rules struct {
+	MinSize int
+	MaxSize int
+	// AllowDuplicateUnits lets a squad field the same unit more than once
+	AllowDuplicateUnits bool
+	// MaxNameLength is in characters
+	MaxNameLength int
+	// MaxBagSize is the most consumable items a squad's bag can hold, counting
+	// every copy
+	MaxBagSize int
+}
+
+// DefaultRules matches the game as documented: one to three distinct units
+// and up to four items in the bag.
+func DefaultRules() Rules {
+	return Rules{
+		MinSize:             1,
+		MaxSize:             3,
+		AllowDuplicateUnits: false,
+		MaxNameLength:       50,
+		MaxBagSize:          4,
+	}
+}
+
+type Service struct {
+	store store.Store
+	rules Rules
+}
+
+func NewService(st store.Store, rules Rules) *Service {
+	return &Service{
+		store: st,
+		rules: rules,
+	}
+}
+
+// Rules returns the limits the service enforces.
+func (s *Service) Rules() Rules {
+	return s.rules
+}
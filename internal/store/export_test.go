@@ -0,0 +1,4 @@
+package store
+
+// SQLiteQuery exposes the SQLite query rewrite to the tests.
+var SQLiteQuery = sqliteQuery
@@ -7,6 +7,7 @@ package store
 
 import (
 	"context"
+	"database/sql"
 )
 
 const createMatchSide = `-- name: CreateMatchSide :one
@@ -18,7 +19,7 @@ INSERT INTO match_sides (
 ) VALUES (
     $1, $2, $3, 0
 )
-RETURNING id, match_id, player_id, squad_id, active_index
+RETURNING id, match_id, player_id, squad_id, active_index, pending_move_id, pending_switch_to, pending_item_id
 `
 
 type CreateMatchSideParams struct {
@@ -36,13 +37,16 @@ func (q *Queries) CreateMatchSide(ctx context.Context, arg CreateMatchSideParams
 		&i.PlayerID,
 		&i.SquadID,
 		&i.ActiveIndex,
+		&i.PendingMoveID,
+		&i.PendingSwitchTo,
+		&i.PendingItemID,
 	)
 	return i, err
 }
 
 const getMatchSidesByMatchID = `-- name: GetMatchSidesByMatchID :many
 SELECT
-    id, match_id, player_id, squad_id, active_index
+    id, match_id, player_id, squad_id, active_index, pending_move_id, pending_switch_to, pending_item_id
 FROM match_sides
 WHERE match_id = $1
 `
@@ -62,6 +66,9 @@ func (q *Queries) GetMatchSidesByMatchID(ctx context.Context, matchID int64) ([]
 			&i.PlayerID,
 			&i.SquadID,
 			&i.ActiveIndex,
+			&i.PendingMoveID,
+			&i.PendingSwitchTo,
+			&i.PendingItemID,
 		); err != nil {
 			return nil, err
 		}
@@ -76,11 +83,39 @@ func (q *Queries) GetMatchSidesByMatchID(ctx context.Context, matchID int64) ([]
 	return items, nil
 }
 
+const setMatchSidePendingAction = `-- name: SetMatchSidePendingAction :exec
+UPDATE match_sides
+SET
+    pending_move_id = $2,
+    pending_switch_to = $3,
+    pending_item_id = $4
+WHERE id = $1
+`
+
+type SetMatchSidePendingActionParams struct {
+	ID              int64
+	PendingMoveID   sql.NullInt64
+	PendingSwitchTo sql.NullInt32
+	PendingItemID   sql.NullInt64
+}
+
+// Records (or, with all NULL, clears) a side's action for the current
+// round of a simultaneous match
+func (q *Queries) SetMatchSidePendingAction(ctx context.Context, arg SetMatchSidePendingActionParams) error {
+	_, err := q.db.ExecContext(ctx, setMatchSidePendingAction,
+		arg.ID,
+		arg.PendingMoveID,
+		arg.PendingSwitchTo,
+		arg.PendingItemID,
+	)
+	return err
+}
+
 const updateMatchSideActiveIndex = `-- name: UpdateMatchSideActiveIndex :one
 UPDATE match_sides
 SET active_index = $2
 WHERE id = $1
-RETURNING id, match_id, player_id, squad_id, active_index
+RETURNING id, match_id, player_id, squad_id, active_index, pending_move_id, pending_switch_to, pending_item_id
 `
 
 type UpdateMatchSideActiveIndexParams struct {
@@ -97,6 +132,9 @@ func (q *Queries) UpdateMatchSideActiveIndex(ctx context.Context, arg UpdateMatc
 		&i.PlayerID,
 		&i.SquadID,
 		&i.ActiveIndex,
+		&i.PendingMoveID,
+		&i.PendingSwitchTo,
+		&i.PendingItemID,
 	)
 	return i, err
 }
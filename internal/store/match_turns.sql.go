@@ -7,6 +7,7 @@ package store
 
 import (
 	"context"
+	"database/sql"
 )
 
 const createMatchTurn = `-- name: CreateMatchTurn :one
@@ -19,9 +20,13 @@ INSERT INTO match_turns (
     target_match_unit_id,
     damage_done,
     target_hp_after,
-    did_ko_target
+    did_ko_target,
+    action,
+    item_id,
+    ability,
+    missed
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
 )
 RETURNING
     id,
@@ -34,7 +39,11 @@ RETURNING
     damage_done,
     target_hp_after,
     did_ko_target,
-    created_at
+    created_at,
+    action,
+    item_id,
+    ability,
+    missed
 `
 
 type CreateMatchTurnParams struct {
@@ -42,11 +51,15 @@ type CreateMatchTurnParams struct {
 	TurnNumber        int32
 	ActingPlayerID    int64
 	ActingMatchUnitID int64
-	MoveID            int64
+	MoveID            sql.NullInt64
 	TargetMatchUnitID int64
 	DamageDone        int32
 	TargetHpAfter     int32
 	DidKoTarget       bool
+	Action            string
+	ItemID            sql.NullInt64
+	Ability           sql.NullString
+	Missed            bool
 }
 
 func (q *Queries) CreateMatchTurn(ctx context.Context, arg CreateMatchTurnParams) (MatchTurn, error) {
@@ -60,6 +73,10 @@ func (q *Queries) CreateMatchTurn(ctx context.Context, arg CreateMatchTurnParams
 		arg.DamageDone,
 		arg.TargetHpAfter,
 		arg.DidKoTarget,
+		arg.Action,
+		arg.ItemID,
+		arg.Ability,
+		arg.Missed,
 	)
 	var i MatchTurn
 	err := row.Scan(
@@ -74,6 +91,10 @@ func (q *Queries) CreateMatchTurn(ctx context.Context, arg CreateMatchTurnParams
 		&i.TargetHpAfter,
 		&i.DidKoTarget,
 		&i.CreatedAt,
+		&i.Action,
+		&i.ItemID,
+		&i.Ability,
+		&i.Missed,
 	)
 	return i, err
 }
@@ -82,10 +103,10 @@ const listMatchTurns = `-- name: ListMatchTurns :many
 SELECT
   id, match_id, turn_number, acting_player_id,
   acting_match_unit_id, move_id, target_match_unit_id,
-  damage_done, target_hp_after, did_ko_target, created_at
+  damage_done, target_hp_after, did_ko_target, created_at, action, item_id, ability, missed
 FROM match_turns
 WHERE match_id = $1
-ORDER BY turn_number
+ORDER BY turn_number, id
 `
 
 func (q *Queries) ListMatchTurns(ctx context.Context, matchID int64) ([]MatchTurn, error) {
@@ -109,6 +130,10 @@ func (q *Queries) ListMatchTurns(ctx context.Context, matchID int64) ([]MatchTur
 			&i.TargetHpAfter,
 			&i.DidKoTarget,
 			&i.CreatedAt,
+			&i.Action,
+			&i.ItemID,
+			&i.Ability,
+			&i.Missed,
 		); err != nil {
 			return nil, err
 		}
@@ -7,6 +7,7 @@ package store
 
 import (
 	"context"
+	"database/sql"
 )
 
 const createMatchUnit = `-- name: CreateMatchUnit :one
@@ -14,11 +15,13 @@ INSERT INTO match_units (
     match_side_id,
     unit_id,
     position,
-    current_hp
+    current_hp,
+    revealed,
+    held_item_id
 ) VALUES (
-    $1, $2, $3, $4
+    $1, $2, $3, $4, $5, $6
 )
-RETURNING id, match_side_id, unit_id, position, current_hp
+RETURNING id, match_side_id, unit_id, position, current_hp, revealed, held_item_id, attack_bonus, speed_bonus
 `
 
 type CreateMatchUnitParams struct {
@@ -26,6 +29,8 @@ type CreateMatchUnitParams struct {
 	UnitID      int64
 	Position    int32
 	CurrentHp   int32
+	Revealed    bool
+	HeldItemID  sql.NullInt64
 }
 
 func (q *Queries) CreateMatchUnit(ctx context.Context, arg CreateMatchUnitParams) (MatchUnit, error) {
@@ -34,6 +39,8 @@ func (q *Queries) CreateMatchUnit(ctx context.Context, arg CreateMatchUnitParams
 		arg.UnitID,
 		arg.Position,
 		arg.CurrentHp,
+		arg.Revealed,
+		arg.HeldItemID,
 	)
 	var i MatchUnit
 	err := row.Scan(
@@ -42,12 +49,17 @@ func (q *Queries) CreateMatchUnit(ctx context.Context, arg CreateMatchUnitParams
 		&i.UnitID,
 		&i.Position,
 		&i.CurrentHp,
+		&i.Revealed,
+		&i.HeldItemID,
+		&i.AttackBonus,
+		&i.SpeedBonus,
 	)
 	return i, err
 }
 
 const getActiveMatchUnitForSide = `-- name: GetActiveMatchUnitForSide :one
-SELECT mu.id, mu.match_side_id, mu.unit_id, mu.position, mu.current_hp
+SELECT mu.id, mu.match_side_id, mu.unit_id, mu.position, mu.current_hp, mu.revealed, mu.held_item_id,
+    mu.attack_bonus, mu.speed_bonus
 FROM match_units mu
 JOIN match_sides ms ON ms.id = mu.match_side_id
 WHERE mu.match_side_id = $1
@@ -63,13 +75,18 @@ func (q *Queries) GetActiveMatchUnitForSide(ctx context.Context, matchSideID int
 		&i.UnitID,
 		&i.Position,
 		&i.CurrentHp,
+		&i.Revealed,
+		&i.HeldItemID,
+		&i.AttackBonus,
+		&i.SpeedBonus,
 	)
 	return i, err
 }
 
 const getMatchUnitsBySideID = `-- name: GetMatchUnitsBySideID :many
 SELECT
-    id, match_side_id, unit_id, position, current_hp
+    id, match_side_id, unit_id, position, current_hp, revealed, held_item_id,
+    attack_bonus, speed_bonus
 FROM match_units
 WHERE match_side_id = $1
 ORDER BY position
@@ -90,6 +107,10 @@ func (q *Queries) GetMatchUnitsBySideID(ctx context.Context, matchSideID int64)
 			&i.UnitID,
 			&i.Position,
 			&i.CurrentHp,
+			&i.Revealed,
+			&i.HeldItemID,
+			&i.AttackBonus,
+			&i.SpeedBonus,
 		); err != nil {
 			return nil, err
 		}
@@ -104,11 +125,131 @@ func (q *Queries) GetMatchUnitsBySideID(ctx context.Context, matchSideID int64)
 	return items, nil
 }
 
+const listMatchUnitDetails = `-- name: ListMatchUnitDetails :many
+SELECT
+    mu.id, mu.match_side_id, mu.unit_id, mu.position, mu.current_hp, mu.revealed,
+    u.name AS unit_name, u.base_hp, u.type_id, ut.name AS type_name,
+    mu.held_item_id, i.name AS held_item_name,
+    u.ability, mu.attack_bonus, mu.speed_bonus
+FROM match_units mu
+JOIN match_sides ms ON ms.id = mu.match_side_id
+JOIN units u ON u.id = mu.unit_id
+JOIN unit_types ut ON ut.id = u.type_id
+LEFT JOIN items i ON i.id = mu.held_item_id
+WHERE ms.match_id = $1
+ORDER BY mu.match_side_id, mu.position
+`
+
+type ListMatchUnitDetailsRow struct {
+	ID           int64
+	MatchSideID  int64
+	UnitID       int64
+	Position     int32
+	CurrentHp    int32
+	Revealed     bool
+	UnitName     string
+	BaseHp       int32
+	TypeID       int64
+	TypeName     string
+	HeldItemID   sql.NullInt64
+	HeldItemName sql.NullString
+	Ability      string
+	AttackBonus  int32
+	SpeedBonus   int32
+}
+
+// Every unit in a match with its catalog name, type and ability, and its
+// held item if any, for match views.
+func (q *Queries) ListMatchUnitDetails(ctx context.Context, matchID int64) ([]ListMatchUnitDetailsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listMatchUnitDetails, matchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListMatchUnitDetailsRow
+	for rows.Next() {
+		var i ListMatchUnitDetailsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.MatchSideID,
+			&i.UnitID,
+			&i.Position,
+			&i.CurrentHp,
+			&i.Revealed,
+			&i.UnitName,
+			&i.BaseHp,
+			&i.TypeID,
+			&i.TypeName,
+			&i.HeldItemID,
+			&i.HeldItemName,
+			&i.Ability,
+			&i.AttackBonus,
+			&i.SpeedBonus,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const revealMatchUnit = `-- name: RevealMatchUnit :exec
+UPDATE match_units
+SET revealed = TRUE
+WHERE match_side_id = $1 AND position = $2
+`
+
+type RevealMatchUnitParams struct {
+	MatchSideID int64
+	Position    int32
+}
+
+func (q *Queries) RevealMatchUnit(ctx context.Context, arg RevealMatchUnitParams) error {
+	_, err := q.db.ExecContext(ctx, revealMatchUnit, arg.MatchSideID, arg.Position)
+	return err
+}
+
+const updateMatchUnitBonuses = `-- name: UpdateMatchUnitBonuses :one
+UPDATE match_units
+SET attack_bonus = $2, speed_bonus = $3
+WHERE id = $1
+RETURNING id, match_side_id, unit_id, position, current_hp, revealed, held_item_id, attack_bonus, speed_bonus
+`
+
+type UpdateMatchUnitBonusesParams struct {
+	ID          int64
+	AttackBonus int32
+	SpeedBonus  int32
+}
+
+func (q *Queries) UpdateMatchUnitBonuses(ctx context.Context, arg UpdateMatchUnitBonusesParams) (MatchUnit, error) {
+	row := q.db.QueryRowContext(ctx, updateMatchUnitBonuses, arg.ID, arg.AttackBonus, arg.SpeedBonus)
+	var i MatchUnit
+	err := row.Scan(
+		&i.ID,
+		&i.MatchSideID,
+		&i.UnitID,
+		&i.Position,
+		&i.CurrentHp,
+		&i.Revealed,
+		&i.HeldItemID,
+		&i.AttackBonus,
+		&i.SpeedBonus,
+	)
+	return i, err
+}
+
 const updateMatchUnitHP = `-- name: UpdateMatchUnitHP :one
 UPDATE match_units
 SET current_hp = $2
 WHERE id = $1
-RETURNING id, match_side_id, unit_id, position, current_hp
+RETURNING id, match_side_id, unit_id, position, current_hp, revealed, held_item_id, attack_bonus, speed_bonus
 `
 
 type UpdateMatchUnitHPParams struct {
@@ -125,6 +266,10 @@ func (q *Queries) UpdateMatchUnitHP(ctx context.Context, arg UpdateMatchUnitHPPa
 		&i.UnitID,
 		&i.Position,
 		&i.CurrentHp,
+		&i.Revealed,
+		&i.HeldItemID,
+		&i.AttackBonus,
+		&i.SpeedBonus,
 	)
 	return i, err
 }
@@ -14,7 +14,7 @@ const completeMatch = `-- name: CompleteMatch :one
 UPDATE matches
 SET
   state = 'COMPLETED',
-  completed_at = now(),
+  completed_at = CURRENT_TIMESTAMP,
   winner_player_id = $2,
   current_actor_player_id = NULL
 WHERE id = $1
@@ -28,7 +28,10 @@ RETURNING
   player2_id,
   winner_player_id,
   current_turn_number,
-  current_actor_player_id
+  current_actor_player_id,
+  is_public,
+  ruleset_id,
+  turn_started_at
 `
 
 type CompleteMatchParams struct {
@@ -50,6 +53,9 @@ func (q *Queries) CompleteMatch(ctx context.Context, arg CompleteMatchParams) (M
 		&i.WinnerPlayerID,
 		&i.CurrentTurnNumber,
 		&i.CurrentActorPlayerID,
+		&i.IsPublic,
+		&i.RulesetID,
+		&i.TurnStartedAt,
 	)
 	return i, err
 }
@@ -60,13 +66,17 @@ INSERT INTO matches (
     player1_id,
     player2_id,
     current_turn_number,
-    current_actor_player_id
+    current_actor_player_id,
+    is_public,
+    ruleset_id
 ) VALUES (
     'PENDING',
     $1,                -- player1_id
     $2,                -- player2_id
     0,
-    NULL
+    NULL,
+    $3,                -- is_public
+    $4                 -- ruleset_id
 )
 RETURNING
     id,
@@ -78,16 +88,26 @@ RETURNING
     player2_id,
     winner_player_id,
     current_turn_number,
-    current_actor_player_id
+    current_actor_player_id,
+    is_public,
+    ruleset_id,
+    turn_started_at
 `
 
 type CreateMatchParams struct {
 	Player1ID int64
 	Player2ID int64
+	IsPublic  bool
+	RulesetID int64
 }
 
 func (q *Queries) CreateMatch(ctx context.Context, arg CreateMatchParams) (Match, error) {
-	row := q.db.QueryRowContext(ctx, createMatch, arg.Player1ID, arg.Player2ID)
+	row := q.db.QueryRowContext(ctx, createMatch,
+		arg.Player1ID,
+		arg.Player2ID,
+		arg.IsPublic,
+		arg.RulesetID,
+	)
 	var i Match
 	err := row.Scan(
 		&i.ID,
@@ -100,6 +120,9 @@ func (q *Queries) CreateMatch(ctx context.Context, arg CreateMatchParams) (Match
 		&i.WinnerPlayerID,
 		&i.CurrentTurnNumber,
 		&i.CurrentActorPlayerID,
+		&i.IsPublic,
+		&i.RulesetID,
+		&i.TurnStartedAt,
 	)
 	return i, err
 }
@@ -115,7 +138,10 @@ SELECT
     player2_id,
     winner_player_id,
     current_turn_number,
-    current_actor_player_id
+    current_actor_player_id,
+    is_public,
+    ruleset_id,
+    turn_started_at
 FROM matches
 WHERE id = $1
 `
@@ -134,10 +160,120 @@ func (q *Queries) GetMatchByID(ctx context.Context, id int64) (Match, error) {
 		&i.WinnerPlayerID,
 		&i.CurrentTurnNumber,
 		&i.CurrentActorPlayerID,
+		&i.IsPublic,
+		&i.RulesetID,
+		&i.TurnStartedAt,
 	)
 	return i, err
 }
 
+const getMatchByIDForUpdate = `-- name: GetMatchByIDForUpdate :one
+SELECT
+    id,
+    state,
+    created_at,
+    started_at,
+    completed_at,
+    player1_id,
+    player2_id,
+    winner_player_id,
+    current_turn_number,
+    current_actor_player_id,
+    is_public,
+    ruleset_id,
+    turn_started_at
+FROM matches
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetMatchByIDForUpdate(ctx context.Context, id int64) (Match, error) {
+	row := q.db.QueryRowContext(ctx, getMatchByIDForUpdate, id)
+	var i Match
+	err := row.Scan(
+		&i.ID,
+		&i.State,
+		&i.CreatedAt,
+		&i.StartedAt,
+		&i.CompletedAt,
+		&i.Player1ID,
+		&i.Player2ID,
+		&i.WinnerPlayerID,
+		&i.CurrentTurnNumber,
+		&i.CurrentActorPlayerID,
+		&i.IsPublic,
+		&i.RulesetID,
+		&i.TurnStartedAt,
+	)
+	return i, err
+}
+
+const listLiveMatches = `-- name: ListLiveMatches :many
+SELECT
+    m.id,
+    m.started_at,
+    m.current_turn_number,
+    m.player1_id,
+    p1.username AS player1_username,
+    m.player2_id,
+    p2.username AS player2_username
+FROM matches m
+JOIN players p1 ON p1.id = m.player1_id
+JOIN players p2 ON p2.id = m.player2_id
+WHERE m.state = 'IN_PROGRESS'
+  AND m.is_public
+  AND (m.id < $1 OR $1 IS NULL)
+ORDER BY m.id DESC
+LIMIT $2
+`
+
+type ListLiveMatchesParams struct {
+	BeforeID sql.NullInt64
+	RowLimit int32
+}
+
+type ListLiveMatchesRow struct {
+	ID                int64
+	StartedAt         sql.NullTime
+	CurrentTurnNumber int32
+	Player1ID         int64
+	Player1Username   string
+	Player2ID         int64
+	Player2Username   string
+}
+
+// In-progress public matches with both players' names, newest first
+func (q *Queries) ListLiveMatches(ctx context.Context, arg ListLiveMatchesParams) ([]ListLiveMatchesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listLiveMatches, arg.BeforeID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListLiveMatchesRow
+	for rows.Next() {
+		var i ListLiveMatchesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.StartedAt,
+			&i.CurrentTurnNumber,
+			&i.Player1ID,
+			&i.Player1Username,
+			&i.Player2ID,
+			&i.Player2Username,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listMatchesForPlayer = `-- name: ListMatchesForPlayer :many
 SELECT
     id,
@@ -149,14 +285,47 @@ SELECT
     player2_id,
     winner_player_id,
     current_turn_number,
-    current_actor_player_id
+    current_actor_player_id,
+    is_public,
+    ruleset_id,
+    turn_started_at
 FROM matches
-WHERE player1_id = $1 OR player2_id = $1
-ORDER BY created_at DESC
+WHERE (player1_id = $1 OR player2_id = $1)
+  -- Optional filters are NULL when unused. Each parameter is compared with
+  -- a column before its IS NULL test so Postgres can infer its type.
+  AND (state = $2 OR $2 IS NULL)
+  AND (player1_id = $3 OR player2_id = $3 OR $3 IS NULL)
+  AND (created_at >= $4 OR $4 IS NULL)
+  AND (created_at < $5 OR $5 IS NULL)
+  AND (current_actor_player_id = $6 OR $6 IS NULL)
+  -- Keyset pagination: the cursor is the last ID of the previous page
+  AND (id < $7 OR $7 IS NULL)
+ORDER BY id DESC
+LIMIT $8
 `
 
-func (q *Queries) ListMatchesForPlayer(ctx context.Context, player1ID int64) ([]Match, error) {
-	rows, err := q.db.QueryContext(ctx, listMatchesForPlayer, player1ID)
+type ListMatchesForPlayerParams struct {
+	PlayerID    int64
+	State       sql.NullString
+	OpponentID  sql.NullInt64
+	CreatedFrom sql.NullTime
+	CreatedTo   sql.NullTime
+	ActorID     sql.NullInt64
+	BeforeID    sql.NullInt64
+	RowLimit    int32
+}
+
+func (q *Queries) ListMatchesForPlayer(ctx context.Context, arg ListMatchesForPlayerParams) ([]Match, error) {
+	rows, err := q.db.QueryContext(ctx, listMatchesForPlayer,
+		arg.PlayerID,
+		arg.State,
+		arg.OpponentID,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.ActorID,
+		arg.BeforeID,
+		arg.RowLimit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -175,6 +344,9 @@ func (q *Queries) ListMatchesForPlayer(ctx context.Context, player1ID int64) ([]
 			&i.WinnerPlayerID,
 			&i.CurrentTurnNumber,
 			&i.CurrentActorPlayerID,
+			&i.IsPublic,
+			&i.RulesetID,
+			&i.TurnStartedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -189,18 +361,66 @@ func (q *Queries) ListMatchesForPlayer(ctx context.Context, player1ID int64) ([]
 	return items, nil
 }
 
+const setMatchPublic = `-- name: SetMatchPublic :one
+UPDATE matches
+SET is_public = $2
+WHERE id = $1
+RETURNING
+  id,
+  state,
+  created_at,
+  started_at,
+  completed_at,
+  player1_id,
+  player2_id,
+  winner_player_id,
+  current_turn_number,
+  current_actor_player_id,
+  is_public,
+  ruleset_id,
+  turn_started_at
+`
+
+type SetMatchPublicParams struct {
+	ID       int64
+	IsPublic bool
+}
+
+func (q *Queries) SetMatchPublic(ctx context.Context, arg SetMatchPublicParams) (Match, error) {
+	row := q.db.QueryRowContext(ctx, setMatchPublic, arg.ID, arg.IsPublic)
+	var i Match
+	err := row.Scan(
+		&i.ID,
+		&i.State,
+		&i.CreatedAt,
+		&i.StartedAt,
+		&i.CompletedAt,
+		&i.Player1ID,
+		&i.Player2ID,
+		&i.WinnerPlayerID,
+		&i.CurrentTurnNumber,
+		&i.CurrentActorPlayerID,
+		&i.IsPublic,
+		&i.RulesetID,
+		&i.TurnStartedAt,
+	)
+	return i, err
+}
+
 const startMatch = `-- name: StartMatch :one
 UPDATE matches
 SET
   state = 'IN_PROGRESS',
-  started_at = now(),
+  started_at = CURRENT_TIMESTAMP,
   current_turn_number = 1,
-  current_actor_player_id = $2  -- initial actor
+  current_actor_player_id = $2, -- initial actor
+  turn_started_at = CURRENT_TIMESTAMP
 WHERE id = $1
 RETURNING
   id, state, created_at, started_at, completed_at,
   player1_id, player2_id, winner_player_id,
-  current_turn_number, current_actor_player_id
+  current_turn_number, current_actor_player_id, is_public,
+  ruleset_id, turn_started_at
 `
 
 type StartMatchParams struct {
@@ -222,6 +442,9 @@ func (q *Queries) StartMatch(ctx context.Context, arg StartMatchParams) (Match,
 		&i.WinnerPlayerID,
 		&i.CurrentTurnNumber,
 		&i.CurrentActorPlayerID,
+		&i.IsPublic,
+		&i.RulesetID,
+		&i.TurnStartedAt,
 	)
 	return i, err
 }
@@ -230,7 +453,8 @@ const updateMatchTurnAndActor = `-- name: UpdateMatchTurnAndActor :one
 UPDATE matches
 SET
   current_turn_number = $2,
-  current_actor_player_id = $3
+  current_actor_player_id = $3,
+  turn_started_at = CURRENT_TIMESTAMP
 WHERE id = $1
 RETURNING 
   id,
@@ -242,7 +466,10 @@ RETURNING
   player2_id,
   winner_player_id,
   current_turn_number,
-  current_actor_player_id
+  current_actor_player_id,
+  is_public,
+  ruleset_id,
+  turn_started_at
 `
 
 type UpdateMatchTurnAndActorParams struct {
@@ -265,6 +492,9 @@ func (q *Queries) UpdateMatchTurnAndActor(ctx context.Context, arg UpdateMatchTu
 		&i.WinnerPlayerID,
 		&i.CurrentTurnNumber,
 		&i.CurrentActorPlayerID,
+		&i.IsPublic,
+		&i.RulesetID,
+		&i.TurnStartedAt,
 	)
 	return i, err
 }
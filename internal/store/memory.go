@@ -0,0 +1,2761 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryStore is a Store that keeps everything in process memory. It mirrors
+// the constraints of the Postgres schema closely enough for tests and demos,
+// but nothing survives a restart. The conformance suite in storetest and the
+// HTTP handler tests run against it as well as the SQL stores.
+//
+// Transactions are serialized: ExecTx holds the store lock for the whole
+// callback and restores a snapshot if the callback fails.
+type MemoryStore struct {
+	*memQueries
+	mu sync.Mutex
+}
+
+// NewMemoryStore returns an empty MemoryStore seeded like the migrations,
+// i.e. with the dev admin player (id 1) and the Standard ruleset (id 1).
+func NewMemoryStore() *MemoryStore {
+	m := &MemoryStore{}
+	m.memQueries = &memQueries{data: newMemData(), mu: &m.mu}
+	m.data.players = append(m.data.players, Player{
+		ID:           1,
+		Username:     "devadmin",
+		PasswordHash: "devpassword",
+		CreatedAt:    time.Now(),
+		IsAdmin:      true,
+		Rating:       1000,
+	})
+	m.data.seq["players"] = 1
+	m.data.rulesets = append(m.data.rulesets, Ruleset{
+		ID:                       1,
+		Name:                     "Standard",
+		MinSquadSize:             1,
+		MaxSquadSize:             3,
+		LevelCap:                 50,
+		DamageModel:              "standard",
+		TurnMode:                 "alternating",
+		SwitchingEnabled:         true,
+		ItemsEnabled:             true,
+		TypeEffectivenessEnabled: true,
+		CreatedAt:                time.Now(),
+		UpdatedAt:                time.Now(),
+	})
+	m.data.seq["rulesets"] = 1
+	return m
+}
+
+func (m *MemoryStore) ExecTx(ctx context.Context, fn func(q Querier) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := m.data.clone()
+	if err := fn(&memQueries{data: m.data}); err != nil {
+		*m.data = *snapshot
+		return err
+	}
+	return nil
+}
+
+// memData holds one slice per table, in insertion (and therefore id) order.
+type memData struct {
+	seq map[string]int64
+
+	adminAuditLog     []AdminAuditLog
+	challenges        []Challenge
+	friendships       []Friendship
+	idempotencyKeys   []IdempotencyKey
+	items             []Item
+	matches           []Match
+	matchMessages     []MatchMessage
+	messageReports    []MatchMessageReport
+	matchSides        []MatchSide
+	matchSideItems    []MatchSideItem
+	matchTurns        []MatchTurn
+	matchUnits        []MatchUnit
+	moves             []Move
+	players           []Player
+	playerDailyStats  []PlayerDailyStat
+	playerMoveStats   []PlayerMoveStat
+	playerUnitStats   []PlayerUnitStat
+	rulesets          []Ruleset
+	squads            []Squad
+	squadBagItems     []SquadBagItem
+	squadUnits        []SquadUnit
+	tournaments       []Tournament
+	tournamentEntries []TournamentEntry
+	tournamentMatches []TournamentMatch
+	typeEffectiveness []TypeEffectiveness
+	units             []Unit
+	unitMoves         []UnitMove
+	unitTypes         []UnitType
+}
+
+func newMemData() *memData {
+	return &memData{seq: make(map[string]int64)}
+}
+
+func (d *memData) clone() *memData {
+	c := &memData{seq: make(map[string]int64, len(d.seq))}
+	for k, v := range d.seq {
+		c.seq[k] = v
+	}
+	c.adminAuditLog = append([]AdminAuditLog(nil), d.adminAuditLog...)
+	c.challenges = append([]Challenge(nil), d.challenges...)
+	c.friendships = append([]Friendship(nil), d.friendships...)
+	c.idempotencyKeys = append([]IdempotencyKey(nil), d.idempotencyKeys...)
+	c.items = append([]Item(nil), d.items...)
+	c.matches = append([]Match(nil), d.matches...)
+	c.matchMessages = append([]MatchMessage(nil), d.matchMessages...)
+	c.messageReports = append([]MatchMessageReport(nil), d.messageReports...)
+	c.matchSides = append([]MatchSide(nil), d.matchSides...)
+	c.matchSideItems = append([]MatchSideItem(nil), d.matchSideItems...)
+	c.matchTurns = append([]MatchTurn(nil), d.matchTurns...)
+	c.matchUnits = append([]MatchUnit(nil), d.matchUnits...)
+	c.moves = append([]Move(nil), d.moves...)
+	c.players = append([]Player(nil), d.players...)
+	c.playerDailyStats = append([]PlayerDailyStat(nil), d.playerDailyStats...)
+	c.playerMoveStats = append([]PlayerMoveStat(nil), d.playerMoveStats...)
+	c.playerUnitStats = append([]PlayerUnitStat(nil), d.playerUnitStats...)
+	c.rulesets = append([]Ruleset(nil), d.rulesets...)
+	c.squads = append([]Squad(nil), d.squads...)
+	c.squadBagItems = append([]SquadBagItem(nil), d.squadBagItems...)
+	c.squadUnits = append([]SquadUnit(nil), d.squadUnits...)
+	c.tournaments = append([]Tournament(nil), d.tournaments...)
+	c.tournamentEntries = append([]TournamentEntry(nil), d.tournamentEntries...)
+	c.tournamentMatches = append([]TournamentMatch(nil), d.tournamentMatches...)
+	c.typeEffectiveness = append([]TypeEffectiveness(nil), d.typeEffectiveness...)
+	c.units = append([]Unit(nil), d.units...)
+	c.unitMoves = append([]UnitMove(nil), d.unitMoves...)
+	c.unitTypes = append([]UnitType(nil), d.unitTypes...)
+	return c
+}
+
+// nextID emulates a BIGSERIAL sequence for table.
+func (d *memData) nextID(table string) int64 {
+	d.seq[table]++
+	return d.seq[table]
+}
+
+// memQueries implements Querier on top of memData. mu is nil inside a
+// transaction, where the lock is already held by ExecTx.
+type memQueries struct {
+	data *memData
+	mu   *sync.Mutex
+}
+
+var _ Querier = (*memQueries)(nil)
+
+func (q *memQueries) lock() func() {
+	if q.mu == nil {
+		return func() {}
+	}
+	q.mu.Lock()
+	return q.mu.Unlock
+}
+
+func errUnique(constraint string) error {
+	return fmt.Errorf("duplicate key value violates unique constraint %q", constraint)
+}
+
+func errForeignKey(constraint string) error {
+	return fmt.Errorf("insert or update violates foreign key constraint %q", constraint)
+}
+
+func (d *memData) findChallenge(id int64) (int, bool) {
+	for i := range d.challenges {
+		if d.challenges[i].ID == id {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (d *memData) findFriendship(playerID, friendID int64) (int, bool) {
+	for i := range d.friendships {
+		if d.friendships[i].PlayerID == playerID && d.friendships[i].FriendID == friendID {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (d *memData) findItem(id int64) (int, bool) {
+	for i := range d.items {
+		if d.items[i].ID == id {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (d *memData) findMatch(id int64) (int, bool) {
+	for i := range d.matches {
+		if d.matches[i].ID == id {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (d *memData) findMatchMessage(id int64) (int, bool) {
+	for i := range d.matchMessages {
+		if d.matchMessages[i].ID == id {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (d *memData) findMatchSide(id int64) (int, bool) {
+	for i := range d.matchSides {
+		if d.matchSides[i].ID == id {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (d *memData) findMatchUnit(id int64) (int, bool) {
+	for i := range d.matchUnits {
+		if d.matchUnits[i].ID == id {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (d *memData) findPlayer(id int64) (int, bool) {
+	for i := range d.players {
+		if d.players[i].ID == id {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (d *memData) findUnit(id int64) (int, bool) {
+	for i := range d.units {
+		if d.units[i].ID == id {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (d *memData) findMove(id int64) (int, bool) {
+	for i := range d.moves {
+		if d.moves[i].ID == id {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (d *memData) findRuleset(id int64) (int, bool) {
+	for i := range d.rulesets {
+		if d.rulesets[i].ID == id {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (d *memData) findSquad(id int64) (int, bool) {
+	for i := range d.squads {
+		if d.squads[i].ID == id {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (d *memData) findTournament(id int64) (int, bool) {
+	for i := range d.tournaments {
+		if d.tournaments[i].ID == id {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (d *memData) findTournamentMatch(id int64) (int, bool) {
+	for i := range d.tournamentMatches {
+		if d.tournamentMatches[i].ID == id {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (d *memData) findUnitType(id int64) (int, bool) {
+	for i := range d.unitTypes {
+		if d.unitTypes[i].ID == id {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (d *memData) findIdempotencyKey(playerID int64, key string) (int, bool) {
+	for i := range d.idempotencyKeys {
+		if d.idempotencyKeys[i].PlayerID == playerID && d.idempotencyKeys[i].Key == key {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// Idempotency keys
+
+// Analytics
+
+// inTimeRange reports whether t is in [from, to), where either end may be
+// unset.
+func inTimeRange(t time.Time, from, to sql.NullTime) bool {
+	return (!from.Valid || !t.Before(from.Time)) && (!to.Valid || t.Before(to.Time))
+}
+
+func (q *memQueries) ListMoveAnalytics(ctx context.Context, arg ListMoveAnalyticsParams) ([]ListMoveAnalyticsRow, error) {
+	defer q.lock()()
+	rows := make(map[int64]*ListMoveAnalyticsRow)
+	var items []*ListMoveAnalyticsRow
+	for _, mv := range q.data.moves {
+		if !mv.DeletedAt.Valid {
+			row := &ListMoveAnalyticsRow{ID: mv.ID, Name: mv.Name}
+			rows[mv.ID] = row
+			items = append(items, row)
+		}
+	}
+
+	type use struct{ matchID, playerID, moveID int64 }
+	used := make(map[use]bool)
+	var totalUses int64
+	for _, mt := range q.data.matchTurns {
+		if !mt.MoveID.Valid {
+			continue
+		}
+		used[use{mt.MatchID, mt.ActingPlayerID, mt.MoveID.Int64}] = true
+		if !inTimeRange(mt.CreatedAt, arg.FromTime, arg.ToTime) {
+			continue
+		}
+		totalUses++
+		row, ok := rows[mt.MoveID.Int64]
+		if !ok {
+			continue
+		}
+		row.Uses++
+		if !mt.Missed {
+			row.Hits++
+		}
+		row.DamageDealt += int64(mt.DamageDone)
+		if mt.DidKoTarget {
+			row.Kos++
+		}
+	}
+	for u := range used {
+		mi, ok := q.data.findMatch(u.matchID)
+		if !ok {
+			continue
+		}
+		m := q.data.matches[mi]
+		row, ok := rows[u.moveID]
+		if !ok || m.State != "COMPLETED" || !inTimeRange(m.CompletedAt.Time, arg.FromTime, arg.ToTime) {
+			continue
+		}
+		row.MatchesUsed++
+		if m.WinnerPlayerID.Valid && m.WinnerPlayerID.Int64 == u.playerID {
+			row.Wins++
+		}
+	}
+
+	out := make([]ListMoveAnalyticsRow, 0, len(items))
+	for _, row := range items {
+		row.TotalUses = totalUses
+		out = append(out, *row)
+	}
+	return out, nil
+}
+
+func (q *memQueries) ListUnitAnalytics(ctx context.Context, arg ListUnitAnalyticsParams) ([]ListUnitAnalyticsRow, error) {
+	defer q.lock()()
+	rows := make(map[int64]*ListUnitAnalyticsRow)
+	var items []*ListUnitAnalyticsRow
+	for _, u := range q.data.units {
+		if !u.DeletedAt.Valid {
+			row := &ListUnitAnalyticsRow{ID: u.ID, Name: u.Name}
+			rows[u.ID] = row
+			items = append(items, row)
+		}
+	}
+
+	// Squads picking each unit
+	var totalSquads int64
+	picked := make(map[[2]int64]bool)
+	for _, sq := range q.data.squads {
+		if inTimeRange(sq.CreatedAt, arg.FromTime, arg.ToTime) {
+			totalSquads++
+		}
+	}
+	for _, su := range q.data.squadUnits {
+		si, ok := q.data.findSquad(su.SquadID)
+		if !ok || !inTimeRange(q.data.squads[si].CreatedAt, arg.FromTime, arg.ToTime) || picked[[2]int64{su.SquadID, su.UnitID}] {
+			continue
+		}
+		picked[[2]int64{su.SquadID, su.UnitID}] = true
+		if row, ok := rows[su.UnitID]; ok {
+			row.SquadsPicked++
+		}
+	}
+
+	// Sides fielding each unit in completed matches
+	fielded := make(map[[2]int64]bool)
+	for _, mu := range q.data.matchUnits {
+		si, ok := q.data.findMatchSide(mu.MatchSideID)
+		if !ok || fielded[[2]int64{mu.MatchSideID, mu.UnitID}] {
+			continue
+		}
+		side := q.data.matchSides[si]
+		mi, ok := q.data.findMatch(side.MatchID)
+		if !ok {
+			continue
+		}
+		m := q.data.matches[mi]
+		row, ok := rows[mu.UnitID]
+		if !ok || m.State != "COMPLETED" || !inTimeRange(m.CompletedAt.Time, arg.FromTime, arg.ToTime) {
+			continue
+		}
+		fielded[[2]int64{mu.MatchSideID, mu.UnitID}] = true
+		row.TimesFielded++
+		if m.WinnerPlayerID.Valid && m.WinnerPlayerID.Int64 == side.PlayerID {
+			row.Wins++
+		}
+	}
+
+	// Turns each unit made a move in
+	for _, mt := range q.data.matchTurns {
+		if !mt.MoveID.Valid || !inTimeRange(mt.CreatedAt, arg.FromTime, arg.ToTime) {
+			continue
+		}
+		mi, ok := q.data.findMatchUnit(mt.ActingMatchUnitID)
+		if !ok {
+			continue
+		}
+		row, ok := rows[q.data.matchUnits[mi].UnitID]
+		if !ok {
+			continue
+		}
+		row.Uses++
+		if !mt.Missed {
+			row.Hits++
+		}
+		row.DamageDealt += int64(mt.DamageDone)
+		if mt.DidKoTarget {
+			row.Kos++
+		}
+	}
+
+	out := make([]ListUnitAnalyticsRow, 0, len(items))
+	for _, row := range items {
+		row.TotalSquads = totalSquads
+		out = append(out, *row)
+	}
+	return out, nil
+}
+
+// Admin audit log
+
+func (q *memQueries) CreateAdminAuditEntry(ctx context.Context, arg CreateAdminAuditEntryParams) (AdminAuditLog, error) {
+	defer q.lock()()
+	if _, ok := q.data.findPlayer(arg.AdminPlayerID); !ok {
+		return AdminAuditLog{}, errForeignKey("admin_audit_log_admin_player_id_fkey")
+	}
+	e := AdminAuditLog{
+		ID:            q.data.nextID("admin_audit_log"),
+		AdminPlayerID: arg.AdminPlayerID,
+		Action:        arg.Action,
+		EntityType:    arg.EntityType,
+		EntityID:      arg.EntityID,
+		Details:       arg.Details,
+		CreatedAt:     time.Now(),
+	}
+	q.data.adminAuditLog = append(q.data.adminAuditLog, e)
+	return e, nil
+}
+
+func (q *memQueries) ListAdminAuditEntries(ctx context.Context, limit int32) ([]AdminAuditLog, error) {
+	defer q.lock()()
+	var items []AdminAuditLog
+	for i := len(q.data.adminAuditLog) - 1; i >= 0 && len(items) < int(limit); i-- {
+		items = append(items, q.data.adminAuditLog[i])
+	}
+	return items, nil
+}
+
+func (q *memQueries) CompleteIdempotencyKey(ctx context.Context, arg CompleteIdempotencyKeyParams) error {
+	defer q.lock()()
+	if i, ok := q.data.findIdempotencyKey(arg.PlayerID, arg.Key); ok {
+		k := &q.data.idempotencyKeys[i]
+		k.StatusCode = arg.StatusCode
+		k.ContentType = arg.ContentType
+		k.ResponseBody = append([]byte(nil), arg.ResponseBody...)
+	}
+	return nil
+}
+
+func (q *memQueries) CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error) {
+	defer q.lock()()
+	if _, ok := q.data.findPlayer(arg.PlayerID); !ok {
+		return IdempotencyKey{}, errForeignKey("idempotency_keys_player_id_fkey")
+	}
+	now := time.Now()
+	if i, ok := q.data.findIdempotencyKey(arg.PlayerID, arg.Key); ok {
+		// ON CONFLICT DO UPDATE ... WHERE yields no row when the WHERE fails
+		k := &q.data.idempotencyKeys[i]
+		if k.StatusCode != 0 || k.RequestHash != arg.RequestHash || !k.ClaimedAt.Before(arg.StaleBefore) {
+			return IdempotencyKey{}, sql.ErrNoRows
+		}
+		k.ClaimedAt = now
+		return *k, nil
+	}
+	k := IdempotencyKey{
+		PlayerID:    arg.PlayerID,
+		Key:         arg.Key,
+		Method:      arg.Method,
+		Path:        arg.Path,
+		RequestHash: arg.RequestHash,
+		CreatedAt:   now,
+		ClaimedAt:   now,
+	}
+	q.data.idempotencyKeys = append(q.data.idempotencyKeys, k)
+	return k, nil
+}
+
+func (q *memQueries) DeleteExpiredIdempotencyKeys(ctx context.Context, createdAt time.Time) error {
+	defer q.lock()()
+	kept := q.data.idempotencyKeys[:0:0]
+	for _, k := range q.data.idempotencyKeys {
+		if !k.CreatedAt.Before(createdAt) {
+			kept = append(kept, k)
+		}
+	}
+	q.data.idempotencyKeys = kept
+	return nil
+}
+
+func (q *memQueries) DeleteIdempotencyKey(ctx context.Context, arg DeleteIdempotencyKeyParams) error {
+	defer q.lock()()
+	if i, ok := q.data.findIdempotencyKey(arg.PlayerID, arg.Key); ok {
+		q.data.idempotencyKeys = append(q.data.idempotencyKeys[:i:i], q.data.idempotencyKeys[i+1:]...)
+	}
+	return nil
+}
+
+func (q *memQueries) GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error) {
+	defer q.lock()()
+	i, ok := q.data.findIdempotencyKey(arg.PlayerID, arg.Key)
+	if !ok {
+		return IdempotencyKey{}, sql.ErrNoRows
+	}
+	return q.data.idempotencyKeys[i], nil
+}
+
+// Items
+
+func (q *memQueries) CreateItem(ctx context.Context, arg CreateItemParams) (Item, error) {
+	defer q.lock()()
+	it := Item{
+		ID:     q.data.nextID("items"),
+		Name:   arg.Name,
+		Kind:   arg.Kind,
+		Effect: arg.Effect,
+		Amount: arg.Amount,
+	}
+	q.data.items = append(q.data.items, it)
+	return it, nil
+}
+
+func (q *memQueries) GetItemByID(ctx context.Context, id int64) (Item, error) {
+	defer q.lock()()
+	i, ok := q.data.findItem(id)
+	if !ok {
+		return Item{}, sql.ErrNoRows
+	}
+	return q.data.items[i], nil
+}
+
+func (q *memQueries) ListItems(ctx context.Context) ([]Item, error) {
+	defer q.lock()()
+	var items []Item
+	for _, it := range q.data.items {
+		if !it.DeletedAt.Valid {
+			items = append(items, it)
+		}
+	}
+	return items, nil
+}
+
+func (q *memQueries) UpdateItem(ctx context.Context, arg UpdateItemParams) (Item, error) {
+	defer q.lock()()
+	i, ok := q.data.findItem(arg.ID)
+	if !ok {
+		return Item{}, sql.ErrNoRows
+	}
+	it := &q.data.items[i]
+	it.Name = arg.Name
+	it.Kind = arg.Kind
+	it.Effect = arg.Effect
+	it.Amount = arg.Amount
+	return *it, nil
+}
+
+func (q *memQueries) SoftDeleteItem(ctx context.Context, id int64) error {
+	defer q.lock()()
+	if i, ok := q.data.findItem(id); ok {
+		q.data.items[i].DeletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+	return nil
+}
+
+func (q *memQueries) CountActiveSquadsWithItem(ctx context.Context, itemID int64) (int64, error) {
+	defer q.lock()()
+	squads := make(map[int64]bool)
+	for _, sb := range q.data.squadBagItems {
+		if sb.ItemID == itemID {
+			squads[sb.SquadID] = true
+		}
+	}
+	for _, su := range q.data.squadUnits {
+		if su.HeldItemID.Valid && su.HeldItemID.Int64 == itemID {
+			squads[su.SquadID] = true
+		}
+	}
+	var count int64
+	for id := range squads {
+		if i, ok := q.data.findSquad(id); ok && !q.data.squads[i].DeletedAt.Valid {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Matches
+
+func (q *memQueries) CompleteMatch(ctx context.Context, arg CompleteMatchParams) (Match, error) {
+	defer q.lock()()
+	i, ok := q.data.findMatch(arg.ID)
+	if !ok {
+		return Match{}, sql.ErrNoRows
+	}
+	m := &q.data.matches[i]
+	m.State = "COMPLETED"
+	m.CompletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	m.WinnerPlayerID = arg.WinnerPlayerID
+	m.CurrentActorPlayerID = sql.NullInt64{}
+	return *m, nil
+}
+
+func (q *memQueries) CreateMatch(ctx context.Context, arg CreateMatchParams) (Match, error) {
+	defer q.lock()()
+	if _, ok := q.data.findPlayer(arg.Player1ID); !ok {
+		return Match{}, errForeignKey("matches_player1_id_fkey")
+	}
+	if _, ok := q.data.findPlayer(arg.Player2ID); !ok {
+		return Match{}, errForeignKey("matches_player2_id_fkey")
+	}
+	if _, ok := q.data.findRuleset(arg.RulesetID); !ok {
+		return Match{}, errForeignKey("matches_ruleset_id_fkey")
+	}
+	m := Match{
+		ID:        q.data.nextID("matches"),
+		State:     "PENDING",
+		CreatedAt: time.Now(),
+		Player1ID: arg.Player1ID,
+		Player2ID: arg.Player2ID,
+		IsPublic:  arg.IsPublic,
+		RulesetID: arg.RulesetID,
+	}
+	q.data.matches = append(q.data.matches, m)
+	return m, nil
+}
+
+func (q *memQueries) GetMatchByID(ctx context.Context, id int64) (Match, error) {
+	defer q.lock()()
+	i, ok := q.data.findMatch(id)
+	if !ok {
+		return Match{}, sql.ErrNoRows
+	}
+	return q.data.matches[i], nil
+}
+
+// GetMatchByIDForUpdate needs no row lock of its own: a MemoryStore
+// transaction already has the whole store to itself.
+func (q *memQueries) GetMatchByIDForUpdate(ctx context.Context, id int64) (Match, error) {
+	return q.GetMatchByID(ctx, id)
+}
+
+func (q *memQueries) ListLiveMatches(ctx context.Context, arg ListLiveMatchesParams) ([]ListLiveMatchesRow, error) {
+	defer q.lock()()
+	var items []ListLiveMatchesRow
+	for _, m := range q.data.matches {
+		if m.State != "IN_PROGRESS" || !m.IsPublic || (arg.BeforeID.Valid && m.ID >= arg.BeforeID.Int64) {
+			continue
+		}
+		p1, _ := q.data.findPlayer(m.Player1ID)
+		p2, _ := q.data.findPlayer(m.Player2ID)
+		items = append(items, ListLiveMatchesRow{
+			ID:                m.ID,
+			StartedAt:         m.StartedAt,
+			CurrentTurnNumber: m.CurrentTurnNumber,
+			Player1ID:         m.Player1ID,
+			Player1Username:   q.data.players[p1].Username,
+			Player2ID:         m.Player2ID,
+			Player2Username:   q.data.players[p2].Username,
+		})
+	}
+	sort.Slice(items, func(a, b int) bool { return items[a].ID > items[b].ID })
+	if len(items) > int(arg.RowLimit) {
+		items = items[:arg.RowLimit]
+	}
+	return items, nil
+}
+
+func (q *memQueries) ListMatchesForPlayer(ctx context.Context, arg ListMatchesForPlayerParams) ([]Match, error) {
+	defer q.lock()()
+	var items []Match
+	for _, m := range q.data.matches {
+		switch {
+		case m.Player1ID != arg.PlayerID && m.Player2ID != arg.PlayerID:
+		case arg.State.Valid && m.State != arg.State.String:
+		case arg.OpponentID.Valid && m.Player1ID != arg.OpponentID.Int64 && m.Player2ID != arg.OpponentID.Int64:
+		case arg.CreatedFrom.Valid && m.CreatedAt.Before(arg.CreatedFrom.Time):
+		case arg.CreatedTo.Valid && !m.CreatedAt.Before(arg.CreatedTo.Time):
+		case arg.ActorID.Valid && m.CurrentActorPlayerID != arg.ActorID:
+		case arg.BeforeID.Valid && m.ID >= arg.BeforeID.Int64:
+		default:
+			items = append(items, m)
+		}
+	}
+	sort.Slice(items, func(a, b int) bool { return items[a].ID > items[b].ID })
+	if len(items) > int(arg.RowLimit) {
+		items = items[:arg.RowLimit]
+	}
+	return items, nil
+}
+
+func (q *memQueries) SetMatchPublic(ctx context.Context, arg SetMatchPublicParams) (Match, error) {
+	defer q.lock()()
+	i, ok := q.data.findMatch(arg.ID)
+	if !ok {
+		return Match{}, sql.ErrNoRows
+	}
+	m := &q.data.matches[i]
+	m.IsPublic = arg.IsPublic
+	return *m, nil
+}
+
+func (q *memQueries) StartMatch(ctx context.Context, arg StartMatchParams) (Match, error) {
+	defer q.lock()()
+	i, ok := q.data.findMatch(arg.ID)
+	if !ok {
+		return Match{}, sql.ErrNoRows
+	}
+	m := &q.data.matches[i]
+	m.State = "IN_PROGRESS"
+	m.StartedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	m.CurrentTurnNumber = 1
+	m.CurrentActorPlayerID = arg.CurrentActorPlayerID
+	m.TurnStartedAt = m.StartedAt
+	return *m, nil
+}
+
+func (q *memQueries) UpdateMatchTurnAndActor(ctx context.Context, arg UpdateMatchTurnAndActorParams) (Match, error) {
+	defer q.lock()()
+	i, ok := q.data.findMatch(arg.ID)
+	if !ok {
+		return Match{}, sql.ErrNoRows
+	}
+	m := &q.data.matches[i]
+	m.CurrentTurnNumber = arg.CurrentTurnNumber
+	m.CurrentActorPlayerID = arg.CurrentActorPlayerID
+	m.TurnStartedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	return *m, nil
+}
+
+// Match sides
+
+func (q *memQueries) CreateMatchSide(ctx context.Context, arg CreateMatchSideParams) (MatchSide, error) {
+	defer q.lock()()
+	if _, ok := q.data.findMatch(arg.MatchID); !ok {
+		return MatchSide{}, errForeignKey("match_sides_match_id_fkey")
+	}
+	for _, ms := range q.data.matchSides {
+		if ms.MatchID == arg.MatchID && ms.PlayerID == arg.PlayerID {
+			return MatchSide{}, errUnique("match_sides_match_id_player_id_key")
+		}
+	}
+	ms := MatchSide{
+		ID:       q.data.nextID("match_sides"),
+		MatchID:  arg.MatchID,
+		PlayerID: arg.PlayerID,
+		SquadID:  arg.SquadID,
+	}
+	q.data.matchSides = append(q.data.matchSides, ms)
+	return ms, nil
+}
+
+func (q *memQueries) GetMatchSidesByMatchID(ctx context.Context, matchID int64) ([]MatchSide, error) {
+	defer q.lock()()
+	var items []MatchSide
+	for _, ms := range q.data.matchSides {
+		if ms.MatchID == matchID {
+			items = append(items, ms)
+		}
+	}
+	return items, nil
+}
+
+func (q *memQueries) UpdateMatchSideActiveIndex(ctx context.Context, arg UpdateMatchSideActiveIndexParams) (MatchSide, error) {
+	defer q.lock()()
+	i, ok := q.data.findMatchSide(arg.ID)
+	if !ok {
+		return MatchSide{}, sql.ErrNoRows
+	}
+	q.data.matchSides[i].ActiveIndex = arg.ActiveIndex
+	return q.data.matchSides[i], nil
+}
+
+func (q *memQueries) SetMatchSidePendingAction(ctx context.Context, arg SetMatchSidePendingActionParams) error {
+	defer q.lock()()
+	i, ok := q.data.findMatchSide(arg.ID)
+	if !ok {
+		return nil
+	}
+	if arg.PendingMoveID.Valid {
+		if _, ok := q.data.findMove(arg.PendingMoveID.Int64); !ok {
+			return errForeignKey("match_sides_pending_move_id_fkey")
+		}
+	}
+	if arg.PendingItemID.Valid {
+		if _, ok := q.data.findItem(arg.PendingItemID.Int64); !ok {
+			return errForeignKey("match_sides_pending_item_id_fkey")
+		}
+	}
+	q.data.matchSides[i].PendingMoveID = arg.PendingMoveID
+	q.data.matchSides[i].PendingSwitchTo = arg.PendingSwitchTo
+	q.data.matchSides[i].PendingItemID = arg.PendingItemID
+	return nil
+}
+
+// Match side items
+
+func (q *memQueries) CreateMatchSideItem(ctx context.Context, arg CreateMatchSideItemParams) (MatchSideItem, error) {
+	defer q.lock()()
+	if _, ok := q.data.findMatchSide(arg.MatchSideID); !ok {
+		return MatchSideItem{}, errForeignKey("match_side_items_match_side_id_fkey")
+	}
+	if _, ok := q.data.findItem(arg.ItemID); !ok {
+		return MatchSideItem{}, errForeignKey("match_side_items_item_id_fkey")
+	}
+	for _, mi := range q.data.matchSideItems {
+		if mi.MatchSideID == arg.MatchSideID && mi.ItemID == arg.ItemID {
+			return MatchSideItem{}, errUnique("match_side_items_pkey")
+		}
+	}
+	mi := MatchSideItem{MatchSideID: arg.MatchSideID, ItemID: arg.ItemID, Quantity: arg.Quantity}
+	q.data.matchSideItems = append(q.data.matchSideItems, mi)
+	return mi, nil
+}
+
+func (q *memQueries) GetMatchSideItem(ctx context.Context, arg GetMatchSideItemParams) (MatchSideItem, error) {
+	defer q.lock()()
+	for _, mi := range q.data.matchSideItems {
+		if mi.MatchSideID == arg.MatchSideID && mi.ItemID == arg.ItemID {
+			return mi, nil
+		}
+	}
+	return MatchSideItem{}, sql.ErrNoRows
+}
+
+func (q *memQueries) UseMatchSideItem(ctx context.Context, arg UseMatchSideItemParams) (int64, error) {
+	defer q.lock()()
+	for i, mi := range q.data.matchSideItems {
+		if mi.MatchSideID == arg.MatchSideID && mi.ItemID == arg.ItemID && mi.Quantity > 0 {
+			q.data.matchSideItems[i].Quantity--
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (q *memQueries) ListMatchSideItemDetails(ctx context.Context, matchID int64) ([]ListMatchSideItemDetailsRow, error) {
+	defer q.lock()()
+	var items []ListMatchSideItemDetailsRow
+	for _, mi := range q.data.matchSideItems {
+		si, ok := q.data.findMatchSide(mi.MatchSideID)
+		if !ok || q.data.matchSides[si].MatchID != matchID {
+			continue
+		}
+		ii, ok := q.data.findItem(mi.ItemID)
+		if !ok {
+			continue
+		}
+		it := q.data.items[ii]
+		items = append(items, ListMatchSideItemDetailsRow{
+			MatchSideID: mi.MatchSideID,
+			ItemID:      mi.ItemID,
+			Quantity:    mi.Quantity,
+			Name:        it.Name,
+			Effect:      it.Effect,
+			Amount:      it.Amount,
+		})
+	}
+	sort.Slice(items, func(a, b int) bool {
+		if items[a].MatchSideID != items[b].MatchSideID {
+			return items[a].MatchSideID < items[b].MatchSideID
+		}
+		return items[a].ItemID < items[b].ItemID
+	})
+	return items, nil
+}
+
+// Match units
+
+func (q *memQueries) CreateMatchUnit(ctx context.Context, arg CreateMatchUnitParams) (MatchUnit, error) {
+	defer q.lock()()
+	if _, ok := q.data.findMatchSide(arg.MatchSideID); !ok {
+		return MatchUnit{}, errForeignKey("match_units_match_side_id_fkey")
+	}
+	if arg.HeldItemID.Valid {
+		if _, ok := q.data.findItem(arg.HeldItemID.Int64); !ok {
+			return MatchUnit{}, errForeignKey("match_units_held_item_id_fkey")
+		}
+	}
+	for _, mu := range q.data.matchUnits {
+		if mu.MatchSideID == arg.MatchSideID && mu.Position == arg.Position {
+			return MatchUnit{}, errUnique("match_units_match_side_id_position_key")
+		}
+	}
+	mu := MatchUnit{
+		ID:          q.data.nextID("match_units"),
+		MatchSideID: arg.MatchSideID,
+		UnitID:      arg.UnitID,
+		Position:    arg.Position,
+		CurrentHp:   arg.CurrentHp,
+		Revealed:    arg.Revealed,
+		HeldItemID:  arg.HeldItemID,
+	}
+	q.data.matchUnits = append(q.data.matchUnits, mu)
+	return mu, nil
+}
+
+func (q *memQueries) GetActiveMatchUnitForSide(ctx context.Context, matchSideID int64) (MatchUnit, error) {
+	defer q.lock()()
+	si, ok := q.data.findMatchSide(matchSideID)
+	if !ok {
+		return MatchUnit{}, sql.ErrNoRows
+	}
+	side := q.data.matchSides[si]
+	for _, mu := range q.data.matchUnits {
+		if mu.MatchSideID == side.ID && mu.Position == side.ActiveIndex {
+			return mu, nil
+		}
+	}
+	return MatchUnit{}, sql.ErrNoRows
+}
+
+func (q *memQueries) GetMatchUnitsBySideID(ctx context.Context, matchSideID int64) ([]MatchUnit, error) {
+	defer q.lock()()
+	var items []MatchUnit
+	for _, mu := range q.data.matchUnits {
+		if mu.MatchSideID == matchSideID {
+			items = append(items, mu)
+		}
+	}
+	sort.Slice(items, func(a, b int) bool { return items[a].Position < items[b].Position })
+	return items, nil
+}
+
+func (q *memQueries) ListMatchUnitDetails(ctx context.Context, matchID int64) ([]ListMatchUnitDetailsRow, error) {
+	defer q.lock()()
+	var items []ListMatchUnitDetailsRow
+	for _, mu := range q.data.matchUnits {
+		si, ok := q.data.findMatchSide(mu.MatchSideID)
+		if !ok || q.data.matchSides[si].MatchID != matchID {
+			continue
+		}
+		ui, ok := q.data.findUnit(mu.UnitID)
+		if !ok {
+			continue
+		}
+		u := q.data.units[ui]
+		ti, ok := q.data.findUnitType(u.TypeID)
+		if !ok {
+			continue
+		}
+		row := ListMatchUnitDetailsRow{
+			ID:          mu.ID,
+			MatchSideID: mu.MatchSideID,
+			UnitID:      mu.UnitID,
+			Position:    mu.Position,
+			CurrentHp:   mu.CurrentHp,
+			Revealed:    mu.Revealed,
+			UnitName:    u.Name,
+			BaseHp:      u.BaseHp,
+			TypeID:      u.TypeID,
+			TypeName:    q.data.unitTypes[ti].Name,
+			HeldItemID:  mu.HeldItemID,
+			Ability:     u.Ability,
+			AttackBonus: mu.AttackBonus,
+			SpeedBonus:  mu.SpeedBonus,
+		}
+		if ii, ok := q.data.findItem(mu.HeldItemID.Int64); mu.HeldItemID.Valid && ok {
+			row.HeldItemName = sql.NullString{String: q.data.items[ii].Name, Valid: true}
+		}
+		items = append(items, row)
+	}
+	sort.Slice(items, func(a, b int) bool {
+		if items[a].MatchSideID != items[b].MatchSideID {
+			return items[a].MatchSideID < items[b].MatchSideID
+		}
+		return items[a].Position < items[b].Position
+	})
+	return items, nil
+}
+
+func (q *memQueries) RevealMatchUnit(ctx context.Context, arg RevealMatchUnitParams) error {
+	defer q.lock()()
+	for i, mu := range q.data.matchUnits {
+		if mu.MatchSideID == arg.MatchSideID && mu.Position == arg.Position {
+			q.data.matchUnits[i].Revealed = true
+		}
+	}
+	return nil
+}
+
+func (q *memQueries) UpdateMatchUnitHP(ctx context.Context, arg UpdateMatchUnitHPParams) (MatchUnit, error) {
+	defer q.lock()()
+	i, ok := q.data.findMatchUnit(arg.ID)
+	if !ok {
+		return MatchUnit{}, sql.ErrNoRows
+	}
+	q.data.matchUnits[i].CurrentHp = arg.CurrentHp
+	return q.data.matchUnits[i], nil
+}
+
+func (q *memQueries) UpdateMatchUnitBonuses(ctx context.Context, arg UpdateMatchUnitBonusesParams) (MatchUnit, error) {
+	defer q.lock()()
+	i, ok := q.data.findMatchUnit(arg.ID)
+	if !ok {
+		return MatchUnit{}, sql.ErrNoRows
+	}
+	q.data.matchUnits[i].AttackBonus = arg.AttackBonus
+	q.data.matchUnits[i].SpeedBonus = arg.SpeedBonus
+	return q.data.matchUnits[i], nil
+}
+
+// Match turns
+
+func (q *memQueries) CreateMatchTurn(ctx context.Context, arg CreateMatchTurnParams) (MatchTurn, error) {
+	defer q.lock()()
+	if _, ok := q.data.findMatch(arg.MatchID); !ok {
+		return MatchTurn{}, errForeignKey("match_turns_match_id_fkey")
+	}
+	t := MatchTurn{
+		ID:                q.data.nextID("match_turns"),
+		MatchID:           arg.MatchID,
+		TurnNumber:        arg.TurnNumber,
+		ActingPlayerID:    arg.ActingPlayerID,
+		ActingMatchUnitID: arg.ActingMatchUnitID,
+		MoveID:            arg.MoveID,
+		TargetMatchUnitID: arg.TargetMatchUnitID,
+		DamageDone:        arg.DamageDone,
+		TargetHpAfter:     arg.TargetHpAfter,
+		DidKoTarget:       arg.DidKoTarget,
+		CreatedAt:         time.Now(),
+		Action:            arg.Action,
+		ItemID:            arg.ItemID,
+		Ability:           arg.Ability,
+		Missed:            arg.Missed,
+	}
+	q.data.matchTurns = append(q.data.matchTurns, t)
+	return t, nil
+}
+
+func (q *memQueries) ListMatchTurns(ctx context.Context, matchID int64) ([]MatchTurn, error) {
+	defer q.lock()()
+	var items []MatchTurn
+	for _, t := range q.data.matchTurns {
+		if t.MatchID == matchID {
+			items = append(items, t)
+		}
+	}
+	sort.SliceStable(items, func(a, b int) bool { return items[a].TurnNumber < items[b].TurnNumber })
+	return items, nil
+}
+
+// Match messages
+
+func (q *memQueries) CountRecentMatchMessagesByPlayer(ctx context.Context, arg CountRecentMatchMessagesByPlayerParams) (int64, error) {
+	defer q.lock()()
+	var count int64
+	for _, mm := range q.data.matchMessages {
+		if mm.PlayerID == arg.PlayerID && mm.CreatedAt.After(arg.CreatedAt) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (q *memQueries) CreateMatchMessage(ctx context.Context, arg CreateMatchMessageParams) (MatchMessage, error) {
+	defer q.lock()()
+	if _, ok := q.data.findMatch(arg.MatchID); !ok {
+		return MatchMessage{}, errForeignKey("match_messages_match_id_fkey")
+	}
+	if _, ok := q.data.findPlayer(arg.PlayerID); !ok {
+		return MatchMessage{}, errForeignKey("match_messages_player_id_fkey")
+	}
+	mm := MatchMessage{
+		ID:        q.data.nextID("match_messages"),
+		MatchID:   arg.MatchID,
+		PlayerID:  arg.PlayerID,
+		Body:      arg.Body,
+		CreatedAt: time.Now(),
+	}
+	q.data.matchMessages = append(q.data.matchMessages, mm)
+	return mm, nil
+}
+
+func (q *memQueries) CreateMatchMessageReport(ctx context.Context, arg CreateMatchMessageReportParams) (int64, error) {
+	defer q.lock()()
+	if _, ok := q.data.findMatchMessage(arg.MessageID); !ok {
+		return 0, errForeignKey("match_message_reports_message_id_fkey")
+	}
+	if _, ok := q.data.findPlayer(arg.ReporterID); !ok {
+		return 0, errForeignKey("match_message_reports_reporter_id_fkey")
+	}
+	for _, r := range q.data.messageReports {
+		if r.MessageID == arg.MessageID && r.ReporterID == arg.ReporterID {
+			return 0, nil
+		}
+	}
+	q.data.messageReports = append(q.data.messageReports, MatchMessageReport{
+		MessageID:  arg.MessageID,
+		ReporterID: arg.ReporterID,
+		Reason:     arg.Reason,
+		CreatedAt:  time.Now(),
+	})
+	return 1, nil
+}
+
+func (q *memQueries) GetMatchMessageByID(ctx context.Context, id int64) (MatchMessage, error) {
+	defer q.lock()()
+	i, ok := q.data.findMatchMessage(id)
+	if !ok {
+		return MatchMessage{}, sql.ErrNoRows
+	}
+	return q.data.matchMessages[i], nil
+}
+
+func (q *memQueries) ListMatchMessages(ctx context.Context, arg ListMatchMessagesParams) ([]ListMatchMessagesRow, error) {
+	defer q.lock()()
+	var items []ListMatchMessagesRow
+	for _, mm := range q.data.matchMessages {
+		if mm.MatchID != arg.MatchID || mm.Hidden || (arg.AfterID.Valid && mm.ID <= arg.AfterID.Int64) {
+			continue
+		}
+		pi, ok := q.data.findPlayer(mm.PlayerID)
+		if !ok {
+			continue
+		}
+		items = append(items, ListMatchMessagesRow{
+			ID:        mm.ID,
+			PlayerID:  mm.PlayerID,
+			Username:  q.data.players[pi].Username,
+			Body:      mm.Body,
+			CreatedAt: mm.CreatedAt,
+		})
+		if len(items) == int(arg.RowLimit) {
+			break
+		}
+	}
+	return items, nil
+}
+
+func (q *memQueries) ListUnreviewedMessageReports(ctx context.Context, limit int32) ([]ListUnreviewedMessageReportsRow, error) {
+	defer q.lock()()
+	var items []ListUnreviewedMessageReportsRow
+	for _, r := range q.data.messageReports {
+		mi, ok := q.data.findMatchMessage(r.MessageID)
+		if !ok || q.data.matchMessages[mi].ReviewedAt.Valid {
+			continue
+		}
+		mm := q.data.matchMessages[mi]
+		ai, ok := q.data.findPlayer(mm.PlayerID)
+		if !ok {
+			continue
+		}
+		ri, ok := q.data.findPlayer(r.ReporterID)
+		if !ok {
+			continue
+		}
+		items = append(items, ListUnreviewedMessageReportsRow{
+			MessageID:        mm.ID,
+			MatchID:          mm.MatchID,
+			PlayerID:         mm.PlayerID,
+			AuthorUsername:   q.data.players[ai].Username,
+			Body:             mm.Body,
+			CreatedAt:        mm.CreatedAt,
+			ReporterID:       r.ReporterID,
+			ReporterUsername: q.data.players[ri].Username,
+			Reason:           r.Reason,
+			ReportedAt:       r.CreatedAt,
+		})
+	}
+	sort.SliceStable(items, func(a, b int) bool { return items[a].MessageID < items[b].MessageID })
+	if len(items) > int(limit) {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+func (q *memQueries) ReviewMatchMessage(ctx context.Context, arg ReviewMatchMessageParams) (MatchMessage, error) {
+	defer q.lock()()
+	i, ok := q.data.findMatchMessage(arg.ID)
+	if !ok {
+		return MatchMessage{}, sql.ErrNoRows
+	}
+	mm := &q.data.matchMessages[i]
+	mm.Hidden = arg.Hidden
+	mm.ReviewedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	return *mm, nil
+}
+
+// Moves
+
+func (q *memQueries) CreateMove(ctx context.Context, arg CreateMoveParams) (Move, error) {
+	defer q.lock()()
+	if _, ok := q.data.findUnitType(arg.TypeID); !ok {
+		return Move{}, errForeignKey("moves_type_id_fkey")
+	}
+	mv := Move{
+		ID:       q.data.nextID("moves"),
+		Name:     arg.Name,
+		Power:    arg.Power,
+		Accuracy: arg.Accuracy,
+		TypeID:   arg.TypeID,
+	}
+	q.data.moves = append(q.data.moves, mv)
+	return mv, nil
+}
+
+func (q *memQueries) CreateUnitMove(ctx context.Context, arg CreateUnitMoveParams) (UnitMove, error) {
+	defer q.lock()()
+	if _, ok := q.data.findUnit(arg.UnitID); !ok {
+		return UnitMove{}, errForeignKey("unit_moves_unit_id_fkey")
+	}
+	if _, ok := q.data.findMove(arg.MoveID); !ok {
+		return UnitMove{}, errForeignKey("unit_moves_move_id_fkey")
+	}
+	for _, um := range q.data.unitMoves {
+		if um == (UnitMove{UnitID: arg.UnitID, MoveID: arg.MoveID}) {
+			return UnitMove{}, errUnique("unit_moves_pkey")
+		}
+	}
+	um := UnitMove{UnitID: arg.UnitID, MoveID: arg.MoveID}
+	q.data.unitMoves = append(q.data.unitMoves, um)
+	return um, nil
+}
+
+func (q *memQueries) DeleteUnitMove(ctx context.Context, arg DeleteUnitMoveParams) (int64, error) {
+	defer q.lock()()
+	var deleted int64
+	kept := q.data.unitMoves[:0:0]
+	for _, um := range q.data.unitMoves {
+		if um == (UnitMove{UnitID: arg.UnitID, MoveID: arg.MoveID}) {
+			deleted++
+			continue
+		}
+		kept = append(kept, um)
+	}
+	q.data.unitMoves = kept
+	return deleted, nil
+}
+
+func (q *memQueries) GetMoveByID(ctx context.Context, id int64) (Move, error) {
+	defer q.lock()()
+	i, ok := q.data.findMove(id)
+	if !ok {
+		return Move{}, sql.ErrNoRows
+	}
+	return q.data.moves[i], nil
+}
+
+func (q *memQueries) ListMoves(ctx context.Context) ([]Move, error) {
+	defer q.lock()()
+	var items []Move
+	for _, mv := range q.data.moves {
+		if !mv.DeletedAt.Valid {
+			items = append(items, mv)
+		}
+	}
+	return items, nil
+}
+
+func (q *memQueries) UpdateMove(ctx context.Context, arg UpdateMoveParams) (Move, error) {
+	defer q.lock()()
+	i, ok := q.data.findMove(arg.ID)
+	if !ok {
+		return Move{}, sql.ErrNoRows
+	}
+	if _, ok := q.data.findUnitType(arg.TypeID); !ok {
+		return Move{}, errForeignKey("moves_type_id_fkey")
+	}
+	mv := &q.data.moves[i]
+	mv.Name = arg.Name
+	mv.Power = arg.Power
+	mv.Accuracy = arg.Accuracy
+	mv.TypeID = arg.TypeID
+	return *mv, nil
+}
+
+func (q *memQueries) SoftDeleteMove(ctx context.Context, id int64) error {
+	defer q.lock()()
+	if i, ok := q.data.findMove(id); ok {
+		q.data.moves[i].DeletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+	return nil
+}
+
+func (q *memQueries) CountActiveSquadsWithMove(ctx context.Context, moveID int64) (int64, error) {
+	defer q.lock()()
+	squads := make(map[int64]bool)
+	for _, um := range q.data.unitMoves {
+		if um.MoveID != moveID {
+			continue
+		}
+		for id := range q.data.activeSquadsWithUnit(um.UnitID) {
+			squads[id] = true
+		}
+	}
+	return int64(len(squads)), nil
+}
+
+func (q *memQueries) ListActiveUnitMovesForMatch(ctx context.Context, matchID int64) ([]ListActiveUnitMovesForMatchRow, error) {
+	defer q.lock()()
+	var items []ListActiveUnitMovesForMatchRow
+	for _, side := range q.data.matchSides {
+		if side.MatchID != matchID {
+			continue
+		}
+		for _, mu := range q.data.matchUnits {
+			if mu.MatchSideID != side.ID || mu.Position != side.ActiveIndex {
+				continue
+			}
+			for _, um := range q.data.unitMoves {
+				if um.UnitID != mu.UnitID {
+					continue
+				}
+				mi, ok := q.data.findMove(um.MoveID)
+				if !ok || q.data.moves[mi].DeletedAt.Valid {
+					continue
+				}
+				mv := q.data.moves[mi]
+				ti, ok := q.data.findUnitType(mv.TypeID)
+				if !ok {
+					continue
+				}
+				items = append(items, ListActiveUnitMovesForMatchRow{
+					MatchUnitID: mu.ID,
+					ID:          mv.ID,
+					Name:        mv.Name,
+					Power:       mv.Power,
+					Accuracy:    mv.Accuracy,
+					TypeID:      mv.TypeID,
+					TypeName:    q.data.unitTypes[ti].Name,
+				})
+			}
+		}
+	}
+	sort.Slice(items, func(a, b int) bool {
+		if items[a].MatchUnitID != items[b].MatchUnitID {
+			return items[a].MatchUnitID < items[b].MatchUnitID
+		}
+		return items[a].ID < items[b].ID
+	})
+	return items, nil
+}
+
+func (q *memQueries) ListMovesForUnit(ctx context.Context, unitID int64) ([]Move, error) {
+	defer q.lock()()
+	var items []Move
+	for _, mv := range q.data.moves {
+		if mv.DeletedAt.Valid {
+			continue
+		}
+		for _, um := range q.data.unitMoves {
+			if um.UnitID == unitID && um.MoveID == mv.ID {
+				items = append(items, mv)
+				break
+			}
+		}
+	}
+	return items, nil
+}
+
+// Challenges
+
+func (q *memQueries) CreateChallenge(ctx context.Context, arg CreateChallengeParams) (Challenge, error) {
+	defer q.lock()()
+	if _, ok := q.data.findPlayer(arg.ChallengerID); !ok {
+		return Challenge{}, errForeignKey("challenges_challenger_id_fkey")
+	}
+	if _, ok := q.data.findPlayer(arg.OpponentID); !ok {
+		return Challenge{}, errForeignKey("challenges_opponent_id_fkey")
+	}
+	if _, ok := q.data.findSquad(arg.ChallengerSquadID); !ok {
+		return Challenge{}, errForeignKey("challenges_challenger_squad_id_fkey")
+	}
+	if _, ok := q.data.findRuleset(arg.RulesetID); !ok {
+		return Challenge{}, errForeignKey("challenges_ruleset_id_fkey")
+	}
+	c := Challenge{
+		ID:                q.data.nextID("challenges"),
+		ChallengerID:      arg.ChallengerID,
+		OpponentID:        arg.OpponentID,
+		ChallengerSquadID: arg.ChallengerSquadID,
+		IsPublic:          arg.IsPublic,
+		State:             "PENDING",
+		CreatedAt:         time.Now(),
+		RulesetID:         arg.RulesetID,
+	}
+	q.data.challenges = append(q.data.challenges, c)
+	return c, nil
+}
+
+func (q *memQueries) GetChallengeByID(ctx context.Context, id int64) (Challenge, error) {
+	defer q.lock()()
+	i, ok := q.data.findChallenge(id)
+	if !ok {
+		return Challenge{}, sql.ErrNoRows
+	}
+	return q.data.challenges[i], nil
+}
+
+func (q *memQueries) GetChallengeByIDForUpdate(ctx context.Context, id int64) (Challenge, error) {
+	return q.GetChallengeByID(ctx, id)
+}
+
+func (q *memQueries) ListPendingChallengesForPlayer(ctx context.Context, playerID int64) ([]ListPendingChallengesForPlayerRow, error) {
+	defer q.lock()()
+	var items []ListPendingChallengesForPlayerRow
+	for i := len(q.data.challenges) - 1; i >= 0; i-- {
+		c := q.data.challenges[i]
+		if c.State != "PENDING" || (c.ChallengerID != playerID && c.OpponentID != playerID) {
+			continue
+		}
+		ci, ok := q.data.findPlayer(c.ChallengerID)
+		if !ok {
+			continue
+		}
+		oi, ok := q.data.findPlayer(c.OpponentID)
+		if !ok {
+			continue
+		}
+		items = append(items, ListPendingChallengesForPlayerRow{
+			ID:                 c.ID,
+			ChallengerID:       c.ChallengerID,
+			ChallengerUsername: q.data.players[ci].Username,
+			OpponentID:         c.OpponentID,
+			OpponentUsername:   q.data.players[oi].Username,
+			IsPublic:           c.IsPublic,
+			CreatedAt:          c.CreatedAt,
+			RulesetID:          c.RulesetID,
+		})
+	}
+	return items, nil
+}
+
+func (q *memQueries) RespondToChallenge(ctx context.Context, arg RespondToChallengeParams) (Challenge, error) {
+	defer q.lock()()
+	i, ok := q.data.findChallenge(arg.ID)
+	if !ok {
+		return Challenge{}, sql.ErrNoRows
+	}
+	c := &q.data.challenges[i]
+	c.State = arg.State
+	c.MatchID = arg.MatchID
+	c.RespondedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	return *c, nil
+}
+
+// Friendships
+
+func (q *memQueries) CountBlocksBetween(ctx context.Context, arg CountBlocksBetweenParams) (int64, error) {
+	defer q.lock()()
+	var count int64
+	for _, f := range q.data.friendships {
+		between := (f.PlayerID == arg.PlayerID && f.FriendID == arg.OtherID) ||
+			(f.PlayerID == arg.OtherID && f.FriendID == arg.PlayerID)
+		if between && f.Status == "blocked" {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (q *memQueries) CreateFriendship(ctx context.Context, arg CreateFriendshipParams) (Friendship, error) {
+	defer q.lock()()
+	if _, ok := q.data.findPlayer(arg.PlayerID); !ok {
+		return Friendship{}, errForeignKey("friendships_player_id_fkey")
+	}
+	if _, ok := q.data.findPlayer(arg.FriendID); !ok {
+		return Friendship{}, errForeignKey("friendships_friend_id_fkey")
+	}
+	if _, ok := q.data.findFriendship(arg.PlayerID, arg.FriendID); ok {
+		return Friendship{}, errUnique("friendships_pkey")
+	}
+	now := time.Now()
+	f := Friendship{
+		PlayerID:  arg.PlayerID,
+		FriendID:  arg.FriendID,
+		Status:    arg.Status,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	q.data.friendships = append(q.data.friendships, f)
+	return f, nil
+}
+
+func (q *memQueries) DeleteFriendship(ctx context.Context, arg DeleteFriendshipParams) error {
+	defer q.lock()()
+	if i, ok := q.data.findFriendship(arg.PlayerID, arg.FriendID); ok {
+		q.data.friendships = append(q.data.friendships[:i], q.data.friendships[i+1:]...)
+	}
+	return nil
+}
+
+func (q *memQueries) ListFriendships(ctx context.Context, playerID int64) ([]ListFriendshipsRow, error) {
+	defer q.lock()()
+	inMatch := make(map[int64]bool)
+	for _, m := range q.data.matches {
+		if m.State == "IN_PROGRESS" {
+			inMatch[m.Player1ID] = true
+			inMatch[m.Player2ID] = true
+		}
+	}
+	var items []ListFriendshipsRow
+	for _, f := range q.data.friendships {
+		otherID := f.FriendID
+		switch {
+		case f.PlayerID == playerID:
+		case f.FriendID == playerID && f.Status != "blocked":
+			otherID = f.PlayerID
+		default:
+			continue
+		}
+		pi, ok := q.data.findPlayer(otherID)
+		if !ok {
+			continue
+		}
+		p := q.data.players[pi]
+		items = append(items, ListFriendshipsRow{
+			PlayerID:    f.PlayerID,
+			FriendID:    f.FriendID,
+			Status:      f.Status,
+			UpdatedAt:   f.UpdatedAt,
+			OtherID:     p.ID,
+			Username:    p.Username,
+			DisplayName: p.DisplayName,
+			AvatarKey:   p.AvatarKey,
+			Rating:      p.Rating,
+			CreatedAt:   p.CreatedAt,
+			LastSeenAt:  p.LastSeenAt,
+			InMatch:     inMatch[p.ID],
+		})
+	}
+	sort.SliceStable(items, func(a, b int) bool { return items[a].Username < items[b].Username })
+	return items, nil
+}
+
+func (q *memQueries) ListFriendshipsBetween(ctx context.Context, arg ListFriendshipsBetweenParams) ([]Friendship, error) {
+	defer q.lock()()
+	var items []Friendship
+	for _, f := range q.data.friendships {
+		if (f.PlayerID == arg.PlayerID && f.FriendID == arg.OtherID) ||
+			(f.PlayerID == arg.OtherID && f.FriendID == arg.PlayerID) {
+			items = append(items, f)
+		}
+	}
+	return items, nil
+}
+
+func (q *memQueries) UpdateFriendshipStatus(ctx context.Context, arg UpdateFriendshipStatusParams) error {
+	defer q.lock()()
+	if i, ok := q.data.findFriendship(arg.PlayerID, arg.FriendID); ok {
+		q.data.friendships[i].Status = arg.Status
+		q.data.friendships[i].UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+// Players
+
+func (q *memQueries) CreatePlayer(ctx context.Context, arg CreatePlayerParams) (CreatePlayerRow, error) {
+	defer q.lock()()
+	for _, p := range q.data.players {
+		if p.Username == arg.Username {
+			return CreatePlayerRow{}, errUnique("players_username_key")
+		}
+	}
+	p := Player{
+		ID:           q.data.nextID("players"),
+		Username:     arg.Username,
+		PasswordHash: arg.PasswordHash,
+		CreatedAt:    time.Now(),
+		Rating:       1000,
+	}
+	q.data.players = append(q.data.players, p)
+	return CreatePlayerRow{
+		ID:           p.ID,
+		Username:     p.Username,
+		PasswordHash: p.PasswordHash,
+		CreatedAt:    p.CreatedAt,
+	}, nil
+}
+
+func (q *memQueries) GetPlayerByID(ctx context.Context, id int64) (Player, error) {
+	defer q.lock()()
+	i, ok := q.data.findPlayer(id)
+	if !ok {
+		return Player{}, sql.ErrNoRows
+	}
+	return q.data.players[i], nil
+}
+
+func (q *memQueries) GetPlayerRatingForUpdate(ctx context.Context, id int64) (int32, error) {
+	defer q.lock()()
+	i, ok := q.data.findPlayer(id)
+	if !ok {
+		return 0, sql.ErrNoRows
+	}
+	return q.data.players[i].Rating, nil
+}
+
+// LockPlayer has nothing to do: ExecTx already runs one transaction at a
+// time.
+func (q *memQueries) LockPlayer(ctx context.Context, id int64) error {
+	return nil
+}
+
+// likePattern compiles a LIKE pattern with \ as the escape character.
+func likePattern(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("(?s)^")
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '%':
+			b.WriteString(".*")
+		case r == '_':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+func (q *memQueries) SearchPlayers(ctx context.Context, arg SearchPlayersParams) ([]SearchPlayersRow, error) {
+	defer q.lock()()
+	like := likePattern(arg.Pattern)
+	var items []SearchPlayersRow
+	for _, p := range q.data.players {
+		if arg.AfterID.Valid && p.ID <= arg.AfterID.Int64 {
+			continue
+		}
+		if !like.MatchString(strings.ToLower(p.Username)) && !like.MatchString(strings.ToLower(p.DisplayName)) {
+			continue
+		}
+		items = append(items, SearchPlayersRow{
+			ID:          p.ID,
+			Username:    p.Username,
+			DisplayName: p.DisplayName,
+			AvatarKey:   p.AvatarKey,
+			Rating:      p.Rating,
+			CreatedAt:   p.CreatedAt,
+		})
+	}
+	sort.Slice(items, func(a, b int) bool { return items[a].ID < items[b].ID })
+	if len(items) > int(arg.RowLimit) {
+		items = items[:arg.RowLimit]
+	}
+	return items, nil
+}
+
+func (q *memQueries) GetPlayerByUsername(ctx context.Context, username string) (GetPlayerByUsernameRow, error) {
+	defer q.lock()()
+	for _, p := range q.data.players {
+		if p.Username == username {
+			return GetPlayerByUsernameRow{
+				ID:           p.ID,
+				Username:     p.Username,
+				PasswordHash: p.PasswordHash,
+				CreatedAt:    p.CreatedAt,
+			}, nil
+		}
+	}
+	return GetPlayerByUsernameRow{}, sql.ErrNoRows
+}
+
+func (q *memQueries) UpdatePlayerRating(ctx context.Context, arg UpdatePlayerRatingParams) error {
+	defer q.lock()()
+	if i, ok := q.data.findPlayer(arg.ID); ok {
+		q.data.players[i].Rating = arg.Rating
+	}
+	return nil
+}
+
+func (q *memQueries) TouchPlayer(ctx context.Context, arg TouchPlayerParams) error {
+	defer q.lock()()
+	i, ok := q.data.findPlayer(arg.ID)
+	if !ok {
+		return nil
+	}
+	p := &q.data.players[i]
+	if !p.LastSeenAt.Valid || p.LastSeenAt.Time.Before(arg.SeenBefore.Time) {
+		p.LastSeenAt = arg.SeenAt
+	}
+	return nil
+}
+
+func (q *memQueries) UpdatePlayerProfile(ctx context.Context, arg UpdatePlayerProfileParams) error {
+	defer q.lock()()
+	if i, ok := q.data.findPlayer(arg.ID); ok {
+		q.data.players[i].DisplayName = arg.DisplayName
+		q.data.players[i].AvatarKey = arg.AvatarKey
+	}
+	return nil
+}
+
+// Player stats
+
+func (q *memQueries) AddPlayerDailyStats(ctx context.Context, arg AddPlayerDailyStatsParams) error {
+	defer q.lock()()
+	if _, ok := q.data.findPlayer(arg.PlayerID); !ok {
+		return errForeignKey("player_daily_stats_player_id_fkey")
+	}
+	var st *PlayerDailyStat
+	for i := range q.data.playerDailyStats {
+		if q.data.playerDailyStats[i].PlayerID == arg.PlayerID && q.data.playerDailyStats[i].Day.Equal(arg.Day) {
+			st = &q.data.playerDailyStats[i]
+			break
+		}
+	}
+	if st == nil {
+		q.data.playerDailyStats = append(q.data.playerDailyStats, PlayerDailyStat{PlayerID: arg.PlayerID, Day: arg.Day})
+		st = &q.data.playerDailyStats[len(q.data.playerDailyStats)-1]
+	}
+	st.MatchesPlayed += arg.MatchesPlayed
+	st.Wins += arg.Wins
+	st.Losses += arg.Losses
+	st.TurnsPlayed += arg.TurnsPlayed
+	st.DamageDealt += arg.DamageDealt
+	st.Kos += arg.Kos
+	return nil
+}
+
+func (q *memQueries) AddPlayerMoveUse(ctx context.Context, arg AddPlayerMoveUseParams) error {
+	defer q.lock()()
+	if _, ok := q.data.findPlayer(arg.PlayerID); !ok {
+		return errForeignKey("player_move_stats_player_id_fkey")
+	}
+	if _, ok := q.data.findMove(arg.MoveID); !ok {
+		return errForeignKey("player_move_stats_move_id_fkey")
+	}
+	for i := range q.data.playerMoveStats {
+		st := &q.data.playerMoveStats[i]
+		if st.PlayerID == arg.PlayerID && st.Day.Equal(arg.Day) && st.MoveID == arg.MoveID {
+			st.Uses++
+			return nil
+		}
+	}
+	q.data.playerMoveStats = append(q.data.playerMoveStats, PlayerMoveStat{PlayerID: arg.PlayerID, Day: arg.Day, MoveID: arg.MoveID, Uses: 1})
+	return nil
+}
+
+func (q *memQueries) AddPlayerUnitMatch(ctx context.Context, arg AddPlayerUnitMatchParams) error {
+	defer q.lock()()
+	if _, ok := q.data.findPlayer(arg.PlayerID); !ok {
+		return errForeignKey("player_unit_stats_player_id_fkey")
+	}
+	if _, ok := q.data.findUnit(arg.UnitID); !ok {
+		return errForeignKey("player_unit_stats_unit_id_fkey")
+	}
+	for i := range q.data.playerUnitStats {
+		st := &q.data.playerUnitStats[i]
+		if st.PlayerID == arg.PlayerID && st.Day.Equal(arg.Day) && st.UnitID == arg.UnitID {
+			st.MatchesFielded++
+			return nil
+		}
+	}
+	q.data.playerUnitStats = append(q.data.playerUnitStats, PlayerUnitStat{PlayerID: arg.PlayerID, Day: arg.Day, UnitID: arg.UnitID, MatchesFielded: 1})
+	return nil
+}
+
+func (q *memQueries) GetPlayerStats(ctx context.Context, arg GetPlayerStatsParams) (GetPlayerStatsRow, error) {
+	defer q.lock()()
+	i, ok := q.data.findPlayer(arg.PlayerID)
+	if !ok {
+		return GetPlayerStatsRow{}, sql.ErrNoRows
+	}
+	p := q.data.players[i]
+	row := GetPlayerStatsRow{ID: p.ID, Username: p.Username, Rating: p.Rating}
+	for _, st := range q.data.playerDailyStats {
+		if st.PlayerID != p.ID || !inTimeRange(st.Day, arg.FromDay, arg.ToDay) {
+			continue
+		}
+		row.MatchesPlayed += int64(st.MatchesPlayed)
+		row.Wins += int64(st.Wins)
+		row.Losses += int64(st.Losses)
+		row.TurnsPlayed += int64(st.TurnsPlayed)
+		row.DamageDealt += st.DamageDealt
+		row.Kos += int64(st.Kos)
+	}
+	return row, nil
+}
+
+func (q *memQueries) ListLeaderboard(ctx context.Context, arg ListLeaderboardParams) ([]ListLeaderboardRow, error) {
+	defer q.lock()()
+	totals := make(map[int64]*ListLeaderboardRow)
+	for _, st := range q.data.playerDailyStats {
+		if !inTimeRange(st.Day, arg.FromDay, arg.ToDay) {
+			continue
+		}
+		row, ok := totals[st.PlayerID]
+		if !ok {
+			pi, ok := q.data.findPlayer(st.PlayerID)
+			if !ok {
+				continue
+			}
+			p := q.data.players[pi]
+			row = &ListLeaderboardRow{ID: p.ID, Username: p.Username, Rating: p.Rating}
+			totals[st.PlayerID] = row
+		}
+		row.MatchesPlayed += int64(st.MatchesPlayed)
+		row.Wins += int64(st.Wins)
+		row.Losses += int64(st.Losses)
+	}
+
+	var items []ListLeaderboardRow
+	for _, row := range totals {
+		if row.MatchesPlayed >= arg.MinMatches {
+			items = append(items, *row)
+		}
+	}
+	key := func(r ListLeaderboardRow) float64 {
+		switch arg.SortBy {
+		case "wins":
+			return float64(r.Wins)
+		case "win_rate":
+			if r.MatchesPlayed == 0 {
+				return 0
+			}
+			return float64(r.Wins) / float64(r.MatchesPlayed)
+		default:
+			return float64(r.Rating)
+		}
+	}
+	sort.Slice(items, func(a, b int) bool {
+		if ka, kb := key(items[a]), key(items[b]); ka != kb {
+			return ka > kb
+		}
+		return items[a].ID < items[b].ID
+	})
+	if len(items) > int(arg.RowLimit) {
+		items = items[:arg.RowLimit]
+	}
+	return items, nil
+}
+
+func (q *memQueries) ListPlayerTopMoves(ctx context.Context, arg ListPlayerTopMovesParams) ([]ListPlayerTopMovesRow, error) {
+	defer q.lock()()
+	totals := make(map[int64]*ListPlayerTopMovesRow)
+	for _, st := range q.data.playerMoveStats {
+		if st.PlayerID != arg.PlayerID || !inTimeRange(st.Day, arg.FromDay, arg.ToDay) {
+			continue
+		}
+		row, ok := totals[st.MoveID]
+		if !ok {
+			mi, ok := q.data.findMove(st.MoveID)
+			if !ok {
+				continue
+			}
+			row = &ListPlayerTopMovesRow{MoveID: st.MoveID, Name: q.data.moves[mi].Name}
+			totals[st.MoveID] = row
+		}
+		row.Uses += int64(st.Uses)
+	}
+	var items []ListPlayerTopMovesRow
+	for _, row := range totals {
+		items = append(items, *row)
+	}
+	sort.Slice(items, func(a, b int) bool {
+		if items[a].Uses != items[b].Uses {
+			return items[a].Uses > items[b].Uses
+		}
+		return items[a].MoveID < items[b].MoveID
+	})
+	if len(items) > int(arg.RowLimit) {
+		items = items[:arg.RowLimit]
+	}
+	return items, nil
+}
+
+func (q *memQueries) ListPlayerTopUnits(ctx context.Context, arg ListPlayerTopUnitsParams) ([]ListPlayerTopUnitsRow, error) {
+	defer q.lock()()
+	totals := make(map[int64]*ListPlayerTopUnitsRow)
+	for _, st := range q.data.playerUnitStats {
+		if st.PlayerID != arg.PlayerID || !inTimeRange(st.Day, arg.FromDay, arg.ToDay) {
+			continue
+		}
+		row, ok := totals[st.UnitID]
+		if !ok {
+			ui, ok := q.data.findUnit(st.UnitID)
+			if !ok {
+				continue
+			}
+			row = &ListPlayerTopUnitsRow{UnitID: st.UnitID, Name: q.data.units[ui].Name}
+			totals[st.UnitID] = row
+		}
+		row.MatchesFielded += int64(st.MatchesFielded)
+	}
+	var items []ListPlayerTopUnitsRow
+	for _, row := range totals {
+		items = append(items, *row)
+	}
+	sort.Slice(items, func(a, b int) bool {
+		if items[a].MatchesFielded != items[b].MatchesFielded {
+			return items[a].MatchesFielded > items[b].MatchesFielded
+		}
+		return items[a].UnitID < items[b].UnitID
+	})
+	if len(items) > int(arg.RowLimit) {
+		items = items[:arg.RowLimit]
+	}
+	return items, nil
+}
+
+// Rulesets
+
+func (q *memQueries) CreateRuleset(ctx context.Context, arg CreateRulesetParams) (Ruleset, error) {
+	defer q.lock()()
+	for _, r := range q.data.rulesets {
+		if r.Name == arg.Name {
+			return Ruleset{}, errUnique("rulesets_name_key")
+		}
+	}
+	now := time.Now()
+	r := Ruleset{
+		ID:                       q.data.nextID("rulesets"),
+		Name:                     arg.Name,
+		MinSquadSize:             arg.MinSquadSize,
+		MaxSquadSize:             arg.MaxSquadSize,
+		LevelCap:                 arg.LevelCap,
+		DamageModel:              arg.DamageModel,
+		TurnTimerSeconds:         arg.TurnTimerSeconds,
+		TurnMode:                 arg.TurnMode,
+		SwitchingEnabled:         arg.SwitchingEnabled,
+		ItemsEnabled:             arg.ItemsEnabled,
+		TypeEffectivenessEnabled: arg.TypeEffectivenessEnabled,
+		CreatedAt:                now,
+		UpdatedAt:                now,
+	}
+	q.data.rulesets = append(q.data.rulesets, r)
+	return r, nil
+}
+
+func (q *memQueries) GetRulesetByID(ctx context.Context, id int64) (Ruleset, error) {
+	defer q.lock()()
+	i, ok := q.data.findRuleset(id)
+	if !ok {
+		return Ruleset{}, sql.ErrNoRows
+	}
+	return q.data.rulesets[i], nil
+}
+
+func (q *memQueries) ListRulesets(ctx context.Context) ([]Ruleset, error) {
+	defer q.lock()()
+	return append([]Ruleset(nil), q.data.rulesets...), nil
+}
+
+func (q *memQueries) UpdateRuleset(ctx context.Context, arg UpdateRulesetParams) (Ruleset, error) {
+	defer q.lock()()
+	i, ok := q.data.findRuleset(arg.ID)
+	if !ok {
+		return Ruleset{}, sql.ErrNoRows
+	}
+	for _, r := range q.data.rulesets {
+		if r.Name == arg.Name && r.ID != arg.ID {
+			return Ruleset{}, errUnique("rulesets_name_key")
+		}
+	}
+	r := &q.data.rulesets[i]
+	r.Name = arg.Name
+	r.MinSquadSize = arg.MinSquadSize
+	r.MaxSquadSize = arg.MaxSquadSize
+	r.LevelCap = arg.LevelCap
+	r.DamageModel = arg.DamageModel
+	r.TurnTimerSeconds = arg.TurnTimerSeconds
+	r.TurnMode = arg.TurnMode
+	r.SwitchingEnabled = arg.SwitchingEnabled
+	r.ItemsEnabled = arg.ItemsEnabled
+	r.TypeEffectivenessEnabled = arg.TypeEffectivenessEnabled
+	r.UpdatedAt = time.Now()
+	return *r, nil
+}
+
+// Squads
+
+func (q *memQueries) CreateSquad(ctx context.Context, arg CreateSquadParams) (Squad, error) {
+	defer q.lock()()
+	if _, ok := q.data.findPlayer(arg.PlayerID); !ok {
+		return Squad{}, errForeignKey("squads_player_id_fkey")
+	}
+	sq := Squad{
+		ID:        q.data.nextID("squads"),
+		PlayerID:  arg.PlayerID,
+		Name:      arg.Name,
+		CreatedAt: time.Now(),
+	}
+	q.data.squads = append(q.data.squads, sq)
+	return sq, nil
+}
+
+func (q *memQueries) GetSquadByID(ctx context.Context, id int64) (Squad, error) {
+	defer q.lock()()
+	i, ok := q.data.findSquad(id)
+	if !ok {
+		return Squad{}, sql.ErrNoRows
+	}
+	return q.data.squads[i], nil
+}
+
+func (q *memQueries) GetSquadByIDForShare(ctx context.Context, id int64) (Squad, error) {
+	return q.GetSquadByID(ctx, id)
+}
+
+func (q *memQueries) GetSquadByIDForUpdate(ctx context.Context, id int64) (Squad, error) {
+	return q.GetSquadByID(ctx, id)
+}
+
+func (q *memQueries) GetSquadsForPlayer(ctx context.Context, playerID int64) ([]Squad, error) {
+	defer q.lock()()
+	var items []Squad
+	for _, sq := range q.data.squads {
+		if sq.PlayerID == playerID && !sq.DeletedAt.Valid {
+			items = append(items, sq)
+		}
+	}
+	sort.SliceStable(items, func(a, b int) bool {
+		return items[a].CreatedAt.After(items[b].CreatedAt)
+	})
+	return items, nil
+}
+
+func (q *memQueries) UpdateSquadName(ctx context.Context, arg UpdateSquadNameParams) (Squad, error) {
+	defer q.lock()()
+	i, ok := q.data.findSquad(arg.ID)
+	if !ok {
+		return Squad{}, sql.ErrNoRows
+	}
+	q.data.squads[i].Name = arg.Name
+	return q.data.squads[i], nil
+}
+
+func (q *memQueries) SoftDeleteSquad(ctx context.Context, id int64) error {
+	defer q.lock()()
+	if i, ok := q.data.findSquad(id); ok {
+		q.data.squads[i].DeletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+	return nil
+}
+
+func (q *memQueries) ClearShowcaseSquads(ctx context.Context, playerID int64) error {
+	defer q.lock()()
+	for i := range q.data.squads {
+		if q.data.squads[i].PlayerID == playerID {
+			q.data.squads[i].Showcased = false
+		}
+	}
+	return nil
+}
+
+func (q *memQueries) ShowcaseSquad(ctx context.Context, arg ShowcaseSquadParams) (int64, error) {
+	defer q.lock()()
+	i, ok := q.data.findSquad(arg.ID)
+	if !ok || q.data.squads[i].PlayerID != arg.PlayerID || q.data.squads[i].DeletedAt.Valid {
+		return 0, nil
+	}
+	q.data.squads[i].Showcased = true
+	return 1, nil
+}
+
+func (q *memQueries) ListShowcaseSquadUnits(ctx context.Context, playerID int64) ([]ListShowcaseSquadUnitsRow, error) {
+	defer q.lock()()
+	var items []ListShowcaseSquadUnitsRow
+	for _, su := range q.data.squadUnits {
+		si, ok := q.data.findSquad(su.SquadID)
+		if !ok {
+			continue
+		}
+		sq := q.data.squads[si]
+		if sq.PlayerID != playerID || !sq.Showcased || sq.DeletedAt.Valid {
+			continue
+		}
+		ui, ok := q.data.findUnit(su.UnitID)
+		if !ok {
+			continue
+		}
+		u := q.data.units[ui]
+		ti, ok := q.data.findUnitType(u.TypeID)
+		if !ok {
+			continue
+		}
+		items = append(items, ListShowcaseSquadUnitsRow{
+			SquadID:   sq.ID,
+			SquadName: sq.Name,
+			Position:  su.Position,
+			UnitID:    u.ID,
+			UnitName:  u.Name,
+			TypeName:  q.data.unitTypes[ti].Name,
+		})
+	}
+	sort.Slice(items, func(a, b int) bool {
+		if items[a].SquadID != items[b].SquadID {
+			return items[a].SquadID < items[b].SquadID
+		}
+		return items[a].Position < items[b].Position
+	})
+	return items, nil
+}
+
+func (q *memQueries) CountInProgressMatchesForSquad(ctx context.Context, squadID int64) (int64, error) {
+	defer q.lock()()
+	var count int64
+	for _, ms := range q.data.matchSides {
+		if ms.SquadID != squadID {
+			continue
+		}
+		if i, ok := q.data.findMatch(ms.MatchID); ok && q.data.matches[i].State == "IN_PROGRESS" {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (q *memQueries) CreateSquadUnit(ctx context.Context, arg CreateSquadUnitParams) (SquadUnit, error) {
+	defer q.lock()()
+	if _, ok := q.data.findSquad(arg.SquadID); !ok {
+		return SquadUnit{}, errForeignKey("squad_units_squad_id_fkey")
+	}
+	if _, ok := q.data.findUnit(arg.UnitID); !ok {
+		return SquadUnit{}, errForeignKey("squad_units_unit_id_fkey")
+	}
+	if arg.HeldItemID.Valid {
+		if _, ok := q.data.findItem(arg.HeldItemID.Int64); !ok {
+			return SquadUnit{}, errForeignKey("squad_units_held_item_id_fkey")
+		}
+	}
+	for _, su := range q.data.squadUnits {
+		if su.SquadID == arg.SquadID && su.Position == arg.Position {
+			return SquadUnit{}, errUnique("squad_units_squad_id_position_key")
+		}
+	}
+	su := SquadUnit{
+		ID:         q.data.nextID("squad_units"),
+		SquadID:    arg.SquadID,
+		UnitID:     arg.UnitID,
+		Position:   arg.Position,
+		HeldItemID: arg.HeldItemID,
+	}
+	q.data.squadUnits = append(q.data.squadUnits, su)
+	return su, nil
+}
+
+func (q *memQueries) DeleteSquadUnits(ctx context.Context, squadID int64) error {
+	defer q.lock()()
+	kept := q.data.squadUnits[:0:0]
+	for _, su := range q.data.squadUnits {
+		if su.SquadID != squadID {
+			kept = append(kept, su)
+		}
+	}
+	q.data.squadUnits = kept
+	return nil
+}
+
+func (q *memQueries) GetSquadUnits(ctx context.Context, squadID int64) ([]SquadUnit, error) {
+	defer q.lock()()
+	var items []SquadUnit
+	for _, su := range q.data.squadUnits {
+		if su.SquadID == squadID {
+			items = append(items, su)
+		}
+	}
+	sort.Slice(items, func(a, b int) bool { return items[a].Position < items[b].Position })
+	return items, nil
+}
+
+func (q *memQueries) CreateSquadBagItem(ctx context.Context, arg CreateSquadBagItemParams) (SquadBagItem, error) {
+	defer q.lock()()
+	if _, ok := q.data.findSquad(arg.SquadID); !ok {
+		return SquadBagItem{}, errForeignKey("squad_bag_items_squad_id_fkey")
+	}
+	if _, ok := q.data.findItem(arg.ItemID); !ok {
+		return SquadBagItem{}, errForeignKey("squad_bag_items_item_id_fkey")
+	}
+	for _, sb := range q.data.squadBagItems {
+		if sb.SquadID == arg.SquadID && sb.ItemID == arg.ItemID {
+			return SquadBagItem{}, errUnique("squad_bag_items_pkey")
+		}
+	}
+	sb := SquadBagItem{SquadID: arg.SquadID, ItemID: arg.ItemID, Quantity: arg.Quantity}
+	q.data.squadBagItems = append(q.data.squadBagItems, sb)
+	return sb, nil
+}
+
+func (q *memQueries) DeleteSquadBagItems(ctx context.Context, squadID int64) error {
+	defer q.lock()()
+	kept := q.data.squadBagItems[:0:0]
+	for _, sb := range q.data.squadBagItems {
+		if sb.SquadID != squadID {
+			kept = append(kept, sb)
+		}
+	}
+	q.data.squadBagItems = kept
+	return nil
+}
+
+func (q *memQueries) ListSquadBagItems(ctx context.Context, squadID int64) ([]SquadBagItem, error) {
+	defer q.lock()()
+	var items []SquadBagItem
+	for _, sb := range q.data.squadBagItems {
+		if sb.SquadID == squadID {
+			items = append(items, sb)
+		}
+	}
+	sort.Slice(items, func(a, b int) bool { return items[a].ItemID < items[b].ItemID })
+	return items, nil
+}
+
+// Units and unit types
+
+func (q *memQueries) CreateUnit(ctx context.Context, arg CreateUnitParams) (Unit, error) {
+	defer q.lock()()
+	if _, ok := q.data.findUnitType(arg.TypeID); !ok {
+		return Unit{}, errForeignKey("units_type_id_fkey")
+	}
+	u := Unit{
+		ID:         q.data.nextID("units"),
+		Name:       arg.Name,
+		TypeID:     arg.TypeID,
+		BaseHp:     arg.BaseHp,
+		BaseAttack: arg.BaseAttack,
+		BaseSpeed:  arg.BaseSpeed,
+		Ability:    arg.Ability,
+	}
+	q.data.units = append(q.data.units, u)
+	return u, nil
+}
+
+func (q *memQueries) GetUnitByID(ctx context.Context, id int64) (Unit, error) {
+	defer q.lock()()
+	i, ok := q.data.findUnit(id)
+	if !ok {
+		return Unit{}, sql.ErrNoRows
+	}
+	return q.data.units[i], nil
+}
+
+func (q *memQueries) ListUnits(ctx context.Context) ([]Unit, error) {
+	defer q.lock()()
+	var items []Unit
+	for _, u := range q.data.units {
+		if !u.DeletedAt.Valid {
+			items = append(items, u)
+		}
+	}
+	return items, nil
+}
+
+func (q *memQueries) UpdateUnit(ctx context.Context, arg UpdateUnitParams) (Unit, error) {
+	defer q.lock()()
+	i, ok := q.data.findUnit(arg.ID)
+	if !ok {
+		return Unit{}, sql.ErrNoRows
+	}
+	if _, ok := q.data.findUnitType(arg.TypeID); !ok {
+		return Unit{}, errForeignKey("units_type_id_fkey")
+	}
+	u := &q.data.units[i]
+	u.Name = arg.Name
+	u.TypeID = arg.TypeID
+	u.BaseHp = arg.BaseHp
+	u.BaseAttack = arg.BaseAttack
+	u.BaseSpeed = arg.BaseSpeed
+	u.Ability = arg.Ability
+	return *u, nil
+}
+
+func (q *memQueries) SoftDeleteUnit(ctx context.Context, id int64) error {
+	defer q.lock()()
+	if i, ok := q.data.findUnit(id); ok {
+		q.data.units[i].DeletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+	return nil
+}
+
+func (q *memQueries) CountActiveSquadsWithUnit(ctx context.Context, unitID int64) (int64, error) {
+	defer q.lock()()
+	return int64(len(q.data.activeSquadsWithUnit(unitID))), nil
+}
+
+// activeSquadsWithUnit returns the IDs of non-deleted squads containing unitID.
+func (d *memData) activeSquadsWithUnit(unitID int64) map[int64]bool {
+	squads := make(map[int64]bool)
+	for _, su := range d.squadUnits {
+		if su.UnitID != unitID {
+			continue
+		}
+		if i, ok := d.findSquad(su.SquadID); ok && !d.squads[i].DeletedAt.Valid {
+			squads[su.SquadID] = true
+		}
+	}
+	return squads
+}
+
+func (q *memQueries) CountUnitTypeReferences(ctx context.Context, typeID int64) (int64, error) {
+	defer q.lock()()
+	var count int64
+	for _, u := range q.data.units {
+		if u.TypeID == typeID {
+			count++
+		}
+	}
+	for _, mv := range q.data.moves {
+		if mv.TypeID == typeID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (q *memQueries) DeleteUnitType(ctx context.Context, id int64) error {
+	defer q.lock()()
+	i, ok := q.data.findUnitType(id)
+	if !ok {
+		return nil
+	}
+	for _, u := range q.data.units {
+		if u.TypeID == id {
+			return fmt.Errorf("update or delete on table \"unit_types\" violates foreign key constraint %q", "units_type_id_fkey")
+		}
+	}
+	for _, mv := range q.data.moves {
+		if mv.TypeID == id {
+			return fmt.Errorf("update or delete on table \"unit_types\" violates foreign key constraint %q", "moves_type_id_fkey")
+		}
+	}
+	q.data.unitTypes = append(q.data.unitTypes[:i:i], q.data.unitTypes[i+1:]...)
+
+	// ON DELETE CASCADE
+	kept := q.data.typeEffectiveness[:0:0]
+	for _, te := range q.data.typeEffectiveness {
+		if te.AttackTypeID != id && te.DefendTypeID != id {
+			kept = append(kept, te)
+		}
+	}
+	q.data.typeEffectiveness = kept
+	return nil
+}
+
+func (q *memQueries) GetUnitTypeByID(ctx context.Context, id int64) (UnitType, error) {
+	defer q.lock()()
+	i, ok := q.data.findUnitType(id)
+	if !ok {
+		return UnitType{}, sql.ErrNoRows
+	}
+	return q.data.unitTypes[i], nil
+}
+
+func (q *memQueries) ListUnitTypes(ctx context.Context) ([]UnitType, error) {
+	defer q.lock()()
+	return append([]UnitType(nil), q.data.unitTypes...), nil
+}
+
+func (q *memQueries) UpdateUnitType(ctx context.Context, arg UpdateUnitTypeParams) (UnitType, error) {
+	defer q.lock()()
+	i, ok := q.data.findUnitType(arg.ID)
+	if !ok {
+		return UnitType{}, sql.ErrNoRows
+	}
+	for _, t := range q.data.unitTypes {
+		if t.Name == arg.Name && t.ID != arg.ID {
+			return UnitType{}, errUnique("unit_types_name_key")
+		}
+	}
+	q.data.unitTypes[i].Name = arg.Name
+	return q.data.unitTypes[i], nil
+}
+
+func (q *memQueries) CreateUnitType(ctx context.Context, name string) (UnitType, error) {
+	defer q.lock()()
+	for _, t := range q.data.unitTypes {
+		if t.Name == name {
+			return UnitType{}, errUnique("unit_types_name_key")
+		}
+	}
+	t := UnitType{ID: q.data.nextID("unit_types"), Name: name}
+	q.data.unitTypes = append(q.data.unitTypes, t)
+	return t, nil
+}
+
+// Tournaments
+
+func (q *memQueries) CreateTournament(ctx context.Context, arg CreateTournamentParams) (Tournament, error) {
+	defer q.lock()()
+	if _, ok := q.data.findPlayer(arg.CreatedBy); !ok {
+		return Tournament{}, errForeignKey("tournaments_created_by_fkey")
+	}
+	if _, ok := q.data.findRuleset(arg.RulesetID); !ok {
+		return Tournament{}, errForeignKey("tournaments_ruleset_id_fkey")
+	}
+	t := Tournament{
+		ID:         q.data.nextID("tournaments"),
+		Name:       arg.Name,
+		Format:     arg.Format,
+		State:      "REGISTRATION",
+		MaxPlayers: arg.MaxPlayers,
+		CreatedBy:  arg.CreatedBy,
+		CreatedAt:  time.Now(),
+		RulesetID:  arg.RulesetID,
+	}
+	q.data.tournaments = append(q.data.tournaments, t)
+	return t, nil
+}
+
+func (q *memQueries) GetTournamentByID(ctx context.Context, id int64) (Tournament, error) {
+	defer q.lock()()
+	i, ok := q.data.findTournament(id)
+	if !ok {
+		return Tournament{}, sql.ErrNoRows
+	}
+	return q.data.tournaments[i], nil
+}
+
+func (q *memQueries) GetTournamentByIDForUpdate(ctx context.Context, id int64) (Tournament, error) {
+	return q.GetTournamentByID(ctx, id)
+}
+
+func (q *memQueries) ListTournaments(ctx context.Context, arg ListTournamentsParams) ([]Tournament, error) {
+	defer q.lock()()
+	var items []Tournament
+	for i := len(q.data.tournaments) - 1; i >= 0; i-- {
+		t := q.data.tournaments[i]
+		if arg.State.Valid && t.State != arg.State.String {
+			continue
+		}
+		if arg.BeforeID.Valid && t.ID >= arg.BeforeID.Int64 {
+			continue
+		}
+		items = append(items, t)
+		if len(items) == int(arg.RowLimit) {
+			break
+		}
+	}
+	return items, nil
+}
+
+func (q *memQueries) StartTournament(ctx context.Context, id int64) (Tournament, error) {
+	defer q.lock()()
+	i, ok := q.data.findTournament(id)
+	if !ok {
+		return Tournament{}, sql.ErrNoRows
+	}
+	t := &q.data.tournaments[i]
+	t.State = "IN_PROGRESS"
+	t.CurrentRound = 1
+	t.StartedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	return *t, nil
+}
+
+func (q *memQueries) UpdateTournamentRound(ctx context.Context, arg UpdateTournamentRoundParams) (Tournament, error) {
+	defer q.lock()()
+	i, ok := q.data.findTournament(arg.ID)
+	if !ok {
+		return Tournament{}, sql.ErrNoRows
+	}
+	q.data.tournaments[i].CurrentRound = arg.CurrentRound
+	return q.data.tournaments[i], nil
+}
+
+func (q *memQueries) CompleteTournament(ctx context.Context, arg CompleteTournamentParams) (Tournament, error) {
+	defer q.lock()()
+	i, ok := q.data.findTournament(arg.ID)
+	if !ok {
+		return Tournament{}, sql.ErrNoRows
+	}
+	t := &q.data.tournaments[i]
+	t.State = "COMPLETED"
+	t.WinnerPlayerID = arg.WinnerPlayerID
+	t.CompletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	return *t, nil
+}
+
+func (q *memQueries) CreateTournamentEntry(ctx context.Context, arg CreateTournamentEntryParams) (TournamentEntry, error) {
+	defer q.lock()()
+	if _, ok := q.data.findTournament(arg.TournamentID); !ok {
+		return TournamentEntry{}, errForeignKey("tournament_entries_tournament_id_fkey")
+	}
+	if _, ok := q.data.findPlayer(arg.PlayerID); !ok {
+		return TournamentEntry{}, errForeignKey("tournament_entries_player_id_fkey")
+	}
+	if _, ok := q.data.findSquad(arg.SquadID); !ok {
+		return TournamentEntry{}, errForeignKey("tournament_entries_squad_id_fkey")
+	}
+	for _, e := range q.data.tournamentEntries {
+		if e.TournamentID == arg.TournamentID && e.PlayerID == arg.PlayerID {
+			return TournamentEntry{}, errUnique("tournament_entries_pkey")
+		}
+	}
+	e := TournamentEntry{
+		TournamentID: arg.TournamentID,
+		PlayerID:     arg.PlayerID,
+		SquadID:      arg.SquadID,
+		CreatedAt:    time.Now(),
+	}
+	q.data.tournamentEntries = append(q.data.tournamentEntries, e)
+	return e, nil
+}
+
+func (q *memQueries) DeleteTournamentEntry(ctx context.Context, arg DeleteTournamentEntryParams) (int64, error) {
+	defer q.lock()()
+	kept := q.data.tournamentEntries[:0]
+	var n int64
+	for _, e := range q.data.tournamentEntries {
+		if e.TournamentID == arg.TournamentID && e.PlayerID == arg.PlayerID {
+			n++
+			continue
+		}
+		kept = append(kept, e)
+	}
+	q.data.tournamentEntries = kept
+	return n, nil
+}
+
+func (q *memQueries) ListTournamentEntries(ctx context.Context, tournamentID int64) ([]ListTournamentEntriesRow, error) {
+	defer q.lock()()
+	var items []ListTournamentEntriesRow
+	for _, e := range q.data.tournamentEntries {
+		if e.TournamentID != tournamentID {
+			continue
+		}
+		pi, ok := q.data.findPlayer(e.PlayerID)
+		if !ok {
+			continue
+		}
+		items = append(items, ListTournamentEntriesRow{
+			PlayerID:  e.PlayerID,
+			Username:  q.data.players[pi].Username,
+			Rating:    q.data.players[pi].Rating,
+			SquadID:   e.SquadID,
+			Seed:      e.Seed,
+			CreatedAt: e.CreatedAt,
+		})
+	}
+	// Seeds are either all NULL (before the start) or all set
+	sort.SliceStable(items, func(a, b int) bool {
+		if items[a].Seed.Int32 != items[b].Seed.Int32 {
+			return items[a].Seed.Int32 < items[b].Seed.Int32
+		}
+		if !items[a].CreatedAt.Equal(items[b].CreatedAt) {
+			return items[a].CreatedAt.Before(items[b].CreatedAt)
+		}
+		return items[a].PlayerID < items[b].PlayerID
+	})
+	return items, nil
+}
+
+func (q *memQueries) SetTournamentEntrySeed(ctx context.Context, arg SetTournamentEntrySeedParams) error {
+	defer q.lock()()
+	for i := range q.data.tournamentEntries {
+		e := &q.data.tournamentEntries[i]
+		if e.TournamentID == arg.TournamentID && e.PlayerID == arg.PlayerID {
+			e.Seed = arg.Seed
+		}
+	}
+	return nil
+}
+
+func (q *memQueries) CountOpenTournamentEntriesForSquad(ctx context.Context, squadID int64) (int64, error) {
+	defer q.lock()()
+	var count int64
+	for _, e := range q.data.tournamentEntries {
+		if e.SquadID != squadID {
+			continue
+		}
+		if i, ok := q.data.findTournament(e.TournamentID); ok && q.data.tournaments[i].State != "COMPLETED" {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (q *memQueries) CreateTournamentMatch(ctx context.Context, arg CreateTournamentMatchParams) (TournamentMatch, error) {
+	defer q.lock()()
+	if _, ok := q.data.findTournament(arg.TournamentID); !ok {
+		return TournamentMatch{}, errForeignKey("tournament_matches_tournament_id_fkey")
+	}
+	for _, tm := range q.data.tournamentMatches {
+		if tm.TournamentID == arg.TournamentID && tm.Round == arg.Round && tm.Slot == arg.Slot {
+			return TournamentMatch{}, errUnique("tournament_matches_tournament_id_round_slot_key")
+		}
+	}
+	tm := TournamentMatch{
+		ID:             q.data.nextID("tournament_matches"),
+		TournamentID:   arg.TournamentID,
+		Round:          arg.Round,
+		Slot:           arg.Slot,
+		Player1ID:      arg.Player1ID,
+		Player2ID:      arg.Player2ID,
+		WinnerPlayerID: arg.WinnerPlayerID,
+	}
+	q.data.tournamentMatches = append(q.data.tournamentMatches, tm)
+	return tm, nil
+}
+
+func (q *memQueries) GetTournamentMatchByMatchID(ctx context.Context, matchID sql.NullInt64) (TournamentMatch, error) {
+	defer q.lock()()
+	for _, tm := range q.data.tournamentMatches {
+		if matchID.Valid && tm.MatchID.Valid && tm.MatchID.Int64 == matchID.Int64 {
+			return tm, nil
+		}
+	}
+	return TournamentMatch{}, sql.ErrNoRows
+}
+
+func (q *memQueries) GetTournamentMatchBySlot(ctx context.Context, arg GetTournamentMatchBySlotParams) (TournamentMatch, error) {
+	defer q.lock()()
+	for _, tm := range q.data.tournamentMatches {
+		if tm.TournamentID == arg.TournamentID && tm.Round == arg.Round && tm.Slot == arg.Slot {
+			return tm, nil
+		}
+	}
+	return TournamentMatch{}, sql.ErrNoRows
+}
+
+func (q *memQueries) ListTournamentMatches(ctx context.Context, tournamentID int64) ([]TournamentMatch, error) {
+	defer q.lock()()
+	var items []TournamentMatch
+	for _, tm := range q.data.tournamentMatches {
+		if tm.TournamentID == tournamentID {
+			items = append(items, tm)
+		}
+	}
+	sort.Slice(items, func(a, b int) bool {
+		if items[a].Round != items[b].Round {
+			return items[a].Round < items[b].Round
+		}
+		return items[a].Slot < items[b].Slot
+	})
+	return items, nil
+}
+
+func (q *memQueries) UpdateTournamentMatch(ctx context.Context, arg UpdateTournamentMatchParams) (TournamentMatch, error) {
+	defer q.lock()()
+	i, ok := q.data.findTournamentMatch(arg.ID)
+	if !ok {
+		return TournamentMatch{}, sql.ErrNoRows
+	}
+	if arg.MatchID.Valid {
+		if _, ok := q.data.findMatch(arg.MatchID.Int64); !ok {
+			return TournamentMatch{}, errForeignKey("tournament_matches_match_id_fkey")
+		}
+		for j, tm := range q.data.tournamentMatches {
+			if j != i && tm.MatchID.Valid && tm.MatchID.Int64 == arg.MatchID.Int64 {
+				return TournamentMatch{}, errUnique("tournament_matches_match_id_idx")
+			}
+		}
+	}
+	tm := &q.data.tournamentMatches[i]
+	tm.Player1ID = arg.Player1ID
+	tm.Player2ID = arg.Player2ID
+	tm.MatchID = arg.MatchID
+	tm.WinnerPlayerID = arg.WinnerPlayerID
+	return *tm, nil
+}
+
+// Type effectiveness
+
+func (q *memQueries) GetTypeEffectiveness(ctx context.Context, arg GetTypeEffectivenessParams) (TypeEffectiveness, error) {
+	defer q.lock()()
+	for _, te := range q.data.typeEffectiveness {
+		if te.AttackTypeID == arg.AttackTypeID && te.DefendTypeID == arg.DefendTypeID {
+			return te, nil
+		}
+	}
+	return TypeEffectiveness{}, sql.ErrNoRows
+}
+
+func (q *memQueries) ListTypeEffectiveness(ctx context.Context) ([]TypeEffectiveness, error) {
+	defer q.lock()()
+	items := append([]TypeEffectiveness(nil), q.data.typeEffectiveness...)
+	sort.Slice(items, func(a, b int) bool {
+		if items[a].AttackTypeID != items[b].AttackTypeID {
+			return items[a].AttackTypeID < items[b].AttackTypeID
+		}
+		return items[a].DefendTypeID < items[b].DefendTypeID
+	})
+	return items, nil
+}
+
+func (q *memQueries) UpsertTypeEffectiveness(ctx context.Context, arg UpsertTypeEffectivenessParams) (TypeEffectiveness, error) {
+	defer q.lock()()
+	if _, ok := q.data.findUnitType(arg.AttackTypeID); !ok {
+		return TypeEffectiveness{}, errForeignKey("type_effectiveness_attack_type_id_fkey")
+	}
+	if _, ok := q.data.findUnitType(arg.DefendTypeID); !ok {
+		return TypeEffectiveness{}, errForeignKey("type_effectiveness_defend_type_id_fkey")
+	}
+	te := TypeEffectiveness{
+		AttackTypeID:      arg.AttackTypeID,
+		DefendTypeID:      arg.DefendTypeID,
+		MultiplierPercent: arg.MultiplierPercent,
+	}
+	for i := range q.data.typeEffectiveness {
+		existing := q.data.typeEffectiveness[i]
+		if existing.AttackTypeID == arg.AttackTypeID && existing.DefendTypeID == arg.DefendTypeID {
+			q.data.typeEffectiveness[i] = te
+			return te, nil
+		}
+	}
+	q.data.typeEffectiveness = append(q.data.typeEffectiveness, te)
+	return te, nil
+}
@@ -9,6 +9,59 @@ import (
 	"time"
 )
 
+type AdminAuditLog struct {
+	ID            int64
+	AdminPlayerID int64
+	Action        string
+	EntityType    string
+	EntityID      int64
+	Details       string
+	CreatedAt     time.Time
+}
+
+type Challenge struct {
+	ID                int64
+	ChallengerID      int64
+	OpponentID        int64
+	ChallengerSquadID int64
+	IsPublic          bool
+	State             string
+	MatchID           sql.NullInt64
+	CreatedAt         time.Time
+	RespondedAt       sql.NullTime
+	RulesetID         int64
+}
+
+type Friendship struct {
+	PlayerID  int64
+	FriendID  int64
+	Status    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type IdempotencyKey struct {
+	PlayerID     int64
+	Key          string
+	Method       string
+	Path         string
+	RequestHash  string
+	StatusCode   int32
+	ContentType  string
+	ResponseBody []byte
+	CreatedAt    time.Time
+	ClaimedAt    time.Time
+}
+
+type Item struct {
+	ID        int64
+	Name      string
+	Kind      string
+	Effect    string
+	Amount    int32
+	DeletedAt sql.NullTime
+}
+
 type Match struct {
 	ID                   int64
 	State                string
@@ -20,14 +73,43 @@ type Match struct {
 	WinnerPlayerID       sql.NullInt64
 	CurrentTurnNumber    int32
 	CurrentActorPlayerID sql.NullInt64
+	IsPublic             bool
+	RulesetID            int64
+	TurnStartedAt        sql.NullTime
+}
+
+type MatchMessage struct {
+	ID         int64
+	MatchID    int64
+	PlayerID   int64
+	Body       string
+	CreatedAt  time.Time
+	Hidden     bool
+	ReviewedAt sql.NullTime
+}
+
+type MatchMessageReport struct {
+	MessageID  int64
+	ReporterID int64
+	Reason     string
+	CreatedAt  time.Time
 }
 
 type MatchSide struct {
-	ID          int64
-	MatchID     int64
-	PlayerID    int64
-	SquadID     int64
-	ActiveIndex int32
+	ID              int64
+	MatchID         int64
+	PlayerID        int64
+	SquadID         int64
+	ActiveIndex     int32
+	PendingMoveID   sql.NullInt64
+	PendingSwitchTo sql.NullInt32
+	PendingItemID   sql.NullInt64
+}
+
+type MatchSideItem struct {
+	MatchSideID int64
+	ItemID      int64
+	Quantity    int32
 }
 
 type MatchTurn struct {
@@ -36,12 +118,16 @@ type MatchTurn struct {
 	TurnNumber        int32
 	ActingPlayerID    int64
 	ActingMatchUnitID int64
-	MoveID            int64
+	MoveID            sql.NullInt64
 	TargetMatchUnitID int64
 	DamageDone        int32
 	TargetHpAfter     int32
 	DidKoTarget       bool
 	CreatedAt         time.Time
+	Action            string
+	ItemID            sql.NullInt64
+	Ability           sql.NullString
+	Missed            bool
 }
 
 type MatchUnit struct {
@@ -50,14 +136,19 @@ type MatchUnit struct {
 	UnitID      int64
 	Position    int32
 	CurrentHp   int32
+	Revealed    bool
+	HeldItemID  sql.NullInt64
+	AttackBonus int32
+	SpeedBonus  int32
 }
 
 type Move struct {
-	ID       int64
-	Name     string
-	Power    int32
-	Accuracy int32
-	TypeID   int64
+	ID        int64
+	Name      string
+	Power     int32
+	Accuracy  int32
+	TypeID    int64
+	DeletedAt sql.NullTime
 }
 
 type Player struct {
@@ -66,6 +157,51 @@ type Player struct {
 	PasswordHash string
 	CreatedAt    time.Time
 	IsAdmin      bool
+	Rating       int32
+	DisplayName  string
+	AvatarKey    string
+	LastSeenAt   sql.NullTime
+}
+
+type PlayerDailyStat struct {
+	PlayerID      int64
+	Day           time.Time
+	MatchesPlayed int32
+	Wins          int32
+	Losses        int32
+	TurnsPlayed   int32
+	DamageDealt   int64
+	Kos           int32
+}
+
+type PlayerMoveStat struct {
+	PlayerID int64
+	Day      time.Time
+	MoveID   int64
+	Uses     int32
+}
+
+type PlayerUnitStat struct {
+	PlayerID       int64
+	Day            time.Time
+	UnitID         int64
+	MatchesFielded int32
+}
+
+type Ruleset struct {
+	ID                       int64
+	Name                     string
+	MinSquadSize             int32
+	MaxSquadSize             int32
+	LevelCap                 int32
+	DamageModel              string
+	TurnTimerSeconds         int32
+	TurnMode                 string
+	SwitchingEnabled         bool
+	ItemsEnabled             bool
+	TypeEffectivenessEnabled bool
+	CreatedAt                time.Time
+	UpdatedAt                time.Time
 }
 
 type Squad struct {
@@ -73,13 +209,62 @@ type Squad struct {
 	PlayerID  int64
 	Name      string
 	CreatedAt time.Time
+	DeletedAt sql.NullTime
+	Showcased bool
 }
 
-type SquadUnit struct {
-	ID       int64
+type SquadBagItem struct {
 	SquadID  int64
-	UnitID   int64
-	Position int32
+	ItemID   int64
+	Quantity int32
+}
+
+type SquadUnit struct {
+	ID         int64
+	SquadID    int64
+	UnitID     int64
+	Position   int32
+	HeldItemID sql.NullInt64
+}
+
+type Tournament struct {
+	ID             int64
+	Name           string
+	Format         string
+	State          string
+	MaxPlayers     int32
+	CurrentRound   int32
+	WinnerPlayerID sql.NullInt64
+	CreatedBy      int64
+	CreatedAt      time.Time
+	StartedAt      sql.NullTime
+	CompletedAt    sql.NullTime
+	RulesetID      int64
+}
+
+type TournamentEntry struct {
+	TournamentID int64
+	PlayerID     int64
+	SquadID      int64
+	Seed         sql.NullInt32
+	CreatedAt    time.Time
+}
+
+type TournamentMatch struct {
+	ID             int64
+	TournamentID   int64
+	Round          int32
+	Slot           int32
+	Player1ID      sql.NullInt64
+	Player2ID      sql.NullInt64
+	MatchID        sql.NullInt64
+	WinnerPlayerID sql.NullInt64
+}
+
+type TypeEffectiveness struct {
+	AttackTypeID      int64
+	DefendTypeID      int64
+	MultiplierPercent int32
 }
 
 type Unit struct {
@@ -89,6 +274,8 @@ type Unit struct {
 	BaseHp     int32
 	BaseAttack int32
 	BaseSpeed  int32
+	DeletedAt  sql.NullTime
+	Ability    string
 }
 
 type UnitMove struct {
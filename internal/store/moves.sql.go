@@ -9,10 +9,26 @@ import (
 	"context"
 )
 
+const countActiveSquadsWithMove = `-- name: CountActiveSquadsWithMove :one
+SELECT COUNT(DISTINCT s.id)
+FROM unit_moves um
+JOIN squad_units su ON su.unit_id = um.unit_id
+JOIN squads s ON s.id = su.squad_id
+WHERE um.move_id = $1
+  AND s.deleted_at IS NULL
+`
+
+func (q *Queries) CountActiveSquadsWithMove(ctx context.Context, moveID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countActiveSquadsWithMove, moveID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createMove = `-- name: CreateMove :one
 INSERT INTO moves (name, power, accuracy, type_id)
 VALUES ($1, $2, $3, $4)
-RETURNING id, name, power, accuracy, type_id
+RETURNING id, name, power, accuracy, type_id, deleted_at
 `
 
 type CreateMoveParams struct {
@@ -36,6 +52,7 @@ func (q *Queries) CreateMove(ctx context.Context, arg CreateMoveParams) (Move, e
 		&i.Power,
 		&i.Accuracy,
 		&i.TypeID,
+		&i.DeletedAt,
 	)
 	return i, err
 }
@@ -58,12 +75,143 @@ func (q *Queries) CreateUnitMove(ctx context.Context, arg CreateUnitMoveParams)
 	return i, err
 }
 
+const deleteUnitMove = `-- name: DeleteUnitMove :execrows
+DELETE FROM unit_moves
+WHERE unit_id = $1 AND move_id = $2
+`
+
+type DeleteUnitMoveParams struct {
+	UnitID int64
+	MoveID int64
+}
+
+func (q *Queries) DeleteUnitMove(ctx context.Context, arg DeleteUnitMoveParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteUnitMove, arg.UnitID, arg.MoveID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getMoveByID = `-- name: GetMoveByID :one
+SELECT id, name, power, accuracy, type_id, deleted_at
+FROM moves
+WHERE id = $1
+`
+
+func (q *Queries) GetMoveByID(ctx context.Context, id int64) (Move, error) {
+	row := q.db.QueryRowContext(ctx, getMoveByID, id)
+	var i Move
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Power,
+		&i.Accuracy,
+		&i.TypeID,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const listActiveUnitMovesForMatch = `-- name: ListActiveUnitMovesForMatch :many
+SELECT
+  mu.id AS match_unit_id, m.id, m.name, m.power, m.accuracy, m.type_id, ut.name AS type_name
+FROM match_sides ms
+JOIN match_units mu ON mu.match_side_id = ms.id AND mu.position = ms.active_index
+JOIN unit_moves um ON um.unit_id = mu.unit_id
+JOIN moves m ON m.id = um.move_id
+JOIN unit_types ut ON ut.id = m.type_id
+WHERE ms.match_id = $1
+  AND m.deleted_at IS NULL
+ORDER BY mu.id, m.id
+`
+
+type ListActiveUnitMovesForMatchRow struct {
+	MatchUnitID int64
+	ID          int64
+	Name        string
+	Power       int32
+	Accuracy    int32
+	TypeID      int64
+	TypeName    string
+}
+
+// The moves of each side's active unit, for match views.
+func (q *Queries) ListActiveUnitMovesForMatch(ctx context.Context, matchID int64) ([]ListActiveUnitMovesForMatchRow, error) {
+	rows, err := q.db.QueryContext(ctx, listActiveUnitMovesForMatch, matchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListActiveUnitMovesForMatchRow
+	for rows.Next() {
+		var i ListActiveUnitMovesForMatchRow
+		if err := rows.Scan(
+			&i.MatchUnitID,
+			&i.ID,
+			&i.Name,
+			&i.Power,
+			&i.Accuracy,
+			&i.TypeID,
+			&i.TypeName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMoves = `-- name: ListMoves :many
+SELECT id, name, power, accuracy, type_id, deleted_at
+FROM moves
+WHERE deleted_at IS NULL
+ORDER BY id
+`
+
+func (q *Queries) ListMoves(ctx context.Context) ([]Move, error) {
+	rows, err := q.db.QueryContext(ctx, listMoves)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Move
+	for rows.Next() {
+		var i Move
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Power,
+			&i.Accuracy,
+			&i.TypeID,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listMovesForUnit = `-- name: ListMovesForUnit :many
 SELECT
-  m.id, m.name, m.power, m.accuracy, m.type_id
+  m.id, m.name, m.power, m.accuracy, m.type_id, m.deleted_at
 FROM moves m
 JOIN unit_moves um ON um.move_id = m.id
 WHERE um.unit_id = $1
+  AND m.deleted_at IS NULL
 ORDER BY m.id
 `
 
@@ -82,6 +230,7 @@ func (q *Queries) ListMovesForUnit(ctx context.Context, unitID int64) ([]Move, e
 			&i.Power,
 			&i.Accuracy,
 			&i.TypeID,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -95,3 +244,49 @@ func (q *Queries) ListMovesForUnit(ctx context.Context, unitID int64) ([]Move, e
 	}
 	return items, nil
 }
+
+const softDeleteMove = `-- name: SoftDeleteMove :exec
+UPDATE moves
+SET deleted_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+func (q *Queries) SoftDeleteMove(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, softDeleteMove, id)
+	return err
+}
+
+const updateMove = `-- name: UpdateMove :one
+UPDATE moves
+SET name = $2, power = $3, accuracy = $4, type_id = $5
+WHERE id = $1
+RETURNING id, name, power, accuracy, type_id, deleted_at
+`
+
+type UpdateMoveParams struct {
+	ID       int64
+	Name     string
+	Power    int32
+	Accuracy int32
+	TypeID   int64
+}
+
+func (q *Queries) UpdateMove(ctx context.Context, arg UpdateMoveParams) (Move, error) {
+	row := q.db.QueryRowContext(ctx, updateMove,
+		arg.ID,
+		arg.Name,
+		arg.Power,
+		arg.Accuracy,
+		arg.TypeID,
+	)
+	var i Move
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Power,
+		&i.Accuracy,
+		&i.TypeID,
+		&i.DeletedAt,
+	)
+	return i, err
+}
@@ -7,6 +7,7 @@ package store
 
 import (
 	"context"
+	"database/sql"
 	"time"
 )
 
@@ -41,7 +42,7 @@ func (q *Queries) CreatePlayer(ctx context.Context, arg CreatePlayerParams) (Cre
 }
 
 const getPlayerByID = `-- name: GetPlayerByID :one
-SELECT id, username, password_hash, created_at, is_admin
+SELECT id, username, password_hash, created_at, is_admin, rating, display_name, avatar_key, last_seen_at
 FROM players
 WHERE id = $1
 `
@@ -55,6 +56,10 @@ func (q *Queries) GetPlayerByID(ctx context.Context, id int64) (Player, error) {
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.IsAdmin,
+		&i.Rating,
+		&i.DisplayName,
+		&i.AvatarKey,
+		&i.LastSeenAt,
 	)
 	return i, err
 }
@@ -83,3 +88,142 @@ func (q *Queries) GetPlayerByUsername(ctx context.Context, username string) (Get
 	)
 	return i, err
 }
+
+const getPlayerRatingForUpdate = `-- name: GetPlayerRatingForUpdate :one
+SELECT rating
+FROM players
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetPlayerRatingForUpdate(ctx context.Context, id int64) (int32, error) {
+	row := q.db.QueryRowContext(ctx, getPlayerRatingForUpdate, id)
+	var rating int32
+	err := row.Scan(&rating)
+	return rating, err
+}
+
+const lockPlayer = `-- name: LockPlayer :exec
+SELECT id
+FROM players
+WHERE id = $1
+FOR UPDATE
+`
+
+// Holds the player's row until the transaction ends, so checks that span
+// all their matches, like the chat rate limit, run one at a time
+func (q *Queries) LockPlayer(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, lockPlayer, id)
+	return err
+}
+
+const searchPlayers = `-- name: SearchPlayers :many
+SELECT id, username, display_name, avatar_key, rating, created_at
+FROM players
+WHERE (LOWER(username) LIKE CAST($1 AS TEXT) ESCAPE '\' OR LOWER(display_name) LIKE CAST($1 AS TEXT) ESCAPE '\')
+  AND (id > $2 OR $2 IS NULL)
+ORDER BY id
+LIMIT $3
+`
+
+type SearchPlayersParams struct {
+	Pattern  string
+	AfterID  sql.NullInt64
+	RowLimit int32
+}
+
+type SearchPlayersRow struct {
+	ID          int64
+	Username    string
+	DisplayName string
+	AvatarKey   string
+	Rating      int32
+	CreatedAt   time.Time
+}
+
+// Players whose username or display name matches pattern, a lowercase LIKE
+// pattern with \ as the escape character, in signup order
+func (q *Queries) SearchPlayers(ctx context.Context, arg SearchPlayersParams) ([]SearchPlayersRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchPlayers, arg.Pattern, arg.AfterID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchPlayersRow
+	for rows.Next() {
+		var i SearchPlayersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.DisplayName,
+			&i.AvatarKey,
+			&i.Rating,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const touchPlayer = `-- name: TouchPlayer :exec
+UPDATE players
+SET last_seen_at = $1
+WHERE id = $2
+  AND (last_seen_at IS NULL OR last_seen_at < $3)
+`
+
+type TouchPlayerParams struct {
+	SeenAt     sql.NullTime
+	ID         int64
+	SeenBefore sql.NullTime
+}
+
+// Records that the player was seen at seen_at. Players already seen since
+// seen_before are left alone, so a busy player isn't written on every request.
+func (q *Queries) TouchPlayer(ctx context.Context, arg TouchPlayerParams) error {
+	_, err := q.db.ExecContext(ctx, touchPlayer, arg.SeenAt, arg.ID, arg.SeenBefore)
+	return err
+}
+
+const updatePlayerProfile = `-- name: UpdatePlayerProfile :exec
+UPDATE players
+SET
+    display_name = $2,
+    avatar_key = $3
+WHERE id = $1
+`
+
+type UpdatePlayerProfileParams struct {
+	ID          int64
+	DisplayName string
+	AvatarKey   string
+}
+
+func (q *Queries) UpdatePlayerProfile(ctx context.Context, arg UpdatePlayerProfileParams) error {
+	_, err := q.db.ExecContext(ctx, updatePlayerProfile, arg.ID, arg.DisplayName, arg.AvatarKey)
+	return err
+}
+
+const updatePlayerRating = `-- name: UpdatePlayerRating :exec
+UPDATE players
+SET rating = $2
+WHERE id = $1
+`
+
+type UpdatePlayerRatingParams struct {
+	ID     int64
+	Rating int32
+}
+
+func (q *Queries) UpdatePlayerRating(ctx context.Context, arg UpdatePlayerRatingParams) error {
+	_, err := q.db.ExecContext(ctx, updatePlayerRating, arg.ID, arg.Rating)
+	return err
+}
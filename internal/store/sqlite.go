@@ -0,0 +1,59 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"sync"
+)
+
+// NewSQLiteStore returns a Store for a SQLite database opened with the
+// "sqlite" driver (modernc.org/sqlite).
+//
+// The queries in sql/queries are written to run on both Postgres and SQLite.
+// The one exception is row locking: SQLite has no SELECT ... FOR UPDATE, so
+// a locking clause ending a query is dropped here. The database must be
+// opened with _txlock=immediate instead, which makes every transaction take
+// the write lock up front and gives the same one-at-a-time guarantee.
+func NewSQLiteStore(db *sql.DB) *SQLStore {
+	return newSQLStore(db, func(c DBTX) DBTX { return sqliteConn{c} })
+}
+
+type sqliteConn struct {
+	DBTX
+}
+
+// lockingClause matches a Postgres row locking clause at the end of a query,
+// in any case and spacing: FOR [NO KEY] UPDATE or FOR [KEY] SHARE, optionally
+// with OF tables and NOWAIT or SKIP LOCKED. A clause anywhere else is left
+// for SQLite to reject, which TestSQLiteQueries catches.
+var lockingClause = regexp.MustCompile(`(?i)\s+FOR\s+(NO\s+KEY\s+UPDATE|UPDATE|KEY\s+SHARE|SHARE)(\s+OF\s+\w+(\s*,\s*\w+)*)?(\s+NOWAIT|\s+SKIP\s+LOCKED)?\s*;?\s*$`)
+
+// sqliteQueries caches the rewritten queries. The queries are the constants
+// in this package, so it stays small.
+var sqliteQueries sync.Map
+
+func sqliteQuery(query string) string {
+	if q, ok := sqliteQueries.Load(query); ok {
+		return q.(string)
+	}
+	q := lockingClause.ReplaceAllString(query, "")
+	sqliteQueries.Store(query, q)
+	return q
+}
+
+func (c sqliteConn) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return c.DBTX.ExecContext(ctx, sqliteQuery(query), args...)
+}
+
+func (c sqliteConn) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return c.DBTX.PrepareContext(ctx, sqliteQuery(query))
+}
+
+func (c sqliteConn) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return c.DBTX.QueryContext(ctx, sqliteQuery(query), args...)
+}
+
+func (c sqliteConn) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return c.DBTX.QueryRowContext(ctx, sqliteQuery(query), args...)
+}
@@ -0,0 +1,97 @@
+package store_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/76dillon/battle_squads/internal/store"
+	"github.com/76dillon/battle_squads/internal/store/storetest"
+)
+
+func TestSQLiteQuery(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"SELECT id FROM matches\nWHERE id = $1\n", "SELECT id FROM matches\nWHERE id = $1\n"},
+		{"SELECT id FROM matches\nWHERE id = $1\nFOR UPDATE\n", "SELECT id FROM matches\nWHERE id = $1"},
+		{"SELECT id FROM matches WHERE id = $1 FOR UPDATE", "SELECT id FROM matches WHERE id = $1"},
+		{"SELECT id FROM matches WHERE id = $1\n  for  update ;\n", "SELECT id FROM matches WHERE id = $1"},
+		{"SELECT id FROM matches WHERE id = $1 FOR UPDATE OF matches NOWAIT", "SELECT id FROM matches WHERE id = $1"},
+		{"SELECT id FROM matches WHERE id = $1 FOR NO KEY UPDATE SKIP LOCKED", "SELECT id FROM matches WHERE id = $1"},
+		{"SELECT id FROM matches WHERE id = $1 FOR SHARE", "SELECT id FROM matches WHERE id = $1"},
+		// Only a clause ending the query is a locking clause
+		{"SELECT 'FOR UPDATE' AS note FROM matches", "SELECT 'FOR UPDATE' AS note FROM matches"},
+		{"SELECT id FROM matches_for_update", "SELECT id FROM matches_for_update"},
+	}
+	for _, tt := range tests {
+		if got := store.SQLiteQuery(tt.in); got != tt.want {
+			t.Errorf("SQLiteQuery(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestSQLiteQueries prepares every generated query, rewritten as the SQLite
+// store runs it, against the SQLite schema. sqlc only checks the queries
+// against the Postgres schema, so this is what keeps sql/queries portable.
+func TestSQLiteQueries(t *testing.T) {
+	db := storetest.OpenSQLite(t)
+	queries := generatedQueries(t)
+	if len(queries) == 0 {
+		t.Fatal("no generated queries found")
+	}
+	for name, q := range queries {
+		stmt, err := db.Prepare(store.SQLiteQuery(q))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		stmt.Close()
+	}
+}
+
+// generatedQueries returns the query constants sqlc generated in this
+// package, by name.
+func generatedQueries(t *testing.T) map[string]string {
+	t.Helper()
+	files, err := filepath.Glob("*.sql.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	queries := make(map[string]string)
+	fset := token.NewFileSet()
+	for _, file := range files {
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				vs := spec.(*ast.ValueSpec)
+				if len(vs.Values) != 1 {
+					continue
+				}
+				lit, ok := vs.Values[0].(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					continue
+				}
+				q, err := strconv.Unquote(lit.Value)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if strings.HasPrefix(q, "-- name: ") {
+					queries[vs.Names[0].Name] = q
+				}
+			}
+		}
+	}
+	return queries
+}
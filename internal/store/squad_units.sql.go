@@ -7,39 +7,58 @@ package store
 
 import (
 	"context"
+	"database/sql"
 )
 
 const createSquadUnit = `-- name: CreateSquadUnit :one
 INSERT INTO squad_units (
     squad_id,
     unit_id,
-    position
+    position,
+    held_item_id
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $3, $4
 )
-RETURNING id, squad_id, unit_id, position
+RETURNING id, squad_id, unit_id, position, held_item_id
 `
 
 type CreateSquadUnitParams struct {
-	SquadID  int64
-	UnitID   int64
-	Position int32
+	SquadID    int64
+	UnitID     int64
+	Position   int32
+	HeldItemID sql.NullInt64
 }
 
 func (q *Queries) CreateSquadUnit(ctx context.Context, arg CreateSquadUnitParams) (SquadUnit, error) {
-	row := q.db.QueryRowContext(ctx, createSquadUnit, arg.SquadID, arg.UnitID, arg.Position)
+	row := q.db.QueryRowContext(ctx, createSquadUnit,
+		arg.SquadID,
+		arg.UnitID,
+		arg.Position,
+		arg.HeldItemID,
+	)
 	var i SquadUnit
 	err := row.Scan(
 		&i.ID,
 		&i.SquadID,
 		&i.UnitID,
 		&i.Position,
+		&i.HeldItemID,
 	)
 	return i, err
 }
 
+const deleteSquadUnits = `-- name: DeleteSquadUnits :exec
+DELETE FROM squad_units
+WHERE squad_id = $1
+`
+
+func (q *Queries) DeleteSquadUnits(ctx context.Context, squadID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteSquadUnits, squadID)
+	return err
+}
+
 const getSquadUnits = `-- name: GetSquadUnits :many
-SELECT id, squad_id, unit_id, position
+SELECT id, squad_id, unit_id, position, held_item_id
 FROM squad_units
 WHERE squad_id = $1
 ORDER BY position
@@ -59,6 +78,7 @@ func (q *Queries) GetSquadUnits(ctx context.Context, squadID int64) ([]SquadUnit
 			&i.SquadID,
 			&i.UnitID,
 			&i.Position,
+			&i.HeldItemID,
 		); err != nil {
 			return nil, err
 		}
@@ -9,6 +9,33 @@ import (
 	"context"
 )
 
+const clearShowcaseSquads = `-- name: ClearShowcaseSquads :exec
+UPDATE squads
+SET showcased = FALSE
+WHERE player_id = $1
+  AND showcased
+`
+
+func (q *Queries) ClearShowcaseSquads(ctx context.Context, playerID int64) error {
+	_, err := q.db.ExecContext(ctx, clearShowcaseSquads, playerID)
+	return err
+}
+
+const countInProgressMatchesForSquad = `-- name: CountInProgressMatchesForSquad :one
+SELECT COUNT(*)
+FROM match_sides ms
+JOIN matches m ON m.id = ms.match_id
+WHERE ms.squad_id = $1
+  AND m.state = 'IN_PROGRESS'
+`
+
+func (q *Queries) CountInProgressMatchesForSquad(ctx context.Context, squadID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countInProgressMatchesForSquad, squadID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createSquad = `-- name: CreateSquad :one
 INSERT INTO squads (
     player_id,
@@ -16,7 +43,7 @@ INSERT INTO squads (
 ) VALUES (
     $1, $2
 )
-RETURNING id, player_id, name, created_at
+RETURNING id, player_id, name, created_at, deleted_at, showcased
 `
 
 type CreateSquadParams struct {
@@ -32,14 +59,79 @@ func (q *Queries) CreateSquad(ctx context.Context, arg CreateSquadParams) (Squad
 		&i.PlayerID,
 		&i.Name,
 		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.Showcased,
+	)
+	return i, err
+}
+
+const getSquadByID = `-- name: GetSquadByID :one
+SELECT id, player_id, name, created_at, deleted_at, showcased
+FROM squads
+WHERE id = $1
+`
+
+func (q *Queries) GetSquadByID(ctx context.Context, id int64) (Squad, error) {
+	row := q.db.QueryRowContext(ctx, getSquadByID, id)
+	var i Squad
+	err := row.Scan(
+		&i.ID,
+		&i.PlayerID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.Showcased,
+	)
+	return i, err
+}
+
+const getSquadByIDForShare = `-- name: GetSquadByIDForShare :one
+SELECT id, player_id, name, created_at, deleted_at, showcased
+FROM squads
+WHERE id = $1
+FOR SHARE
+`
+
+func (q *Queries) GetSquadByIDForShare(ctx context.Context, id int64) (Squad, error) {
+	row := q.db.QueryRowContext(ctx, getSquadByIDForShare, id)
+	var i Squad
+	err := row.Scan(
+		&i.ID,
+		&i.PlayerID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.Showcased,
+	)
+	return i, err
+}
+
+const getSquadByIDForUpdate = `-- name: GetSquadByIDForUpdate :one
+SELECT id, player_id, name, created_at, deleted_at, showcased
+FROM squads
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetSquadByIDForUpdate(ctx context.Context, id int64) (Squad, error) {
+	row := q.db.QueryRowContext(ctx, getSquadByIDForUpdate, id)
+	var i Squad
+	err := row.Scan(
+		&i.ID,
+		&i.PlayerID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.Showcased,
 	)
 	return i, err
 }
 
 const getSquadsForPlayer = `-- name: GetSquadsForPlayer :many
-SELECT id, player_id, name, created_at
+SELECT id, player_id, name, created_at, deleted_at, showcased
 FROM squads
 WHERE player_id = $1
+  AND deleted_at IS NULL
 ORDER BY created_at DESC
 `
 
@@ -57,6 +149,8 @@ func (q *Queries) GetSquadsForPlayer(ctx context.Context, playerID int64) ([]Squ
 			&i.PlayerID,
 			&i.Name,
 			&i.CreatedAt,
+			&i.DeletedAt,
+			&i.Showcased,
 		); err != nil {
 			return nil, err
 		}
@@ -70,3 +164,121 @@ func (q *Queries) GetSquadsForPlayer(ctx context.Context, playerID int64) ([]Squ
 	}
 	return items, nil
 }
+
+const listShowcaseSquadUnits = `-- name: ListShowcaseSquadUnits :many
+SELECT
+    s.id AS squad_id,
+    s.name AS squad_name,
+    su.position,
+    u.id AS unit_id,
+    u.name AS unit_name,
+    ut.name AS type_name
+FROM squads s
+JOIN squad_units su ON su.squad_id = s.id
+JOIN units u ON u.id = su.unit_id
+JOIN unit_types ut ON ut.id = u.type_id
+WHERE s.player_id = $1
+  AND s.showcased
+  AND s.deleted_at IS NULL
+ORDER BY s.id, su.position
+`
+
+type ListShowcaseSquadUnitsRow struct {
+	SquadID   int64
+	SquadName string
+	Position  int32
+	UnitID    int64
+	UnitName  string
+	TypeName  string
+}
+
+// Every unit of a player's showcased squads, squad by squad
+func (q *Queries) ListShowcaseSquadUnits(ctx context.Context, playerID int64) ([]ListShowcaseSquadUnitsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listShowcaseSquadUnits, playerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListShowcaseSquadUnitsRow
+	for rows.Next() {
+		var i ListShowcaseSquadUnitsRow
+		if err := rows.Scan(
+			&i.SquadID,
+			&i.SquadName,
+			&i.Position,
+			&i.UnitID,
+			&i.UnitName,
+			&i.TypeName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const showcaseSquad = `-- name: ShowcaseSquad :execrows
+UPDATE squads
+SET showcased = TRUE
+WHERE id = $1
+  AND player_id = $2
+  AND deleted_at IS NULL
+`
+
+type ShowcaseSquadParams struct {
+	ID       int64
+	PlayerID int64
+}
+
+// Affects no rows unless the squad exists, belongs to the player and hasn't
+// been deleted
+func (q *Queries) ShowcaseSquad(ctx context.Context, arg ShowcaseSquadParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, showcaseSquad, arg.ID, arg.PlayerID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const softDeleteSquad = `-- name: SoftDeleteSquad :exec
+UPDATE squads
+SET deleted_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+func (q *Queries) SoftDeleteSquad(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, softDeleteSquad, id)
+	return err
+}
+
+const updateSquadName = `-- name: UpdateSquadName :one
+UPDATE squads
+SET name = $2
+WHERE id = $1
+RETURNING id, player_id, name, created_at, deleted_at, showcased
+`
+
+type UpdateSquadNameParams struct {
+	ID   int64
+	Name string
+}
+
+func (q *Queries) UpdateSquadName(ctx context.Context, arg UpdateSquadNameParams) (Squad, error) {
+	row := q.db.QueryRowContext(ctx, updateSquadName, arg.ID, arg.Name)
+	var i Squad
+	err := row.Scan(
+		&i.ID,
+		&i.PlayerID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.Showcased,
+	)
+	return i, err
+}
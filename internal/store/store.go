@@ -0,0 +1,54 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Store is everything the server and game service need from storage: the
+// sqlc queries plus a way to run several of them in one transaction.
+type Store interface {
+	Querier
+	// ExecTx runs fn in a transaction. The transaction is committed if fn
+	// returns nil and rolled back otherwise.
+	ExecTx(ctx context.Context, fn func(q Querier) error) error
+}
+
+// SQLStore is a Store backed by a database/sql connection pool.
+type SQLStore struct {
+	*Queries
+	db *sql.DB
+	// conn adapts a connection or transaction to the database's dialect
+	conn func(DBTX) DBTX
+}
+
+// NewSQLStore returns a Store for a Postgres database.
+func NewSQLStore(db *sql.DB) *SQLStore {
+	return newSQLStore(db, func(c DBTX) DBTX { return c })
+}
+
+func newSQLStore(db *sql.DB, conn func(DBTX) DBTX) *SQLStore {
+	return &SQLStore{
+		Queries: New(conn(db)),
+		db:      db,
+		conn:    conn,
+	}
+}
+
+func (s *SQLStore) ExecTx(ctx context.Context, fn func(q Querier) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+
+	if err := fn(New(s.conn(tx))); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
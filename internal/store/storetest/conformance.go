@@ -0,0 +1,353 @@
+package storetest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/76dillon/battle_squads/internal/store"
+)
+
+// Run is the conformance suite: the behaviour the services rely on from
+// every store, beyond what each query returns. Each test gets a fresh store
+// from open.
+func Run(t *testing.T, open Opener) {
+	tests := []struct {
+		name string
+		fn   func(*testing.T, store.Store)
+	}{
+		{"Seed", testSeed},
+		{"ExecTxCommits", testExecTxCommits},
+		{"ExecTxRollsBack", testExecTxRollsBack},
+		{"Unique", testUnique},
+		{"ForeignKey", testForeignKey},
+		{"OnConflictDoNothing", testOnConflictDoNothing},
+		{"OnConflictDoUpdateWhere", testOnConflictDoUpdateWhere},
+		{"ForUpdate", testForUpdate},
+		{"ForUpdateSerializes", testForUpdateSerializes},
+		{"NullableFilter", testNullableFilter},
+		{"Timestamps", testTimestamps},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, open(t))
+		})
+	}
+}
+
+// adminID and standardRulesetID are the rows the migrations seed.
+const (
+	adminID           = 1
+	standardRulesetID = 1
+)
+
+func testSeed(t *testing.T, st store.Store) {
+	ctx := context.Background()
+	admin, err := st.GetPlayerByID(ctx, adminID)
+	if err != nil {
+		t.Fatalf("get admin: %v", err)
+	}
+	if !admin.IsAdmin {
+		t.Errorf("player %d is not an admin", adminID)
+	}
+	if _, err := st.GetRulesetByID(ctx, standardRulesetID); err != nil {
+		t.Errorf("get standard ruleset: %v", err)
+	}
+}
+
+func testExecTxCommits(t *testing.T, st store.Store) {
+	ctx := context.Background()
+	err := st.ExecTx(ctx, func(q store.Querier) error {
+		_, err := q.CreateUnitType(ctx, "Fire")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := countUnitTypes(t, st); n != 1 {
+		t.Errorf("%d unit types after commit, want 1", n)
+	}
+}
+
+func testExecTxRollsBack(t *testing.T, st store.Store) {
+	ctx := context.Background()
+	errFail := errors.New("fail")
+	err := st.ExecTx(ctx, func(q store.Querier) error {
+		if _, err := q.CreateUnitType(ctx, "Fire"); err != nil {
+			return err
+		}
+		return errFail
+	})
+	if !errors.Is(err, errFail) {
+		t.Fatalf("ExecTx: got %v, want the callback's error", err)
+	}
+	if n := countUnitTypes(t, st); n != 0 {
+		t.Errorf("%d unit types after rollback, want 0", n)
+	}
+}
+
+func testUnique(t *testing.T, st store.Store) {
+	ctx := context.Background()
+	if _, err := st.CreateUnitType(ctx, "Fire"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.CreateUnitType(ctx, "Fire"); err == nil {
+		t.Error("duplicate unit type name was accepted")
+	}
+}
+
+func testForeignKey(t *testing.T, st store.Store) {
+	ctx := context.Background()
+	_, err := st.CreateSquad(ctx, store.CreateSquadParams{PlayerID: 999, Name: "Nobody's"})
+	if err == nil {
+		t.Error("squad for a missing player was accepted")
+	}
+}
+
+func testOnConflictDoNothing(t *testing.T, st store.Store) {
+	ctx := context.Background()
+	arg := store.CreateIdempotencyKeyParams{
+		PlayerID:    adminID,
+		Key:         "k",
+		Method:      "POST",
+		Path:        "/matches",
+		RequestHash: "h",
+	}
+	if _, err := st.CreateIdempotencyKey(ctx, arg); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.CreateIdempotencyKey(ctx, arg); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("second claim: got %v, want sql.ErrNoRows", err)
+	}
+}
+
+// testOnConflictDoUpdateWhere reclaims an idempotency key, which only
+// happens while it is in flight, for the same request and claimed before
+// stale_before.
+func testOnConflictDoUpdateWhere(t *testing.T, st store.Store) {
+	ctx := context.Background()
+	arg := store.CreateIdempotencyKeyParams{
+		PlayerID:    adminID,
+		Key:         "k",
+		Method:      "POST",
+		Path:        "/matches",
+		RequestHash: "h",
+	}
+	first, err := st.CreateIdempotencyKey(ctx, arg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stale := arg
+	stale.StaleBefore = time.Now().Add(time.Hour)
+	other := stale
+	other.RequestHash = "other"
+	if _, err := st.CreateIdempotencyKey(ctx, other); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("claim for another request: got %v, want sql.ErrNoRows", err)
+	}
+	again, err := st.CreateIdempotencyKey(ctx, stale)
+	if err != nil {
+		t.Fatalf("reclaim: %v", err)
+	}
+	if !again.CreatedAt.Equal(first.CreatedAt) || again.ClaimedAt.Before(first.ClaimedAt) {
+		t.Errorf("reclaim: created %v claimed %v, want created %v and claimed no earlier than %v",
+			again.CreatedAt, again.ClaimedAt, first.CreatedAt, first.ClaimedAt)
+	}
+
+	err = st.CompleteIdempotencyKey(ctx, store.CompleteIdempotencyKeyParams{
+		PlayerID:   adminID,
+		Key:        "k",
+		StatusCode: 201,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.CreateIdempotencyKey(ctx, stale); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("claim of a completed key: got %v, want sql.ErrNoRows", err)
+	}
+}
+
+// testForUpdate runs every locking query, which the SQLite store rewrites.
+func testForUpdate(t *testing.T, st store.Store) {
+	ctx := context.Background()
+	bob := newPlayer(t, st, "bob")
+	sq, err := st.CreateSquad(ctx, store.CreateSquadParams{PlayerID: adminID, Name: "Admins"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := st.CreateMatch(ctx, store.CreateMatchParams{Player1ID: adminID, Player2ID: bob, RulesetID: standardRulesetID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch, err := st.CreateChallenge(ctx, store.CreateChallengeParams{
+		ChallengerID:      adminID,
+		OpponentID:        bob,
+		ChallengerSquadID: sq.ID,
+		RulesetID:         standardRulesetID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tn, err := st.CreateTournament(ctx, store.CreateTournamentParams{
+		Name:       "Cup",
+		Format:     "round_robin",
+		MaxPlayers: 4,
+		CreatedBy:  adminID,
+		RulesetID:  standardRulesetID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = st.ExecTx(ctx, func(q store.Querier) error {
+		if rating, err := q.GetPlayerRatingForUpdate(ctx, bob); err != nil {
+			t.Errorf("GetPlayerRatingForUpdate: %v", err)
+		} else if rating != 1000 {
+			t.Errorf("rating = %d, want 1000", rating)
+		}
+		if got, err := q.GetMatchByIDForUpdate(ctx, m.ID); err != nil {
+			t.Errorf("GetMatchByIDForUpdate: %v", err)
+		} else if got.ID != m.ID {
+			t.Errorf("GetMatchByIDForUpdate: got row %d, want %d", got.ID, m.ID)
+		}
+		if got, err := q.GetChallengeByIDForUpdate(ctx, ch.ID); err != nil {
+			t.Errorf("GetChallengeByIDForUpdate: %v", err)
+		} else if got.ID != ch.ID {
+			t.Errorf("GetChallengeByIDForUpdate: got row %d, want %d", got.ID, ch.ID)
+		}
+		if got, err := q.GetSquadByIDForShare(ctx, sq.ID); err != nil {
+			t.Errorf("GetSquadByIDForShare: %v", err)
+		} else if got.ID != sq.ID {
+			t.Errorf("GetSquadByIDForShare: got row %d, want %d", got.ID, sq.ID)
+		}
+		if got, err := q.GetSquadByIDForUpdate(ctx, sq.ID); err != nil {
+			t.Errorf("GetSquadByIDForUpdate: %v", err)
+		} else if got.ID != sq.ID {
+			t.Errorf("GetSquadByIDForUpdate: got row %d, want %d", got.ID, sq.ID)
+		}
+		if got, err := q.GetTournamentByIDForUpdate(ctx, tn.ID); err != nil {
+			t.Errorf("GetTournamentByIDForUpdate: %v", err)
+		} else if got.ID != tn.ID || got.RulesetID != standardRulesetID {
+			t.Errorf("GetTournamentByIDForUpdate: got %+v", got)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testForUpdateSerializes runs read-modify-write transactions on the same
+// row at once. The row lock must apply them one at a time, so none is lost.
+func testForUpdateSerializes(t *testing.T, st store.Store) {
+	ctx := context.Background()
+	const n = 10
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = st.ExecTx(ctx, func(q store.Querier) error {
+				rating, err := q.GetPlayerRatingForUpdate(ctx, adminID)
+				if err != nil {
+					return err
+				}
+				return q.UpdatePlayerRating(ctx, store.UpdatePlayerRatingParams{ID: adminID, Rating: rating + 1})
+			})
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	admin, err := st.GetPlayerByID(ctx, adminID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if admin.Rating != 1000+n {
+		t.Errorf("rating = %d, want %d", admin.Rating, 1000+n)
+	}
+}
+
+// testNullableFilter checks a filter that is skipped when its argument is
+// NULL.
+func testNullableFilter(t *testing.T, st store.Store) {
+	ctx := context.Background()
+	for _, name := range []string{"Spring Cup", "Summer Cup"} {
+		_, err := st.CreateTournament(ctx, store.CreateTournamentParams{
+			Name:       name,
+			Format:     "round_robin",
+			MaxPlayers: 4,
+			CreatedBy:  adminID,
+			RulesetID:  standardRulesetID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	all, err := st.ListTournaments(ctx, store.ListTournamentsParams{RowLimit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 || all[0].Name != "Summer Cup" {
+		t.Errorf("all tournaments, newest first: got %+v", all)
+	}
+	older, err := st.ListTournaments(ctx, store.ListTournamentsParams{
+		BeforeID: sql.NullInt64{Int64: all[0].ID, Valid: true},
+		RowLimit: 10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(older) != 1 || older[0].Name != "Spring Cup" {
+		t.Errorf("tournaments before %d: got %+v", all[0].ID, older)
+	}
+	started, err := st.ListTournaments(ctx, store.ListTournamentsParams{
+		State:    sql.NullString{String: "IN_PROGRESS", Valid: true},
+		RowLimit: 10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(started) != 0 {
+		t.Errorf("started tournaments: got %+v, want none", started)
+	}
+}
+
+func testTimestamps(t *testing.T, st store.Store) {
+	ctx := context.Background()
+	before := time.Now().Add(-time.Minute)
+	id := newPlayer(t, st, "bob")
+	p, err := st.GetPlayerByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.CreatedAt.Before(before) || p.CreatedAt.After(time.Now().Add(time.Minute)) {
+		t.Errorf("created_at = %v, want about now", p.CreatedAt)
+	}
+}
+
+func newPlayer(t *testing.T, st store.Store, name string) int64 {
+	t.Helper()
+	p, err := st.CreatePlayer(context.Background(), store.CreatePlayerParams{Username: name, PasswordHash: "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p.ID
+}
+
+func countUnitTypes(t *testing.T, st store.Store) int {
+	t.Helper()
+	types, err := st.ListUnitTypes(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(types)
+}
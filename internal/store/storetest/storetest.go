@@ -0,0 +1,133 @@
+// Package storetest opens the stores the tests run against, and holds the
+// conformance suite every store must pass.
+package storetest
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/lib/pq"  // register postgres driver
+	_ "modernc.org/sqlite" // register sqlite driver
+
+	"github.com/76dillon/battle_squads/internal/config"
+	"github.com/76dillon/battle_squads/internal/migrate"
+	"github.com/76dillon/battle_squads/internal/store"
+)
+
+// Opener returns a fresh, empty store, seeded like the migrations seed a
+// database, that is cleaned up when t finishes.
+type Opener func(t testing.TB) store.Store
+
+// Backends returns every store to run the tests against, by name. Postgres
+// is only included when TEST_DATABASE_URL is set.
+func Backends() map[string]Opener {
+	b := map[string]Opener{
+		"memory": Memory,
+		"sqlite": SQLite,
+	}
+	if os.Getenv("TEST_DATABASE_URL") != "" {
+		b["postgres"] = Postgres
+	}
+	return b
+}
+
+// Memory returns an empty MemoryStore.
+func Memory(t testing.TB) store.Store {
+	return store.NewMemoryStore()
+}
+
+// Postgres returns a store backed by a fresh, fully migrated schema in the
+// Postgres database at TEST_DATABASE_URL, a postgres:// URL. The schema is
+// dropped when t finishes. The test is skipped if TEST_DATABASE_URL is unset.
+func Postgres(t testing.TB) store.Store {
+	t.Helper()
+	dbURL := os.Getenv("TEST_DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	driver, dsn, err := config.ParseDatabaseURL(dbURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if driver != "postgres" {
+		t.Fatalf("TEST_DATABASE_URL must be a postgres:// URL, got %s", driver)
+	}
+
+	admin, err := sql.Open(driver, dsn)
+	if err != nil {
+		t.Fatalf("open postgres: %v", err)
+	}
+	t.Cleanup(func() { admin.Close() })
+	var b [6]byte
+	rand.Read(b[:])
+	schema := "test_" + hex.EncodeToString(b[:])
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec("DROP SCHEMA " + schema + " CASCADE"); err != nil {
+			t.Errorf("drop schema: %v", err)
+		}
+	})
+
+	// Every connection gets the schema first on its search path, so the
+	// migrations and queries run in it unchanged
+	u, err := url.Parse(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	q.Set("search_path", schema)
+	u.RawQuery = q.Encode()
+	db, err := sql.Open(driver, u.String())
+	if err != nil {
+		t.Fatalf("open postgres: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	migrateUp(t, db, driver)
+	return store.NewSQLStore(db)
+}
+
+// SQLite returns a store backed by a fresh, fully migrated SQLite database
+// in t's temp directory, opened the way the server opens it.
+func SQLite(t testing.TB) store.Store {
+	t.Helper()
+	return store.NewSQLiteStore(OpenSQLite(t))
+}
+
+// OpenSQLite opens and migrates a fresh SQLite database in t's temp
+// directory. It is closed when t finishes.
+func OpenSQLite(t testing.TB) *sql.DB {
+	t.Helper()
+	driver, dsn, err := config.ParseDatabaseURL("sqlite://" + filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	// SQLite allows one writer at a time anyway
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	migrateUp(t, db, driver)
+	return db
+}
+
+func migrateUp(t testing.TB, db *sql.DB, driver string) {
+	t.Helper()
+	m, err := migrate.New(db, driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Up(context.Background(), io.Discard); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -9,10 +9,25 @@ import (
 	"context"
 )
 
+const countActiveSquadsWithUnit = `-- name: CountActiveSquadsWithUnit :one
+SELECT COUNT(DISTINCT s.id)
+FROM squad_units su
+JOIN squads s ON s.id = su.squad_id
+WHERE su.unit_id = $1
+  AND s.deleted_at IS NULL
+`
+
+func (q *Queries) CountActiveSquadsWithUnit(ctx context.Context, unitID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countActiveSquadsWithUnit, unitID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createUnit = `-- name: CreateUnit :one
-INSERT INTO units (name, type_id, base_hp, base_attack, base_speed)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, name, type_id, base_hp, base_attack, base_speed
+INSERT INTO units (name, type_id, base_hp, base_attack, base_speed, ability)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, name, type_id, base_hp, base_attack, base_speed, deleted_at, ability
 `
 
 type CreateUnitParams struct {
@@ -21,6 +36,7 @@ type CreateUnitParams struct {
 	BaseHp     int32
 	BaseAttack int32
 	BaseSpeed  int32
+	Ability    string
 }
 
 func (q *Queries) CreateUnit(ctx context.Context, arg CreateUnitParams) (Unit, error) {
@@ -30,6 +46,7 @@ func (q *Queries) CreateUnit(ctx context.Context, arg CreateUnitParams) (Unit, e
 		arg.BaseHp,
 		arg.BaseAttack,
 		arg.BaseSpeed,
+		arg.Ability,
 	)
 	var i Unit
 	err := row.Scan(
@@ -39,13 +56,15 @@ func (q *Queries) CreateUnit(ctx context.Context, arg CreateUnitParams) (Unit, e
 		&i.BaseHp,
 		&i.BaseAttack,
 		&i.BaseSpeed,
+		&i.DeletedAt,
+		&i.Ability,
 	)
 	return i, err
 }
 
 const getUnitByID = `-- name: GetUnitByID :one
 SELECT
-  id, name, type_id, base_hp, base_attack, base_speed
+  id, name, type_id, base_hp, base_attack, base_speed, deleted_at, ability
 FROM units
 WHERE id = $1
 `
@@ -60,14 +79,17 @@ func (q *Queries) GetUnitByID(ctx context.Context, id int64) (Unit, error) {
 		&i.BaseHp,
 		&i.BaseAttack,
 		&i.BaseSpeed,
+		&i.DeletedAt,
+		&i.Ability,
 	)
 	return i, err
 }
 
 const listUnits = `-- name: ListUnits :many
 SELECT
-  id, name, type_id, base_hp, base_attack, base_speed
+  id, name, type_id, base_hp, base_attack, base_speed, deleted_at, ability
 FROM units
+WHERE deleted_at IS NULL
 ORDER BY id
 `
 
@@ -87,6 +109,8 @@ func (q *Queries) ListUnits(ctx context.Context) ([]Unit, error) {
 			&i.BaseHp,
 			&i.BaseAttack,
 			&i.BaseSpeed,
+			&i.DeletedAt,
+			&i.Ability,
 		); err != nil {
 			return nil, err
 		}
@@ -100,3 +124,55 @@ func (q *Queries) ListUnits(ctx context.Context) ([]Unit, error) {
 	}
 	return items, nil
 }
+
+const softDeleteUnit = `-- name: SoftDeleteUnit :exec
+UPDATE units
+SET deleted_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+func (q *Queries) SoftDeleteUnit(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, softDeleteUnit, id)
+	return err
+}
+
+const updateUnit = `-- name: UpdateUnit :one
+UPDATE units
+SET name = $2, type_id = $3, base_hp = $4, base_attack = $5, base_speed = $6, ability = $7
+WHERE id = $1
+RETURNING id, name, type_id, base_hp, base_attack, base_speed, deleted_at, ability
+`
+
+type UpdateUnitParams struct {
+	ID         int64
+	Name       string
+	TypeID     int64
+	BaseHp     int32
+	BaseAttack int32
+	BaseSpeed  int32
+	Ability    string
+}
+
+func (q *Queries) UpdateUnit(ctx context.Context, arg UpdateUnitParams) (Unit, error) {
+	row := q.db.QueryRowContext(ctx, updateUnit,
+		arg.ID,
+		arg.Name,
+		arg.TypeID,
+		arg.BaseHp,
+		arg.BaseAttack,
+		arg.BaseSpeed,
+		arg.Ability,
+	)
+	var i Unit
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.TypeID,
+		&i.BaseHp,
+		&i.BaseAttack,
+		&i.BaseSpeed,
+		&i.DeletedAt,
+		&i.Ability,
+	)
+	return i, err
+}
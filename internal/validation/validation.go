@@ -0,0 +1,46 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Problem is one reason a request was rejected. Field names the request
+// field it relates to, e.g. "name" or "unit_ids[2]".
+type Problem struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error lists every problem found with a request, so clients can show them
+// all at once instead of fixing one per request. Subject names what was being
+// validated, e.g. "squad" or "unit".
+type Error struct {
+	Subject  string
+	Problems []Problem
+}
+
+func New(subject string) *Error {
+	return &Error{Subject: subject}
+}
+
+func (e *Error) Error() string {
+	msgs := make([]string, 0, len(e.Problems))
+	for _, p := range e.Problems {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", p.Field, p.Message))
+	}
+	return "invalid " + e.Subject + ": " + strings.Join(msgs, "; ")
+}
+
+// Add records a problem with field.
+func (e *Error) Add(field, format string, args ...any) {
+	e.Problems = append(e.Problems, Problem{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// Err returns e if any problems were recorded, nil otherwise.
+func (e *Error) Err() error {
+	if len(e.Problems) == 0 {
+		return nil
+	}
+	return e
+}
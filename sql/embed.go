@@ -0,0 +1,15 @@
+// Package sql embeds the goose migrations so the server binary can apply
+// them itself.
+package sql
+
+import "embed"
+
+// PostgresSchema holds the migrations in sql/schema.
+//
+//go:embed schema/*.sql
+var PostgresSchema embed.FS
+
+// SQLiteSchema holds the migrations in sql/sqlite/schema.
+//
+//go:embed sqlite/schema/*.sql
+var SQLiteSchema embed.FS
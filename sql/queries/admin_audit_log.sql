@@ -0,0 +1,10 @@
+-- name: CreateAdminAuditEntry :one
+INSERT INTO admin_audit_log (admin_player_id, action, entity_type, entity_id, details)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, admin_player_id, action, entity_type, entity_id, details, created_at;
+
+-- name: ListAdminAuditEntries :many
+SELECT id, admin_player_id, action, entity_type, entity_id, details, created_at
+FROM admin_audit_log
+ORDER BY id DESC
+LIMIT $1;
@@ -0,0 +1,113 @@
+-- name: ListUnitAnalytics :many
+-- Raw counts behind the unit balance report, for every unit that hasn't
+-- been deleted. Squads count by creation time, results by completion time
+-- and turns by when they were played, each within [from_time, to_time).
+-- hits counts the uses that didn't miss.
+SELECT
+    u.id,
+    u.name,
+    (
+        SELECT COUNT(*)
+        FROM squads s
+        WHERE (s.created_at >= sqlc.narg(from_time) OR sqlc.narg(from_time) IS NULL)
+          AND (s.created_at < sqlc.narg(to_time) OR sqlc.narg(to_time) IS NULL)
+    ) AS total_squads,
+    CAST(COALESCE(picks.squads, 0) AS BIGINT) AS squads_picked,
+    CAST(COALESCE(results.fielded, 0) AS BIGINT) AS times_fielded,
+    CAST(COALESCE(results.wins, 0) AS BIGINT) AS wins,
+    CAST(COALESCE(turns.uses, 0) AS BIGINT) AS uses,
+    CAST(COALESCE(turns.hits, 0) AS BIGINT) AS hits,
+    CAST(COALESCE(turns.damage, 0) AS BIGINT) AS damage_dealt,
+    CAST(COALESCE(turns.kos, 0) AS BIGINT) AS kos
+FROM units u
+LEFT JOIN (
+    SELECT su.unit_id, COUNT(DISTINCT su.squad_id) AS squads
+    FROM squad_units su
+    JOIN squads s ON s.id = su.squad_id
+    WHERE (s.created_at >= sqlc.narg(from_time) OR sqlc.narg(from_time) IS NULL)
+      AND (s.created_at < sqlc.narg(to_time) OR sqlc.narg(to_time) IS NULL)
+    GROUP BY su.unit_id
+) picks ON picks.unit_id = u.id
+LEFT JOIN (
+    -- One row per side that fielded the unit in a completed match
+    SELECT
+        mu.unit_id,
+        COUNT(DISTINCT ms.id) AS fielded,
+        COUNT(DISTINCT CASE WHEN ms.player_id = m.winner_player_id THEN ms.id END) AS wins
+    FROM match_units mu
+    JOIN match_sides ms ON ms.id = mu.match_side_id
+    JOIN matches m ON m.id = ms.match_id
+    WHERE m.state = 'COMPLETED'
+      AND (m.completed_at >= sqlc.narg(from_time) OR sqlc.narg(from_time) IS NULL)
+      AND (m.completed_at < sqlc.narg(to_time) OR sqlc.narg(to_time) IS NULL)
+    GROUP BY mu.unit_id
+) results ON results.unit_id = u.id
+LEFT JOIN (
+    SELECT
+        mu.unit_id,
+        COUNT(*) AS uses,
+        SUM(CASE WHEN mt.missed THEN 0 ELSE 1 END) AS hits,
+        SUM(mt.damage_done) AS damage,
+        SUM(CASE WHEN mt.did_ko_target THEN 1 ELSE 0 END) AS kos
+    FROM match_turns mt
+    JOIN match_units mu ON mu.id = mt.acting_match_unit_id
+    WHERE mt.move_id IS NOT NULL
+      AND (mt.created_at >= sqlc.narg(from_time) OR sqlc.narg(from_time) IS NULL)
+      AND (mt.created_at < sqlc.narg(to_time) OR sqlc.narg(to_time) IS NULL)
+    GROUP BY mu.unit_id
+) turns ON turns.unit_id = u.id
+WHERE u.deleted_at IS NULL
+ORDER BY u.id;
+
+-- name: ListMoveAnalytics :many
+-- Raw counts behind the move balance report, for every move that hasn't
+-- been deleted. Uses count by when they were played and results by
+-- completion time, each within [from_time, to_time). hits counts the uses
+-- that didn't miss.
+SELECT
+    mv.id,
+    mv.name,
+    (
+        SELECT COUNT(*)
+        FROM match_turns mt
+        WHERE mt.move_id IS NOT NULL
+          AND (mt.created_at >= sqlc.narg(from_time) OR sqlc.narg(from_time) IS NULL)
+          AND (mt.created_at < sqlc.narg(to_time) OR sqlc.narg(to_time) IS NULL)
+    ) AS total_uses,
+    CAST(COALESCE(turns.uses, 0) AS BIGINT) AS uses,
+    CAST(COALESCE(turns.hits, 0) AS BIGINT) AS hits,
+    CAST(COALESCE(turns.damage, 0) AS BIGINT) AS damage_dealt,
+    CAST(COALESCE(turns.kos, 0) AS BIGINT) AS kos,
+    CAST(COALESCE(results.matches_used, 0) AS BIGINT) AS matches_used,
+    CAST(COALESCE(results.wins, 0) AS BIGINT) AS wins
+FROM moves mv
+LEFT JOIN (
+    SELECT
+        mt.move_id,
+        COUNT(*) AS uses,
+        SUM(CASE WHEN mt.missed THEN 0 ELSE 1 END) AS hits,
+        SUM(mt.damage_done) AS damage,
+        SUM(CASE WHEN mt.did_ko_target THEN 1 ELSE 0 END) AS kos
+    FROM match_turns mt
+    WHERE (mt.created_at >= sqlc.narg(from_time) OR sqlc.narg(from_time) IS NULL)
+      AND (mt.created_at < sqlc.narg(to_time) OR sqlc.narg(to_time) IS NULL)
+    GROUP BY mt.move_id
+) turns ON turns.move_id = mv.id
+LEFT JOIN (
+    -- One row per player who used the move in a completed match
+    SELECT
+        used.move_id,
+        COUNT(*) AS matches_used,
+        SUM(CASE WHEN used.acting_player_id = m.winner_player_id THEN 1 ELSE 0 END) AS wins
+    FROM (
+        SELECT DISTINCT match_id, acting_player_id, move_id
+        FROM match_turns
+    ) used
+    JOIN matches m ON m.id = used.match_id
+    WHERE m.state = 'COMPLETED'
+      AND (m.completed_at >= sqlc.narg(from_time) OR sqlc.narg(from_time) IS NULL)
+      AND (m.completed_at < sqlc.narg(to_time) OR sqlc.narg(to_time) IS NULL)
+    GROUP BY used.move_id
+) results ON results.move_id = mv.id
+WHERE mv.deleted_at IS NULL
+ORDER BY mv.id;
@@ -0,0 +1,90 @@
+-- name: CreateChallenge :one
+INSERT INTO challenges (
+    challenger_id,
+    opponent_id,
+    challenger_squad_id,
+    is_public,
+    ruleset_id
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+RETURNING
+    id,
+    challenger_id,
+    opponent_id,
+    challenger_squad_id,
+    is_public,
+    state,
+    match_id,
+    created_at,
+    responded_at,
+    ruleset_id;
+
+-- name: GetChallengeByID :one
+SELECT
+    id,
+    challenger_id,
+    opponent_id,
+    challenger_squad_id,
+    is_public,
+    state,
+    match_id,
+    created_at,
+    responded_at,
+    ruleset_id
+FROM challenges
+WHERE id = $1;
+
+-- name: GetChallengeByIDForUpdate :one
+SELECT
+    id,
+    challenger_id,
+    opponent_id,
+    challenger_squad_id,
+    is_public,
+    state,
+    match_id,
+    created_at,
+    responded_at,
+    ruleset_id
+FROM challenges
+WHERE id = $1
+FOR UPDATE;
+
+-- name: ListPendingChallengesForPlayer :many
+-- Challenges player_id sent or received that are waiting for an answer,
+-- newest first
+SELECT
+    c.id,
+    c.challenger_id,
+    challenger.username AS challenger_username,
+    c.opponent_id,
+    opponent.username AS opponent_username,
+    c.is_public,
+    c.created_at,
+    c.ruleset_id
+FROM challenges c
+JOIN players challenger ON challenger.id = c.challenger_id
+JOIN players opponent ON opponent.id = c.opponent_id
+WHERE c.state = 'PENDING'
+  AND (c.challenger_id = sqlc.arg(player_id) OR c.opponent_id = sqlc.arg(player_id))
+ORDER BY c.id DESC;
+
+-- name: RespondToChallenge :one
+UPDATE challenges
+SET
+    state = $2,
+    match_id = $3,
+    responded_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING
+    id,
+    challenger_id,
+    opponent_id,
+    challenger_squad_id,
+    is_public,
+    state,
+    match_id,
+    created_at,
+    responded_at,
+    ruleset_id;
@@ -0,0 +1,65 @@
+-- name: CountBlocksBetween :one
+-- Non-zero if either player has blocked the other
+SELECT COUNT(*)
+FROM friendships
+WHERE status = 'blocked'
+  AND ((player_id = sqlc.arg(player_id) AND friend_id = sqlc.arg(other_id))
+    OR (player_id = sqlc.arg(other_id) AND friend_id = sqlc.arg(player_id)));
+
+-- name: CreateFriendship :one
+INSERT INTO friendships (
+    player_id,
+    friend_id,
+    status
+) VALUES (
+    $1, $2, $3
+)
+RETURNING player_id, friend_id, status, created_at, updated_at;
+
+-- name: DeleteFriendship :exec
+DELETE FROM friendships
+WHERE player_id = $1
+  AND friend_id = $2;
+
+-- name: ListFriendshipsBetween :many
+-- The rows between two players in either direction: one request or
+-- friendship, or up to two blocks
+SELECT player_id, friend_id, status, created_at, updated_at
+FROM friendships
+WHERE (player_id = sqlc.arg(player_id) AND friend_id = sqlc.arg(other_id))
+   OR (player_id = sqlc.arg(other_id) AND friend_id = sqlc.arg(player_id));
+
+-- name: ListFriendships :many
+-- Every player player_id has a row with, except players who blocked them,
+-- with whether the other player is in a match right now
+SELECT
+    f.player_id,
+    f.friend_id,
+    f.status,
+    f.updated_at,
+    p.id AS other_id,
+    p.username,
+    p.display_name,
+    p.avatar_key,
+    p.rating,
+    p.created_at,
+    p.last_seen_at,
+    EXISTS (
+        SELECT 1
+        FROM matches m
+        WHERE m.state = 'IN_PROGRESS'
+          AND (m.player1_id = p.id OR m.player2_id = p.id)
+    ) AS in_match
+FROM friendships f
+JOIN players p ON p.id = CASE WHEN f.player_id = sqlc.arg(player_id) THEN f.friend_id ELSE f.player_id END
+WHERE f.player_id = sqlc.arg(player_id)
+   OR (f.friend_id = sqlc.arg(player_id) AND f.status <> 'blocked')
+ORDER BY p.username;
+
+-- name: UpdateFriendshipStatus :exec
+UPDATE friendships
+SET
+    status = $3,
+    updated_at = CURRENT_TIMESTAMP
+WHERE player_id = $1
+  AND friend_id = $2;
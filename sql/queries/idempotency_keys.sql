@@ -0,0 +1,45 @@
+-- name: CreateIdempotencyKey :one
+-- Claims a key. A key already claimed for the same request is claimed again
+-- if it is still in flight and was claimed before stale_before; otherwise no
+-- row is returned.
+INSERT INTO idempotency_keys (
+    player_id,
+    key,
+    method,
+    path,
+    request_hash,
+    claimed_at
+) VALUES (
+    $1, $2, $3, $4, $5, CURRENT_TIMESTAMP
+)
+ON CONFLICT (player_id, key) DO UPDATE
+SET claimed_at = CURRENT_TIMESTAMP
+WHERE idempotency_keys.status_code = 0
+  AND idempotency_keys.request_hash = excluded.request_hash
+  AND idempotency_keys.claimed_at < sqlc.arg(stale_before)
+RETURNING
+    player_id, key, method, path, request_hash,
+    status_code, content_type, response_body, created_at, claimed_at;
+
+-- name: GetIdempotencyKey :one
+SELECT
+    player_id, key, method, path, request_hash,
+    status_code, content_type, response_body, created_at, claimed_at
+FROM idempotency_keys
+WHERE player_id = $1 AND key = $2;
+
+-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET
+    status_code = $3,
+    content_type = $4,
+    response_body = $5
+WHERE player_id = $1 AND key = $2;
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE player_id = $1 AND key = $2;
+
+-- name: DeleteExpiredIdempotencyKeys :exec
+DELETE FROM idempotency_keys
+WHERE created_at < $1;
@@ -0,0 +1,36 @@
+-- name: CreateItem :one
+INSERT INTO items (name, kind, effect, amount)
+VALUES ($1, $2, $3, $4)
+RETURNING id, name, kind, effect, amount, deleted_at;
+
+-- name: GetItemByID :one
+SELECT id, name, kind, effect, amount, deleted_at
+FROM items
+WHERE id = $1;
+
+-- name: ListItems :many
+SELECT id, name, kind, effect, amount, deleted_at
+FROM items
+WHERE deleted_at IS NULL
+ORDER BY id;
+
+-- name: UpdateItem :one
+UPDATE items
+SET name = $2, kind = $3, effect = $4, amount = $5
+WHERE id = $1
+RETURNING id, name, kind, effect, amount, deleted_at;
+
+-- name: SoftDeleteItem :exec
+UPDATE items
+SET deleted_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: CountActiveSquadsWithItem :one
+-- Non-deleted squads with the item in their bag or held by one of their units
+SELECT COUNT(*)
+FROM squads s
+WHERE s.deleted_at IS NULL
+  AND (
+    EXISTS (SELECT 1 FROM squad_bag_items sb WHERE sb.squad_id = s.id AND sb.item_id = sqlc.arg(item_id))
+    OR EXISTS (SELECT 1 FROM squad_units su WHERE su.squad_id = s.id AND su.held_item_id = sqlc.arg(item_id))
+  );
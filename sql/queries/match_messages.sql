@@ -0,0 +1,76 @@
+-- name: CountRecentMatchMessagesByPlayer :one
+SELECT COUNT(*)
+FROM match_messages
+WHERE player_id = $1
+  AND created_at > $2;
+
+-- name: CreateMatchMessage :one
+INSERT INTO match_messages (
+    match_id,
+    player_id,
+    body
+) VALUES (
+    $1, $2, $3
+)
+RETURNING id, match_id, player_id, body, created_at, hidden, reviewed_at;
+
+-- name: CreateMatchMessageReport :execrows
+-- Affects no rows if the player already reported the message
+INSERT INTO match_message_reports (
+    message_id,
+    reporter_id,
+    reason
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (message_id, reporter_id) DO NOTHING;
+
+-- name: GetMatchMessageByID :one
+SELECT id, match_id, player_id, body, created_at, hidden, reviewed_at
+FROM match_messages
+WHERE id = $1;
+
+-- name: ListMatchMessages :many
+-- A match's visible messages with their authors, oldest first
+SELECT
+    mm.id,
+    mm.player_id,
+    p.username,
+    mm.body,
+    mm.created_at
+FROM match_messages mm
+JOIN players p ON p.id = mm.player_id
+WHERE mm.match_id = sqlc.arg(match_id)
+  AND NOT mm.hidden
+  AND (mm.id > sqlc.narg(after_id) OR sqlc.narg(after_id) IS NULL)
+ORDER BY mm.id
+LIMIT sqlc.arg(row_limit);
+
+-- name: ListUnreviewedMessageReports :many
+-- Reports on messages no admin has reviewed yet, grouped by message
+SELECT
+    mm.id AS message_id,
+    mm.match_id,
+    mm.player_id,
+    author.username AS author_username,
+    mm.body,
+    mm.created_at,
+    r.reporter_id,
+    reporter.username AS reporter_username,
+    r.reason,
+    r.created_at AS reported_at
+FROM match_message_reports r
+JOIN match_messages mm ON mm.id = r.message_id
+JOIN players author ON author.id = mm.player_id
+JOIN players reporter ON reporter.id = r.reporter_id
+WHERE mm.reviewed_at IS NULL
+ORDER BY mm.id, r.created_at
+LIMIT $1;
+
+-- name: ReviewMatchMessage :one
+UPDATE match_messages
+SET
+    hidden = $2,
+    reviewed_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, match_id, player_id, body, created_at, hidden, reviewed_at;
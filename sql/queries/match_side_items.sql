@@ -0,0 +1,34 @@
+-- name: CreateMatchSideItem :one
+INSERT INTO match_side_items (
+    match_side_id,
+    item_id,
+    quantity
+) VALUES (
+    $1, $2, $3
+)
+RETURNING match_side_id, item_id, quantity;
+
+-- name: GetMatchSideItem :one
+SELECT match_side_id, item_id, quantity
+FROM match_side_items
+WHERE match_side_id = $1 AND item_id = $2;
+
+-- name: UseMatchSideItem :execrows
+-- Takes one of the item out of the side's bag. Affects no rows if none is
+-- left.
+UPDATE match_side_items
+SET quantity = quantity - 1
+WHERE match_side_id = $1
+  AND item_id = $2
+  AND quantity > 0;
+
+-- name: ListMatchSideItemDetails :many
+-- Every bag item in a match with its catalog details, for match views
+SELECT
+    msi.match_side_id, msi.item_id, msi.quantity,
+    i.name, i.effect, i.amount
+FROM match_side_items msi
+JOIN match_sides ms ON ms.id = msi.match_side_id
+JOIN items i ON i.id = msi.item_id
+WHERE ms.match_id = $1
+ORDER BY msi.match_side_id, msi.item_id;
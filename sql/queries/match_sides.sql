@@ -7,11 +7,11 @@ INSERT INTO match_sides (
 ) VALUES (
     $1, $2, $3, 0
 )
-RETURNING id, match_id, player_id, squad_id, active_index;
+RETURNING id, match_id, player_id, squad_id, active_index, pending_move_id, pending_switch_to, pending_item_id;
 
 -- name: GetMatchSidesByMatchID :many
 SELECT
-    id, match_id, player_id, squad_id, active_index
+    id, match_id, player_id, squad_id, active_index, pending_move_id, pending_switch_to, pending_item_id
 FROM match_sides
 WHERE match_id = $1;
 
@@ -19,4 +19,13 @@ WHERE match_id = $1;
 UPDATE match_sides
 SET active_index = $2
 WHERE id = $1
-RETURNING id, match_id, player_id, squad_id, active_index;
\ No newline at end of file
+RETURNING id, match_id, player_id, squad_id, active_index, pending_move_id, pending_switch_to, pending_item_id;
+-- name: SetMatchSidePendingAction :exec
+-- Records (or, with all NULL, clears) a side's action for the current
+-- round of a simultaneous match
+UPDATE match_sides
+SET
+    pending_move_id = $2,
+    pending_switch_to = $3,
+    pending_item_id = $4
+WHERE id = $1;
@@ -8,9 +8,13 @@ INSERT INTO match_turns (
     target_match_unit_id,
     damage_done,
     target_hp_after,
-    did_ko_target
+    did_ko_target,
+    action,
+    item_id,
+    ability,
+    missed
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
 )
 RETURNING
     id,
@@ -23,13 +27,17 @@ RETURNING
     damage_done,
     target_hp_after,
     did_ko_target,
-    created_at;
+    created_at,
+    action,
+    item_id,
+    ability,
+    missed;
 
 -- name: ListMatchTurns :many
 SELECT
   id, match_id, turn_number, acting_player_id,
   acting_match_unit_id, move_id, target_match_unit_id,
-  damage_done, target_hp_after, did_ko_target, created_at
+  damage_done, target_hp_after, did_ko_target, created_at, action, item_id, ability, missed
 FROM match_turns
 WHERE match_id = $1
-ORDER BY turn_number;
\ No newline at end of file
+ORDER BY turn_number, id;
\ No newline at end of file
@@ -3,21 +3,25 @@ INSERT INTO match_units (
     match_side_id,
     unit_id,
     position,
-    current_hp
+    current_hp,
+    revealed,
+    held_item_id
 ) VALUES (
-    $1, $2, $3, $4
+    $1, $2, $3, $4, $5, $6
 )
-RETURNING id, match_side_id, unit_id, position, current_hp;
+RETURNING id, match_side_id, unit_id, position, current_hp, revealed, held_item_id, attack_bonus, speed_bonus;
 
 -- name: GetMatchUnitsBySideID :many
 SELECT
-    id, match_side_id, unit_id, position, current_hp
+    id, match_side_id, unit_id, position, current_hp, revealed, held_item_id,
+    attack_bonus, speed_bonus
 FROM match_units
 WHERE match_side_id = $1
 ORDER BY position;
 
 -- name: GetActiveMatchUnitForSide :one
-SELECT mu.id, mu.match_side_id, mu.unit_id, mu.position, mu.current_hp
+SELECT mu.id, mu.match_side_id, mu.unit_id, mu.position, mu.current_hp, mu.revealed, mu.held_item_id,
+    mu.attack_bonus, mu.speed_bonus
 FROM match_units mu
 JOIN match_sides ms ON ms.id = mu.match_side_id
 WHERE mu.match_side_id = $1
@@ -27,4 +31,31 @@ WHERE mu.match_side_id = $1
 UPDATE match_units
 SET current_hp = $2
 WHERE id = $1
-RETURNING id, match_side_id, unit_id, position, current_hp;
\ No newline at end of file
+RETURNING id, match_side_id, unit_id, position, current_hp, revealed, held_item_id, attack_bonus, speed_bonus;
+
+-- name: UpdateMatchUnitBonuses :one
+UPDATE match_units
+SET attack_bonus = $2, speed_bonus = $3
+WHERE id = $1
+RETURNING id, match_side_id, unit_id, position, current_hp, revealed, held_item_id, attack_bonus, speed_bonus;
+
+-- name: RevealMatchUnit :exec
+UPDATE match_units
+SET revealed = TRUE
+WHERE match_side_id = $1 AND position = $2;
+
+-- name: ListMatchUnitDetails :many
+-- Every unit in a match with its catalog name, type and ability, and its
+-- held item if any, for match views.
+SELECT
+    mu.id, mu.match_side_id, mu.unit_id, mu.position, mu.current_hp, mu.revealed,
+    u.name AS unit_name, u.base_hp, u.type_id, ut.name AS type_name,
+    mu.held_item_id, i.name AS held_item_name,
+    u.ability, mu.attack_bonus, mu.speed_bonus
+FROM match_units mu
+JOIN match_sides ms ON ms.id = mu.match_side_id
+JOIN units u ON u.id = mu.unit_id
+JOIN unit_types ut ON ut.id = u.type_id
+LEFT JOIN items i ON i.id = mu.held_item_id
+WHERE ms.match_id = $1
+ORDER BY mu.match_side_id, mu.position;
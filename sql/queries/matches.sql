@@ -4,13 +4,17 @@ INSERT INTO matches (
     player1_id,
     player2_id,
     current_turn_number,
-    current_actor_player_id
+    current_actor_player_id,
+    is_public,
+    ruleset_id
 ) VALUES (
     'PENDING',
     $1,                -- player1_id
     $2,                -- player2_id
     0,
-    NULL
+    NULL,
+    $3,                -- is_public
+    $4                 -- ruleset_id
 )
 RETURNING
     id,
@@ -22,7 +26,10 @@ RETURNING
     player2_id,
     winner_player_id,
     current_turn_number,
-    current_actor_player_id;
+    current_actor_player_id,
+    is_public,
+    ruleset_id,
+    turn_started_at;
 
 -- name: GetMatchByID :one
 SELECT
@@ -35,10 +42,32 @@ SELECT
     player2_id,
     winner_player_id,
     current_turn_number,
-    current_actor_player_id
+    current_actor_player_id,
+    is_public,
+    ruleset_id,
+    turn_started_at
 FROM matches
 WHERE id = $1;
 
+-- name: GetMatchByIDForUpdate :one
+SELECT
+    id,
+    state,
+    created_at,
+    started_at,
+    completed_at,
+    player1_id,
+    player2_id,
+    winner_player_id,
+    current_turn_number,
+    current_actor_player_id,
+    is_public,
+    ruleset_id,
+    turn_started_at
+FROM matches
+WHERE id = $1
+FOR UPDATE;
+
 -- name: ListMatchesForPlayer :many
 SELECT
     id,
@@ -50,29 +79,44 @@ SELECT
     player2_id,
     winner_player_id,
     current_turn_number,
-    current_actor_player_id
+    current_actor_player_id,
+    is_public,
+    ruleset_id,
+    turn_started_at
 FROM matches
-WHERE player1_id = $1 OR player2_id = $1
-ORDER BY created_at DESC;
+WHERE (player1_id = sqlc.arg(player_id) OR player2_id = sqlc.arg(player_id))
+  -- Optional filters are NULL when unused. Each parameter is compared with
+  -- a column before its IS NULL test so Postgres can infer its type.
+  AND (state = sqlc.narg(state) OR sqlc.narg(state) IS NULL)
+  AND (player1_id = sqlc.narg(opponent_id) OR player2_id = sqlc.narg(opponent_id) OR sqlc.narg(opponent_id) IS NULL)
+  AND (created_at >= sqlc.narg(created_from) OR sqlc.narg(created_from) IS NULL)
+  AND (created_at < sqlc.narg(created_to) OR sqlc.narg(created_to) IS NULL)
+  AND (current_actor_player_id = sqlc.narg(actor_id) OR sqlc.narg(actor_id) IS NULL)
+  -- Keyset pagination: the cursor is the last ID of the previous page
+  AND (id < sqlc.narg(before_id) OR sqlc.narg(before_id) IS NULL)
+ORDER BY id DESC
+LIMIT sqlc.arg(row_limit);
 
 -- name: StartMatch :one
 UPDATE matches
 SET
   state = 'IN_PROGRESS',
-  started_at = now(),
+  started_at = CURRENT_TIMESTAMP,
   current_turn_number = 1,
-  current_actor_player_id = $2  -- initial actor
+  current_actor_player_id = $2, -- initial actor
+  turn_started_at = CURRENT_TIMESTAMP
 WHERE id = $1
 RETURNING
   id, state, created_at, started_at, completed_at,
   player1_id, player2_id, winner_player_id,
-  current_turn_number, current_actor_player_id;
+  current_turn_number, current_actor_player_id, is_public,
+  ruleset_id, turn_started_at;
 
 -- name: CompleteMatch :one
 UPDATE matches
 SET
   state = 'COMPLETED',
-  completed_at = now(),
+  completed_at = CURRENT_TIMESTAMP,
   winner_player_id = $2,
   current_actor_player_id = NULL
 WHERE id = $1
@@ -86,13 +130,17 @@ RETURNING
   player2_id,
   winner_player_id,
   current_turn_number,
-  current_actor_player_id;
+  current_actor_player_id,
+  is_public,
+  ruleset_id,
+  turn_started_at;
 
 -- name: UpdateMatchTurnAndActor :one
 UPDATE matches
 SET
   current_turn_number = $2,
-  current_actor_player_id = $3
+  current_actor_player_id = $3,
+  turn_started_at = CURRENT_TIMESTAMP
 WHERE id = $1
 RETURNING 
   id,
@@ -104,4 +152,45 @@ RETURNING
   player2_id,
   winner_player_id,
   current_turn_number,
-  current_actor_player_id;
\ No newline at end of file
+  current_actor_player_id,
+  is_public,
+  ruleset_id,
+  turn_started_at;
+
+-- name: SetMatchPublic :one
+UPDATE matches
+SET is_public = $2
+WHERE id = $1
+RETURNING
+  id,
+  state,
+  created_at,
+  started_at,
+  completed_at,
+  player1_id,
+  player2_id,
+  winner_player_id,
+  current_turn_number,
+  current_actor_player_id,
+  is_public,
+  ruleset_id,
+  turn_started_at;
+
+-- name: ListLiveMatches :many
+-- In-progress public matches with both players' names, newest first
+SELECT
+    m.id,
+    m.started_at,
+    m.current_turn_number,
+    m.player1_id,
+    p1.username AS player1_username,
+    m.player2_id,
+    p2.username AS player2_username
+FROM matches m
+JOIN players p1 ON p1.id = m.player1_id
+JOIN players p2 ON p2.id = m.player2_id
+WHERE m.state = 'IN_PROGRESS'
+  AND m.is_public
+  AND (m.id < sqlc.narg(before_id) OR sqlc.narg(before_id) IS NULL)
+ORDER BY m.id DESC
+LIMIT sqlc.arg(row_limit);
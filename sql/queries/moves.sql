@@ -1,17 +1,65 @@
 -- name: ListMovesForUnit :many
 SELECT
-  m.id, m.name, m.power, m.accuracy, m.type_id
+  m.id, m.name, m.power, m.accuracy, m.type_id, m.deleted_at
 FROM moves m
 JOIN unit_moves um ON um.move_id = m.id
 WHERE um.unit_id = $1
+  AND m.deleted_at IS NULL
 ORDER BY m.id;
 
+-- name: ListMoves :many
+SELECT id, name, power, accuracy, type_id, deleted_at
+FROM moves
+WHERE deleted_at IS NULL
+ORDER BY id;
+
+-- name: GetMoveByID :one
+SELECT id, name, power, accuracy, type_id, deleted_at
+FROM moves
+WHERE id = $1;
+
 -- name: CreateMove :one
 INSERT INTO moves (name, power, accuracy, type_id)
 VALUES ($1, $2, $3, $4)
-RETURNING id, name, power, accuracy, type_id;
+RETURNING id, name, power, accuracy, type_id, deleted_at;
+
+-- name: UpdateMove :one
+UPDATE moves
+SET name = $2, power = $3, accuracy = $4, type_id = $5
+WHERE id = $1
+RETURNING id, name, power, accuracy, type_id, deleted_at;
+
+-- name: SoftDeleteMove :exec
+UPDATE moves
+SET deleted_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: CountActiveSquadsWithMove :one
+SELECT COUNT(DISTINCT s.id)
+FROM unit_moves um
+JOIN squad_units su ON su.unit_id = um.unit_id
+JOIN squads s ON s.id = su.squad_id
+WHERE um.move_id = $1
+  AND s.deleted_at IS NULL;
 
 -- name: CreateUnitMove :one
 INSERT INTO unit_moves (unit_id, move_id)
 VALUES ($1, $2)
-RETURNING unit_id, move_id;
\ No newline at end of file
+RETURNING unit_id, move_id;
+
+-- name: DeleteUnitMove :execrows
+DELETE FROM unit_moves
+WHERE unit_id = $1 AND move_id = $2;
+
+-- name: ListActiveUnitMovesForMatch :many
+-- The moves of each side's active unit, for match views.
+SELECT
+  mu.id AS match_unit_id, m.id, m.name, m.power, m.accuracy, m.type_id, ut.name AS type_name
+FROM match_sides ms
+JOIN match_units mu ON mu.match_side_id = ms.id AND mu.position = ms.active_index
+JOIN unit_moves um ON um.unit_id = mu.unit_id
+JOIN moves m ON m.id = um.move_id
+JOIN unit_types ut ON ut.id = m.type_id
+WHERE ms.match_id = $1
+  AND m.deleted_at IS NULL
+ORDER BY mu.id, m.id;
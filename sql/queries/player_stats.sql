@@ -0,0 +1,105 @@
+-- name: AddPlayerDailyStats :exec
+-- Adds to a player's totals for the day, creating the row on first use
+INSERT INTO player_daily_stats (
+    player_id,
+    day,
+    matches_played,
+    wins,
+    losses,
+    turns_played,
+    damage_dealt,
+    kos
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+)
+ON CONFLICT (player_id, day) DO UPDATE
+SET
+    matches_played = player_daily_stats.matches_played + excluded.matches_played,
+    wins = player_daily_stats.wins + excluded.wins,
+    losses = player_daily_stats.losses + excluded.losses,
+    turns_played = player_daily_stats.turns_played + excluded.turns_played,
+    damage_dealt = player_daily_stats.damage_dealt + excluded.damage_dealt,
+    kos = player_daily_stats.kos + excluded.kos;
+
+-- name: AddPlayerUnitMatch :exec
+INSERT INTO player_unit_stats (player_id, day, unit_id, matches_fielded)
+VALUES ($1, $2, $3, 1)
+ON CONFLICT (player_id, day, unit_id) DO UPDATE
+SET matches_fielded = player_unit_stats.matches_fielded + 1;
+
+-- name: AddPlayerMoveUse :exec
+INSERT INTO player_move_stats (player_id, day, move_id, uses)
+VALUES ($1, $2, $3, 1)
+ON CONFLICT (player_id, day, move_id) DO UPDATE
+SET uses = player_move_stats.uses + 1;
+
+-- name: GetPlayerStats :one
+-- A player's totals over an optional range of days, [from_day, to_day)
+SELECT
+    p.id,
+    p.username,
+    p.rating,
+    CAST(COALESCE(SUM(s.matches_played), 0) AS BIGINT) AS matches_played,
+    CAST(COALESCE(SUM(s.wins), 0) AS BIGINT) AS wins,
+    CAST(COALESCE(SUM(s.losses), 0) AS BIGINT) AS losses,
+    CAST(COALESCE(SUM(s.turns_played), 0) AS BIGINT) AS turns_played,
+    CAST(COALESCE(SUM(s.damage_dealt), 0) AS BIGINT) AS damage_dealt,
+    CAST(COALESCE(SUM(s.kos), 0) AS BIGINT) AS kos
+FROM players p
+LEFT JOIN player_daily_stats s
+    ON s.player_id = p.id
+    AND (s.day >= sqlc.narg(from_day) OR sqlc.narg(from_day) IS NULL)
+    AND (s.day < sqlc.narg(to_day) OR sqlc.narg(to_day) IS NULL)
+WHERE p.id = sqlc.arg(player_id)
+GROUP BY p.id, p.username, p.rating;
+
+-- name: ListLeaderboard :many
+-- Players with at least min_matches results in [from_day, to_day), best
+-- first. sort_by is 'rating', 'wins' or 'win_rate'; ties go to the player
+-- who signed up first.
+SELECT
+    p.id,
+    p.username,
+    p.rating,
+    CAST(SUM(s.matches_played) AS BIGINT) AS matches_played,
+    CAST(SUM(s.wins) AS BIGINT) AS wins,
+    CAST(SUM(s.losses) AS BIGINT) AS losses
+FROM player_daily_stats s
+JOIN players p ON p.id = s.player_id
+WHERE (s.day >= sqlc.narg(from_day) OR sqlc.narg(from_day) IS NULL)
+  AND (s.day < sqlc.narg(to_day) OR sqlc.narg(to_day) IS NULL)
+GROUP BY p.id, p.username, p.rating
+HAVING SUM(s.matches_played) >= CAST(sqlc.arg(min_matches) AS BIGINT)
+ORDER BY
+    CASE CAST(sqlc.arg(sort_by) AS TEXT)
+        WHEN 'wins' THEN CAST(SUM(s.wins) AS DOUBLE PRECISION)
+        WHEN 'win_rate' THEN COALESCE(CAST(SUM(s.wins) AS DOUBLE PRECISION) / NULLIF(SUM(s.matches_played), 0), 0)
+        ELSE CAST(p.rating AS DOUBLE PRECISION)
+    END DESC,
+    p.id
+LIMIT sqlc.arg(row_limit);
+
+-- name: ListPlayerTopUnits :many
+-- The units a player has fielded in the most matches completed in
+-- [from_day, to_day)
+SELECT pus.unit_id, u.name, CAST(SUM(pus.matches_fielded) AS BIGINT) AS matches_fielded
+FROM player_unit_stats pus
+JOIN units u ON u.id = pus.unit_id
+WHERE pus.player_id = sqlc.arg(player_id)
+  AND (pus.day >= sqlc.narg(from_day) OR sqlc.narg(from_day) IS NULL)
+  AND (pus.day < sqlc.narg(to_day) OR sqlc.narg(to_day) IS NULL)
+GROUP BY pus.unit_id, u.name
+ORDER BY SUM(pus.matches_fielded) DESC, pus.unit_id
+LIMIT sqlc.arg(row_limit);
+
+-- name: ListPlayerTopMoves :many
+-- The moves a player has used most in [from_day, to_day)
+SELECT pms.move_id, m.name, CAST(SUM(pms.uses) AS BIGINT) AS uses
+FROM player_move_stats pms
+JOIN moves m ON m.id = pms.move_id
+WHERE pms.player_id = sqlc.arg(player_id)
+  AND (pms.day >= sqlc.narg(from_day) OR sqlc.narg(from_day) IS NULL)
+  AND (pms.day < sqlc.narg(to_day) OR sqlc.narg(to_day) IS NULL)
+GROUP BY pms.move_id, m.name
+ORDER BY SUM(pms.uses) DESC, pms.move_id
+LIMIT sqlc.arg(row_limit);
@@ -9,6 +9,50 @@ FROM players
 WHERE username = $1;
 
 -- name: GetPlayerByID :one
-SELECT id, username, password_hash, created_at, is_admin
+SELECT id, username, password_hash, created_at, is_admin, rating, display_name, avatar_key, last_seen_at
 FROM players
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: GetPlayerRatingForUpdate :one
+SELECT rating
+FROM players
+WHERE id = $1
+FOR UPDATE;
+
+-- name: LockPlayer :exec
+-- Holds the player's row until the transaction ends, so checks that span
+-- all their matches, like the chat rate limit, run one at a time
+SELECT id
+FROM players
+WHERE id = $1
+FOR UPDATE;
+
+-- name: UpdatePlayerRating :exec
+UPDATE players
+SET rating = $2
+WHERE id = $1;
+
+-- name: UpdatePlayerProfile :exec
+UPDATE players
+SET
+    display_name = $2,
+    avatar_key = $3
+WHERE id = $1;
+
+-- name: SearchPlayers :many
+-- Players whose username or display name matches pattern, a lowercase LIKE
+-- pattern with \ as the escape character, in signup order
+SELECT id, username, display_name, avatar_key, rating, created_at
+FROM players
+WHERE (LOWER(username) LIKE CAST(sqlc.arg(pattern) AS TEXT) ESCAPE '\' OR LOWER(display_name) LIKE CAST(sqlc.arg(pattern) AS TEXT) ESCAPE '\')
+  AND (id > sqlc.narg(after_id) OR sqlc.narg(after_id) IS NULL)
+ORDER BY id
+LIMIT sqlc.arg(row_limit);
+
+-- name: TouchPlayer :exec
+-- Records that the player was seen at seen_at. Players already seen since
+-- seen_before are left alone, so a busy player isn't written on every request.
+UPDATE players
+SET last_seen_at = sqlc.arg(seen_at)
+WHERE id = sqlc.arg(id)
+  AND (last_seen_at IS NULL OR last_seen_at < sqlc.arg(seen_before));
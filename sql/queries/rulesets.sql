@@ -0,0 +1,95 @@
+-- name: CreateRuleset :one
+INSERT INTO rulesets (
+    name,
+    min_squad_size,
+    max_squad_size,
+    level_cap,
+    damage_model,
+    turn_timer_seconds,
+    turn_mode,
+    switching_enabled,
+    items_enabled,
+    type_effectiveness_enabled
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+)
+RETURNING
+    id,
+    name,
+    min_squad_size,
+    max_squad_size,
+    level_cap,
+    damage_model,
+    turn_timer_seconds,
+    turn_mode,
+    switching_enabled,
+    items_enabled,
+    type_effectiveness_enabled,
+    created_at,
+    updated_at;
+
+-- name: GetRulesetByID :one
+SELECT
+    id,
+    name,
+    min_squad_size,
+    max_squad_size,
+    level_cap,
+    damage_model,
+    turn_timer_seconds,
+    turn_mode,
+    switching_enabled,
+    items_enabled,
+    type_effectiveness_enabled,
+    created_at,
+    updated_at
+FROM rulesets
+WHERE id = $1;
+
+-- name: ListRulesets :many
+SELECT
+    id,
+    name,
+    min_squad_size,
+    max_squad_size,
+    level_cap,
+    damage_model,
+    turn_timer_seconds,
+    turn_mode,
+    switching_enabled,
+    items_enabled,
+    type_effectiveness_enabled,
+    created_at,
+    updated_at
+FROM rulesets
+ORDER BY id;
+
+-- name: UpdateRuleset :one
+UPDATE rulesets
+SET
+    name = $2,
+    min_squad_size = $3,
+    max_squad_size = $4,
+    level_cap = $5,
+    damage_model = $6,
+    turn_timer_seconds = $7,
+    turn_mode = $8,
+    switching_enabled = $9,
+    items_enabled = $10,
+    type_effectiveness_enabled = $11,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING
+    id,
+    name,
+    min_squad_size,
+    max_squad_size,
+    level_cap,
+    damage_model,
+    turn_timer_seconds,
+    turn_mode,
+    switching_enabled,
+    items_enabled,
+    type_effectiveness_enabled,
+    created_at,
+    updated_at;
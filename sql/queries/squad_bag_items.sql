@@ -0,0 +1,19 @@
+-- name: CreateSquadBagItem :one
+INSERT INTO squad_bag_items (
+    squad_id,
+    item_id,
+    quantity
+) VALUES (
+    $1, $2, $3
+)
+RETURNING squad_id, item_id, quantity;
+
+-- name: ListSquadBagItems :many
+SELECT squad_id, item_id, quantity
+FROM squad_bag_items
+WHERE squad_id = $1
+ORDER BY item_id;
+
+-- name: DeleteSquadBagItems :exec
+DELETE FROM squad_bag_items
+WHERE squad_id = $1;
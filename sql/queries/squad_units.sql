@@ -2,14 +2,19 @@
 INSERT INTO squad_units (
     squad_id,
     unit_id,
-    position
+    position,
+    held_item_id
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $3, $4
 )
-RETURNING id, squad_id, unit_id, position;
+RETURNING id, squad_id, unit_id, position, held_item_id;
 
 -- name: GetSquadUnits :many
-SELECT id, squad_id, unit_id, position
+SELECT id, squad_id, unit_id, position, held_item_id
 FROM squad_units
 WHERE squad_id = $1
-ORDER BY position;
\ No newline at end of file
+ORDER BY position;
+
+-- name: DeleteSquadUnits :exec
+DELETE FROM squad_units
+WHERE squad_id = $1;
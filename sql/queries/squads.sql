@@ -5,10 +5,79 @@ INSERT INTO squads (
 ) VALUES (
     $1, $2
 )
-RETURNING id, player_id, name, created_at;
+RETURNING id, player_id, name, created_at, deleted_at, showcased;
+
+-- name: GetSquadByID :one
+SELECT id, player_id, name, created_at, deleted_at, showcased
+FROM squads
+WHERE id = $1;
+
+-- name: GetSquadByIDForShare :one
+SELECT id, player_id, name, created_at, deleted_at, showcased
+FROM squads
+WHERE id = $1
+FOR SHARE;
+
+-- name: GetSquadByIDForUpdate :one
+SELECT id, player_id, name, created_at, deleted_at, showcased
+FROM squads
+WHERE id = $1
+FOR UPDATE;
 
 -- name: GetSquadsForPlayer :many
-SELECT id, player_id, name, created_at
+SELECT id, player_id, name, created_at, deleted_at, showcased
 FROM squads
 WHERE player_id = $1
-ORDER BY created_at DESC;
\ No newline at end of file
+  AND deleted_at IS NULL
+ORDER BY created_at DESC;
+
+-- name: UpdateSquadName :one
+UPDATE squads
+SET name = $2
+WHERE id = $1
+RETURNING id, player_id, name, created_at, deleted_at, showcased;
+
+-- name: SoftDeleteSquad :exec
+UPDATE squads
+SET deleted_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: CountInProgressMatchesForSquad :one
+SELECT COUNT(*)
+FROM match_sides ms
+JOIN matches m ON m.id = ms.match_id
+WHERE ms.squad_id = $1
+  AND m.state = 'IN_PROGRESS';
+
+-- name: ClearShowcaseSquads :exec
+UPDATE squads
+SET showcased = FALSE
+WHERE player_id = $1
+  AND showcased;
+
+-- name: ShowcaseSquad :execrows
+-- Affects no rows unless the squad exists, belongs to the player and hasn't
+-- been deleted
+UPDATE squads
+SET showcased = TRUE
+WHERE id = $1
+  AND player_id = $2
+  AND deleted_at IS NULL;
+
+-- name: ListShowcaseSquadUnits :many
+-- Every unit of a player's showcased squads, squad by squad
+SELECT
+    s.id AS squad_id,
+    s.name AS squad_name,
+    su.position,
+    u.id AS unit_id,
+    u.name AS unit_name,
+    ut.name AS type_name
+FROM squads s
+JOIN squad_units su ON su.squad_id = s.id
+JOIN units u ON u.id = su.unit_id
+JOIN unit_types ut ON ut.id = u.type_id
+WHERE s.player_id = $1
+  AND s.showcased
+  AND s.deleted_at IS NULL
+ORDER BY s.id, su.position;
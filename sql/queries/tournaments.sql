@@ -0,0 +1,224 @@
+-- name: CreateTournament :one
+INSERT INTO tournaments (
+    name,
+    format,
+    max_players,
+    created_by,
+    ruleset_id
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+RETURNING
+    id,
+    name,
+    format,
+    state,
+    max_players,
+    current_round,
+    winner_player_id,
+    created_by,
+    created_at,
+    started_at,
+    completed_at,
+    ruleset_id;
+
+-- name: GetTournamentByID :one
+SELECT
+    id,
+    name,
+    format,
+    state,
+    max_players,
+    current_round,
+    winner_player_id,
+    created_by,
+    created_at,
+    started_at,
+    completed_at,
+    ruleset_id
+FROM tournaments
+WHERE id = $1;
+
+-- name: GetTournamentByIDForUpdate :one
+SELECT
+    id,
+    name,
+    format,
+    state,
+    max_players,
+    current_round,
+    winner_player_id,
+    created_by,
+    created_at,
+    started_at,
+    completed_at,
+    ruleset_id
+FROM tournaments
+WHERE id = $1
+FOR UPDATE;
+
+-- name: ListTournaments :many
+-- Newest first, optionally in one state
+SELECT
+    id,
+    name,
+    format,
+    state,
+    max_players,
+    current_round,
+    winner_player_id,
+    created_by,
+    created_at,
+    started_at,
+    completed_at,
+    ruleset_id
+FROM tournaments
+WHERE (state = sqlc.narg(state) OR sqlc.narg(state) IS NULL)
+  AND (id < sqlc.narg(before_id) OR sqlc.narg(before_id) IS NULL)
+ORDER BY id DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: StartTournament :one
+UPDATE tournaments
+SET
+    state = 'IN_PROGRESS',
+    current_round = 1,
+    started_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING
+    id,
+    name,
+    format,
+    state,
+    max_players,
+    current_round,
+    winner_player_id,
+    created_by,
+    created_at,
+    started_at,
+    completed_at,
+    ruleset_id;
+
+-- name: UpdateTournamentRound :one
+UPDATE tournaments
+SET current_round = $2
+WHERE id = $1
+RETURNING
+    id,
+    name,
+    format,
+    state,
+    max_players,
+    current_round,
+    winner_player_id,
+    created_by,
+    created_at,
+    started_at,
+    completed_at,
+    ruleset_id;
+
+-- name: CompleteTournament :one
+UPDATE tournaments
+SET
+    state = 'COMPLETED',
+    winner_player_id = $2,
+    completed_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING
+    id,
+    name,
+    format,
+    state,
+    max_players,
+    current_round,
+    winner_player_id,
+    created_by,
+    created_at,
+    started_at,
+    completed_at,
+    ruleset_id;
+
+-- name: CreateTournamentEntry :one
+INSERT INTO tournament_entries (
+    tournament_id,
+    player_id,
+    squad_id
+) VALUES (
+    $1, $2, $3
+)
+RETURNING tournament_id, player_id, squad_id, seed, created_at;
+
+-- name: DeleteTournamentEntry :execrows
+DELETE FROM tournament_entries
+WHERE tournament_id = $1
+  AND player_id = $2;
+
+-- name: ListTournamentEntries :many
+-- Entries with the player's name and rating, by seed once the tournament
+-- has started and by registration order before
+SELECT
+    e.player_id,
+    p.username,
+    p.rating,
+    e.squad_id,
+    e.seed,
+    e.created_at
+FROM tournament_entries e
+JOIN players p ON p.id = e.player_id
+WHERE e.tournament_id = $1
+ORDER BY e.seed, e.created_at, e.player_id;
+
+-- name: SetTournamentEntrySeed :exec
+UPDATE tournament_entries
+SET seed = $3
+WHERE tournament_id = $1
+  AND player_id = $2;
+
+-- name: CountOpenTournamentEntriesForSquad :one
+-- Non-zero while the squad is registered in a tournament that isn't completed
+SELECT COUNT(*)
+FROM tournament_entries e
+JOIN tournaments t ON t.id = e.tournament_id
+WHERE e.squad_id = $1
+  AND t.state <> 'COMPLETED';
+
+-- name: CreateTournamentMatch :one
+INSERT INTO tournament_matches (
+    tournament_id,
+    round,
+    slot,
+    player1_id,
+    player2_id,
+    winner_player_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+RETURNING id, tournament_id, round, slot, player1_id, player2_id, match_id, winner_player_id;
+
+-- name: GetTournamentMatchByMatchID :one
+SELECT id, tournament_id, round, slot, player1_id, player2_id, match_id, winner_player_id
+FROM tournament_matches
+WHERE match_id = $1;
+
+-- name: GetTournamentMatchBySlot :one
+SELECT id, tournament_id, round, slot, player1_id, player2_id, match_id, winner_player_id
+FROM tournament_matches
+WHERE tournament_id = $1
+  AND round = $2
+  AND slot = $3;
+
+-- name: ListTournamentMatches :many
+SELECT id, tournament_id, round, slot, player1_id, player2_id, match_id, winner_player_id
+FROM tournament_matches
+WHERE tournament_id = $1
+ORDER BY round, slot;
+
+-- name: UpdateTournamentMatch :one
+UPDATE tournament_matches
+SET
+    player1_id = $2,
+    player2_id = $3,
+    match_id = $4,
+    winner_player_id = $5
+WHERE id = $1
+RETURNING id, tournament_id, round, slot, player1_id, player2_id, match_id, winner_player_id;
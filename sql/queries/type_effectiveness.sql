@@ -0,0 +1,16 @@
+-- name: ListTypeEffectiveness :many
+SELECT attack_type_id, defend_type_id, multiplier_percent
+FROM type_effectiveness
+ORDER BY attack_type_id, defend_type_id;
+
+-- name: GetTypeEffectiveness :one
+SELECT attack_type_id, defend_type_id, multiplier_percent
+FROM type_effectiveness
+WHERE attack_type_id = $1 AND defend_type_id = $2;
+
+-- name: UpsertTypeEffectiveness :one
+INSERT INTO type_effectiveness (attack_type_id, defend_type_id, multiplier_percent)
+VALUES ($1, $2, $3)
+ON CONFLICT (attack_type_id, defend_type_id)
+DO UPDATE SET multiplier_percent = excluded.multiplier_percent
+RETURNING attack_type_id, defend_type_id, multiplier_percent;
@@ -0,0 +1,29 @@
+-- name: ListUnitTypes :many
+SELECT id, name
+FROM unit_types
+ORDER BY id;
+
+-- name: GetUnitTypeByID :one
+SELECT id, name
+FROM unit_types
+WHERE id = $1;
+
+-- name: CreateUnitType :one
+INSERT INTO unit_types (name)
+VALUES ($1)
+RETURNING id, name;
+
+-- name: UpdateUnitType :one
+UPDATE unit_types
+SET name = $2
+WHERE id = $1
+RETURNING id, name;
+
+-- name: DeleteUnitType :exec
+DELETE FROM unit_types
+WHERE id = $1;
+
+-- name: CountUnitTypeReferences :one
+SELECT CAST(
+  (SELECT COUNT(*) FROM units WHERE units.type_id = $1) +
+  (SELECT COUNT(*) FROM moves WHERE moves.type_id = $1) AS BIGINT) AS count;
@@ -1,16 +1,35 @@
 -- name: ListUnits :many
 SELECT
-  id, name, type_id, base_hp, base_attack, base_speed
+  id, name, type_id, base_hp, base_attack, base_speed, deleted_at, ability
 FROM units
+WHERE deleted_at IS NULL
 ORDER BY id;
 
 -- name: GetUnitByID :one
 SELECT
-  id, name, type_id, base_hp, base_attack, base_speed
+  id, name, type_id, base_hp, base_attack, base_speed, deleted_at, ability
 FROM units
 WHERE id = $1;
 
 -- name: CreateUnit :one
-INSERT INTO units (name, type_id, base_hp, base_attack, base_speed)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, name, type_id, base_hp, base_attack, base_speed;
\ No newline at end of file
+INSERT INTO units (name, type_id, base_hp, base_attack, base_speed, ability)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, name, type_id, base_hp, base_attack, base_speed, deleted_at, ability;
+
+-- name: UpdateUnit :one
+UPDATE units
+SET name = $2, type_id = $3, base_hp = $4, base_attack = $5, base_speed = $6, ability = $7
+WHERE id = $1
+RETURNING id, name, type_id, base_hp, base_attack, base_speed, deleted_at, ability;
+
+-- name: SoftDeleteUnit :exec
+UPDATE units
+SET deleted_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: CountActiveSquadsWithUnit :one
+SELECT COUNT(DISTINCT s.id)
+FROM squad_units su
+JOIN squads s ON s.id = su.squad_id
+WHERE su.unit_id = $1
+  AND s.deleted_at IS NULL;
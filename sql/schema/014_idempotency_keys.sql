@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE idempotency_keys (
+    player_id     BIGINT      NOT NULL REFERENCES players(id),
+    key           TEXT        NOT NULL,
+    method        TEXT        NOT NULL,
+    path          TEXT        NOT NULL,
+    request_hash  TEXT        NOT NULL, -- sha256 of method, path and body
+    status_code   INT         NOT NULL DEFAULT 0, -- 0 while the request is in flight
+    content_type  TEXT        NOT NULL DEFAULT '',
+    response_body BYTEA,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (player_id, key)
+);
+
+CREATE INDEX idempotency_keys_created_at_idx ON idempotency_keys (created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS idempotency_keys;
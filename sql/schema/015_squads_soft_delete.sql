@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE squads
+ADD COLUMN deleted_at TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE squads
+DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,25 @@
+-- +goose Up
+ALTER TABLE units
+ADD COLUMN deleted_at TIMESTAMPTZ;
+
+ALTER TABLE moves
+ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE TABLE admin_audit_log (
+    id              BIGSERIAL   PRIMARY KEY,
+    admin_player_id BIGINT      NOT NULL REFERENCES players(id),
+    action          TEXT        NOT NULL, -- 'create', 'update', 'delete'
+    entity_type     TEXT        NOT NULL, -- 'unit_type', 'unit', 'move', 'unit_move'
+    entity_id       BIGINT      NOT NULL,
+    details         TEXT        NOT NULL DEFAULT '', -- JSON snapshot of the change
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS admin_audit_log;
+
+ALTER TABLE moves
+DROP COLUMN IF EXISTS deleted_at;
+
+ALTER TABLE units
+DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE type_effectiveness (
+    attack_type_id     BIGINT NOT NULL REFERENCES unit_types(id) ON DELETE CASCADE,
+    defend_type_id     BIGINT NOT NULL REFERENCES unit_types(id) ON DELETE CASCADE,
+    multiplier_percent INT    NOT NULL, -- 200 = super effective, 50 = resisted, 0 = immune
+    PRIMARY KEY (attack_type_id, defend_type_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS type_effectiveness;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Public matches are listed in GET /matches/live and open to spectators
+ALTER TABLE matches
+ADD COLUMN is_public BOOLEAN NOT NULL DEFAULT TRUE;
+
+CREATE INDEX matches_live_idx ON matches (id) WHERE state = 'IN_PROGRESS' AND is_public;
+
+-- +goose Down
+DROP INDEX IF EXISTS matches_live_idx;
+ALTER TABLE matches
+DROP COLUMN IF EXISTS is_public;
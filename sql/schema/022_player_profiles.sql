@@ -0,0 +1,22 @@
+-- +goose Up
+-- Shown instead of the username where set. The avatar key names an image
+-- the client knows how to draw; the server doesn't interpret it.
+ALTER TABLE players
+ADD COLUMN display_name TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE players
+ADD COLUMN avatar_key TEXT NOT NULL DEFAULT '';
+
+-- Squads a player shows on their public profile
+ALTER TABLE squads
+ADD COLUMN showcased BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE squads
+DROP COLUMN IF EXISTS showcased;
+
+ALTER TABLE players
+DROP COLUMN IF EXISTS avatar_key;
+
+ALTER TABLE players
+DROP COLUMN IF EXISTS display_name;
@@ -0,0 +1,72 @@
+-- +goose Up
+-- The rules a match is played under. Every unit plays at level_cap, with
+-- its stats scaled by level_cap / 50. turn_timer_seconds is how long a
+-- player has to act before the opponent can claim the match; 0 means no
+-- limit. Turns alternate between the players, or in 'simultaneous' mode
+-- both choose an action each round and the faster unit acts first.
+CREATE TABLE rulesets (
+    id                         BIGSERIAL   PRIMARY KEY,
+    name                       TEXT        NOT NULL UNIQUE,
+    min_squad_size             INT         NOT NULL,
+    max_squad_size             INT         NOT NULL,
+    level_cap                  INT         NOT NULL,
+    damage_model               TEXT        NOT NULL CHECK (damage_model IN ('standard', 'fixed')),
+    turn_timer_seconds         INT         NOT NULL,
+    turn_mode                  TEXT        NOT NULL CHECK (turn_mode IN ('alternating', 'simultaneous')),
+    switching_enabled          BOOLEAN     NOT NULL,
+    items_enabled              BOOLEAN     NOT NULL,
+    type_effectiveness_enabled BOOLEAN     NOT NULL,
+    created_at                 TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at                 TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- The rules every match was played under so far
+INSERT INTO rulesets (
+    id, name, min_squad_size, max_squad_size, level_cap, damage_model,
+    turn_timer_seconds, turn_mode, switching_enabled, items_enabled,
+    type_effectiveness_enabled
+) VALUES (
+    1, 'Standard', 1, 3, 50, 'standard', 0, 'alternating', TRUE, TRUE, TRUE
+);
+SELECT setval(pg_get_serial_sequence('rulesets', 'id'), 1);
+
+ALTER TABLE matches
+ADD COLUMN ruleset_id BIGINT NOT NULL DEFAULT 1 REFERENCES rulesets(id),
+-- When the current actor (or, in simultaneous mode, the round) started,
+-- for the turn timer
+ADD COLUMN turn_started_at TIMESTAMPTZ;
+
+ALTER TABLE challenges
+ADD COLUMN ruleset_id BIGINT NOT NULL DEFAULT 1 REFERENCES rulesets(id);
+
+ALTER TABLE tournaments
+ADD COLUMN ruleset_id BIGINT NOT NULL DEFAULT 1 REFERENCES rulesets(id);
+
+-- The action a side has chosen for the current round of a simultaneous
+-- match: a move, or the bench position to switch to
+ALTER TABLE match_sides
+ADD COLUMN pending_move_id BIGINT REFERENCES moves(id),
+ADD COLUMN pending_switch_to INT;
+
+-- Turns are now 'move' or 'switch'. A switch has no move; its acting unit
+-- is the one leaving and its target the one coming in.
+ALTER TABLE match_turns
+ADD COLUMN action TEXT NOT NULL DEFAULT 'move',
+ALTER COLUMN move_id DROP NOT NULL;
+
+-- +goose Down
+DELETE FROM match_turns WHERE move_id IS NULL;
+ALTER TABLE match_turns
+DROP COLUMN IF EXISTS action,
+ALTER COLUMN move_id SET NOT NULL;
+ALTER TABLE match_sides
+DROP COLUMN IF EXISTS pending_switch_to,
+DROP COLUMN IF EXISTS pending_move_id;
+ALTER TABLE tournaments
+DROP COLUMN IF EXISTS ruleset_id;
+ALTER TABLE challenges
+DROP COLUMN IF EXISTS ruleset_id;
+ALTER TABLE matches
+DROP COLUMN IF EXISTS turn_started_at,
+DROP COLUMN IF EXISTS ruleset_id;
+DROP TABLE IF EXISTS rulesets;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- When the key was last claimed. A key still in flight (status_code 0) long
+-- after its claim belongs to a request that died without releasing it, and
+-- can be claimed again.
+ALTER TABLE idempotency_keys
+ADD COLUMN claimed_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+UPDATE idempotency_keys SET claimed_at = created_at;
+
+-- +goose Down
+ALTER TABLE idempotency_keys
+DROP COLUMN claimed_at;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Moves roll their accuracy; a move that missed did no damage. Turns played
+-- before this hit, as moves never missed then.
+ALTER TABLE match_turns
+ADD COLUMN missed BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE match_turns
+DROP COLUMN missed;
@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE players (
+    id             INTEGER   PRIMARY KEY,
+    username       TEXT      NOT NULL UNIQUE,
+    password_hash  TEXT      NOT NULL,
+    created_at     TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS players;
@@ -0,0 +1,8 @@
+-- +goose Up
+CREATE TABLE unit_types (
+    id   INTEGER PRIMARY KEY,
+    name TEXT    NOT NULL UNIQUE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS unit_types;
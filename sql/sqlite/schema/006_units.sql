@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE units (
+    id          INTEGER PRIMARY KEY,
+    name        TEXT    NOT NULL,
+    type_id     BIGINT  NOT NULL REFERENCES unit_types(id),
+    base_hp     INT     NOT NULL,
+    base_attack INT     NOT NULL,
+    base_speed  INT     NOT NULL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS units;
@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE squads (
+    id         INTEGER   PRIMARY KEY,
+    player_id  BIGINT    NOT NULL REFERENCES players(id),
+    name       TEXT      NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS squads;
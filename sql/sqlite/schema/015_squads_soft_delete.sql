@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE squads
+ADD COLUMN deleted_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE squads
+DROP COLUMN deleted_at;
@@ -0,0 +1,25 @@
+-- +goose Up
+ALTER TABLE units
+ADD COLUMN deleted_at TIMESTAMP;
+
+ALTER TABLE moves
+ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE TABLE admin_audit_log (
+    id              INTEGER   PRIMARY KEY,
+    admin_player_id BIGINT    NOT NULL REFERENCES players(id),
+    action          TEXT      NOT NULL, -- 'create', 'update', 'delete'
+    entity_type     TEXT      NOT NULL, -- 'unit_type', 'unit', 'move', 'unit_move'
+    entity_id       BIGINT    NOT NULL,
+    details         TEXT      NOT NULL DEFAULT '', -- JSON snapshot of the change
+    created_at      TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS admin_audit_log;
+
+ALTER TABLE moves
+DROP COLUMN deleted_at;
+
+ALTER TABLE units
+DROP COLUMN deleted_at;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Match lists are per player, newest first
+CREATE INDEX matches_player1_id_idx ON matches (player1_id, id);
+CREATE INDEX matches_player2_id_idx ON matches (player2_id, id);
+
+-- +goose Down
+DROP INDEX IF EXISTS matches_player2_id_idx;
+DROP INDEX IF EXISTS matches_player1_id_idx;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- A unit is revealed to the opponent once it has been the active unit
+ALTER TABLE match_units
+ADD COLUMN revealed BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Until now units only switched in when the one before them was KO'd, so
+-- every unit up to the active one has been seen
+UPDATE match_units
+SET revealed = TRUE
+WHERE position <= (
+    SELECT ms.active_index FROM match_sides ms WHERE ms.id = match_units.match_side_id
+);
+
+-- +goose Down
+ALTER TABLE match_units
+DROP COLUMN revealed;
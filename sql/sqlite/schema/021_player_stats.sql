@@ -0,0 +1,98 @@
+-- +goose Up
+-- Elo rating, updated when a match completes
+ALTER TABLE players
+ADD COLUMN rating INTEGER NOT NULL DEFAULT 1000;
+
+-- Running totals kept up to date by the game service as turns are applied
+-- and matches complete, so stats and leaderboards never scan match_turns.
+-- One row per player per UTC day lets them cover any range of days. Days
+-- are stored the way the Go driver writes a UTC midnight, which is what the
+-- queries compare them with.
+CREATE TABLE player_daily_stats (
+    player_id      BIGINT  NOT NULL REFERENCES players(id),
+    day            DATE    NOT NULL,
+    matches_played INTEGER NOT NULL DEFAULT 0,
+    wins           INTEGER NOT NULL DEFAULT 0,
+    losses         INTEGER NOT NULL DEFAULT 0,
+    turns_played   INTEGER NOT NULL DEFAULT 0,
+    damage_dealt   BIGINT  NOT NULL DEFAULT 0,
+    kos            INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (player_id, day)
+);
+
+CREATE INDEX player_daily_stats_day_idx ON player_daily_stats (day);
+
+-- Completed matches each player has fielded each unit in
+CREATE TABLE player_unit_stats (
+    player_id       BIGINT  NOT NULL REFERENCES players(id),
+    unit_id         BIGINT  NOT NULL REFERENCES units(id),
+    matches_fielded INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (player_id, unit_id)
+);
+
+-- Turns each player has used each move in
+CREATE TABLE player_move_stats (
+    player_id BIGINT  NOT NULL REFERENCES players(id),
+    move_id   BIGINT  NOT NULL REFERENCES moves(id),
+    uses      INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (player_id, move_id)
+);
+
+-- Backfill from the matches played so far. Past results are not replayed
+-- into ratings: everyone starts at 1000.
+INSERT INTO player_daily_stats (player_id, day, matches_played, wins, losses, turns_played)
+SELECT
+    player_id,
+    day,
+    COUNT(*),
+    SUM(CASE WHEN winner_player_id = player_id THEN 1 ELSE 0 END),
+    SUM(CASE WHEN winner_player_id = player_id THEN 0 ELSE 1 END),
+    SUM(current_turn_number)
+FROM (
+    SELECT player1_id AS player_id, date(completed_at) || ' 00:00:00+00:00' AS day, winner_player_id, current_turn_number
+    FROM matches
+    WHERE state = 'COMPLETED'
+    UNION ALL
+    SELECT player2_id, date(completed_at) || ' 00:00:00+00:00', winner_player_id, current_turn_number
+    FROM matches
+    WHERE state = 'COMPLETED'
+) AS results
+GROUP BY player_id, day;
+
+-- SQLite needs the WHERE: without one it reads the ON of ON CONFLICT as a
+-- join constraint.
+-- damage_done was not clamped to the target's HP before this migration, and
+-- the HP a knocked out unit had left is not recorded anywhere, so damage
+-- dealt in turns played before it counts the full damage of KO hits.
+INSERT INTO player_daily_stats (player_id, day, damage_dealt, kos)
+SELECT
+    acting_player_id,
+    date(created_at) || ' 00:00:00+00:00',
+    SUM(damage_done),
+    SUM(CASE WHEN did_ko_target THEN 1 ELSE 0 END)
+FROM match_turns
+WHERE TRUE
+GROUP BY acting_player_id, date(created_at) || ' 00:00:00+00:00'
+ON CONFLICT (player_id, day) DO UPDATE
+SET damage_dealt = excluded.damage_dealt, kos = excluded.kos;
+
+INSERT INTO player_unit_stats (player_id, unit_id, matches_fielded)
+SELECT ms.player_id, mu.unit_id, COUNT(DISTINCT m.id)
+FROM match_units mu
+JOIN match_sides ms ON ms.id = mu.match_side_id
+JOIN matches m ON m.id = ms.match_id
+WHERE m.state = 'COMPLETED'
+GROUP BY ms.player_id, mu.unit_id;
+
+INSERT INTO player_move_stats (player_id, move_id, uses)
+SELECT acting_player_id, move_id, COUNT(*)
+FROM match_turns
+GROUP BY acting_player_id, move_id;
+
+-- +goose Down
+DROP TABLE IF EXISTS player_move_stats;
+DROP TABLE IF EXISTS player_unit_stats;
+DROP TABLE IF EXISTS player_daily_stats;
+
+ALTER TABLE players
+DROP COLUMN rating;
@@ -0,0 +1,43 @@
+-- +goose Up
+-- When the player last made a request, for their friends' presence
+ALTER TABLE players
+ADD COLUMN last_seen_at TIMESTAMP;
+
+-- player_id's relationship with friend_id. A friend request is a 'requested'
+-- row from the requester that becomes 'accepted' when the other player
+-- accepts, so a pair has one such row. A block is a 'blocked' row from the
+-- blocker and replaces any request or friendship between the two.
+CREATE TABLE friendships (
+    player_id  BIGINT      NOT NULL REFERENCES players(id),
+    friend_id  BIGINT      NOT NULL REFERENCES players(id),
+    status     TEXT        NOT NULL CHECK (status IN ('requested', 'accepted', 'blocked')),
+    created_at TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (player_id, friend_id),
+    CHECK (player_id <> friend_id)
+);
+
+CREATE INDEX friendships_friend_id_idx ON friendships (friend_id);
+
+-- A match offered to a friend. The challenger picks their squad and the
+-- match settings up front; the opponent picks theirs when accepting.
+CREATE TABLE challenges (
+    id                  INTEGER     PRIMARY KEY,
+    challenger_id       BIGINT      NOT NULL REFERENCES players(id),
+    opponent_id         BIGINT      NOT NULL REFERENCES players(id),
+    challenger_squad_id BIGINT      NOT NULL REFERENCES squads(id),
+    is_public           BOOLEAN     NOT NULL,
+    state               TEXT        NOT NULL DEFAULT 'PENDING', -- 'PENDING', 'ACCEPTED', 'DECLINED', 'CANCELLED'
+    match_id            BIGINT      REFERENCES matches(id),
+    created_at          TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    responded_at        TIMESTAMP
+);
+
+CREATE INDEX challenges_challenger_id_idx ON challenges (challenger_id) WHERE state = 'PENDING';
+CREATE INDEX challenges_opponent_id_idx ON challenges (opponent_id) WHERE state = 'PENDING';
+
+-- +goose Down
+DROP TABLE IF EXISTS challenges;
+DROP TABLE IF EXISTS friendships;
+ALTER TABLE players
+DROP COLUMN last_seen_at;
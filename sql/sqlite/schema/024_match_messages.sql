@@ -0,0 +1,29 @@
+-- +goose Up
+-- Chat between the two players of a match
+CREATE TABLE match_messages (
+    id          INTEGER     PRIMARY KEY,
+    match_id    BIGINT      NOT NULL REFERENCES matches(id),
+    player_id   BIGINT      NOT NULL REFERENCES players(id),
+    body        TEXT        NOT NULL,
+    created_at  TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    -- Set when an admin reviews the message's reports; hidden messages are
+    -- left out of the chat
+    hidden      BOOLEAN     NOT NULL DEFAULT FALSE,
+    reviewed_at TIMESTAMP
+);
+
+CREATE INDEX match_messages_match_id_idx ON match_messages (match_id, id);
+CREATE INDEX match_messages_player_id_idx ON match_messages (player_id, created_at);
+
+-- Messages players have flagged for admin review, one report per player
+CREATE TABLE match_message_reports (
+    message_id  BIGINT      NOT NULL REFERENCES match_messages(id),
+    reporter_id BIGINT      NOT NULL REFERENCES players(id),
+    reason      TEXT        NOT NULL DEFAULT '',
+    created_at  TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (message_id, reporter_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS match_message_reports;
+DROP TABLE IF EXISTS match_messages;
@@ -0,0 +1,55 @@
+-- +goose Up
+-- A competition among registered players, run as a single-elimination
+-- bracket or a round robin. Players register while the tournament is in
+-- REGISTRATION; starting it seeds them and creates the first matches.
+CREATE TABLE tournaments (
+    id               INTEGER     PRIMARY KEY,
+    name             TEXT        NOT NULL,
+    format           TEXT        NOT NULL CHECK (format IN ('single_elimination', 'round_robin')),
+    state            TEXT        NOT NULL DEFAULT 'REGISTRATION', -- 'REGISTRATION', 'IN_PROGRESS', 'COMPLETED'
+    max_players      INT         NOT NULL,
+    -- The latest round with a match started; 0 until the tournament starts
+    current_round    INT         NOT NULL DEFAULT 0,
+    winner_player_id BIGINT      REFERENCES players(id),
+    created_by       BIGINT      NOT NULL REFERENCES players(id),
+    created_at       TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    started_at       TIMESTAMP,
+    completed_at     TIMESTAMP
+);
+
+-- A registered player and the squad they play every match with. The squad
+-- can't be edited or deleted until the tournament is completed.
+CREATE TABLE tournament_entries (
+    tournament_id BIGINT      NOT NULL REFERENCES tournaments(id),
+    player_id     BIGINT      NOT NULL REFERENCES players(id),
+    squad_id      BIGINT      NOT NULL REFERENCES squads(id),
+    -- 1 is the top seed; set when the tournament starts
+    seed          INT,
+    created_at    TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (tournament_id, player_id)
+);
+
+CREATE INDEX tournament_entries_squad_id_idx ON tournament_entries (squad_id);
+
+-- One pairing of the bracket or round robin. Later bracket rounds are
+-- created empty and filled in as winners advance; match_id is set once both
+-- players are known and the match is created. A bye has a winner and no
+-- match.
+CREATE TABLE tournament_matches (
+    id               INTEGER   PRIMARY KEY,
+    tournament_id    BIGINT    NOT NULL REFERENCES tournaments(id),
+    round            INT       NOT NULL,
+    slot             INT       NOT NULL,
+    player1_id       BIGINT    REFERENCES players(id),
+    player2_id       BIGINT    REFERENCES players(id),
+    match_id         BIGINT    REFERENCES matches(id),
+    winner_player_id BIGINT    REFERENCES players(id),
+    UNIQUE (tournament_id, round, slot)
+);
+
+CREATE UNIQUE INDEX tournament_matches_match_id_idx ON tournament_matches (match_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS tournament_matches;
+DROP TABLE IF EXISTS tournament_entries;
+DROP TABLE IF EXISTS tournaments;
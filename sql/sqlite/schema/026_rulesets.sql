@@ -0,0 +1,120 @@
+-- +goose Up
+-- The rules a match is played under. Every unit plays at level_cap, with
+-- its stats scaled by level_cap / 50. turn_timer_seconds is how long a
+-- player has to act before the opponent can claim the match; 0 means no
+-- limit. Turns alternate between the players, or in 'simultaneous' mode
+-- both choose an action each round and the faster unit acts first.
+CREATE TABLE rulesets (
+    id                         INTEGER     PRIMARY KEY,
+    name                       TEXT        NOT NULL UNIQUE,
+    min_squad_size             INT         NOT NULL,
+    max_squad_size             INT         NOT NULL,
+    level_cap                  INT         NOT NULL,
+    damage_model               TEXT        NOT NULL CHECK (damage_model IN ('standard', 'fixed')),
+    turn_timer_seconds         INT         NOT NULL,
+    turn_mode                  TEXT        NOT NULL CHECK (turn_mode IN ('alternating', 'simultaneous')),
+    switching_enabled          BOOLEAN     NOT NULL,
+    items_enabled              BOOLEAN     NOT NULL,
+    type_effectiveness_enabled BOOLEAN     NOT NULL,
+    created_at                 TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at                 TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- The rules every match was played under so far
+INSERT INTO rulesets (
+    id, name, min_squad_size, max_squad_size, level_cap, damage_model,
+    turn_timer_seconds, turn_mode, switching_enabled, items_enabled,
+    type_effectiveness_enabled
+) VALUES (
+    1, 'Standard', 1, 3, 50, 'standard', 0, 'alternating', TRUE, TRUE, TRUE
+);
+
+-- SQLite can't add a column that references another table with a non-NULL
+-- default, so ruleset_id is not a foreign key here
+ALTER TABLE matches
+ADD COLUMN ruleset_id BIGINT NOT NULL DEFAULT 1;
+-- When the current actor (or, in simultaneous mode, the round) started,
+-- for the turn timer
+ALTER TABLE matches
+ADD COLUMN turn_started_at TIMESTAMP;
+
+ALTER TABLE challenges
+ADD COLUMN ruleset_id BIGINT NOT NULL DEFAULT 1;
+
+ALTER TABLE tournaments
+ADD COLUMN ruleset_id BIGINT NOT NULL DEFAULT 1;
+
+-- The action a side has chosen for the current round of a simultaneous
+-- match: a move, or the bench position to switch to. Not a foreign key so
+-- the down migration can drop it.
+ALTER TABLE match_sides
+ADD COLUMN pending_move_id BIGINT;
+ALTER TABLE match_sides
+ADD COLUMN pending_switch_to INT;
+
+-- Turns are now 'move' or 'switch'. A switch has no move; its acting unit
+-- is the one leaving and its target the one coming in. SQLite can't drop
+-- NOT NULL from move_id, so the table is rebuilt.
+CREATE TABLE match_turns_new (
+    id                   INTEGER   PRIMARY KEY,
+    match_id             BIGINT    NOT NULL REFERENCES matches(id),
+    turn_number          INT       NOT NULL,
+    acting_player_id     BIGINT    NOT NULL REFERENCES players(id),
+    acting_match_unit_id BIGINT    NOT NULL REFERENCES match_units(id),
+    move_id              BIGINT    REFERENCES moves(id),
+    target_match_unit_id BIGINT    NOT NULL REFERENCES match_units(id),
+    damage_done          INT       NOT NULL,
+    target_hp_after      INT       NOT NULL,
+    did_ko_target        BOOLEAN   NOT NULL DEFAULT FALSE,
+    created_at           TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    action               TEXT      NOT NULL DEFAULT 'move'
+);
+INSERT INTO match_turns_new (
+    id, match_id, turn_number, acting_player_id, acting_match_unit_id, move_id,
+    target_match_unit_id, damage_done, target_hp_after, did_ko_target, created_at
+)
+SELECT
+    id, match_id, turn_number, acting_player_id, acting_match_unit_id, move_id,
+    target_match_unit_id, damage_done, target_hp_after, did_ko_target, created_at
+FROM match_turns;
+DROP TABLE match_turns;
+ALTER TABLE match_turns_new RENAME TO match_turns;
+
+-- +goose Down
+CREATE TABLE match_turns_old (
+    id                   INTEGER   PRIMARY KEY,
+    match_id             BIGINT    NOT NULL REFERENCES matches(id),
+    turn_number          INT       NOT NULL,
+    acting_player_id     BIGINT    NOT NULL REFERENCES players(id),
+    acting_match_unit_id BIGINT    NOT NULL REFERENCES match_units(id),
+    move_id              BIGINT    NOT NULL REFERENCES moves(id),
+    target_match_unit_id BIGINT    NOT NULL REFERENCES match_units(id),
+    damage_done          INT       NOT NULL,
+    target_hp_after      INT       NOT NULL,
+    did_ko_target        BOOLEAN   NOT NULL DEFAULT FALSE,
+    created_at           TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+INSERT INTO match_turns_old (
+    id, match_id, turn_number, acting_player_id, acting_match_unit_id, move_id,
+    target_match_unit_id, damage_done, target_hp_after, did_ko_target, created_at
+)
+SELECT
+    id, match_id, turn_number, acting_player_id, acting_match_unit_id, move_id,
+    target_match_unit_id, damage_done, target_hp_after, did_ko_target, created_at
+FROM match_turns
+WHERE move_id IS NOT NULL;
+DROP TABLE match_turns;
+ALTER TABLE match_turns_old RENAME TO match_turns;
+ALTER TABLE match_sides
+DROP COLUMN pending_switch_to;
+ALTER TABLE match_sides
+DROP COLUMN pending_move_id;
+ALTER TABLE tournaments
+DROP COLUMN ruleset_id;
+ALTER TABLE challenges
+DROP COLUMN ruleset_id;
+ALTER TABLE matches
+DROP COLUMN turn_started_at;
+ALTER TABLE matches
+DROP COLUMN ruleset_id;
+DROP TABLE IF EXISTS rulesets;
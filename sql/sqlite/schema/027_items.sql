@@ -0,0 +1,60 @@
+-- +goose Up
+-- Items admins define for squads to carry. A 'held' item is equipped by one
+-- squad unit and works passively; a 'consumable' goes in a squad's bag and
+-- is used up as a turn action. amount is a percentage: of attack or speed
+-- for boosts, of max HP for heals.
+CREATE TABLE items (
+    id         INTEGER   PRIMARY KEY,
+    name       TEXT      NOT NULL,
+    kind       TEXT      NOT NULL CHECK (kind IN ('held', 'consumable')),
+    effect     TEXT      NOT NULL,
+    amount     INT       NOT NULL,
+    deleted_at TIMESTAMP
+);
+
+-- SQLite can't drop a column that is a foreign key, so the columns added to
+-- existing tables here don't reference items
+ALTER TABLE squad_units
+ADD COLUMN held_item_id BIGINT;
+
+-- The consumables a squad takes into every match it plays
+CREATE TABLE squad_bag_items (
+    squad_id BIGINT NOT NULL REFERENCES squads(id),
+    item_id  BIGINT NOT NULL REFERENCES items(id),
+    quantity INT    NOT NULL,
+    PRIMARY KEY (squad_id, item_id)
+);
+
+-- Held items and bags are copied when a match starts, so squad edits never
+-- reach a running match. quantity is what is left.
+ALTER TABLE match_units
+ADD COLUMN held_item_id BIGINT;
+
+CREATE TABLE match_side_items (
+    match_side_id BIGINT NOT NULL REFERENCES match_sides(id),
+    item_id       BIGINT NOT NULL REFERENCES items(id),
+    quantity      INT    NOT NULL,
+    PRIMARY KEY (match_side_id, item_id)
+);
+
+ALTER TABLE match_sides
+ADD COLUMN pending_item_id BIGINT;
+
+-- 'item' turns use a consumable on the acting unit, or record a held item
+-- taking effect. Either way the unit is both actor and target.
+ALTER TABLE match_turns
+ADD COLUMN item_id BIGINT;
+
+-- +goose Down
+DELETE FROM match_turns WHERE action = 'item';
+ALTER TABLE match_turns
+DROP COLUMN item_id;
+ALTER TABLE match_sides
+DROP COLUMN pending_item_id;
+DROP TABLE IF EXISTS match_side_items;
+ALTER TABLE match_units
+DROP COLUMN held_item_id;
+DROP TABLE IF EXISTS squad_bag_items;
+ALTER TABLE squad_units
+DROP COLUMN held_item_id;
+DROP TABLE IF EXISTS items;
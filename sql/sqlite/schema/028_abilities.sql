@@ -0,0 +1,28 @@
+-- +goose Up
+-- The key of a unit's passive ability, as registered in the game engine.
+-- An empty key means the unit has none.
+ALTER TABLE units
+ADD COLUMN ability TEXT NOT NULL DEFAULT '';
+
+-- Percentages abilities have added to a match unit's attack and speed,
+-- negative when lowered. They last until the unit is switched out.
+ALTER TABLE match_units
+ADD COLUMN attack_bonus INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE match_units
+ADD COLUMN speed_bonus INTEGER NOT NULL DEFAULT 0;
+
+-- 'ability' turns record an ability triggering: the acting unit has the
+-- ability and the target is the unit it affected.
+ALTER TABLE match_turns
+ADD COLUMN ability TEXT;
+
+-- +goose Down
+DELETE FROM match_turns WHERE action = 'ability';
+ALTER TABLE match_turns
+DROP COLUMN ability;
+ALTER TABLE match_units
+DROP COLUMN speed_bonus;
+ALTER TABLE match_units
+DROP COLUMN attack_bonus;
+ALTER TABLE units
+DROP COLUMN ability;
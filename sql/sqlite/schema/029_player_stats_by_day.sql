@@ -0,0 +1,69 @@
+-- +goose Up
+-- Units fielded and moves used are kept per UTC day, like the other player
+-- totals, so they can be reported for a range of days. The tables are
+-- rebuilt from the match history. Days are stored as midnight UTC
+-- timestamps, like player_daily_stats.day.
+DROP TABLE player_unit_stats;
+DROP TABLE player_move_stats;
+
+CREATE TABLE player_unit_stats (
+    player_id       BIGINT  NOT NULL REFERENCES players(id),
+    day             DATE    NOT NULL,
+    unit_id         BIGINT  NOT NULL REFERENCES units(id),
+    matches_fielded INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (player_id, day, unit_id)
+);
+
+CREATE TABLE player_move_stats (
+    player_id BIGINT  NOT NULL REFERENCES players(id),
+    day       DATE    NOT NULL,
+    move_id   BIGINT  NOT NULL REFERENCES moves(id),
+    uses      INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (player_id, day, move_id)
+);
+
+INSERT INTO player_unit_stats (player_id, day, unit_id, matches_fielded)
+SELECT ms.player_id, date(m.completed_at) || ' 00:00:00+00:00', mu.unit_id, COUNT(DISTINCT m.id)
+FROM match_units mu
+JOIN match_sides ms ON ms.id = mu.match_side_id
+JOIN matches m ON m.id = ms.match_id
+WHERE m.state = 'COMPLETED'
+GROUP BY ms.player_id, date(m.completed_at) || ' 00:00:00+00:00', mu.unit_id;
+
+INSERT INTO player_move_stats (player_id, day, move_id, uses)
+SELECT acting_player_id, date(created_at) || ' 00:00:00+00:00', move_id, COUNT(*)
+FROM match_turns
+WHERE move_id IS NOT NULL
+GROUP BY acting_player_id, date(created_at) || ' 00:00:00+00:00', move_id;
+
+-- +goose Down
+DROP TABLE player_unit_stats;
+DROP TABLE player_move_stats;
+
+CREATE TABLE player_unit_stats (
+    player_id       BIGINT  NOT NULL REFERENCES players(id),
+    unit_id         BIGINT  NOT NULL REFERENCES units(id),
+    matches_fielded INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (player_id, unit_id)
+);
+
+CREATE TABLE player_move_stats (
+    player_id BIGINT  NOT NULL REFERENCES players(id),
+    move_id   BIGINT  NOT NULL REFERENCES moves(id),
+    uses      INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (player_id, move_id)
+);
+
+INSERT INTO player_unit_stats (player_id, unit_id, matches_fielded)
+SELECT ms.player_id, mu.unit_id, COUNT(DISTINCT m.id)
+FROM match_units mu
+JOIN match_sides ms ON ms.id = mu.match_side_id
+JOIN matches m ON m.id = ms.match_id
+WHERE m.state = 'COMPLETED'
+GROUP BY ms.player_id, mu.unit_id;
+
+INSERT INTO player_move_stats (player_id, move_id, uses)
+SELECT acting_player_id, move_id, COUNT(*)
+FROM match_turns
+WHERE move_id IS NOT NULL
+GROUP BY acting_player_id, move_id;
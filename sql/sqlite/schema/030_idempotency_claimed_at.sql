@@ -0,0 +1,13 @@
+-- +goose Up
+-- When the key was last claimed. A key still in flight (status_code 0) long
+-- after its claim belongs to a request that died without releasing it, and
+-- can be claimed again. SQLite can't add a column defaulting to the current
+-- time, so CreateIdempotencyKey always sets it.
+ALTER TABLE idempotency_keys
+ADD COLUMN claimed_at TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00';
+
+UPDATE idempotency_keys SET claimed_at = created_at;
+
+-- +goose Down
+ALTER TABLE idempotency_keys
+DROP COLUMN claimed_at;
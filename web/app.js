@@ -15,11 +15,58 @@ function appendLogEntry(text) {
   logEl.scrollTop = logEl.scrollHeight;
 }
 
+// errorText formats an error response body ({"error": {"code", "message"}}).
+async function errorText(res) {
+  const text = await res.text();
+  try {
+    const { error } = JSON.parse(text);
+    const problems = error.details?.problems ?? [];
+    const extra = problems.map((p) => `${p.field}: ${p.message}`).join("; ");
+    return `${error.code}: ${error.message}${extra ? ` (${extra})` : ""}`;
+  } catch {
+    return text;
+  }
+}
+
+const POST_ATTEMPTS = 3;
+
+// postIdempotent POSTs body to path as the current player. The
+// Idempotency-Key is made once per call, i.e. per user action, and reused
+// when the request is retried after a network or server error, so the
+// action takes effect at most once however many attempts reach the server.
+async function postIdempotent(path, body) {
+  const key = crypto.randomUUID();
+  for (let attempt = 1; ; attempt++) {
+    try {
+      const res = await fetch(`${API_BASE}${path}`, {
+        method: "POST",
+        headers: {
+          "Content-Type": "application/json",
+          "X-Player-ID": String(currentPlayerId),
+          "Idempotency-Key": key,
+        },
+        body: JSON.stringify(body),
+      });
+      if (res.status < 500 || attempt === POST_ATTEMPTS) {
+        return res;
+      }
+    } catch (err) {
+      if (attempt === POST_ATTEMPTS) {
+        throw err;
+      }
+    }
+    await new Promise((resolve) => setTimeout(resolve, 250 * 2 ** attempt));
+  }
+}
+
 async function fetchMatch() {
   if (!currentMatchId) {
     throw new Error("No match selected");
   }
-  const res = await fetch(`${API_BASE}/matches/${currentMatchId}`);
+  const headers = currentPlayerId
+    ? { "X-Player-ID": String(currentPlayerId) }
+    : {};
+  const res = await fetch(`${API_BASE}/matches/${currentMatchId}`, { headers });
   if (!res.ok) {
     throw new Error(`Failed to fetch match: ${res.status}`);
   }
@@ -79,7 +126,7 @@ Current actor: ${turnInfo}
       currentHPs.set(u.match_unit_id, u.current_hp);
 
       const li = document.createElement("li");
-      li.textContent = `Unit ${u.unit_id} [match_unit ${u.match_unit_id}] pos=${u.position} HP=${u.current_hp}`;
+      li.textContent = `${u.name} (${u.type_name}) [match_unit ${u.match_unit_id}] pos=${u.position} HP=${u.current_hp}/${u.max_hp}`;
 
       if (u.is_active && Array.isArray(u.moves) && u.moves.length > 0) {
         const movesContainer = document.createElement("div");
@@ -93,7 +140,7 @@ Current actor: ${turnInfo}
 
         u.moves.forEach((mv) => {
           const btn = document.createElement("button");
-          btn.textContent = `${mv.name} (id=${mv.id}, pow=${mv.power})`;
+          btn.textContent = `${mv.name} (${mv.type_name}, pow=${mv.power})`;
           btn.style.marginRight = "4px";
 
           if (!isYourTurn) {
@@ -101,7 +148,7 @@ Current actor: ${turnInfo}
             btn.title = "Not your turn";
           } else {
             btn.addEventListener("click", () => {
-              playTurnWithMove(mv.id);
+              playTurnWithMove(mv.id, m.current_turn_number);
             });
           }
           movesContainer.appendChild(btn);
@@ -242,7 +289,7 @@ async function login() {
     });
 
     if (!res.ok) {
-      const text = await res.text();
+      const text = await errorText(res);
       errorEl.textContent = `Login failed: ${res.status} ${text}`;
       return;
     }
@@ -267,7 +314,7 @@ async function login() {
   }
 }
 
-async function playTurnWithMove(moveId) {
+async function playTurnWithMove(moveId, expectedTurnNumber) {
   const errorEl = document.getElementById("error");
   const statusEl = document.getElementById("status");
   errorEl.textContent = "";
@@ -284,17 +331,13 @@ async function playTurnWithMove(moveId) {
   }
 
   try {
-    const res = await fetch(`${API_BASE}/matches/${currentMatchId}/turns`, {
-      method: "POST",
-      headers: {
-        "Content-Type": "application/json",
-        "X-Player-ID": String(currentPlayerId),
-      },
-      body: JSON.stringify({ move_id: moveId }),
+    const res = await postIdempotent(`/matches/${currentMatchId}/turns`, {
+      move_id: moveId,
+      expected_turn_number: expectedTurnNumber,
     });
 
     if (!res.ok) {
-      const text = await res.text();
+      const text = await errorText(res);
       errorEl.textContent = `Error: ${res.status} ${text}`;
       return;
     }
@@ -344,7 +387,8 @@ async function fetchMyMatches() {
   if (!res.ok) {
     throw new Error(`Failed to fetch matches: ${res.status}`);
   }
-  return res.json(); // array of MatchView
+  const page = await res.json(); // { matches: [MatchView], next_cursor }
+  return page.matches;
 }
 
 async function createMatch() {
@@ -372,21 +416,14 @@ async function createMatch() {
   }
 
   try {
-    const res = await fetch(`${API_BASE}/matches`, {
-      method: "POST",
-      headers: {
-        "Content-Type": "application/json",
-        "X-Player-ID": String(currentPlayerId),
-      },
-      body: JSON.stringify({
-        opponent_player_id: opponentId,
-        player1_squad_id: squad1Id,
-        player2_squad_id: squad2Id,
-      }),
+    const res = await postIdempotent("/matches", {
+      opponent_player_id: opponentId,
+      player1_squad_id: squad1Id,
+      player2_squad_id: squad2Id,
     });
 
     if (!res.ok) {
-      const text = await res.text();
+      const text = await errorText(res);
       errorEl.textContent = `Create match failed: ${res.status} ${text}`;
       return;
     }
@@ -413,12 +450,36 @@ async function fetchUnits() {
   return res.json(); // [{id, name, ...}]
 }
 
+async function fetchUnit(unitId) {
+  const res = await fetch(`${API_BASE}/units/${unitId}`);
+  if (!res.ok) {
+    throw new Error(`Failed to fetch unit ${unitId}: ${res.status}`);
+  }
+  return res.json(); // {id, name, type_name, ..., moves: [...]}
+}
+
 function renderUnits(units) {
   const listEl = document.getElementById("unit-list");
   listEl.innerHTML = "";
   units.forEach((u) => {
     const li = document.createElement("li");
-    li.textContent = `ID=${u.id} Name=${u.name} HP=${u.base_hp} ATK=${u.base_attack} SPD=${u.base_speed}`;
+    li.textContent = `ID=${u.id} Name=${u.name} Type=${u.type_name} HP=${u.base_hp} ATK=${u.base_attack} SPD=${u.base_speed}`;
+    li.title = "Click to show moves";
+    li.style.cursor = "pointer";
+    // Show the learnset on first click so players can compare units before building a squad
+    li.addEventListener("click", async () => {
+      if (li.dataset.loaded) return;
+      try {
+        const unit = await fetchUnit(u.id);
+        const moves = unit.moves
+          .map((m) => `${m.name} (${m.type_name}, POW ${m.power}, ACC ${m.accuracy})`)
+          .join(", ");
+        li.textContent += ` | Moves: ${moves || "none"}`;
+        li.dataset.loaded = "true";
+      } catch (err) {
+        li.textContent += ` | ${err.message}`;
+      }
+    });
     listEl.appendChild(li);
   });
 }
@@ -485,20 +546,13 @@ async function createSquad() {
   }
 
   try {
-    const res = await fetch(`${API_BASE}/me/squads`, {
-      method: "POST",
-      headers: {
-        "Content-Type": "application/json",
-        "X-Player-ID": String(currentPlayerId),
-      },
-      body: JSON.stringify({
-        name: name,
-        unit_ids: unitIds,
-      }),
+    const res = await postIdempotent("/me/squads", {
+      name: name,
+      unit_ids: unitIds,
     });
 
     if (!res.ok) {
-      const text = await res.text();
+      const text = await errorText(res);
       errorEl.textContent = `Create squad failed: ${res.status} ${text}`;
       return;
     }
@@ -557,7 +611,7 @@ async function signup() {
     });
 
     if (!res.ok) {
-      const text = await res.text();
+      const text = await errorText(res);
       errorEl.textContent = `Signup failed: ${res.status} ${text}`;
       return;
     }
@@ -625,7 +679,7 @@ async function adminCreateUnit() {
     });
 
     if (!res.ok) {
-      const text = await res.text();
+      const text = await errorText(res);
       errorEl.textContent = `Create unit failed: ${res.status} ${text}`;
       return;
     }
@@ -698,7 +752,7 @@ async function adminCreateMove() {
     });
 
     if (!res.ok) {
-      const text = await res.text();
+      const text = await errorText(res);
       errorEl.textContent = `Create move failed: ${res.status} ${text}`;
       return;
     }